@@ -1,6 +1,7 @@
 package baseapp
 
 import (
+	"context"
 	"crypto/sha256"
 	"errors"
 	"fmt"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	snapshottypes "github.com/cosmos/cosmos-sdk/snapshots/types"
+	"github.com/cosmos/cosmos-sdk/store/tracekv"
 	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
@@ -61,6 +63,14 @@ func (app *BaseApp) InitChain(req abci.RequestInitChain) (res abci.ResponseInitC
 
 	res = app.initChainer(app.deliverState.ctx, req)
 
+	// The gas configs set in genesis apply from the first block, which is
+	// executed on the deliver state of InitChain.
+	if configs := app.loadStoreGasConfigs(app.deliverState.ctx); configs != nil {
+		app.deliverState.ms = app.deliverState.ms.WithGasConfigs(configs)
+		app.deliverState.ctx = app.deliverState.ctx.WithMultiStore(app.deliverState.ms)
+		app.setCheckState(initHeader)
+	}
+
 	// sanity check
 	if len(req.Validators) > 0 {
 		if len(req.Validators) != len(res.Validators) {
@@ -146,9 +156,10 @@ func (app *BaseApp) BeginBlock(req abci.RequestBeginBlock) (res abci.ResponseBeg
 
 	if app.cms.TracingEnabled() {
 		app.cms.SetTracingContext(sdk.TraceContext(
-			map[string]interface{}{"blockHeight": req.Header.Height},
+			map[string]interface{}{tracekv.TraceContextBlockHeight: req.Header.Height},
 		))
 	}
+	app.deliverTxIndex = 0
 
 	if err := app.validateHeight(req); err != nil {
 		panic(err)
@@ -195,6 +206,20 @@ func (app *BaseApp) BeginBlock(req abci.RequestBeginBlock) (res abci.ResponseBeg
 	}
 	// set the signed validators for addition to context in deliverTx
 	app.voteInfos = req.LastCommitInfo.GetVotes()
+
+	app.rebaseOptimisticExecution(req.Hash)
+	app.executeAcceptedProposal(req.Hash)
+
+	// call the hooks with the BeginBlock messages
+	for _, streamingListener := range app.abciListeners {
+		if err := streamingListener.ListenBeginBlock(app.deliverState.ctx, req, res); err != nil {
+			app.logger.Error("BeginBlock listening hook failed", "height", req.Header.Height, "err", err)
+			if streamingListener.HaltAppOnDeliveryError() {
+				app.halt()
+			}
+		}
+	}
+
 	return res
 }
 
@@ -202,8 +227,16 @@ func (app *BaseApp) BeginBlock(req abci.RequestBeginBlock) (res abci.ResponseBeg
 func (app *BaseApp) EndBlock(req abci.RequestEndBlock) (res abci.ResponseEndBlock) {
 	defer telemetry.MeasureSince(time.Now(), "abci", "end_block")
 
+	app.abortOptimisticExecution()
+
 	if app.deliverState.ms.TracingEnabled() {
-		app.deliverState.ms = app.deliverState.ms.SetTracingContext(nil).(sdk.CacheMultiStore)
+		app.deliverState.ms = app.deliverState.ms.SetTracingContext(sdk.TraceContext(
+			map[string]interface{}{
+				tracekv.TraceContextTxIndex:  nil,
+				tracekv.TraceContextTxHash:   nil,
+				tracekv.TraceContextMsgIndex: nil,
+			},
+		)).(sdk.CacheMultiStore)
 	}
 
 	if app.endBlocker != nil {
@@ -215,6 +248,16 @@ func (app *BaseApp) EndBlock(req abci.RequestEndBlock) (res abci.ResponseEndBloc
 		res.ConsensusParamUpdates = cp
 	}
 
+	// call the streaming service hooks with the EndBlock messages
+	for _, streamingListener := range app.abciListeners {
+		if err := streamingListener.ListenEndBlock(app.deliverState.ctx, req, res); err != nil {
+			app.logger.Error("EndBlock listening hook failed", "height", req.Height, "err", err)
+			if streamingListener.HaltAppOnDeliveryError() {
+				app.halt()
+			}
+		}
+	}
+
 	return res
 }
 
@@ -262,7 +305,24 @@ func (app *BaseApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
 func (app *BaseApp) DeliverTx(req abci.RequestDeliverTx) abci.ResponseDeliverTx {
 	defer telemetry.MeasureSince(time.Now(), "abci", "deliver_tx")
 
-	gInfo := sdk.GasInfo{}
+	if app.deliverState.ms.TracingEnabled() {
+		app.deliverState.ms = app.deliverState.ms.SetTracingContext(sdk.TraceContext(
+			map[string]interface{}{tracekv.TraceContextTxIndex: app.deliverTxIndex},
+		)).(sdk.CacheMultiStore)
+	}
+	app.deliverTxIndex++
+
+	if e, ok := app.deliverTxOptimistic(req.Tx); ok {
+		return app.deliverTxResponse(req, e.gInfo, e.result, e.err)
+	}
+
+	gInfo, result, err := app.runTx(runTxModeDeliver, req.Tx)
+	return app.deliverTxResponse(req, gInfo, result, err)
+}
+
+// deliverTxResponse builds the ResponseDeliverTx of an executed tx, records
+// its telemetry and passes it to the streaming service hooks.
+func (app *BaseApp) deliverTxResponse(req abci.RequestDeliverTx, gInfo sdk.GasInfo, result *sdk.Result, err error) (res abci.ResponseDeliverTx) {
 	resultStr := "successful"
 
 	defer func() {
@@ -272,7 +332,18 @@ func (app *BaseApp) DeliverTx(req abci.RequestDeliverTx) abci.ResponseDeliverTx
 		telemetry.SetGauge(float32(gInfo.GasWanted), "tx", "gas", "wanted")
 	}()
 
-	gInfo, result, err := app.runTx(runTxModeDeliver, req.Tx)
+	defer func() {
+		// call the hooks with the DeliverTx messages
+		for _, streamingListener := range app.abciListeners {
+			if err := streamingListener.ListenDeliverTx(app.deliverState.ctx, req, res); err != nil {
+				app.logger.Error("DeliverTx listening hook failed", "err", err)
+				if streamingListener.HaltAppOnDeliveryError() {
+					app.halt()
+				}
+			}
+		}
+	}()
+
 	if err != nil {
 		resultStr = "failed"
 		return sdkerrors.ResponseDeliverTx(err, gInfo.GasWanted, gInfo.GasUsed, app.trace)
@@ -306,6 +377,25 @@ func (app *BaseApp) Commit() (res abci.ResponseCommit) {
 	app.deliverState.ms.Write()
 	commitID := app.cms.Commit()
 	app.logger.Info("commit synced", "commit", fmt.Sprintf("%X", commitID))
+	app.lastBlockTime.Store(header.Time)
+
+	// Changes of the StoreGasConfigs parameter apply from the next block on.
+	app.loadStoreGasConfigs(app.deliverState.ctx)
+
+	res = abci.ResponseCommit{
+		Data:         commitID.Hash,
+		RetainHeight: retainHeight,
+	}
+
+	// call the streaming service hooks with the Commit messages
+	for _, streamingListener := range app.abciListeners {
+		if err := streamingListener.ListenCommit(app.deliverState.ctx, res); err != nil {
+			app.logger.Error("Commit listening hook failed", "height", header.Height, "err", err)
+			if streamingListener.HaltAppOnDeliveryError() {
+				app.halt()
+			}
+		}
+	}
 
 	// Reset the Check state to the latest committed.
 	//
@@ -313,16 +403,22 @@ func (app *BaseApp) Commit() (res abci.ResponseCommit) {
 	// Commit. Use the header from this latest block.
 	app.setCheckState(header)
 
-	// empty/reset the deliver state
+	// empty/reset the deliver and proposal states
 	app.deliverState = nil
+	app.prepareProposalState = nil
+	app.processProposalState = nil
 
 	var halt bool
 
+	app.runtimeMtx.RLock()
+	haltHeight, haltTime := app.haltHeight, app.haltTime
+	app.runtimeMtx.RUnlock()
+
 	switch {
-	case app.haltHeight > 0 && uint64(header.Height) >= app.haltHeight:
+	case haltHeight > 0 && uint64(header.Height) >= haltHeight:
 		halt = true
 
-	case app.haltTime > 0 && header.Time.Unix() >= int64(app.haltTime):
+	case haltTime > 0 && header.Time.Unix() >= int64(haltTime):
 		halt = true
 	}
 
@@ -338,16 +434,15 @@ func (app *BaseApp) Commit() (res abci.ResponseCommit) {
 		go app.snapshot(header.Height)
 	}
 
-	return abci.ResponseCommit{
-		Data:         commitID.Hash,
-		RetainHeight: retainHeight,
-	}
+	return res
 }
 
 // halt attempts to gracefully shutdown the node via SIGINT and SIGTERM falling
 // back on os.Exit if both fail.
 func (app *BaseApp) halt() {
+	app.runtimeMtx.RLock()
 	app.logger.Info("halting node per configuration", "height", app.haltHeight, "time", app.haltTime)
+	app.runtimeMtx.RUnlock()
 
 	p, err := os.FindProcess(os.Getpid())
 	if err == nil {
@@ -396,23 +491,71 @@ func (app *BaseApp) snapshot(height int64) {
 	}
 }
 
-// PreprocessTxs fullfills the celestia-core version of the ACBI interface. It
-// allows for arbitrary processing steps before transaction data is included in
-// the block.
-func (app *BaseApp) PrepareProposal(req abci.RequestPrepareProposal) abci.ResponsePrepareProposal {
-	// TODO(evan): fully implement
-	// pass through txs w/o processing for now
-	return abci.ResponsePrepareProposal{
-		BlockData: req.BlockData,
+// PrepareProposal fulfills the celestia-core version of the ABCI++ interface.
+// It allows the proposer to modify the block data, e.g. to drop invalid
+// transactions, before it is included in a block proposal. The configured
+// PrepareProposalHandler is executed against a branch of the latest committed
+// state, which is discarded afterwards.
+func (app *BaseApp) PrepareProposal(req abci.RequestPrepareProposal) (res abci.ResponsePrepareProposal) {
+	defer telemetry.MeasureSince(time.Now(), "abci", "prepare_proposal")
+
+	if app.prepareProposal == nil {
+		return abci.ResponsePrepareProposal{BlockData: req.BlockData}
 	}
+
+	header := app.checkState.ctx.BlockHeader()
+	header.Height = app.LastBlockHeight() + 1
+
+	app.setPrepareProposalState(header)
+	ctx := app.prepareProposalState.ctx
+	ctx = ctx.WithConsensusParams(app.GetConsensusParams(ctx))
+
+	defer func() {
+		if r := recover(); r != nil {
+			app.logger.Error("panic recovered in PrepareProposal", "height", header.Height, "panic", r)
+			res = abci.ResponsePrepareProposal{BlockData: req.BlockData}
+		}
+	}()
+
+	return app.prepareProposal(ctx, req)
 }
 
 // ProcessProposal fulfills the celestia-core version of the ABCI++ interface.
-// It allows for arbitrary processing to occur after recieving a proposal block
-func (app *BaseApp) ProcessProposal(req abci.RequestProcessProposal) abci.ResponseProcessProposal {
-	return abci.ResponseProcessProposal{
-		Result: abci.ResponseProcessProposal_ACCEPT,
+// It allows for arbitrary processing to occur after recieving a proposal block.
+// The configured ProcessProposalHandler is executed against a branch of the
+// latest committed state, which is discarded afterwards. A panic in the
+// handler results in the proposal being rejected. If optimistic execution is
+// enabled, the txs of an accepted proposal are executed in the background, see
+// SetOptimisticExecution.
+func (app *BaseApp) ProcessProposal(req abci.RequestProcessProposal) (res abci.ResponseProcessProposal) {
+	defer telemetry.MeasureSince(time.Now(), "abci", "process_proposal")
+
+	// a new proposal supersedes the proposal of a previous round
+	app.abortOptimisticExecution()
+	app.acceptedProposal = nil
+	defer func() {
+		if res.Result == abci.ResponseProcessProposal_ACCEPT {
+			app.startOptimisticExecution(req)
+			app.setAcceptedProposal(req)
+		}
+	}()
+
+	if app.processProposal == nil {
+		return abci.ResponseProcessProposal{Result: abci.ResponseProcessProposal_ACCEPT}
 	}
+
+	app.setProcessProposalState(req.Header)
+	ctx := app.processProposalState.ctx
+	ctx = ctx.WithConsensusParams(app.GetConsensusParams(ctx))
+
+	defer func() {
+		if r := recover(); r != nil {
+			app.logger.Error("panic recovered in ProcessProposal", "height", req.Header.Height, "panic", r)
+			res = abci.ResponseProcessProposal{Result: abci.ResponseProcessProposal_REJECT}
+		}
+	}()
+
+	return app.processProposal(ctx, req)
 }
 
 // Query implements the ABCI interface. It delegates to CommitMultiStore if it
@@ -562,9 +705,13 @@ func (app *BaseApp) ApplySnapshotChunk(req abci.RequestApplySnapshotChunk) abci.
 		return abci.ResponseApplySnapshotChunk{Result: abci.ResponseApplySnapshotChunk_ABORT}
 	}
 
-	_, err := app.snapshotManager.RestoreChunk(req.Chunk)
+	done, err := app.snapshotManager.RestoreChunk(req.Chunk)
 	switch {
 	case err == nil:
+		// the gas configs of the restored state apply to the next block
+		if done {
+			app.loadStoreGasConfigs(sdk.NewContext(app.cms.CacheMultiStore(), tmproto.Header{}, false, app.logger))
+		}
 		return abci.ResponseApplySnapshotChunk{Result: abci.ResponseApplySnapshotChunk_ACCEPT}
 
 	case errors.Is(err, snapshottypes.ErrChunkHashMismatch):
@@ -586,13 +733,20 @@ func (app *BaseApp) ApplySnapshotChunk(req abci.RequestApplySnapshotChunk) abci.
 	}
 }
 
-func (app *BaseApp) handleQueryGRPC(handler GRPCQueryHandler, req abci.RequestQuery) abci.ResponseQuery {
+func (app *BaseApp) handleQueryGRPC(handler GRPCQueryHandler, req abci.RequestQuery) (res abci.ResponseQuery) {
 	ctx, err := app.createQueryContext(req.Height, req.Prove)
 	if err != nil {
 		return sdkerrors.QueryResult(err)
 	}
 
-	res, err := handler(ctx, req)
+	defer func() {
+		if r := recover(); r != nil {
+			res = sdkerrors.QueryResult(queryPanicToError(r, ctx.GasMeter()))
+			res.Height = req.Height
+		}
+	}()
+
+	res, err = handler(ctx, req)
 	if err != nil {
 		res = sdkerrors.QueryResult(gRPCErrorToSDKError(err))
 		res.Height = req.Height
@@ -665,11 +819,68 @@ func (app *BaseApp) createQueryContext(height int64, prove bool) (sdk.Context, e
 	// branch the commit-multistore for safety
 	ctx := sdk.NewContext(
 		cacheMS, app.checkState.ctx.BlockHeader(), true, app.logger,
-	).WithMinGasPrices(app.minGasPrices)
+	).WithMinGasPrices(app.getMinGasPrices())
+
+	app.runtimeMtx.RLock()
+	queryGasLimit := app.queryGasLimit
+	app.runtimeMtx.RUnlock()
+
+	if queryGasLimit > 0 {
+		ctx = ctx.WithGasMeter(sdk.NewGasMeter(queryGasLimit))
+	}
 
 	return ctx, nil
 }
 
+// queryCanceled is the panic value raised by a cancelableGasMeter when the
+// context of the query it meters is done.
+type queryCanceled struct {
+	err error
+}
+
+// cancelableGasMeter wraps the gas meter of a query and aborts the query as
+// soon as the client's context is canceled or its deadline is exceeded. As
+// every store access consumes gas, long running queries are interrupted at
+// the next read.
+type cancelableGasMeter struct {
+	sdk.GasMeter
+	ctx context.Context
+}
+
+func newCancelableGasMeter(ctx context.Context, gm sdk.GasMeter) sdk.GasMeter {
+	return &cancelableGasMeter{GasMeter: gm, ctx: ctx}
+}
+
+// ConsumeGas implements the GasMeter interface. It panics with queryCanceled
+// if the context is done.
+func (gm *cancelableGasMeter) ConsumeGas(amount sdk.Gas, descriptor string) {
+	if err := gm.ctx.Err(); err != nil {
+		panic(queryCanceled{err})
+	}
+
+	gm.GasMeter.ConsumeGas(amount, descriptor)
+}
+
+// queryPanicToError converts a panic raised during the execution of a query
+// into an error if it was caused by the query running out of gas or being
+// canceled. Any other panic is re-raised.
+func queryPanicToError(r interface{}, gm sdk.GasMeter) error {
+	switch e := r.(type) {
+	case sdk.ErrorOutOfGas:
+		return sdkerrors.Wrapf(
+			sdkerrors.ErrOutOfGas,
+			"query out of gas in location: %v; gasLimit: %d, gasUsed: %d",
+			e.Descriptor, gm.Limit(), gm.GasConsumed(),
+		)
+
+	case queryCanceled:
+		return e.err
+
+	default:
+		panic(r)
+	}
+}
+
 // GetBlockRetentionHeight returns the height for which all blocks below this height
 // are pruned from Tendermint. Given a commitment height and a non-zero local
 // minRetainBlocks configuration, the retentionHeight is the smallest height that
@@ -692,8 +903,12 @@ func (app *BaseApp) createQueryContext(height int64, prove bool) (sdk.Context, e
 // be a need to vary retention for other nodes, e.g. sentry nodes which do not
 // need historical blocks.
 func (app *BaseApp) GetBlockRetentionHeight(commitHeight int64) int64 {
+	app.runtimeMtx.RLock()
+	minRetainBlocks := app.minRetainBlocks
+	app.runtimeMtx.RUnlock()
+
 	// pruning is disabled if minRetainBlocks is zero
-	if app.minRetainBlocks == 0 {
+	if minRetainBlocks == 0 {
 		return 0
 	}
 
@@ -746,7 +961,7 @@ func (app *BaseApp) GetBlockRetentionHeight(commitHeight int64) int64 {
 		retentionHeight = minNonZero(retentionHeight, v)
 	}
 
-	v := commitHeight - int64(app.minRetainBlocks)
+	v := commitHeight - int64(minRetainBlocks)
 	retentionHeight = minNonZero(retentionHeight, v)
 
 	if retentionHeight <= 0 {
@@ -858,7 +1073,7 @@ func handleQueryP2P(app *BaseApp, path []string) abci.ResponseQuery {
 	return resp
 }
 
-func handleQueryCustom(app *BaseApp, path []string, req abci.RequestQuery) abci.ResponseQuery {
+func handleQueryCustom(app *BaseApp, path []string, req abci.RequestQuery) (res abci.ResponseQuery) {
 	// path[0] should be "custom" because "/custom" prefix is required for keeper
 	// queries.
 	//
@@ -878,13 +1093,20 @@ func handleQueryCustom(app *BaseApp, path []string, req abci.RequestQuery) abci.
 		return sdkerrors.QueryResult(err)
 	}
 
+	defer func() {
+		if r := recover(); r != nil {
+			res = sdkerrors.QueryResult(queryPanicToError(r, ctx.GasMeter()))
+			res.Height = req.Height
+		}
+	}()
+
 	// Passes the rest of the path as an argument to the querier.
 	//
 	// For example, in the path "custom/gov/proposal/test", the gov querier gets
 	// []string{"proposal", "test"} as the path.
 	resBytes, err := querier(ctx, path[2:], req)
 	if err != nil {
-		res := sdkerrors.QueryResult(err)
+		res = sdkerrors.QueryResult(err)
 		res.Height = req.Height
 		return res
 	}
@@ -4,8 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/gogo/protobuf/proto"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto/tmhash"
@@ -17,16 +22,20 @@ import (
 	"github.com/cosmos/cosmos-sdk/snapshots"
 	"github.com/cosmos/cosmos-sdk/store"
 	"github.com/cosmos/cosmos-sdk/store/rootmulti"
+	"github.com/cosmos/cosmos-sdk/store/tracekv"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
 )
 
 const (
-	runTxModeCheck    runTxMode = iota // Check a transaction
-	runTxModeReCheck                   // Recheck a (pending) transaction after a commit
-	runTxModeSimulate                  // Simulate a transaction
-	runTxModeDeliver                   // Deliver a transaction
+	runTxModeCheck           runTxMode = iota // Check a transaction
+	runTxModeReCheck                          // Recheck a (pending) transaction after a commit
+	runTxModeSimulate                         // Simulate a transaction
+	runTxModeDeliver                          // Deliver a transaction
+	runTxModePrepareProposal                  // Verify a transaction while preparing a block proposal
+	runTxModeProcessProposal                  // Verify a transaction while processing a block proposal
 )
 
 var (
@@ -59,13 +68,17 @@ type BaseApp struct { // nolint: maligned
 	interfaceRegistry types.InterfaceRegistry
 	txDecoder         sdk.TxDecoder // unmarshal []byte into sdk.Tx
 
-	anteHandler    sdk.AnteHandler  // ante handler for fee and auth
-	initChainer    sdk.InitChainer  // initialize state with validators and state blob
-	beginBlocker   sdk.BeginBlocker // logic to run before any txs
-	endBlocker     sdk.EndBlocker   // logic to run after all txs, and to determine valset changes
-	addrPeerFilter sdk.PeerFilter   // filter peers by address and port
-	idPeerFilter   sdk.PeerFilter   // filter peers by node ID
-	fauxMerkleMode bool             // if true, IAVL MountStores uses MountStoresDB for simulation speed.
+	anteHandler     sdk.AnteHandler            // ante handler for fee and auth
+	initChainer     sdk.InitChainer            // initialize state with validators and state blob
+	beginBlocker    sdk.BeginBlocker           // logic to run before any txs
+	endBlocker      sdk.EndBlocker             // logic to run after all txs, and to determine valset changes
+	prepareProposal sdk.PrepareProposalHandler // logic to build the block data of a proposal
+	processProposal sdk.ProcessProposalHandler // logic to accept or reject the block data of a proposal
+	addrPeerFilter  sdk.PeerFilter             // filter peers by address and port
+	idPeerFilter    sdk.PeerFilter             // filter peers by node ID
+	fauxMerkleMode  bool                       // if true, IAVL MountStores uses MountStoresDB for simulation speed.
+
+	stateOverrideHandler StateOverrideHandler // applies the state overrides of simulations
 
 	// manages snapshots, i.e. dumps of app state at certain intervals
 	snapshotManager    *snapshots.Manager
@@ -76,8 +89,12 @@ type BaseApp struct { // nolint: maligned
 	//
 	// checkState is set on InitChain and reset on Commit
 	// deliverState is set on InitChain and BeginBlock and set to nil on Commit
-	checkState   *state // for CheckTx
-	deliverState *state // for DeliverTx
+	// prepareProposalState is set on PrepareProposal and set to nil on Commit
+	// processProposalState is set on ProcessProposal and set to nil on Commit
+	checkState           *state // for CheckTx
+	deliverState         *state // for DeliverTx
+	prepareProposalState *state // for PrepareProposal
+	processProposalState *state // for ProcessProposal
 
 	// an inter-block write-through cache provided to the context during deliverState
 	interBlockCache sdk.MultiStorePersistentCache
@@ -89,6 +106,11 @@ type BaseApp struct { // nolint: maligned
 	// application parameter store.
 	paramStore ParamStore
 
+	// runtimeMtx guards the settings which can be updated while the app is
+	// running: minGasPrices, haltHeight, haltTime, minRetainBlocks and
+	// queryGasLimit.
+	runtimeMtx sync.RWMutex
+
 	// The minimum gas prices a validator is willing to accept for processing a
 	// transaction. This is mainly used for DoS and spam prevention.
 	minGasPrices sdk.DecCoins
@@ -99,6 +121,9 @@ type BaseApp struct { // nolint: maligned
 	// flag for sealing options and parameters to a BaseApp
 	sealed bool
 
+	// moduleKeys records the modules whose ModuleKey was issued
+	moduleKeys map[string]bool
+
 	// block height at which to halt the chain and gracefully shutdown
 	haltHeight uint64
 
@@ -133,6 +158,44 @@ type BaseApp struct { // nolint: maligned
 	// indexEvents defines the set of events in the form {eventType}.{attributeKey},
 	// which informs Tendermint what to index. If empty, all events will be indexed.
 	indexEvents map[string]struct{}
+
+	// abciListeners for hooking into the ABCI message processing of the BaseApp
+	// and exposing the requests and responses to external consumers
+	abciListeners []ABCIListener
+
+	// storeKeys holds the keys of all stores mounted with MountStore
+	storeKeys []sdk.StoreKey
+
+	// parallelTxWorkers is the number of workers used to execute the txs of a
+	// block in parallel. A value below 2 disables parallel execution.
+	parallelTxWorkers int
+	// acceptedProposal is the latest block proposal accepted by
+	// ProcessProposal, whose txs are executed in parallel on BeginBlock if it
+	// is finalized.
+	acceptedProposal *acceptedProposal
+
+	// optimisticExecution enables the optimistic execution of accepted block
+	// proposals during ProcessProposal.
+	optimisticExecution bool
+	// optimisticExec is the optimistic or parallel execution of the txs of
+	// the current block, if any. It is reset on EndBlock.
+	optimisticExec *optimisticExecution
+
+	// queryGasLimit defines the maximum gas a single query may consume before
+	// it is aborted. A value of 0 indicates that queries are not gas limited.
+	queryGasLimit uint64
+
+	// deliverTxIndex is the index in the block of the next tx delivered with
+	// DeliverTx, which is recorded by the store tracer.
+	deliverTxIndex int
+
+	// storeGasConfigs override the default gas configs of specific stores. The
+	// StoreGasConfigs parameter takes precedence over them.
+	storeGasConfigs map[sdk.StoreKey]sdk.GasConfig
+
+	// lastBlockTime holds the time.Time of the last block committed since the
+	// app started, and is read concurrently by the health checks.
+	lastBlockTime atomic.Value
 }
 
 // NewBaseApp returns a reference to an initialized BaseApp. It accepts a
@@ -161,6 +224,18 @@ func NewBaseApp(
 		option(app)
 	}
 
+	if app.prepareProposal == nil || app.processProposal == nil {
+		proposalHandler := NewDefaultProposalHandler(app)
+
+		if app.prepareProposal == nil {
+			app.prepareProposal = proposalHandler.PrepareProposalHandler()
+		}
+
+		if app.processProposal == nil {
+			app.processProposal = proposalHandler.ProcessProposalHandler()
+		}
+	}
+
 	if app.interBlockCache != nil {
 		app.cms.SetInterBlockCache(app.interBlockCache)
 	}
@@ -255,6 +330,7 @@ func (app *BaseApp) MountMemoryStores(keys map[string]*sdk.MemoryStoreKey) {
 // using the default DB.
 func (app *BaseApp) MountStore(key sdk.StoreKey, typ sdk.StoreType) {
 	app.cms.MountStoreWithDB(key, typ, nil)
+	app.storeKeys = append(app.storeKeys, key)
 }
 
 // LoadLatestVersion loads the latest application version. It will panic if
@@ -294,11 +370,21 @@ func (app *BaseApp) LastBlockHeight() int64 {
 	return app.cms.LastCommitID().Version
 }
 
+// LastBlockTime returns the time of the last block committed since the app
+// started, or the zero time if none was. It is safe for concurrent use.
+func (app *BaseApp) LastBlockTime() time.Time {
+	t, _ := app.lastBlockTime.Load().(time.Time)
+	return t
+}
+
 func (app *BaseApp) init() error {
 	if app.sealed {
 		panic("cannot call initFromMainStore: baseapp already sealed")
 	}
 
+	// the gas configs must be set before the check state branches the store
+	app.loadStoreGasConfigs(sdk.NewContext(app.cms.CacheMultiStore(), tmproto.Header{}, false, app.logger))
+
 	// needed for the export command which inits from store but never calls initchain
 	app.setCheckState(tmproto.Header{})
 	app.Seal()
@@ -321,29 +407,102 @@ func (app *BaseApp) init() error {
 }
 
 func (app *BaseApp) setMinGasPrices(gasPrices sdk.DecCoins) {
+	app.runtimeMtx.Lock()
+	defer app.runtimeMtx.Unlock()
 	app.minGasPrices = gasPrices
 }
 
 func (app *BaseApp) setHaltHeight(haltHeight uint64) {
+	app.runtimeMtx.Lock()
+	defer app.runtimeMtx.Unlock()
 	app.haltHeight = haltHeight
 }
 
 func (app *BaseApp) setHaltTime(haltTime uint64) {
+	app.runtimeMtx.Lock()
+	defer app.runtimeMtx.Unlock()
 	app.haltTime = haltTime
 }
 
 func (app *BaseApp) setMinRetainBlocks(minRetainBlocks uint64) {
+	app.runtimeMtx.Lock()
+	defer app.runtimeMtx.Unlock()
 	app.minRetainBlocks = minRetainBlocks
 }
 
+func (app *BaseApp) setQueryGasLimit(limit uint64) {
+	app.runtimeMtx.Lock()
+	defer app.runtimeMtx.Unlock()
+	app.queryGasLimit = limit
+}
+
+// UpdateMinGasPrices sets the minimum gas prices of CheckTx while the app is
+// running. They apply from the next block on. It is safe for concurrent use.
+func (app *BaseApp) UpdateMinGasPrices(gasPrices sdk.DecCoins) {
+	app.setMinGasPrices(gasPrices)
+}
+
+// UpdateHaltHeight sets the halt block height while the app is running. It is
+// safe for concurrent use.
+func (app *BaseApp) UpdateHaltHeight(haltHeight uint64) {
+	app.setHaltHeight(haltHeight)
+}
+
+// UpdateHaltTime sets the halt block time while the app is running. It is
+// safe for concurrent use.
+func (app *BaseApp) UpdateHaltTime(haltTime uint64) {
+	app.setHaltTime(haltTime)
+}
+
+// UpdateMinRetainBlocks sets the minimum block retention height offset while
+// the app is running. It is safe for concurrent use.
+func (app *BaseApp) UpdateMinRetainBlocks(minRetainBlocks uint64) {
+	app.setMinRetainBlocks(minRetainBlocks)
+}
+
+// UpdateQueryGasLimit sets the maximum gas of a query while the app is
+// running. It is safe for concurrent use.
+func (app *BaseApp) UpdateQueryGasLimit(limit uint64) {
+	app.setQueryGasLimit(limit)
+}
+
+// getMinGasPrices returns the minimum gas prices of CheckTx.
+func (app *BaseApp) getMinGasPrices() sdk.DecCoins {
+	app.runtimeMtx.RLock()
+	defer app.runtimeMtx.RUnlock()
+	return app.minGasPrices
+}
+
 func (app *BaseApp) setInterBlockCache(cache sdk.MultiStorePersistentCache) {
 	app.interBlockCache = cache
 }
 
+func (app *BaseApp) setAsyncPruning(opts rootmulti.AsyncPruningOptions) {
+	if opts == (rootmulti.AsyncPruningOptions{}) {
+		return
+	}
+
+	rms, ok := app.cms.(*rootmulti.Store)
+	if !ok {
+		panic("async pruning requires a rootmulti store")
+	}
+	if err := rms.SetAsyncPruning(opts); err != nil {
+		panic(fmt.Sprintf("failed to enable async pruning: %v", err))
+	}
+}
+
 func (app *BaseApp) setTrace(trace bool) {
 	app.trace = trace
 }
 
+func (app *BaseApp) setParallelTxWorkers(workers int) {
+	app.parallelTxWorkers = workers
+}
+
+func (app *BaseApp) setOptimisticExecution(enabled bool) {
+	app.optimisticExecution = enabled
+}
+
 func (app *BaseApp) setIndexEvents(ie []string) {
 	app.indexEvents = make(map[string]struct{})
 
@@ -369,6 +528,23 @@ func (app *BaseApp) QueryRouter() sdk.QueryRouter { return app.queryRouter }
 // Seal seals a BaseApp. It prohibits any further modifications to a BaseApp.
 func (app *BaseApp) Seal() { app.sealed = true }
 
+// CommitMultiStore returns the root multi-store of the BaseApp.
+func (app *BaseApp) CommitMultiStore() sdk.CommitMultiStore { return app.cms }
+
+// SnapshotManager returns the snapshot manager of the BaseApp, which can be
+// used to register snapshot extensions. It is nil if no snapshot store is set.
+func (app *BaseApp) SnapshotManager() *snapshots.Manager { return app.snapshotManager }
+
+// Close releases the resources of the BaseApp once it is no longer used. It
+// stops the background pruning of the multistore, if enabled.
+func (app *BaseApp) Close() error {
+	if rms, ok := app.cms.(*rootmulti.Store); ok {
+		return rms.Close()
+	}
+
+	return nil
+}
+
 // IsSealed returns true if the BaseApp is sealed and false otherwise.
 func (app *BaseApp) IsSealed() bool { return app.sealed }
 
@@ -380,7 +556,7 @@ func (app *BaseApp) setCheckState(header tmproto.Header) {
 	ms := app.cms.CacheMultiStore()
 	app.checkState = &state{
 		ms:  ms,
-		ctx: sdk.NewContext(ms, header, true, app.logger).WithMinGasPrices(app.minGasPrices),
+		ctx: sdk.NewContext(ms, header, true, app.logger).WithMinGasPrices(app.getMinGasPrices()),
 	}
 }
 
@@ -396,6 +572,28 @@ func (app *BaseApp) setDeliverState(header tmproto.Header) {
 	}
 }
 
+// setPrepareProposalState sets the BaseApp's prepareProposalState with a
+// branched multi-store of the latest committed state and a new Context with
+// the provided header. It is set on PrepareProposal and set to nil on Commit.
+func (app *BaseApp) setPrepareProposalState(header tmproto.Header) {
+	ms := app.cms.CacheMultiStore()
+	app.prepareProposalState = &state{
+		ms:  ms,
+		ctx: sdk.NewContext(ms, header, false, app.logger),
+	}
+}
+
+// setProcessProposalState sets the BaseApp's processProposalState with a
+// branched multi-store of the latest committed state and a new Context with
+// the provided header. It is set on ProcessProposal and set to nil on Commit.
+func (app *BaseApp) setProcessProposalState(header tmproto.Header) {
+	ms := app.cms.CacheMultiStore()
+	app.processProposalState = &state{
+		ms:  ms,
+		ctx: sdk.NewContext(ms, header, false, app.logger),
+	}
+}
+
 // GetConsensusParams returns the current consensus parameters from the BaseApp's
 // ParamStore. If the BaseApp has no ParamStore defined, nil is returned.
 func (app *BaseApp) GetConsensusParams(ctx sdk.Context) *abci.ConsensusParams {
@@ -451,6 +649,46 @@ func (app *BaseApp) StoreConsensusParams(ctx sdk.Context, cp *abci.ConsensusPara
 	app.paramStore.Set(ctx, ParamStoreKeyValidatorParams, cp.Validator)
 }
 
+// loadStoreGasConfigs sets the gas configs set with SetStoreGasConfigs and by
+// the StoreGasConfigs parameter in the given state on the root multi-store,
+// and returns them.
+func (app *BaseApp) loadStoreGasConfigs(ctx sdk.Context) map[sdk.StoreKey]sdk.GasConfig {
+	if app.paramStore == nil && len(app.storeGasConfigs) == 0 {
+		return nil
+	}
+
+	var params []StoreGasConfig
+	if app.paramStore != nil && app.paramStore.Has(ctx, ParamStoreKeyStoreGasConfigs) {
+		app.paramStore.Get(ctx, ParamStoreKeyStoreGasConfigs, &params)
+	}
+
+	configs := make(map[sdk.StoreKey]sdk.GasConfig, len(app.storeGasConfigs)+len(params))
+	for key, config := range app.storeGasConfigs {
+		configs[key] = config
+	}
+	for _, param := range params {
+		key := app.getStoreKey(param.StoreKey)
+		if key == nil {
+			app.logger.Error("ignoring the gas config of an unknown store", "store", param.StoreKey)
+			continue
+		}
+		configs[key] = param.GasConfig
+	}
+
+	app.cms.SetGasConfigs(configs)
+	return configs
+}
+
+// getStoreKey returns the key of the store mounted with the given name, if any.
+func (app *BaseApp) getStoreKey(name string) sdk.StoreKey {
+	for _, key := range app.storeKeys {
+		if key.Name() == name {
+			return key
+		}
+	}
+	return nil
+}
+
 // getMaximumBlockGas gets the maximum gas from the consensus params. It panics
 // if maximum block gas is less than negative one and returns zero if negative
 // one.
@@ -517,14 +755,23 @@ func validateBasicTxMsgs(msgs []sdk.Msg) error {
 	return nil
 }
 
-// Returns the applications's deliverState if app is in runTxModeDeliver,
-// otherwise it returns the application's checkstate.
+// Returns the applications's deliverState if app is in runTxModeDeliver, the
+// matching proposal state if app is verifying a block proposal, otherwise it
+// returns the application's checkstate.
 func (app *BaseApp) getState(mode runTxMode) *state {
-	if mode == runTxModeDeliver {
+	switch mode {
+	case runTxModeDeliver:
 		return app.deliverState
-	}
 
-	return app.checkState
+	case runTxModePrepareProposal:
+		return app.prepareProposalState
+
+	case runTxModeProcessProposal:
+		return app.processProposalState
+
+	default:
+		return app.checkState
+	}
 }
 
 // retrieve the context for the tx w/ txBytes and other memoized values.
@@ -556,7 +803,8 @@ func (app *BaseApp) cacheTxContext(ctx sdk.Context, txBytes []byte) (sdk.Context
 		msCache = msCache.SetTracingContext(
 			sdk.TraceContext(
 				map[string]interface{}{
-					"txHash": fmt.Sprintf("%X", tmhash.Sum(txBytes)),
+					tracekv.TraceContextTxHash:   fmt.Sprintf("%X", tmhash.Sum(txBytes)),
+					tracekv.TraceContextMsgIndex: nil,
 				},
 			),
 		).(sdk.CacheMultiStore)
@@ -573,12 +821,17 @@ func (app *BaseApp) cacheTxContext(ctx sdk.Context, txBytes []byte) (sdk.Context
 // returned if the tx does not run out of gas and if all the messages are valid
 // and execute successfully. An error is returned otherwise.
 func (app *BaseApp) runTx(mode runTxMode, txBytes []byte) (gInfo sdk.GasInfo, result *sdk.Result, err error) {
+	return app.runTxWithContext(app.getContextForTx(mode, txBytes), mode, txBytes)
+}
+
+// runTxWithContext processes a transaction within the given context, which
+// must have been derived with getContextForTx. See runTx for details.
+func (app *BaseApp) runTxWithContext(ctx sdk.Context, mode runTxMode, txBytes []byte) (gInfo sdk.GasInfo, result *sdk.Result, err error) {
 	// NOTE: GasWanted should be returned by the AnteHandler. GasUsed is
 	// determined by the GasMeter. We need access to the context to get the gas
 	// meter so we initialize upfront.
 	var gasWanted uint64
 
-	ctx := app.getContextForTx(mode, txBytes)
 	ms := ctx.MultiStore()
 
 	// only run the tx if there is block gas remaining
@@ -703,20 +956,19 @@ func (app *BaseApp) runMsgs(ctx sdk.Context, msgs []sdk.Msg, mode runTxMode) (*s
 
 	// NOTE: GasWanted is determined by the AnteHandler and GasUsed by the GasMeter.
 	for i, msg := range msgs {
-		// skip actual execution for (Re)CheckTx mode
-		if mode == runTxModeCheck || mode == runTxModeReCheck {
+		// skip actual execution for (Re)CheckTx and proposal verification modes
+		if mode != runTxModeDeliver && mode != runTxModeSimulate {
 			break
 		}
 
 		var (
-			msgResult    *sdk.Result
+			handler      sdk.Handler
 			eventMsgName string // name to use as value in event `message.action`
-			err          error
 		)
 
-		if handler := app.msgServiceRouter.Handler(msg); handler != nil {
+		if msgHandler := app.msgServiceRouter.Handler(msg); msgHandler != nil {
 			// ADR 031 request type routing
-			msgResult, err = handler(ctx, msg)
+			handler = msgHandler
 			eventMsgName = sdk.MsgTypeURL(msg)
 		} else if legacyMsg, ok := msg.(legacytx.LegacyMsg); ok {
 			// legacy sdk.Msg routing
@@ -726,22 +978,31 @@ func (app *BaseApp) runMsgs(ctx sdk.Context, msgs []sdk.Msg, mode runTxMode) (*s
 			// registered within the `msgServiceRouter` already.
 			msgRoute := legacyMsg.Route()
 			eventMsgName = legacyMsg.Type()
-			handler := app.router.Route(ctx, msgRoute)
+			handler = app.router.Route(ctx, msgRoute)
 			if handler == nil {
 				return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unrecognized message route: %s; message index: %d", msgRoute, i)
 			}
-
-			msgResult, err = handler(ctx, msg)
 		} else {
 			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "can't route message %+v", msg)
 		}
 
+		if ctx.MultiStore().TracingEnabled() {
+			ctx.MultiStore().SetTracingContext(sdk.TraceContext(
+				map[string]interface{}{tracekv.TraceContextMsgIndex: i},
+			))
+		}
+
+		msgResult, gasUsed, err := app.runMsgHandler(ctx, handler, msg, mode)
 		if err != nil {
 			return nil, sdkerrors.Wrapf(err, "failed to execute message; message index: %d", i)
 		}
 
 		msgEvents := sdk.Events{
-			sdk.NewEvent(sdk.EventTypeMessage, sdk.NewAttribute(sdk.AttributeKeyAction, eventMsgName)),
+			sdk.NewEvent(
+				sdk.EventTypeMessage,
+				sdk.NewAttribute(sdk.AttributeKeyAction, eventMsgName),
+				sdk.NewAttribute(sdk.AttributeKeyGasUsed, strconv.FormatUint(gasUsed, 10)),
+			),
 		}
 		msgEvents = msgEvents.AppendEvents(msgResult.GetEvents())
 
@@ -755,6 +1016,13 @@ func (app *BaseApp) runMsgs(ctx sdk.Context, msgs []sdk.Msg, mode runTxMode) (*s
 		msgLogs = append(msgLogs, sdk.NewABCIMessageLog(uint32(i), msgResult.Log, msgEvents))
 	}
 
+	// the writes of the tx are traced once its branch is written
+	if ctx.MultiStore().TracingEnabled() {
+		ctx.MultiStore().SetTracingContext(sdk.TraceContext(
+			map[string]interface{}{tracekv.TraceContextMsgIndex: nil},
+		))
+	}
+
 	data, err := proto.Marshal(txMsgData)
 	if err != nil {
 		return nil, sdkerrors.Wrap(err, "failed to marshal tx data")
@@ -766,3 +1034,70 @@ func (app *BaseApp) runMsgs(ctx sdk.Context, msgs []sdk.Msg, mode runTxMode) (*s
 		Events: events.ToABCIEvents(),
 	}, nil
 }
+
+// runMsgHandler executes the handler of a message and returns the gas it
+// consumed. In DeliverTx mode, it records the telemetry of the handler, see
+// msgTelemetry, which is emitted at once unless the tx is executed
+// speculatively, in which case it is only emitted once the execution of the tx
+// is applied to the deliver state.
+func (app *BaseApp) runMsgHandler(ctx sdk.Context, handler sdk.Handler, msg sdk.Msg, mode runTxMode) (result *sdk.Result, gasUsed uint64, err error) {
+	startingGas := ctx.GasMeter().GasConsumed()
+	if mode != runTxModeDeliver {
+		result, err = handler(ctx, msg)
+		return result, ctx.GasMeter().GasConsumed() - startingGas, err
+	}
+
+	record := msgTelemetry{msgType: sdk.MsgTypeURL(msg), panicked: true}
+	start := time.Now()
+
+	defer func() {
+		record.duration = time.Since(start)
+		if collector, ok := ctx.Context().Value(msgTelemetryKey{}).(*[]msgTelemetry); ok {
+			*collector = append(*collector, record)
+		} else {
+			record.emit()
+		}
+	}()
+
+	result, err = handler(ctx, msg)
+	gasUsed = ctx.GasMeter().GasConsumed() - startingGas
+	record.panicked, record.gasUsed, record.err = false, gasUsed, err
+
+	return result, gasUsed, err
+}
+
+// msgTelemetryKey is the context key of the telemetry recorded by the message
+// handlers of a tx executed speculatively.
+type msgTelemetryKey struct{}
+
+// msgTelemetry is the telemetry of the execution of a message handler, labelled
+// by the type URL of the message: the execution time and gas of the handler,
+// its failures by codespace and code, and its panics, e.g. when running out of
+// gas.
+type msgTelemetry struct {
+	msgType  string
+	duration time.Duration
+	gasUsed  uint64
+	err      error
+	panicked bool
+}
+
+func (t msgTelemetry) emit() {
+	labels := []metrics.Label{telemetry.NewLabel(telemetry.MetricLabelNameMsgType, t.msgType)}
+	telemetry.MeasureSinceWithLabels([]string{"tx", "msg", "time"}, time.Now().Add(-t.duration), labels)
+
+	if t.panicked {
+		telemetry.IncrCounterWithLabels([]string{"tx", "msg", "panic"}, 1, labels)
+		return
+	}
+
+	telemetry.AddSampleWithLabels([]string{"tx", "msg", "gas", "used"}, float32(t.gasUsed), labels)
+
+	if t.err != nil {
+		codespace, code, _ := sdkerrors.ABCIInfo(t.err, false)
+		telemetry.IncrCounterWithLabels(
+			[]string{"tx", "msg", "failed"}, 1,
+			append(labels, telemetry.NewLabel("codespace", codespace), telemetry.NewLabel("code", strconv.FormatUint(uint64(code), 10))),
+		)
+	}
+}
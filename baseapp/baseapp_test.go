@@ -2,9 +2,11 @@ package baseapp
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"os"
@@ -13,22 +15,33 @@ import (
 	"testing"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/gogo/protobuf/jsonpb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 	"github.com/tendermint/tendermint/libs/log"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
 	dbm "github.com/tendermint/tm-db"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/snapshots"
 	snapshottypes "github.com/cosmos/cosmos-sdk/snapshots/types"
+	"github.com/cosmos/cosmos-sdk/store/cache"
 	"github.com/cosmos/cosmos-sdk/store/rootmulti"
+	"github.com/cosmos/cosmos-sdk/store/tracekv"
 	store "github.com/cosmos/cosmos-sdk/store/types"
 	"github.com/cosmos/cosmos-sdk/testutil/testdata"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/intermodule"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
 )
 
@@ -550,6 +563,30 @@ func TestSetMinGasPrices(t *testing.T) {
 	require.Equal(t, minGasPrices, app.minGasPrices)
 }
 
+func TestUpdateRuntimeSettings(t *testing.T) {
+	app := setupBaseApp(t, SetQueryGasLimit(1000))
+	app.InitChain(abci.RequestInitChain{})
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 1}})
+	app.Commit()
+
+	minGasPrices := sdk.DecCoins{sdk.NewInt64DecCoin("stake", 5000)}
+	app.UpdateMinGasPrices(minGasPrices)
+	app.UpdateQueryGasLimit(2000)
+	app.UpdateMinRetainBlocks(1)
+
+	ctx, err := app.createQueryContext(0, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2000), ctx.GasMeter().Limit())
+	require.Equal(t, minGasPrices, ctx.MinGasPrices())
+
+	// the minimum gas prices of CheckTx apply from the next block on
+	require.True(t, app.checkState.ctx.MinGasPrices().IsZero())
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 2}})
+	res := app.Commit()
+	require.Equal(t, minGasPrices, app.checkState.ctx.MinGasPrices())
+	require.Equal(t, int64(1), res.RetainHeight)
+}
+
 func TestInitChainer(t *testing.T) {
 	name := t.Name()
 	// keep the db and logger ourselves so
@@ -631,6 +668,36 @@ func TestInitChainer(t *testing.T) {
 	require.Equal(t, value, res.Value)
 }
 
+func TestLastBlockTime(t *testing.T) {
+	db := dbm.NewMemDB()
+	app := NewBaseApp(t.Name(), defaultLogger(), db, nil)
+	app.MountStores(capKey1)
+	require.NoError(t, app.LoadLatestVersion())
+
+	// no block is committed yet
+	require.True(t, app.LastBlockTime().IsZero())
+
+	app.InitChain(abci.RequestInitChain{ChainId: "test-chain-id"})
+	header := tmproto.Header{Height: 1, Time: time.Unix(1_000_000, 0).UTC()}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+	app.EndBlock(abci.RequestEndBlock{Height: header.Height})
+	app.Commit()
+	require.Equal(t, header.Time, app.LastBlockTime())
+
+	// the time is that of the last block committed since the app started
+	app = NewBaseApp(t.Name(), defaultLogger(), db, nil)
+	app.MountStores(capKey1)
+	require.NoError(t, app.LoadLatestVersion())
+	require.Equal(t, int64(1), app.LastBlockHeight())
+	require.True(t, app.LastBlockTime().IsZero())
+
+	header = tmproto.Header{Height: 2, Time: header.Time.Add(5 * time.Second)}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+	app.EndBlock(abci.RequestEndBlock{Height: header.Height})
+	app.Commit()
+	require.Equal(t, header.Time, app.LastBlockTime())
+}
+
 func TestInitChain_WithInitialHeight(t *testing.T) {
 	name := t.Name()
 	db := dbm.NewMemDB()
@@ -1011,6 +1078,69 @@ func TestDeliverTx(t *testing.T) {
 	}
 }
 
+func TestDeliverTxTraceContext(t *testing.T) {
+	var trace bytes.Buffer
+	tracer, err := tracekv.NewTracer(&trace, tracekv.TracerOptions{
+		Include: []tracekv.Filter{{StoreKey: capKey1.Name(), KeyPrefix: []byte("deliver-key")}},
+	})
+	require.NoError(t, err)
+
+	deliverKey := []byte("deliver-key")
+	routerOpt := func(bapp *BaseApp) {
+		r := sdk.NewRoute(routeMsgCounter, handlerMsgCounter(t, capKey1, deliverKey))
+		bapp.Router().AddRoute(r)
+	}
+	tracerOpt := func(bapp *BaseApp) { bapp.SetCommitMultiStoreTracer(tracer) }
+
+	app := setupBaseApp(t, tracerOpt, routerOpt)
+	app.InitChain(abci.RequestInitChain{})
+
+	codec := codec.NewLegacyAmino()
+	registerTestCodec(codec)
+
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 1}})
+	for i := int64(0); i < 2; i++ {
+		txBytes, err := codec.Marshal(newTxCounter(i, i))
+		require.NoError(t, err)
+
+		res := app.DeliverTx(abci.RequestDeliverTx{Tx: txBytes})
+		require.True(t, res.IsOK(), fmt.Sprintf("%v", res))
+	}
+	app.EndBlock(abci.RequestEndBlock{})
+	app.Commit()
+
+	var reads, txWrites, blockWrites int
+	rr := tracekv.NewRecordReader(&trace)
+	for {
+		rec, err := rr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, int64(1), rec.BlockHeight)
+		require.Equal(t, capKey1.Name(), rec.Store)
+
+		switch {
+		case rec.Operation == "read" && rec.TxIndex == 1:
+			// the message handler reads the counter of the previous tx
+			require.Equal(t, int64(0), rec.MsgIndex)
+			require.NotEmpty(t, rec.TxHash)
+			reads++
+		case rec.Operation == "write" && rec.TxIndex >= 0:
+			// the writes of a tx are traced when its branch is written
+			require.Equal(t, int64(-1), rec.MsgIndex)
+			txWrites++
+		case rec.Operation == "write":
+			// the writes of the block are traced on commit
+			require.Empty(t, rec.TxHash)
+			blockWrites++
+		}
+	}
+	require.NotZero(t, reads)
+	require.Equal(t, 2, txWrites)
+	require.Equal(t, 1, blockWrites)
+}
+
 // Number of messages doesn't matter to CheckTx.
 func TestMultiMsgCheckTx(t *testing.T) {
 	// TODO: ensure we get the same results
@@ -1163,6 +1293,70 @@ func TestSimulateTx(t *testing.T) {
 	}
 }
 
+func TestSimulateTxStateOverrides(t *testing.T) {
+	key := []byte("funded")
+
+	anteOpt := func(bapp *BaseApp) {
+		bapp.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (newCtx sdk.Context, err error) {
+			if !ctx.KVStore(capKey1).Has(key) {
+				return ctx, sdkerrors.Wrap(sdkerrors.ErrInsufficientFunds, "not funded")
+			}
+			return ctx, nil
+		})
+	}
+
+	routerOpt := func(bapp *BaseApp) {
+		r := sdk.NewRoute(routeMsgCounter, func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+			return &sdk.Result{}, nil
+		})
+		bapp.Router().AddRoute(r)
+	}
+
+	var handled []txtypes.StateOverride
+	handlerOpt := func(bapp *BaseApp) {
+		bapp.SetStateOverrideHandler(func(ctx sdk.Context, override txtypes.StateOverride) error {
+			handled = append(handled, override)
+			return nil
+		})
+	}
+
+	app := setupBaseApp(t, anteOpt, routerOpt, handlerOpt)
+	app.InitChain(abci.RequestInitChain{})
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 1}})
+
+	cdc := codec.NewLegacyAmino()
+	registerTestCodec(cdc)
+	txBytes, err := cdc.Marshal(newTxCounter(0, 0))
+	require.NoError(t, err)
+
+	kvOverride := txtypes.StateOverride{Sum: &txtypes.StateOverride_KvStore{KvStore: &txtypes.KVStoreOverride{
+		StoreKey: capKey1.Name(), Key: key, Value: []byte{1},
+	}}}
+	balanceOverride := txtypes.StateOverride{Sum: &txtypes.StateOverride_Balance{Balance: &txtypes.BalanceOverride{}}}
+
+	_, _, err = app.Simulate(txBytes)
+	require.True(t, sdkerrors.ErrInsufficientFunds.Is(err), err)
+
+	_, result, err := app.Simulate(txBytes, kvOverride, balanceOverride)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, []txtypes.StateOverride{balanceOverride}, handled)
+
+	// the overrides are applied to a throwaway branch of the check state only
+	require.False(t, app.checkState.ctx.KVStore(capKey1).Has(key))
+	_, _, err = app.Simulate(txBytes)
+	require.True(t, sdkerrors.ErrInsufficientFunds.Is(err), err)
+
+	// invalid overrides are rejected before the tx is run
+	_, _, err = app.Simulate(txBytes, txtypes.StateOverride{})
+	require.True(t, sdkerrors.ErrInvalidRequest.Is(err), err)
+	unknownStore := txtypes.StateOverride{Sum: &txtypes.StateOverride_KvStore{KvStore: &txtypes.KVStoreOverride{
+		StoreKey: "unknown", Key: key, Value: []byte{1},
+	}}}
+	_, _, err = app.Simulate(txBytes, unknownStore)
+	require.True(t, sdkerrors.ErrInvalidRequest.Is(err), err)
+}
+
 func TestRunInvalidTransaction(t *testing.T) {
 	anteOpt := func(bapp *BaseApp) {
 		bapp.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (newCtx sdk.Context, err error) {
@@ -1737,6 +1931,82 @@ func TestGRPCQuery(t *testing.T) {
 	require.Equal(t, "Hello foo!", res.Greeting)
 }
 
+func TestQueryGasLimit(t *testing.T) {
+	queryRouterOpt := func(bapp *BaseApp) {
+		bapp.QueryRouter().AddRoute("gas", func(ctx sdk.Context, path []string, req abci.RequestQuery) ([]byte, error) {
+			store := ctx.KVStore(capKey1)
+			for i := 0; i < 100; i++ {
+				store.Get([]byte("key"))
+			}
+			return []byte("ok"), nil
+		})
+	}
+
+	testCases := []struct {
+		limit     uint64
+		expectErr bool
+	}{
+		{0, false},
+		{1000000, false},
+		{1000, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("limit=%d", tc.limit), func(t *testing.T) {
+			app := setupBaseApp(t, queryRouterOpt, SetQueryGasLimit(tc.limit))
+			app.InitChain(abci.RequestInitChain{})
+			app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 1}})
+			app.Commit()
+
+			res := app.Query(abci.RequestQuery{Path: "/custom/gas"})
+			if tc.expectErr {
+				require.Equal(t, sdkerrors.ErrOutOfGas.ABCICode(), res.Code, res.Log)
+				require.Contains(t, res.Log, "query out of gas")
+			} else {
+				require.Equal(t, abci.CodeTypeOK, res.Code, res.Log)
+				require.Equal(t, []byte("ok"), res.Value)
+			}
+		})
+	}
+}
+
+func TestQueryContextCancellation(t *testing.T) {
+	app := setupBaseApp(t, SetQueryGasLimit(1000))
+	app.InitChain(abci.RequestInitChain{})
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 1}})
+	app.Commit()
+
+	ctx, err := app.createQueryContext(0, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000), ctx.GasMeter().Limit())
+
+	goCtx, cancel := context.WithCancel(context.Background())
+	gasMeter := newCancelableGasMeter(goCtx, ctx.GasMeter())
+	ctx = ctx.WithGasMeter(gasMeter)
+
+	ctx.KVStore(capKey1).Get([]byte("key"))
+	require.NotZero(t, gasMeter.GasConsumed())
+
+	cancel()
+	func() {
+		defer func() {
+			err := queryPanicToError(recover(), gasMeter)
+			require.ErrorIs(t, err, context.Canceled)
+			require.Equal(t, codes.Canceled, grpcstatus.Code(queryErrorToGRPCError(err)))
+		}()
+		ctx.KVStore(capKey1).Get([]byte("key"))
+	}()
+
+	func() {
+		defer func() {
+			err := queryPanicToError(recover(), gasMeter)
+			require.True(t, sdkerrors.ErrOutOfGas.Is(err))
+			require.Equal(t, codes.ResourceExhausted, grpcstatus.Code(queryErrorToGRPCError(err)))
+		}()
+		ctx.GasMeter().(*cancelableGasMeter).GasMeter.ConsumeGas(1000, "test")
+	}()
+}
+
 // Test p2p filter queries
 func TestP2PQuery(t *testing.T) {
 	addrPeerFilterOpt := func(bapp *BaseApp) {
@@ -1798,8 +2068,8 @@ func TestListSnapshots(t *testing.T) {
 		s.Metadata = nil
 	}
 	assert.Equal(t, abci.ResponseListSnapshots{Snapshots: []*abci.Snapshot{
-		{Height: 4, Format: 1, Chunks: 2},
-		{Height: 2, Format: 1, Chunks: 1},
+		{Height: 4, Format: snapshottypes.CurrentFormat, Chunks: 2},
+		{Height: 2, Format: snapshottypes.CurrentFormat, Chunks: 1},
 	}}, resp)
 }
 
@@ -1813,13 +2083,13 @@ func TestLoadSnapshotChunk(t *testing.T) {
 		chunk       uint32
 		expectEmpty bool
 	}{
-		"Existing snapshot": {2, 1, 1, false},
-		"Missing height":    {100, 1, 1, true},
-		"Missing format":    {2, 2, 1, true},
-		"Missing chunk":     {2, 1, 9, true},
-		"Zero height":       {0, 1, 1, true},
+		"Existing snapshot": {2, snapshottypes.CurrentFormat, 1, false},
+		"Missing height":    {100, snapshottypes.CurrentFormat, 1, true},
+		"Missing format":    {2, snapshottypes.CurrentFormat + 1, 1, true},
+		"Missing chunk":     {2, snapshottypes.CurrentFormat, 9, true},
+		"Zero height":       {0, snapshottypes.CurrentFormat, 1, true},
 		"Zero format":       {2, 0, 1, true},
-		"Zero chunk":        {2, 1, 0, false},
+		"Zero chunk":        {2, snapshottypes.CurrentFormat, 0, false},
 	}
 	for name, tc := range testcases {
 		tc := tc
@@ -1857,13 +2127,13 @@ func TestOfferSnapshot_Errors(t *testing.T) {
 			Height: 1, Format: 9, Chunks: 3, Hash: hash, Metadata: metadata,
 		}, abci.ResponseOfferSnapshot_REJECT_FORMAT},
 		"incorrect chunk count": {&abci.Snapshot{
-			Height: 1, Format: 1, Chunks: 2, Hash: hash, Metadata: metadata,
+			Height: 1, Format: snapshottypes.CurrentFormat, Chunks: 2, Hash: hash, Metadata: metadata,
 		}, abci.ResponseOfferSnapshot_REJECT},
 		"no chunks": {&abci.Snapshot{
-			Height: 1, Format: 1, Chunks: 0, Hash: hash, Metadata: metadata,
+			Height: 1, Format: snapshottypes.CurrentFormat, Chunks: 0, Hash: hash, Metadata: metadata,
 		}, abci.ResponseOfferSnapshot_REJECT},
 		"invalid metadata serialization": {&abci.Snapshot{
-			Height: 1, Format: 1, Chunks: 0, Hash: hash, Metadata: []byte{3, 1, 4},
+			Height: 1, Format: snapshottypes.CurrentFormat, Chunks: 0, Hash: hash, Metadata: []byte{3, 1, 4},
 		}, abci.ResponseOfferSnapshot_REJECT},
 	}
 	for name, tc := range testcases {
@@ -1909,6 +2179,13 @@ func TestApplySnapshotChunk(t *testing.T) {
 	// Make sure the snapshot has at least 3 chunks
 	require.GreaterOrEqual(t, snapshot.Chunks, uint32(3), "Not enough snapshot chunks")
 
+	// The param store of the tests is not part of the snapshot, so the gas
+	// configs of the restored state are set on the target directly.
+	paramConfig := sdk.GasConfig{HasCost: 2}
+	target.paramStore.Set(sdk.Context{}, ParamStoreKeyStoreGasConfigs, []StoreGasConfig{
+		{StoreKey: capKey2.Name(), GasConfig: paramConfig},
+	})
+
 	// Begin a snapshot restoration in the target
 	respOffer := target.OfferSnapshot(abci.RequestOfferSnapshot{Snapshot: snapshot})
 	require.Equal(t, abci.ResponseOfferSnapshot{Result: abci.ResponseOfferSnapshot_ACCEPT}, respOffer)
@@ -1944,6 +2221,11 @@ func TestApplySnapshotChunk(t *testing.T) {
 
 	// The target should now have the same hash as the source
 	assert.Equal(t, source.LastCommitID(), target.LastCommitID())
+
+	// and the gas configs of the restored state
+	config, ok := target.cms.GetGasConfig(capKey2)
+	require.True(t, ok)
+	require.Equal(t, paramConfig, config)
 }
 
 // NOTE: represents a new custom router for testing purposes of WithRouter()
@@ -2039,3 +2321,808 @@ func TestBaseApp_EndBlock(t *testing.T) {
 	require.Equal(t, int64(100), res.GetValidatorUpdates()[0].Power)
 	require.Equal(t, cp.Block.MaxGas, res.ConsensusParamUpdates.Block.MaxGas)
 }
+
+func TestPrepareProposal(t *testing.T) {
+	counterKey := []byte("counter-key")
+	anteOpt := func(bapp *BaseApp) { bapp.SetAnteHandler(anteHandlerTxTest(t, capKey1, counterKey)) }
+
+	app := setupBaseApp(t, anteOpt)
+
+	cdc := codec.NewLegacyAmino()
+	registerTestCodec(cdc)
+
+	txs := make([][]byte, 0, 4)
+	for _, tx := range []*txTest{newTxCounter(0, 0), newTxCounter(1, 1), newTxCounter(2, 2)} {
+		txBytes, err := cdc.Marshal(tx)
+		require.NoError(t, err)
+		txs = append(txs, txBytes)
+	}
+
+	failingTx := newTxCounter(1, 1)
+	failingTx.setFailOnAnte(true)
+	failingTxBytes, err := cdc.Marshal(failingTx)
+	require.NoError(t, err)
+
+	app.InitChain(abci.RequestInitChain{
+		ConsensusParams: &abci.ConsensusParams{
+			Block: &abci.BlockParams{
+				MaxBytes: int64(len(txs[0]) + len(txs[1]) + len(txs[2]) - 1),
+			},
+		},
+	})
+
+	res := app.PrepareProposal(abci.RequestPrepareProposal{
+		BlockData: &tmproto.Data{
+			Txs:                [][]byte{txs[0], []byte("garbage"), failingTxBytes, txs[1], txs[2]},
+			OriginalSquareSize: 4,
+		},
+	})
+
+	// the undecodable and ante-failing txs are dropped and the last tx no
+	// longer fits into the block
+	require.Equal(t, [][]byte{txs[0], txs[1]}, res.BlockData.Txs)
+	require.Equal(t, uint64(4), res.BlockData.OriginalSquareSize)
+
+	// the check state is not affected by preparing a proposal
+	checkStateStore := app.checkState.ctx.KVStore(capKey1)
+	require.Nil(t, checkStateStore.Get(counterKey))
+}
+
+func TestProcessProposal(t *testing.T) {
+	counterKey := []byte("counter-key")
+	anteOpt := func(bapp *BaseApp) { bapp.SetAnteHandler(anteHandlerTxTest(t, capKey1, counterKey)) }
+
+	app := setupBaseApp(t, anteOpt)
+
+	cdc := codec.NewLegacyAmino()
+	registerTestCodec(cdc)
+
+	txs := make([][]byte, 0, 3)
+	for _, tx := range []*txTest{newTxCounter(0, 0), newTxCounter(1, 1), newTxCounter(2, 2)} {
+		txBytes, err := cdc.Marshal(tx)
+		require.NoError(t, err)
+		txs = append(txs, txBytes)
+	}
+
+	failingTx := newTxCounter(1, 1)
+	failingTx.setFailOnAnte(true)
+	failingTxBytes, err := cdc.Marshal(failingTx)
+	require.NoError(t, err)
+
+	app.InitChain(abci.RequestInitChain{
+		ConsensusParams: &abci.ConsensusParams{
+			Block: &abci.BlockParams{
+				MaxBytes: int64(len(txs[0]) + len(txs[1])),
+			},
+		},
+	})
+
+	testCases := map[string]struct {
+		txs      [][]byte
+		expected abci.ResponseProcessProposal_Result
+	}{
+		"empty block":          {nil, abci.ResponseProcessProposal_ACCEPT},
+		"valid txs":            {[][]byte{txs[0], txs[1]}, abci.ResponseProcessProposal_ACCEPT},
+		"undecodable tx":       {[][]byte{txs[0], []byte("garbage")}, abci.ResponseProcessProposal_REJECT},
+		"ante failing tx":      {[][]byte{txs[0], failingTxBytes}, abci.ResponseProcessProposal_REJECT},
+		"exceeds block bytes":  {[][]byte{txs[0], txs[1], txs[2]}, abci.ResponseProcessProposal_REJECT},
+		"valid txs once again": {[][]byte{txs[0]}, abci.ResponseProcessProposal_ACCEPT},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			res := app.ProcessProposal(abci.RequestProcessProposal{
+				Header:    tmproto.Header{Height: 1},
+				BlockData: &tmproto.Data{Txs: tc.txs},
+			})
+			require.Equal(t, tc.expected, res.Result)
+		})
+	}
+}
+
+func TestCustomProposalHandlers(t *testing.T) {
+	prepareOpt := func(bapp *BaseApp) {
+		bapp.SetPrepareProposalHandler(func(ctx sdk.Context, req abci.RequestPrepareProposal) abci.ResponsePrepareProposal {
+			return abci.ResponsePrepareProposal{BlockData: &tmproto.Data{Txs: [][]byte{[]byte("custom")}}}
+		})
+	}
+	processOpt := func(bapp *BaseApp) {
+		bapp.SetProcessProposalHandler(func(ctx sdk.Context, req abci.RequestProcessProposal) abci.ResponseProcessProposal {
+			panic("process proposal failure")
+		})
+	}
+
+	app := setupBaseApp(t, prepareOpt, processOpt)
+	app.InitChain(abci.RequestInitChain{})
+
+	prepareRes := app.PrepareProposal(abci.RequestPrepareProposal{BlockData: &tmproto.Data{}})
+	require.Equal(t, [][]byte{[]byte("custom")}, prepareRes.BlockData.Txs)
+
+	// a panicking handler rejects the proposal
+	processRes := app.ProcessProposal(abci.RequestProcessProposal{Header: tmproto.Header{Height: 1}})
+	require.Equal(t, abci.ResponseProcessProposal_REJECT, processRes.Result)
+
+	require.Panics(t, func() { app.SetPrepareProposalHandler(nil) })
+	require.Panics(t, func() { app.SetProcessProposalHandler(nil) })
+}
+
+type mockStreamingService struct {
+	pairs         []store.StoreKVPair
+	beginBlocks   int
+	deliverTxs    int
+	endBlocks     int
+	commits       int
+	pairsOnCommit int
+}
+
+func (m *mockStreamingService) OnWrite(storeKey store.StoreKey, key []byte, value []byte, delete bool) error {
+	m.pairs = append(m.pairs, store.StoreKVPair{StoreKey: storeKey.Name(), Key: key, Value: value, Delete: delete})
+	return nil
+}
+
+func (m *mockStreamingService) Listeners() map[store.StoreKey][]store.WriteListener {
+	return map[store.StoreKey][]store.WriteListener{capKey1: {m}}
+}
+
+func (m *mockStreamingService) ListenBeginBlock(sdk.Context, abci.RequestBeginBlock, abci.ResponseBeginBlock) error {
+	m.beginBlocks++
+	return nil
+}
+
+func (m *mockStreamingService) ListenDeliverTx(sdk.Context, abci.RequestDeliverTx, abci.ResponseDeliverTx) error {
+	m.deliverTxs++
+	return nil
+}
+
+func (m *mockStreamingService) ListenEndBlock(sdk.Context, abci.RequestEndBlock, abci.ResponseEndBlock) error {
+	m.endBlocks++
+	return nil
+}
+
+func (m *mockStreamingService) ListenCommit(sdk.Context, abci.ResponseCommit) error {
+	m.commits++
+	m.pairsOnCommit = len(m.pairs)
+	return nil
+}
+
+func (m *mockStreamingService) HaltAppOnDeliveryError() bool { return false }
+
+func (m *mockStreamingService) Close() error { return nil }
+
+func TestStreamingService(t *testing.T) {
+	anteKey := []byte("ante-key")
+	deliverKey := []byte("deliver-key")
+	streamingService := &mockStreamingService{}
+
+	anteOpt := func(bapp *BaseApp) { bapp.SetAnteHandler(anteHandlerTxTest(t, capKey1, anteKey)) }
+	routerOpt := func(bapp *BaseApp) {
+		r := sdk.NewRoute(routeMsgCounter, handlerMsgCounter(t, capKey1, deliverKey))
+		bapp.Router().AddRoute(r)
+	}
+	streamingOpt := func(bapp *BaseApp) { bapp.SetStreamingService(streamingService) }
+
+	app := setupBaseApp(t, anteOpt, routerOpt, streamingOpt)
+	app.InitChain(abci.RequestInitChain{})
+
+	codec := codec.NewLegacyAmino()
+	registerTestCodec(codec)
+
+	// CheckTx state transitions are never observed
+	txBytes, err := codec.Marshal(newTxCounter(0, 0))
+	require.NoError(t, err)
+	require.True(t, app.CheckTx(abci.RequestCheckTx{Tx: txBytes}).IsOK())
+	require.Empty(t, streamingService.pairs)
+
+	header := tmproto.Header{Height: 1}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+
+	for i := int64(0); i < 3; i++ {
+		txBytes, err := codec.Marshal(newTxCounter(i, i))
+		require.NoError(t, err)
+		require.True(t, app.DeliverTx(abci.RequestDeliverTx{Tx: txBytes}).IsOK())
+	}
+
+	// a failing tx does not produce any state changes
+	tx := newTxCounter(3, 3)
+	tx.setFailOnHandler(true)
+	txBytes, err = codec.Marshal(tx)
+	require.NoError(t, err)
+	require.False(t, app.DeliverTx(abci.RequestDeliverTx{Tx: txBytes}).IsOK())
+
+	app.EndBlock(abci.RequestEndBlock{Height: header.Height})
+
+	// state changes are only observed once the block is committed
+	require.Empty(t, streamingService.pairs)
+	app.Commit()
+
+	require.Equal(t, 1, streamingService.beginBlocks)
+	require.Equal(t, 4, streamingService.deliverTxs)
+	require.Equal(t, 1, streamingService.endBlocks)
+	require.Equal(t, 1, streamingService.commits)
+	require.Equal(t, len(streamingService.pairs), streamingService.pairsOnCommit)
+
+	// each key is observed once with its final value
+	observed := make(map[string][]byte)
+	for _, pair := range streamingService.pairs {
+		require.Equal(t, capKey1.Name(), pair.StoreKey)
+		require.NotContains(t, observed, string(pair.Key))
+		observed[string(pair.Key)] = pair.Value
+	}
+
+	store := app.cms.GetKVStore(capKey1)
+	require.Equal(t, store.Get(anteKey), observed[string(anteKey)])
+	require.Equal(t, store.Get(deliverKey), observed[string(deliverKey)])
+}
+
+func TestDeliverTxBatch(t *testing.T) {
+	sharedKey := []byte("shared-key")
+
+	// every tx increments a shared counter in the AnteHandler and writes its
+	// own key when its message is executed
+	anteOpt := func(bapp *BaseApp) {
+		bapp.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			if tx.(txTest).FailOnAnte {
+				return ctx, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "ante handler failure")
+			}
+
+			ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+			store := ctx.KVStore(capKey1)
+			setIntOnStore(store, sharedKey, getIntFromStore(store, sharedKey)+1)
+			return ctx, nil
+		})
+	}
+	routerOpt := func(bapp *BaseApp) {
+		bapp.Router().AddRoute(sdk.NewRoute(routeMsgCounter, func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+			m := msg.(*msgCounter)
+			if m.FailOnHandler {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "message handler failure")
+			}
+
+			store := ctx.KVStore(capKey2)
+			shared := getIntFromStore(ctx.KVStore(capKey1), sharedKey)
+			setIntOnStore(store, []byte(fmt.Sprintf("key-%d", m.Counter)), shared)
+
+			return &sdk.Result{}, nil
+		}))
+	}
+
+	codec := codec.NewLegacyAmino()
+	registerTestCodec(codec)
+
+	var reqs []abci.RequestDeliverTx
+	for i := int64(0); i < 20; i++ {
+		tx := newTxCounter(i, i)
+		switch i % 7 {
+		case 3:
+			tx.setFailOnAnte(true)
+		case 5:
+			tx.setFailOnHandler(true)
+		}
+
+		txBytes, err := codec.Marshal(tx)
+		require.NoError(t, err)
+		reqs = append(reqs, abci.RequestDeliverTx{Tx: txBytes})
+	}
+
+	var (
+		responses [][]abci.ResponseDeliverTx
+		commits   []abci.ResponseCommit
+	)
+	for _, workers := range []int{0, 4} {
+		app := setupBaseApp(t, anteOpt, routerOpt, SetParallelTxExecution(workers))
+		app.InitChain(abci.RequestInitChain{})
+		app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 1}})
+
+		responses = append(responses, app.DeliverTxBatch(reqs))
+		app.EndBlock(abci.RequestEndBlock{})
+		commits = append(commits, app.Commit())
+
+		store := app.cms.GetKVStore(capKey1)
+		require.Equal(t, int64(17), getIntFromStore(store, sharedKey))
+	}
+
+	require.Equal(t, responses[0], responses[1])
+	require.Equal(t, commits[0], commits[1])
+}
+
+func TestOptimisticExecution(t *testing.T) {
+	sharedKey := []byte("shared-key")
+
+	// every tx increments a shared counter in the AnteHandler and writes its
+	// own key when its message is executed
+	anteOpt := func(bapp *BaseApp) {
+		bapp.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			if tx.(txTest).FailOnAnte {
+				return ctx, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "ante handler failure")
+			}
+
+			ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+			store := ctx.KVStore(capKey1)
+			setIntOnStore(store, sharedKey, getIntFromStore(store, sharedKey)+1)
+			return ctx, nil
+		})
+	}
+	routerOpt := func(bapp *BaseApp) {
+		bapp.Router().AddRoute(sdk.NewRoute(routeMsgCounter, func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+			m := msg.(*msgCounter)
+			if m.FailOnHandler {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "message handler failure")
+			}
+
+			store := ctx.KVStore(capKey2)
+			shared := getIntFromStore(ctx.KVStore(capKey1), sharedKey)
+			setIntOnStore(store, []byte(fmt.Sprintf("key-%d", m.Counter)), shared)
+
+			return &sdk.Result{}, nil
+		}))
+	}
+	// the BeginBlocker of block 3 writes the shared counter, which invalidates
+	// the optimistic executions of all txs
+	beginBlockerOpt := func(bapp *BaseApp) {
+		bapp.SetBeginBlocker(func(ctx sdk.Context, req abci.RequestBeginBlock) abci.ResponseBeginBlock {
+			if req.Header.Height == 3 {
+				store := ctx.KVStore(capKey1)
+				setIntOnStore(store, sharedKey, getIntFromStore(store, sharedKey)+100)
+			}
+
+			return abci.ResponseBeginBlock{}
+		})
+	}
+
+	codec := codec.NewLegacyAmino()
+	registerTestCodec(codec)
+
+	var txs [][]byte
+	for i := int64(0); i < 20; i++ {
+		tx := newTxCounter(i, i)
+		if i%7 == 5 {
+			tx.setFailOnHandler(true)
+		}
+
+		txBytes, err := codec.Marshal(tx)
+		require.NoError(t, err)
+		txs = append(txs, txBytes)
+	}
+
+	// newHeader returns a valid header of the given height and its hash
+	newHeader := func(height int64, round int) (tmproto.Header, []byte) {
+		header := tmtypes.Header{
+			Version:         tmversion.Consensus{Block: version.BlockProtocol},
+			ChainID:         "test-chain",
+			Height:          height,
+			Time:            time.Unix(height, int64(round)).UTC(),
+			ValidatorsHash:  tmhash.Sum([]byte("validators")),
+			ProposerAddress: tmhash.SumTruncated([]byte("proposer")),
+		}
+
+		return *header.ToProto(), header.Hash()
+	}
+
+	testCases := []struct {
+		name      string
+		proposed  [][]byte
+		round     int
+		delivered [][]byte
+		// matched is true if the proposed block is finalized, and reused is
+		// true if all optimistic executions are reused by DeliverTx
+		matched bool
+		reused  bool
+	}{
+		// the first block cannot be executed optimistically, as its state is
+		// not committed, and is executed in parallel on BeginBlock instead
+		{"first block", txs, 0, txs, true, true},
+		{"same block", txs, 0, txs, true, true},
+		{"invalidated by BeginBlock", txs, 0, txs, true, true},
+		{"different block", txs[:10], 1, txs, false, false},
+		{"different txs", txs, 0, append(txs[:5:5], txs[10:]...), true, false},
+		{"empty block", nil, 0, nil, true, true},
+	}
+
+	var (
+		responses [2][][]abci.ResponseDeliverTx
+		commits   [2][]abci.ResponseCommit
+	)
+	for i, optimistic := range []bool{false, true} {
+		// the blocks are executed sequentially without optimistic execution
+		workers := 0
+		if optimistic {
+			workers = 4
+		}
+		app := setupBaseApp(t, anteOpt, routerOpt, beginBlockerOpt,
+			SetParallelTxExecution(workers), SetOptimisticExecution(optimistic),
+			SetInterBlockCache(cache.NewCommitKVStoreCacheManager(cache.DefaultCommitKVStoreCacheSize)))
+		app.InitChain(abci.RequestInitChain{})
+
+		for j, tc := range testCases {
+			height := int64(j + 1)
+			proposedHeader, _ := newHeader(height, 0)
+			res := app.ProcessProposal(abci.RequestProcessProposal{
+				Header:    proposedHeader,
+				BlockData: &tmproto.Data{Txs: tc.proposed},
+			})
+			require.Equal(t, abci.ResponseProcessProposal_ACCEPT, res.Result, tc.name)
+
+			// CheckTx and the queries read the committed state while the txs
+			// are executed in the background, which the race detector checks
+			for _, tx := range tc.proposed {
+				app.CheckTx(abci.RequestCheckTx{Tx: tx})
+				app.Query(abci.RequestQuery{Path: "/store/key1/key", Data: sharedKey})
+			}
+
+			header, hash := newHeader(height, tc.round)
+			app.BeginBlock(abci.RequestBeginBlock{Header: header, Hash: hash})
+			require.Equal(t, optimistic && tc.matched, app.optimisticExec != nil, tc.name)
+
+			var blockResponses []abci.ResponseDeliverTx
+			for _, tx := range tc.delivered {
+				blockResponses = append(blockResponses, app.DeliverTx(abci.RequestDeliverTx{Tx: tx}))
+			}
+			require.Equal(t, optimistic && tc.reused, app.optimisticExec != nil, tc.name)
+
+			app.EndBlock(abci.RequestEndBlock{Height: height})
+			require.Nil(t, app.optimisticExec)
+
+			responses[i] = append(responses[i], blockResponses)
+			commits[i] = append(commits[i], app.Commit())
+		}
+	}
+
+	require.Equal(t, responses[0], responses[1])
+	require.Equal(t, commits[0], commits[1])
+}
+
+func TestParallelBlockExecution(t *testing.T) {
+	sharedKey := []byte("shared-key")
+
+	// every tx increments a shared counter in the AnteHandler and writes its
+	// own key when its message is executed
+	anteOpt := func(bapp *BaseApp) {
+		bapp.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+			store := ctx.KVStore(capKey1)
+			setIntOnStore(store, sharedKey, getIntFromStore(store, sharedKey)+1)
+			return ctx, nil
+		})
+	}
+	routerOpt := func(bapp *BaseApp) {
+		bapp.Router().AddRoute(sdk.NewRoute(routeMsgCounter, func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+			m := msg.(*msgCounter)
+			if m.FailOnHandler {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "message handler failure")
+			}
+
+			store := ctx.KVStore(capKey2)
+			shared := getIntFromStore(ctx.KVStore(capKey1), sharedKey)
+			setIntOnStore(store, []byte(fmt.Sprintf("key-%d", m.Counter)), shared)
+
+			return &sdk.Result{}, nil
+		}))
+	}
+
+	codec := codec.NewLegacyAmino()
+	registerTestCodec(codec)
+
+	var txs [][]byte
+	for i := int64(0); i < 20; i++ {
+		tx := newTxCounter(i, i)
+		if i%7 == 5 {
+			tx.setFailOnHandler(true)
+		}
+
+		txBytes, err := codec.Marshal(tx)
+		require.NoError(t, err)
+		txs = append(txs, txBytes)
+	}
+
+	newHeader := func(height int64, round int) (tmproto.Header, []byte) {
+		header := tmtypes.Header{
+			Version:         tmversion.Consensus{Block: version.BlockProtocol},
+			ChainID:         "test-chain",
+			Height:          height,
+			Time:            time.Unix(height, int64(round)).UTC(),
+			ValidatorsHash:  tmhash.Sum([]byte("validators")),
+			ProposerAddress: tmhash.SumTruncated([]byte("proposer")),
+		}
+
+		return *header.ToProto(), header.Hash()
+	}
+
+	testCases := []struct {
+		name      string
+		proposed  [][]byte
+		round     int
+		delivered [][]byte
+		// executed is true if the txs are executed in parallel on BeginBlock,
+		// and reused is true if all executions are reused by DeliverTx
+		executed bool
+		reused   bool
+	}{
+		{"first block", txs, 0, txs, true, true},
+		{"same block", txs, 0, txs, true, true},
+		{"different block", txs[:10], 1, txs, false, false},
+		{"different txs", txs, 0, append(txs[:5:5], txs[10:]...), true, false},
+		{"no proposal", nil, 0, txs, false, false},
+	}
+
+	var (
+		responses [2][][]abci.ResponseDeliverTx
+		commits   [2][]abci.ResponseCommit
+	)
+	for i, workers := range []int{0, 4} {
+		app := setupBaseApp(t, anteOpt, routerOpt, SetParallelTxExecution(workers))
+		app.InitChain(abci.RequestInitChain{})
+
+		for j, tc := range testCases {
+			height := int64(j + 1)
+			if tc.proposed != nil {
+				proposedHeader, _ := newHeader(height, 0)
+				res := app.ProcessProposal(abci.RequestProcessProposal{
+					Header:    proposedHeader,
+					BlockData: &tmproto.Data{Txs: tc.proposed},
+				})
+				require.Equal(t, abci.ResponseProcessProposal_ACCEPT, res.Result, tc.name)
+			}
+
+			header, hash := newHeader(height, tc.round)
+			app.BeginBlock(abci.RequestBeginBlock{Header: header, Hash: hash})
+			require.Equal(t, workers > 0 && tc.executed, app.optimisticExec != nil, tc.name)
+
+			var blockResponses []abci.ResponseDeliverTx
+			for _, tx := range tc.delivered {
+				blockResponses = append(blockResponses, app.DeliverTx(abci.RequestDeliverTx{Tx: tx}))
+			}
+			require.Equal(t, workers > 0 && tc.reused, app.optimisticExec != nil, tc.name)
+
+			app.EndBlock(abci.RequestEndBlock{Height: height})
+			require.Nil(t, app.optimisticExec)
+
+			responses[i] = append(responses[i], blockResponses)
+			commits[i] = append(commits[i], app.Commit())
+		}
+	}
+
+	require.Equal(t, responses[0], responses[1])
+	require.Equal(t, commits[0], commits[1])
+}
+
+func TestModuleKeyReentry(t *testing.T) {
+	app := setupBaseApp(t)
+	testdata.RegisterQueryServer(app.GRPCQueryRouter(), testdata.QueryImpl{})
+	app.InitChain(abci.RequestInitChain{})
+
+	ctx := app.NewContext(false, tmproto.Header{})
+	key := app.rootModuleKey("foo")
+
+	res, err := testdata.NewQueryClient(key).Echo(sdk.WrapSDKContext(ctx), &testdata.EchoRequest{Message: "hello"})
+	require.NoError(t, err)
+	require.Equal(t, "hello", res.Message)
+
+	// a module cannot be called while it is in the call stack
+	ctx = ctx.WithContext(context.WithValue(ctx.Context(), moduleCallersKey{}, []string{"foo", "bar"}))
+	_, err = testdata.NewQueryClient(app.rootModuleKey("baz")).Echo(sdk.WrapSDKContext(ctx), &testdata.EchoRequest{})
+	require.NoError(t, err)
+	_, err = testdata.NewQueryClient(key).Echo(sdk.WrapSDKContext(ctx), &testdata.EchoRequest{})
+	require.True(t, sdkerrors.ErrUnauthorized.Is(err), err)
+}
+
+func TestModuleKeyIssuer(t *testing.T) {
+	app := newBaseApp(t.Name())
+	issue := app.ModuleKeyIssuer()
+	require.Panics(t, func() { app.ModuleKeyIssuer() })
+
+	key := issue("foo")
+	require.Equal(t, intermodule.ModuleID{ModuleName: "foo"}, key.ID())
+	require.NotPanics(t, func() { issue("bar") })
+
+	// the key of a module is only issued once
+	require.Panics(t, func() { issue("foo") })
+	require.Panics(t, func() { app.rootModuleKey("bar") })
+
+	// the issuer must be requested before the BaseApp is sealed
+	app = newBaseApp(t.Name())
+	app.Seal()
+	require.Panics(t, func() { app.ModuleKeyIssuer() })
+}
+
+func TestMsgTelemetry(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Hour, time.Hour)
+	cfg := metrics.DefaultConfig("test")
+	cfg.EnableHostname = false
+	cfg.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(cfg, sink)
+	require.NoError(t, err)
+	defer metrics.NewGlobal(metrics.DefaultConfig(""), &metrics.BlackholeSink{}) //nolint:errcheck
+
+	anteOpt := func(bapp *BaseApp) {
+		bapp.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			return ctx.WithGasMeter(sdk.NewGasMeter(100)), nil
+		})
+	}
+	routerOpt := func(bapp *BaseApp) {
+		bapp.Router().AddRoute(sdk.NewRoute(routeMsgCounter, func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+			m := msg.(*msgCounter)
+			ctx.GasMeter().ConsumeGas(uint64(m.Counter), "test")
+			if m.FailOnHandler {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "message handler failure")
+			}
+
+			return &sdk.Result{}, nil
+		}))
+	}
+
+	app := setupBaseApp(t, anteOpt, routerOpt)
+	app.InitChain(abci.RequestInitChain{})
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 1}})
+
+	codec := codec.NewLegacyAmino()
+	registerTestCodec(codec)
+
+	deliver := func(tx *txTest) abci.ResponseDeliverTx {
+		txBytes, err := codec.Marshal(tx)
+		require.NoError(t, err)
+		return app.DeliverTx(abci.RequestDeliverTx{Tx: txBytes})
+	}
+
+	// the gas used by every message is added to its message event
+	res := deliver(newTxCounter(0, 10, 20))
+	require.True(t, res.IsOK(), res.Log)
+
+	var gasUsed []string
+	for _, event := range res.Events {
+		for _, attr := range event.Attributes {
+			if event.Type == sdk.EventTypeMessage && string(attr.Key) == sdk.AttributeKeyGasUsed {
+				gasUsed = append(gasUsed, string(attr.Value))
+			}
+		}
+	}
+	require.Equal(t, []string{"10", "20"}, gasUsed)
+
+	failingTx := newTxCounter(1, 30)
+	failingTx.setFailOnHandler(true)
+	require.False(t, deliver(failingTx).IsOK())
+
+	// a message which runs out of gas panics
+	require.False(t, deliver(newTxCounter(2, 200)).IsOK())
+
+	msgType := sdk.MsgTypeURL(&msgCounter{})
+	data := sink.Data()
+	require.NotEmpty(t, data)
+
+	samples := data[0].Samples
+	require.Equal(t, 3, samples["test.tx.msg.gas.used;msg_type="+msgType].Count)
+	require.Equal(t, 4, samples["test.tx.msg.time;msg_type="+msgType].Count)
+
+	counters := data[0].Counters
+	require.Equal(t, 1, counters["test.tx.msg.failed;msg_type="+msgType+";codespace=sdk;code=18"].Count)
+	require.Equal(t, 1, counters["test.tx.msg.panic;msg_type="+msgType].Count)
+}
+
+func TestMsgTelemetrySpeculative(t *testing.T) {
+	sharedKey := []byte("shared-key")
+
+	// every tx increments a shared counter in the AnteHandler, slowly enough
+	// for the txs executed at once to conflict and be executed again
+	anteOpt := func(bapp *BaseApp) {
+		bapp.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+			store := ctx.KVStore(capKey1)
+			shared := getIntFromStore(store, sharedKey)
+			time.Sleep(time.Millisecond)
+			setIntOnStore(store, sharedKey, shared+1)
+			return ctx, nil
+		})
+	}
+	routerOpt := func(bapp *BaseApp) {
+		bapp.Router().AddRoute(sdk.NewRoute(routeMsgCounter, func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+			if msg.(*msgCounter).FailOnHandler {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "message handler failure")
+			}
+			return &sdk.Result{}, nil
+		}))
+	}
+
+	codec := codec.NewLegacyAmino()
+	registerTestCodec(codec)
+
+	var reqs []abci.RequestDeliverTx
+	for i := int64(0); i < 10; i++ {
+		tx := newTxCounter(i, i)
+		tx.setFailOnHandler(i%2 == 1)
+		txBytes, err := codec.Marshal(tx)
+		require.NoError(t, err)
+		reqs = append(reqs, abci.RequestDeliverTx{Tx: txBytes})
+	}
+
+	msgType := sdk.MsgTypeURL(&msgCounter{})
+	for _, workers := range []int{0, 4} {
+		sink := metrics.NewInmemSink(time.Hour, time.Hour)
+		cfg := metrics.DefaultConfig("test")
+		cfg.EnableHostname = false
+		cfg.EnableRuntimeMetrics = false
+		_, err := metrics.NewGlobal(cfg, sink)
+		require.NoError(t, err)
+
+		app := setupBaseApp(t, anteOpt, routerOpt, SetParallelTxExecution(workers))
+		app.InitChain(abci.RequestInitChain{})
+		app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 1}})
+		app.DeliverTxBatch(reqs)
+
+		// only the executions applied to the deliver state are accounted for
+		data := sink.Data()
+		require.NotEmpty(t, data)
+		require.Equal(t, len(reqs), data[0].Samples["test.tx.msg.gas.used;msg_type="+msgType].Count)
+		require.Equal(t, len(reqs), data[0].Samples["test.tx.msg.time;msg_type="+msgType].Count)
+		require.Equal(t, len(reqs)/2, data[0].Counters["test.tx.msg.failed;msg_type="+msgType+";codespace=sdk;code=18"].Count)
+		if workers > 0 {
+			require.Positive(t, data[0].Counters["test.tx.parallel.reexecuted"].Sum)
+		}
+	}
+	metrics.NewGlobal(metrics.DefaultConfig(""), &metrics.BlackholeSink{}) //nolint:errcheck
+}
+
+func TestStoreGasConfigs(t *testing.T) {
+	codeConfig := sdk.GasConfig{HasCost: 1}
+	app := setupBaseApp(t, func(app *BaseApp) {
+		app.SetStoreGasConfigs(map[sdk.StoreKey]sdk.GasConfig{capKey1: codeConfig})
+	})
+
+	hasCost := func(ctx sdk.Context, key sdk.StoreKey) sdk.Gas {
+		ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+		ctx.KVStore(key).Has([]byte("key"))
+		return ctx.GasMeter().GasConsumed()
+	}
+	require.Equal(t, codeConfig.HasCost, hasCost(app.checkState.ctx, capKey1))
+	require.Equal(t, store.KVGasConfig().HasCost, hasCost(app.checkState.ctx, capKey2))
+
+	// the parameter overrides the gas configs set in code from the next block on
+	paramConfig := sdk.GasConfig{HasCost: 2}
+	header := tmproto.Header{Height: 1}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+	app.paramStore.Set(app.deliverState.ctx, ParamStoreKeyStoreGasConfigs, []StoreGasConfig{
+		{StoreKey: capKey1.Name(), GasConfig: paramConfig},
+		{StoreKey: capKey2.Name(), GasConfig: paramConfig},
+		{StoreKey: "unknown", GasConfig: paramConfig},
+	})
+	require.Equal(t, codeConfig.HasCost, hasCost(app.deliverState.ctx, capKey1))
+	app.EndBlock(abci.RequestEndBlock{Height: header.Height})
+	app.Commit()
+
+	require.Equal(t, paramConfig.HasCost, hasCost(app.checkState.ctx, capKey1))
+	require.Equal(t, paramConfig.HasCost, hasCost(app.checkState.ctx, capKey2))
+
+	header.Height++
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+	require.Equal(t, paramConfig.HasCost, hasCost(app.deliverState.ctx, capKey1))
+}
+
+func TestInitChainStoreGasConfigs(t *testing.T) {
+	paramConfig := sdk.GasConfig{HasCost: 2}
+	app := setupBaseApp(t, func(app *BaseApp) {
+		app.SetInitChainer(func(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
+			app.paramStore.Set(ctx, ParamStoreKeyStoreGasConfigs, []StoreGasConfig{
+				{StoreKey: capKey1.Name(), GasConfig: paramConfig},
+			})
+			return abci.ResponseInitChain{}
+		})
+	})
+
+	app.InitChain(abci.RequestInitChain{ChainId: "test-chain-id"})
+
+	// the gas configs set in genesis apply from the first block
+	for _, ctx := range []sdk.Context{app.deliverState.ctx, app.checkState.ctx} {
+		ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+		ctx.KVStore(capKey1).Has([]byte("key"))
+		require.Equal(t, paramConfig.HasCost, ctx.GasMeter().GasConsumed())
+	}
+	config, ok := app.cms.GetGasConfig(capKey1)
+	require.True(t, ok)
+	require.Equal(t, paramConfig, config)
+}
+
+func TestValidateStoreGasConfigs(t *testing.T) {
+	require.NoError(t, ValidateStoreGasConfigs([]StoreGasConfig{{StoreKey: "a"}, {StoreKey: "b"}}))
+	require.Error(t, ValidateStoreGasConfigs([]StoreGasConfig{{StoreKey: ""}}))
+	require.Error(t, ValidateStoreGasConfigs([]StoreGasConfig{{StoreKey: "a"}, {StoreKey: "a"}}))
+	require.Error(t, ValidateStoreGasConfigs(sdk.GasConfig{}))
+}
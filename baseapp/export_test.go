@@ -0,0 +1,9 @@
+package baseapp
+
+import "github.com/cosmos/cosmos-sdk/types/intermodule"
+
+// RootModuleKey exposes rootModuleKey to the tests of the baseapp_test package,
+// as the module keys of an app are only handed to its modules.
+func (app *BaseApp) RootModuleKey(moduleName string) intermodule.RootModuleKey {
+	return app.rootModuleKey(moduleName)
+}
@@ -57,12 +57,26 @@ func (app *BaseApp) RegisterGRPCServer(server gogogrpc.Server) {
 			height = sdkCtx.BlockHeight() // If height was not set in the request, set it to the latest
 		}
 
+		// Abort the query once the client's context is done, and report the gas
+		// consumed by the query, even if it failed.
+		gasMeter := newCancelableGasMeter(grpcCtx, sdkCtx.GasMeter())
+		sdkCtx = sdkCtx.WithContext(grpcCtx).WithGasMeter(gasMeter)
+
 		// Attach the sdk.Context into the gRPC's context.Context.
 		grpcCtx = context.WithValue(grpcCtx, sdk.SdkContextKey, sdkCtx)
 
 		md = metadata.Pairs(grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10))
 		grpc.SetHeader(grpcCtx, md)
 
+		defer func() {
+			if r := recover(); r != nil {
+				resp, err = nil, queryErrorToGRPCError(queryPanicToError(r, gasMeter))
+			}
+
+			md := metadata.Pairs(grpctypes.GRPCQueryGasUsedHeader, strconv.FormatUint(gasMeter.GasConsumed(), 10))
+			grpc.SetHeader(grpcCtx, md)
+		}()
+
 		return handler(grpcCtx, req)
 	}
 
@@ -96,3 +110,13 @@ func (app *BaseApp) RegisterGRPCServer(server gogogrpc.Server) {
 		server.RegisterService(newDesc, data.handler)
 	}
 }
+
+// queryErrorToGRPCError converts an error returned by queryPanicToError into
+// a gRPC status error.
+func queryErrorToGRPCError(err error) error {
+	if sdkerrors.IsOf(err, sdkerrors.ErrOutOfGas) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	return status.FromContextError(err).Err()
+}
@@ -0,0 +1,142 @@
+package baseapp
+
+import (
+	gocontext "context"
+	"fmt"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/intermodule"
+)
+
+// ModuleKeyIssuer returns the function issuing the ModuleKeys of the modules,
+// to be handed to module.NewConfiguratorWithModuleKeys so that every module
+// registered in the module manager gets its own key. It panics if it is
+// called more than once or once the BaseApp is sealed.
+func (app *BaseApp) ModuleKeyIssuer() func(moduleName string) intermodule.RootModuleKey {
+	if app.sealed {
+		panic("ModuleKeyIssuer() on sealed BaseApp")
+	}
+	if app.moduleKeys != nil {
+		panic("ModuleKeyIssuer() called more than once")
+	}
+
+	app.moduleKeys = make(map[string]bool)
+	return app.rootModuleKey
+}
+
+// rootModuleKey returns the ModuleKey of the root account of the given module,
+// which routes Msgs through the MsgServiceRouter and queries through the
+// GRPCQueryRouter of the BaseApp. The key of a module is only issued once, so
+// that it can only be handed to the module itself.
+func (app *BaseApp) rootModuleKey(moduleName string) intermodule.RootModuleKey {
+	if app.moduleKeys == nil {
+		app.moduleKeys = make(map[string]bool)
+	}
+	if app.moduleKeys[moduleName] {
+		panic(fmt.Sprintf("module key of module %s already issued", moduleName))
+	}
+	app.moduleKeys[moduleName] = true
+
+	router := interModuleRouter{msr: app.msgServiceRouter, qrt: app.grpcQueryRouter}
+	return intermodule.NewRootModuleKey(moduleName, router.invoke)
+}
+
+// moduleCallersKey is the context key of the modules in the current call
+// stack of inter-module calls.
+type moduleCallersKey struct{}
+
+// interModuleRouter routes the calls made with a ModuleKey to the Msg and
+// Query services of the modules. Its invoke method is the Invoker of
+// the keys issued by the BaseApp.
+type interModuleRouter struct {
+	msr *MsgServiceRouter
+	qrt *GRPCQueryRouter
+}
+
+// invoke executes the given Msg or query on behalf of the caller. Msgs are
+// executed on a branch of the state, which is written if the Msg succeeds,
+// while queries are executed on a branch which is always discarded. A module
+// which is already in the call stack cannot make further calls, so that no
+// module is re-entered while it calls another module.
+func (r interModuleRouter) invoke(goCtx gocontext.Context, caller intermodule.ModuleID, method string, args, reply interface{}) error {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	callers, _ := ctx.Context().Value(moduleCallersKey{}).([]string)
+	for _, name := range callers {
+		if name == caller.ModuleName {
+			return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "re-entrant call of module %s to %s", caller.ModuleName, method)
+		}
+	}
+
+	callers = append(callers[:len(callers):len(callers)], caller.ModuleName)
+	ctx = ctx.WithContext(gocontext.WithValue(ctx.Context(), moduleCallersKey{}, callers))
+
+	if msg, ok := args.(sdk.Msg); ok {
+		if handler := r.msr.Handler(msg); handler != nil {
+			return r.invokeMsg(ctx, caller, handler, msg, reply)
+		}
+	}
+
+	if handler := r.qrt.Route(method); handler != nil {
+		return r.invokeQuery(ctx, handler, method, args, reply)
+	}
+
+	return sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unrecognized method %s", method)
+}
+
+func (r interModuleRouter) invokeMsg(ctx sdk.Context, caller intermodule.ModuleID, handler MsgServiceHandler, msg sdk.Msg, reply interface{}) error {
+	if err := msg.ValidateBasic(); err != nil {
+		return err
+	}
+
+	signers := msg.GetSigners()
+	if len(signers) != 1 || !signers[0].Equals(caller.Address()) {
+		return sdkerrors.Wrapf(
+			sdkerrors.ErrUnauthorized, "module %s cannot sign %s as %s, expected signers %v",
+			caller.ModuleName, sdk.MsgTypeURL(msg), caller.Address(), signers,
+		)
+	}
+
+	cacheCtx, write := ctx.CacheContext()
+	res, err := handler(cacheCtx, msg)
+	if err != nil {
+		return err
+	}
+
+	write()
+	ctx.EventManager().EmitEvents(res.GetEvents())
+
+	return unmarshalReply(res.Data, reply, r.msr.interfaceRegistry)
+}
+
+func (r interModuleRouter) invokeQuery(ctx sdk.Context, handler GRPCQueryHandler, method string, args, reply interface{}) error {
+	reqBz, err := protoCodec.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	cacheCtx, _ := ctx.CacheContext()
+	res, err := handler(cacheCtx, abci.RequestQuery{Data: reqBz, Path: method, Height: ctx.BlockHeight()})
+	if err != nil {
+		return err
+	}
+
+	return unmarshalReply(res.Value, reply, r.qrt.interfaceRegistry)
+}
+
+// unmarshalReply unmarshals the response of an inter-module call into reply.
+func unmarshalReply(bz []byte, reply interface{}, interfaceRegistry codectypes.InterfaceRegistry) error {
+	if err := protoCodec.Unmarshal(bz, reply); err != nil {
+		return err
+	}
+
+	if interfaceRegistry != nil {
+		return codectypes.UnpackInterfaces(reply, interfaceRegistry)
+	}
+
+	return nil
+}
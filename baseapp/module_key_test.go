@@ -0,0 +1,64 @@
+package baseapp_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+func TestModuleKeyMsg(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{})
+
+	rootKey := app.BaseApp.RootModuleKey("foo")
+	derivedKey := rootKey.Derive([]byte("pool"))
+	require.Equal(t, authtypes.NewModuleAddress("foo"), rootKey.Address())
+	require.NotEqual(t, rootKey.Address(), derivedKey.Address())
+
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 100))
+	require.NoError(t, simapp.FundAccount(app.BankKeeper, ctx, rootKey.Address(), coins))
+
+	// the root account sends coins to the derived account
+	_, err := banktypes.NewMsgClient(rootKey).Send(sdk.WrapSDKContext(ctx),
+		banktypes.NewMsgSend(rootKey.Address(), derivedKey.Address(), coins))
+	require.NoError(t, err)
+	require.Equal(t, coins, app.BankKeeper.GetAllBalances(ctx, derivedKey.Address()))
+	require.NotEmpty(t, ctx.EventManager().Events())
+
+	// the root account cannot act as the derived account
+	_, err = banktypes.NewMsgClient(rootKey).Send(sdk.WrapSDKContext(ctx),
+		banktypes.NewMsgSend(derivedKey.Address(), rootKey.Address(), coins))
+	require.True(t, sdkerrors.ErrUnauthorized.Is(err), err)
+
+	// a failed Msg does not modify the state
+	_, err = banktypes.NewMsgClient(derivedKey).Send(sdk.WrapSDKContext(ctx),
+		banktypes.NewMsgSend(derivedKey.Address(), rootKey.Address(), coins.Add(coins...)))
+	require.True(t, sdkerrors.ErrInsufficientFunds.Is(err), err)
+	require.Equal(t, coins, app.BankKeeper.GetAllBalances(ctx, derivedKey.Address()))
+
+	_, err = banktypes.NewMsgClient(derivedKey).Send(sdk.WrapSDKContext(ctx),
+		banktypes.NewMsgSend(derivedKey.Address(), rootKey.Address(), coins))
+	require.NoError(t, err)
+	require.Equal(t, coins, app.BankKeeper.GetAllBalances(ctx, rootKey.Address()))
+}
+
+func TestModuleKeyQuery(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{})
+
+	key := app.BaseApp.RootModuleKey("foo")
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 100))
+	require.NoError(t, simapp.FundAccount(app.BankKeeper, ctx, key.Address(), coins))
+
+	res, err := banktypes.NewQueryClient(key).AllBalances(sdk.WrapSDKContext(ctx),
+		&banktypes.QueryAllBalancesRequest{Address: key.Address().String()})
+	require.NoError(t, err)
+	require.Equal(t, coins, res.Balances)
+}
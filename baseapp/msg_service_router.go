@@ -17,6 +17,14 @@ import (
 type MsgServiceRouter struct {
 	interfaceRegistry codectypes.InterfaceRegistry
 	routes            map[string]MsgServiceHandler
+	circuitBreaker    CircuitBreaker
+}
+
+// CircuitBreaker is consulted by the MsgServiceRouter before dispatching a Msg
+// to its handler, allowing individual Msg types to be disabled.
+type CircuitBreaker interface {
+	// IsAllowed returns true if Msgs of the given type URL may be executed.
+	IsAllowed(ctx sdk.Context, typeURL string) bool
 }
 
 var _ gogogrpc.Server = &MsgServiceRouter{}
@@ -107,6 +115,10 @@ func (msr *MsgServiceRouter) RegisterService(sd *grpc.ServiceDesc, handler inter
 		}
 
 		msr.routes[requestTypeName] = func(ctx sdk.Context, req sdk.Msg) (*sdk.Result, error) {
+			if msr.circuitBreaker != nil && !msr.circuitBreaker.IsAllowed(ctx, requestTypeName) {
+				return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "%s is disabled by the circuit breaker", requestTypeName)
+			}
+
 			ctx = ctx.WithEventManager(sdk.NewEventManager())
 			interceptor := func(goCtx context.Context, _ interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 				goCtx = context.WithValue(goCtx, sdk.SdkContextKey, ctx)
@@ -134,6 +146,12 @@ func (msr *MsgServiceRouter) SetInterfaceRegistry(interfaceRegistry codectypes.I
 	msr.interfaceRegistry = interfaceRegistry
 }
 
+// SetCircuitBreaker sets the circuit breaker which is consulted before
+// dispatching a Msg to its handler.
+func (msr *MsgServiceRouter) SetCircuitBreaker(cb CircuitBreaker) {
+	msr.circuitBreaker = cb
+}
+
 func noopDecoder(_ interface{}) error { return nil }
 func noopInterceptor(_ context.Context, _ interface{}, _ *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
 	return nil, nil
@@ -1,6 +1,7 @@
 package baseapp_test
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -14,6 +15,8 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/simapp"
 	"github.com/cosmos/cosmos-sdk/testutil/testdata"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
 )
@@ -120,3 +123,37 @@ func TestMsgService(t *testing.T) {
 	res := app.DeliverTx(abci.RequestDeliverTx{Tx: txBytes})
 	require.Equal(t, abci.CodeTypeOK, res.Code, "res=%+v", res)
 }
+
+type mockCircuitBreaker map[string]bool
+
+func (cb mockCircuitBreaker) IsAllowed(_ sdk.Context, typeURL string) bool {
+	return !cb[typeURL]
+}
+
+func TestMsgServiceCircuitBreaker(t *testing.T) {
+	encCfg := simapp.MakeTestEncodingConfig()
+	testdata.RegisterInterfaces(encCfg.InterfaceRegistry)
+	app := baseapp.NewBaseApp("test", log.NewNopLogger(), dbm.NewMemDB(), encCfg.TxConfig.TxDecoder())
+	app.SetInterfaceRegistry(encCfg.InterfaceRegistry)
+	testdata.RegisterMsgServer(
+		app.MsgServiceRouter(),
+		testdata.MsgServerImpl{},
+	)
+
+	msg := &testdata.MsgCreateDog{Dog: &testdata.Dog{Name: "Spot"}}
+	handler := app.MsgServiceRouter().Handler(msg)
+	ctx := sdk.Context{}.WithContext(context.Background())
+
+	breaker := mockCircuitBreaker{}
+	app.MsgServiceRouter().SetCircuitBreaker(breaker)
+	_, err := handler(ctx, msg)
+	require.NoError(t, err)
+
+	breaker[sdk.MsgTypeURL(msg)] = true
+	_, err = handler(ctx, msg)
+	require.ErrorIs(t, err, sdkerrors.ErrUnauthorized)
+
+	delete(breaker, sdk.MsgTypeURL(msg))
+	_, err = handler(ctx, msg)
+	require.NoError(t, err)
+}
@@ -0,0 +1,213 @@
+package baseapp
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/store/multiversion"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// optimisticExecution holds the optimistic execution of the txs of a block
+// proposal, which is started on ProcessProposal before the block is decided.
+//
+// The txs are executed in DeliverTx mode on multi-version stores on top of an
+// immutable view of the latest committed version, as done by DeliverTxBatch. If the same
+// block is then finalized, the multi-version stores are rebased onto the
+// deliver state once BeginBlock has run, and every DeliverTx reuses the
+// execution of its tx if it is still valid, i.e. if it did not observe a key
+// that was since written by BeginBlock or by a preceding tx. Otherwise the tx
+// is executed again on top of the final writes of all preceding txs.
+//
+// The parallel execution of a finalized block on BeginBlock, started by
+// executeAcceptedProposal, is reused by DeliverTx in the same way.
+type optimisticExecution struct {
+	// name is the name of the kind of execution in the telemetry
+	name string
+	// hash is the hash of the header of the proposed block
+	hash []byte
+	txs  [][]byte
+
+	ctx        sdk.Context
+	parents    []sdk.KVStore
+	mvStores   []*multiversion.Store
+	executions []*txExecution
+
+	// next is the index of the tx expected by the next DeliverTx
+	next int
+
+	aborted int32
+	done    chan struct{}
+}
+
+// abort stops the execution of further txs and waits for the txs that are
+// being executed.
+func (oe *optimisticExecution) abort() {
+	atomic.StoreInt32(&oe.aborted, 1)
+	<-oe.done
+}
+
+// startOptimisticExecution starts the optimistic execution of the txs of an
+// accepted block proposal in the background, if it is enabled with
+// SetOptimisticExecution.
+//
+// As the votes of the last commit are not known before BeginBlock, the txs are
+// executed with a context without vote infos.
+func (app *BaseApp) startOptimisticExecution(req abci.RequestProcessProposal) {
+	// The state of the first block is initialized by InitChain and is not
+	// committed before the block is executed.
+	if !app.optimisticExecution || app.deliverState != nil || app.cms.TracingEnabled() ||
+		req.Header.Height != app.LastBlockHeight()+1 || req.BlockData == nil {
+		return
+	}
+
+	hash, err := proposalHash(req.Header)
+	if err != nil {
+		app.logger.Error("failed to start optimistic execution", "height", req.Header.Height, "err", err)
+		return
+	}
+	if len(hash) == 0 {
+		return
+	}
+
+	// The txs are executed in the background, concurrently with CheckTx and the
+	// queries, so they read the latest committed state through an immutable
+	// view of its version instead of the shared IAVL trees and inter-block
+	// caches.
+	ms, err := app.cms.CacheMultiStoreWithVersion(app.LastBlockHeight())
+	if err != nil {
+		app.logger.Error("failed to start optimistic execution", "height", req.Header.Height, "err", err)
+		return
+	}
+
+	ctx := sdk.NewContext(ms, req.Header, false, app.logger).WithHeaderHash(hash)
+	ctx = ctx.WithConsensusParams(app.GetConsensusParams(ctx))
+
+	parents, mvStores := app.newMultiVersionStores(ms)
+	oe := &optimisticExecution{
+		name:       "optimistic_execution",
+		hash:       hash,
+		txs:        req.BlockData.Txs,
+		ctx:        ctx,
+		parents:    parents,
+		mvStores:   mvStores,
+		executions: make([]*txExecution, len(req.BlockData.Txs)),
+		done:       make(chan struct{}),
+	}
+
+	workers := app.parallelTxWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	go func() {
+		defer close(oe.done)
+
+		runParallel(workers, len(oe.txs), func(index int) bool {
+			if atomic.LoadInt32(&oe.aborted) == 1 {
+				return false
+			}
+
+			oe.executions[index] = app.executeTxVersioned(ctx, mvStores, index, oe.txs[index])
+			return true
+		})
+	}()
+
+	app.optimisticExec = oe
+	telemetry.IncrCounter(1, oe.name, "started")
+}
+
+// proposalHash returns the hash of the header of a block proposal.
+func proposalHash(h tmproto.Header) ([]byte, error) {
+	header, err := tmtypes.HeaderFromProto(&h)
+	if err != nil {
+		return nil, err
+	}
+
+	return header.Hash(), nil
+}
+
+// abortOptimisticExecution aborts and discards the optimistic execution, if
+// any.
+func (app *BaseApp) abortOptimisticExecution() {
+	if app.optimisticExec == nil {
+		return
+	}
+
+	app.optimisticExec.abort()
+	app.optimisticExec = nil
+}
+
+// rebaseOptimisticExecution is called on BeginBlock, after the BeginBlocker
+// has run. It waits for the optimistic execution of the block with the given
+// hash and rebases it onto the deliver state, or discards the optimistic
+// execution of any other block.
+func (app *BaseApp) rebaseOptimisticExecution(hash []byte) {
+	oe := app.optimisticExec
+	if oe == nil {
+		return
+	}
+
+	if !bytes.Equal(oe.hash, hash) {
+		app.abortOptimisticExecution()
+		telemetry.IncrCounter(1, oe.name, "discarded")
+		return
+	}
+
+	<-oe.done
+
+	for i, key := range app.storeKeys {
+		oe.parents[i] = app.deliverState.ms.GetKVStore(key)
+		oe.mvStores[i].SetParent(oe.parents[i])
+	}
+	oe.ctx = app.getContextForTx(runTxModeDeliver, nil)
+}
+
+// deliverTxOptimistic returns the optimistic execution of the given tx and
+// applies its writes to the deliver state. It returns false if there is no
+// optimistic execution of the tx, in which case it must be executed with
+// runTx. The optimistic execution is discarded once the txs of the block are
+// not delivered in the proposed order or a tx exhausts the block gas.
+func (app *BaseApp) deliverTxOptimistic(txBytes []byte) (*txExecution, bool) {
+	oe := app.optimisticExec
+	if oe == nil {
+		return nil, false
+	}
+
+	index := oe.next
+	if index >= len(oe.txs) || !bytes.Equal(oe.txs[index], txBytes) {
+		app.optimisticExec = nil
+		telemetry.IncrCounter(1, oe.name, "discarded")
+		return nil, false
+	}
+
+	// Once all preceding executions are validated, their writes are final,
+	// so executing an invalid tx again always yields a valid execution.
+	e := oe.executions[index]
+	if e == nil || !e.validate() {
+		e = app.executeTxVersioned(oe.ctx, oe.mvStores, index, txBytes)
+		telemetry.IncrCounter(1, oe.name, "reexecuted")
+	}
+
+	// A tx which exhausts the block gas fails when executed in sequence.
+	blockGasMeter := app.deliverState.ctx.BlockGasMeter()
+	if !fitsBlockGas(blockGasMeter, e.blockGas) {
+		app.optimisticExec = nil
+		telemetry.IncrCounter(1, oe.name, "discarded")
+		return nil, false
+	}
+
+	for i, mvStore := range oe.mvStores {
+		mvStore.WriteTo(index, oe.parents[i])
+	}
+	blockGasMeter.ConsumeGas(e.blockGas, "block gas meter")
+	e.commit()
+	oe.next++
+
+	return e, true
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/snapshots"
 	"github.com/cosmos/cosmos-sdk/store"
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
@@ -47,6 +48,12 @@ func SetMinRetainBlocks(minRetainBlocks uint64) func(*BaseApp) {
 	return func(bapp *BaseApp) { bapp.setMinRetainBlocks(minRetainBlocks) }
 }
 
+// SetQueryGasLimit returns a BaseApp option function that sets the maximum gas
+// a single gRPC or ABCI query may consume. A value of 0 disables the limit.
+func SetQueryGasLimit(limit uint64) func(*BaseApp) {
+	return func(bapp *BaseApp) { bapp.setQueryGasLimit(limit) }
+}
+
 // SetTrace will turn on or off trace flag
 func SetTrace(trace bool) func(*BaseApp) {
 	return func(app *BaseApp) { app.setTrace(trace) }
@@ -57,12 +64,36 @@ func SetIndexEvents(ie []string) func(*BaseApp) {
 	return func(app *BaseApp) { app.setIndexEvents(ie) }
 }
 
+// SetParallelTxExecution provides a BaseApp option function that sets the
+// number of workers used to execute the txs of a block in parallel, either with
+// DeliverTxBatch or on BeginBlock for the block proposals accepted by
+// ProcessProposal. A value below 2 disables parallel execution.
+func SetParallelTxExecution(workers int) func(*BaseApp) {
+	return func(app *BaseApp) { app.setParallelTxWorkers(workers) }
+}
+
+// SetOptimisticExecution provides a BaseApp option function that enables the
+// optimistic execution of accepted block proposals during ProcessProposal. The
+// execution is reused if the same block is finalized. The txs are executed on
+// the number of workers set with SetParallelTxExecution and the same
+// requirements apply.
+func SetOptimisticExecution(enabled bool) func(*BaseApp) {
+	return func(app *BaseApp) { app.setOptimisticExecution(enabled) }
+}
+
 // SetInterBlockCache provides a BaseApp option function that sets the
 // inter-block cache.
 func SetInterBlockCache(cache sdk.MultiStorePersistentCache) func(*BaseApp) {
 	return func(app *BaseApp) { app.setInterBlockCache(cache) }
 }
 
+// SetAsyncPruning returns a BaseApp option function that enables the pruning
+// of the multistore in a background goroutine. The zero options leave it
+// disabled.
+func SetAsyncPruning(opts rootmulti.AsyncPruningOptions) func(*BaseApp) {
+	return func(app *BaseApp) { app.setAsyncPruning(opts) }
+}
+
 // SetSnapshotInterval sets the snapshot interval.
 func SetSnapshotInterval(interval uint64) func(*BaseApp) {
 	return func(app *BaseApp) { app.SetSnapshotInterval(interval) }
@@ -95,6 +126,17 @@ func (app *BaseApp) SetParamStore(ps ParamStore) {
 	app.paramStore = ps
 }
 
+// SetStoreGasConfigs overrides the default gas configs of the KVStores of the
+// given keys, e.g. to make cheap stores that are read frequently cheaper. The
+// overrides of the StoreGasConfigs parameter take precedence over them.
+func (app *BaseApp) SetStoreGasConfigs(configs map[sdk.StoreKey]sdk.GasConfig) {
+	if app.sealed {
+		panic("SetStoreGasConfigs() on sealed BaseApp")
+	}
+
+	app.storeGasConfigs = configs
+}
+
 // SetVersion sets the application's version string.
 func (app *BaseApp) SetVersion(v string) {
 	if app.sealed {
@@ -156,6 +198,36 @@ func (app *BaseApp) SetAnteHandler(ah sdk.AnteHandler) {
 	app.anteHandler = ah
 }
 
+// SetPrepareProposalHandler sets the handler used to build the block data of
+// a proposal in PrepareProposal.
+func (app *BaseApp) SetPrepareProposalHandler(handler sdk.PrepareProposalHandler) {
+	if app.sealed {
+		panic("SetPrepareProposalHandler() on sealed BaseApp")
+	}
+
+	app.prepareProposal = handler
+}
+
+// SetProcessProposalHandler sets the handler used to accept or reject the
+// block data of a proposal in ProcessProposal.
+func (app *BaseApp) SetProcessProposalHandler(handler sdk.ProcessProposalHandler) {
+	if app.sealed {
+		panic("SetProcessProposalHandler() on sealed BaseApp")
+	}
+
+	app.processProposal = handler
+}
+
+// SetStateOverrideHandler sets the handler used to apply the state overrides
+// of a simulation other than raw KV store entries, e.g. account balances.
+func (app *BaseApp) SetStateOverrideHandler(handler StateOverrideHandler) {
+	if app.sealed {
+		panic("SetStateOverrideHandler() on sealed BaseApp")
+	}
+
+	app.stateOverrideHandler = handler
+}
+
 func (app *BaseApp) SetAddrPeerFilter(pf sdk.PeerFilter) {
 	if app.sealed {
 		panic("SetAddrPeerFilter() on sealed BaseApp")
@@ -231,6 +303,22 @@ func (app *BaseApp) SetSnapshotKeepRecent(snapshotKeepRecent uint32) {
 	app.snapshotKeepRecent = snapshotKeepRecent
 }
 
+// SetStreamingService is used to set a streaming service into the BaseApp hooks and load the listeners into the multistore
+func (app *BaseApp) SetStreamingService(s StreamingService) {
+	if app.sealed {
+		panic("SetStreamingService() on sealed BaseApp")
+	}
+
+	// add the listeners for each StoreKey
+	for key, lis := range s.Listeners() {
+		app.cms.AddListeners(key, lis)
+	}
+	// register the StreamingService within the BaseApp
+	// BaseApp will pass BeginBlock, DeliverTx, EndBlock and Commit requests and
+	// responses to the streaming services to update their ABCI context
+	app.abciListeners = append(app.abciListeners, s)
+}
+
 // SetInterfaceRegistry sets the InterfaceRegistry.
 func (app *BaseApp) SetInterfaceRegistry(registry types.InterfaceRegistry) {
 	app.interfaceRegistry = registry
@@ -0,0 +1,323 @@
+package baseapp
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/store/cachemulti"
+	"github.com/cosmos/cosmos-sdk/store/multiversion"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// txExecution holds the outcome of a single execution of a tx on the
+// multi-version stores of a block.
+type txExecution struct {
+	gInfo  sdk.GasInfo
+	result *sdk.Result
+	err    error
+
+	// blockGas is the gas the tx consumed from the block gas meter
+	blockGas uint64
+	stores   []*multiversion.VersionIndexedStore
+
+	// msgTelemetry is the telemetry of the message handlers, emitted by
+	// commit once the execution is applied to the deliver state
+	msgTelemetry []msgTelemetry
+}
+
+// validate returns true if the execution observed the final writes of all
+// preceding txs.
+func (e *txExecution) validate() bool {
+	for _, store := range e.stores {
+		if !store.Validate() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// commit is called once the writes of the execution are applied to the deliver
+// state, and emits the telemetry of its message handlers, so that the
+// discarded executions are not accounted for.
+func (e *txExecution) commit() {
+	for _, t := range e.msgTelemetry {
+		t.emit()
+	}
+}
+
+// acceptedProposal is a block proposal accepted by ProcessProposal.
+type acceptedProposal struct {
+	// hash is the hash of the header of the proposed block
+	hash []byte
+	txs  [][]byte
+}
+
+// DeliverTxBatch executes the given txs of the current block in DeliverTx mode
+// and returns their responses in block order. It must be called between
+// BeginBlock and EndBlock, and its result is identical to calling DeliverTx for
+// each tx in order.
+//
+// The ABCI DeliverTx calls of Tendermint are executed in parallel in the same
+// way when the block was accepted by ProcessProposal, see
+// executeAcceptedProposal.
+//
+// If parallel execution is enabled with SetParallelTxExecution, the txs are
+// first executed speculatively in parallel, each on multi-version branches of
+// the deliver state that record the keys it reads and writes. The executions
+// are then validated in block order: an execution that observed a key which was
+// since written by a preceding tx is discarded and the tx is executed again on
+// top of the final writes of all preceding txs. Finally, the writes are applied
+// to the deliver state in block order.
+//
+// Parallel execution requires that modules keep all of their state in stores
+// mounted with MountStore and that the AnteHandler sets the gas meter of every
+// tx, as the SetUpContextDecorator of x/auth does. It is disabled while store
+// tracing is enabled and while the block is executed optimistically, in which
+// case the optimistic executions are reused by DeliverTx.
+func (app *BaseApp) DeliverTxBatch(reqs []abci.RequestDeliverTx) []abci.ResponseDeliverTx {
+	responses := make([]abci.ResponseDeliverTx, len(reqs))
+
+	if app.parallelTxWorkers < 2 || len(reqs) < 2 || app.deliverState.ms.TracingEnabled() || app.optimisticExec != nil {
+		for i, req := range reqs {
+			responses[i] = app.DeliverTx(req)
+		}
+
+		return responses
+	}
+
+	defer telemetry.MeasureSince(time.Now(), "abci", "deliver_tx_batch")
+
+	executions := app.executeTxsParallel(reqs)
+	for i, req := range reqs {
+		e := executions[i]
+		responses[i] = app.deliverTxResponse(req, e.gInfo, e.result, e.err)
+	}
+
+	return responses
+}
+
+// setAcceptedProposal records a block proposal accepted by ProcessProposal,
+// whose txs are executed in parallel on BeginBlock if it is finalized and
+// parallel execution is enabled. The optimistic execution, if started, is
+// reused instead.
+func (app *BaseApp) setAcceptedProposal(req abci.RequestProcessProposal) {
+	if app.parallelTxWorkers < 2 || app.optimisticExec != nil || req.BlockData == nil || len(req.BlockData.Txs) < 2 {
+		return
+	}
+
+	hash, err := proposalHash(req.Header)
+	if err != nil || len(hash) == 0 {
+		return
+	}
+
+	app.acceptedProposal = &acceptedProposal{hash: hash, txs: req.BlockData.Txs}
+}
+
+// executeAcceptedProposal is called on BeginBlock, after the optimistic
+// execution of the block is rebased, if any. If the block is the proposal
+// accepted by ProcessProposal, its txs are executed in parallel on
+// multi-version stores on top of the deliver state, as done by DeliverTxBatch,
+// and every DeliverTx then applies the execution of its tx in block order, as
+// for the optimistic execution. The blocks which were not accepted by
+// ProcessProposal, e.g. when a node catches up with block sync, are executed
+// sequentially.
+func (app *BaseApp) executeAcceptedProposal(hash []byte) {
+	proposal := app.acceptedProposal
+	app.acceptedProposal = nil
+
+	if proposal == nil || app.optimisticExec != nil || app.deliverState.ms.TracingEnabled() ||
+		!bytes.Equal(proposal.hash, hash) {
+		return
+	}
+
+	defer telemetry.MeasureSince(time.Now(), "abci", "parallel_execution")
+
+	parents, mvStores := app.newMultiVersionStores(app.deliverState.ms)
+	ctx := app.getContextForTx(runTxModeDeliver, nil)
+	oe := &optimisticExecution{
+		name:       "parallel_execution",
+		hash:       proposal.hash,
+		txs:        proposal.txs,
+		ctx:        ctx,
+		parents:    parents,
+		mvStores:   mvStores,
+		executions: make([]*txExecution, len(proposal.txs)),
+		done:       make(chan struct{}),
+	}
+
+	runParallel(app.parallelTxWorkers, len(oe.txs), func(index int) bool {
+		oe.executions[index] = app.executeTxVersioned(ctx, mvStores, index, oe.txs[index])
+		return true
+	})
+	close(oe.done)
+
+	app.optimisticExec = oe
+	telemetry.IncrCounter(1, oe.name, "started")
+}
+
+// executeTxsParallel executes the given txs in parallel and applies their
+// writes to the deliver state in block order. See DeliverTxBatch for details.
+func (app *BaseApp) executeTxsParallel(reqs []abci.RequestDeliverTx) []*txExecution {
+	parents, mvStores := app.newMultiVersionStores(app.deliverState.ms)
+	ctx := app.getContextForTx(runTxModeDeliver, nil)
+	execute := func(index int) *txExecution {
+		return app.executeTxVersioned(ctx, mvStores, index, reqs[index].Tx)
+	}
+
+	// execute all txs speculatively
+	executions := make([]*txExecution, len(reqs))
+	runParallel(app.parallelTxWorkers, len(reqs), func(index int) bool {
+		executions[index] = execute(index)
+		return true
+	})
+
+	// Validate the executions in block order. Once an execution is validated,
+	// the writes of all txs up to and including it are final, so executing an
+	// invalid tx again always yields a valid execution.
+	reexecuted := 0
+	for index, e := range executions {
+		if !e.validate() {
+			executions[index] = execute(index)
+			reexecuted++
+		}
+	}
+	telemetry.IncrCounter(float32(reexecuted), "tx", "parallel", "reexecuted")
+
+	// apply the writes to the deliver state in block order
+	blockGasMeter := app.deliverState.ctx.BlockGasMeter()
+	for index, e := range executions {
+		// A tx which exhausts the block gas would fail when executed in
+		// sequence, so fall back to sequential execution from there on.
+		if !fitsBlockGas(blockGasMeter, e.blockGas) {
+			for i := index; i < len(reqs); i++ {
+				gInfo, result, err := app.runTx(runTxModeDeliver, reqs[i].Tx)
+				executions[i] = &txExecution{gInfo: gInfo, result: result, err: err}
+			}
+
+			break
+		}
+
+		for i, mvStore := range mvStores {
+			mvStore.WriteTo(index, parents[i])
+		}
+		blockGasMeter.ConsumeGas(e.blockGas, "block gas meter")
+		e.commit()
+	}
+
+	return executions
+}
+
+// newMultiVersionStores returns a multi-version store on top of every store of
+// the given multi-store that was mounted with MountStore, together with the
+// parent stores.
+func (app *BaseApp) newMultiVersionStores(ms sdk.MultiStore) ([]sdk.KVStore, []*multiversion.Store) {
+	parents := make([]sdk.KVStore, len(app.storeKeys))
+	mvStores := make([]*multiversion.Store, len(app.storeKeys))
+	for i, key := range app.storeKeys {
+		parents[i] = ms.GetKVStore(key)
+
+		// Iterating a branch sorts its pending writes. Do so upfront, as the
+		// branches are not written to during the parallel execution.
+		parents[i].Iterator(nil, nil).Close()
+
+		mvStores[i] = multiversion.NewStore(parents[i])
+	}
+
+	return parents, mvStores
+}
+
+// executeTxVersioned executes the tx at the given index of the block in
+// DeliverTx mode on the given multi-version stores, and publishes its writes to
+// the stores. The telemetry of its message handlers is recorded in the
+// execution, to be emitted once it is committed.
+func (app *BaseApp) executeTxVersioned(ctx sdk.Context, mvStores []*multiversion.Store, index int, txBytes []byte) *txExecution {
+	stores := make([]*multiversion.VersionIndexedStore, len(app.storeKeys))
+	wrappers := make(map[sdk.StoreKey]sdk.CacheWrapper, len(app.storeKeys))
+	gasConfigs := make(map[sdk.StoreKey]sdk.GasConfig)
+	for i, key := range app.storeKeys {
+		stores[i] = multiversion.NewVersionIndexedStore(mvStores[i], index)
+		wrappers[key] = stores[i]
+		if config, ok := ctx.MultiStore().GetGasConfig(key); ok {
+			gasConfigs[key] = config
+		}
+	}
+
+	ms := cachemulti.NewStore(dbm.NewMemDB(), wrappers, nil, nil, nil, nil, gasConfigs)
+	blockGasMeter := sdk.NewInfiniteGasMeter()
+	var msgRecords []msgTelemetry
+	txCtx := ctx.
+		WithContext(context.WithValue(ctx.Context(), msgTelemetryKey{}, &msgRecords)).
+		WithTxBytes(txBytes).
+		WithMultiStore(ms).
+		WithGasMeter(sdk.NewInfiniteGasMeter()).
+		WithBlockGasMeter(blockGasMeter).
+		WithEventManager(sdk.NewEventManager())
+
+	gInfo, result, err := app.runTxWithContext(txCtx, runTxModeDeliver, txBytes)
+
+	ms.Write()
+	for _, store := range stores {
+		store.Publish()
+	}
+
+	return &txExecution{
+		gInfo:        gInfo,
+		result:       result,
+		err:          err,
+		blockGas:     blockGasMeter.GasConsumed(),
+		stores:       stores,
+		msgTelemetry: msgRecords,
+	}
+}
+
+// runParallel calls fn for the indices 0 to n-1 on the given number of
+// workers. Once fn returns false, no further indices are handed out.
+func runParallel(workers, n int, fn func(index int) bool) {
+	var (
+		wg      sync.WaitGroup
+		stopped int32
+	)
+
+	indices := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				if !fn(index) {
+					atomic.StoreInt32(&stopped, 1)
+				}
+			}
+		}()
+	}
+
+	for index := 0; index < n && atomic.LoadInt32(&stopped) == 0; index++ {
+		indices <- index
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// fitsBlockGas returns true if the given gas can be consumed from the block gas
+// meter without running out of gas.
+func fitsBlockGas(meter sdk.GasMeter, gas uint64) bool {
+	if meter.IsOutOfGas() {
+		return false
+	}
+
+	// the infinite gas meter has no limit
+	if meter.Limit() == 0 {
+		return true
+	}
+
+	consumed := meter.GasConsumed()
+	return consumed+gas >= consumed && consumed+gas <= meter.Limit()
+}
@@ -18,8 +18,19 @@ var (
 	ParamStoreKeyBlockParams     = []byte("BlockParams")
 	ParamStoreKeyEvidenceParams  = []byte("EvidenceParams")
 	ParamStoreKeyValidatorParams = []byte("ValidatorParams")
+
+	// ParamStoreKeyStoreGasConfigs is the key of the gas configs overriding
+	// the default ones of specific stores.
+	ParamStoreKeyStoreGasConfigs = []byte("StoreGasConfigs")
 )
 
+// StoreGasConfig is the gas config overriding the default one of the KVStore
+// of the given name.
+type StoreGasConfig struct {
+	StoreKey  string        `json:"store_key" yaml:"store_key"`
+	GasConfig sdk.GasConfig `json:"gas_config" yaml:"gas_config"`
+}
+
 // ParamStore defines the interface the parameter store used by the BaseApp must
 // fulfill.
 type ParamStore interface {
@@ -84,3 +95,25 @@ func ValidateValidatorParams(i interface{}) error {
 
 	return nil
 }
+
+// ValidateStoreGasConfigs defines a stateless validation on the StoreGasConfigs.
+// This function is called whenever the parameters are updated or stored.
+func ValidateStoreGasConfigs(i interface{}) error {
+	v, ok := i.([]StoreGasConfig)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	seen := make(map[string]bool, len(v))
+	for _, config := range v {
+		if config.StoreKey == "" {
+			return errors.New("store gas config store key must not be empty")
+		}
+		if seen[config.StoreKey] {
+			return fmt.Errorf("duplicate gas config for store %s", config.StoreKey)
+		}
+		seen[config.StoreKey] = true
+	}
+
+	return nil
+}
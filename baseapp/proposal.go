@@ -0,0 +1,183 @@
+package baseapp
+
+import (
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+type (
+	// GasTx defines the contract that a transaction with a gas limit must
+	// implement in order to be accounted against the maximum block gas.
+	GasTx interface {
+		GetGas() uint64
+	}
+
+	// ProposalTxVerifier defines the interface that is implemented by BaseApp,
+	// that any custom PrepareProposal and ProcessProposal handler can use to
+	// decode and verify transactions.
+	ProposalTxVerifier interface {
+		TxDecode(txBz []byte) (sdk.Tx, error)
+		PrepareProposalVerifyTx(txBz []byte) error
+		ProcessProposalVerifyTx(txBz []byte) error
+	}
+
+	// DefaultProposalHandler defines the default PrepareProposal and
+	// ProcessProposal handlers used by BaseApp when none are set.
+	DefaultProposalHandler struct {
+		txVerifier ProposalTxVerifier
+	}
+)
+
+// NewDefaultProposalHandler returns a DefaultProposalHandler which verifies
+// transactions using the provided ProposalTxVerifier.
+func NewDefaultProposalHandler(txVerifier ProposalTxVerifier) DefaultProposalHandler {
+	return DefaultProposalHandler{txVerifier: txVerifier}
+}
+
+// PrepareProposalHandler returns the default PrepareProposal handler. It
+// iterates over the proposed transactions in order and drops any transaction
+// that cannot be decoded or fails the AnteHandler. Selection stops once the
+// next transaction would exceed the maximum block bytes or maximum block gas
+// defined by the consensus params.
+//
+// Note, the AnteHandler is run against a single branch of the latest committed
+// state, so state transitions of earlier transactions, e.g. sequence
+// increments, are visible to later ones.
+func (h DefaultProposalHandler) PrepareProposalHandler() sdk.PrepareProposalHandler {
+	return func(ctx sdk.Context, req abci.RequestPrepareProposal) abci.ResponsePrepareProposal {
+		maxBytes, maxGas := proposalLimits(ctx)
+		if req.BlockDataSize > 0 && (maxBytes <= 0 || req.BlockDataSize < maxBytes) {
+			maxBytes = req.BlockDataSize
+		}
+
+		var (
+			totalBytes int64
+			totalGas   uint64
+		)
+
+		txs := make([][]byte, 0, len(req.BlockData.GetTxs()))
+		for _, txBz := range req.BlockData.GetTxs() {
+			tx, err := h.txVerifier.TxDecode(txBz)
+			if err != nil {
+				ctx.Logger().Debug("dropping undecodable tx from proposal", "err", err)
+				continue
+			}
+
+			txBytes := int64(len(txBz))
+			if maxBytes > 0 && totalBytes+txBytes > maxBytes {
+				break
+			}
+
+			txGas := txGasLimit(tx)
+			if maxGas > 0 && totalGas+txGas > maxGas {
+				break
+			}
+
+			if err := h.txVerifier.PrepareProposalVerifyTx(txBz); err != nil {
+				ctx.Logger().Debug("dropping invalid tx from proposal", "err", err)
+				continue
+			}
+
+			totalBytes += txBytes
+			totalGas += txGas
+			txs = append(txs, txBz)
+		}
+
+		var blockData tmproto.Data
+		if req.BlockData != nil {
+			blockData = *req.BlockData
+		}
+		blockData.Txs = txs
+
+		return abci.ResponsePrepareProposal{BlockData: &blockData}
+	}
+}
+
+// ProcessProposalHandler returns the default ProcessProposal handler. It
+// rejects the proposal if any transaction cannot be decoded or fails the
+// AnteHandler, or if the proposed transactions exceed the maximum block bytes
+// or maximum block gas defined by the consensus params.
+func (h DefaultProposalHandler) ProcessProposalHandler() sdk.ProcessProposalHandler {
+	return func(ctx sdk.Context, req abci.RequestProcessProposal) abci.ResponseProcessProposal {
+		maxBytes, maxGas := proposalLimits(ctx)
+
+		var (
+			totalBytes int64
+			totalGas   uint64
+		)
+
+		for _, txBz := range req.BlockData.GetTxs() {
+			tx, err := h.txVerifier.TxDecode(txBz)
+			if err != nil {
+				ctx.Logger().Info("rejecting proposal with undecodable tx", "height", req.Header.Height, "err", err)
+				return abci.ResponseProcessProposal{Result: abci.ResponseProcessProposal_REJECT}
+			}
+
+			totalBytes += int64(len(txBz))
+			if maxBytes > 0 && totalBytes > maxBytes {
+				ctx.Logger().Info("rejecting proposal exceeding max block bytes", "height", req.Header.Height, "max_bytes", maxBytes)
+				return abci.ResponseProcessProposal{Result: abci.ResponseProcessProposal_REJECT}
+			}
+
+			totalGas += txGasLimit(tx)
+			if maxGas > 0 && totalGas > maxGas {
+				ctx.Logger().Info("rejecting proposal exceeding max block gas", "height", req.Header.Height, "max_gas", maxGas)
+				return abci.ResponseProcessProposal{Result: abci.ResponseProcessProposal_REJECT}
+			}
+
+			if err := h.txVerifier.ProcessProposalVerifyTx(txBz); err != nil {
+				ctx.Logger().Info("rejecting proposal with invalid tx", "height", req.Header.Height, "err", err)
+				return abci.ResponseProcessProposal{Result: abci.ResponseProcessProposal_REJECT}
+			}
+		}
+
+		return abci.ResponseProcessProposal{Result: abci.ResponseProcessProposal_ACCEPT}
+	}
+}
+
+// TxDecode decodes the given transaction bytes using the BaseApp's TxDecoder.
+func (app *BaseApp) TxDecode(txBz []byte) (sdk.Tx, error) {
+	return app.txDecoder(txBz)
+}
+
+// PrepareProposalVerifyTx runs the AnteHandler for the given transaction
+// against the PrepareProposal state. Messages are not executed.
+func (app *BaseApp) PrepareProposalVerifyTx(txBz []byte) error {
+	_, _, err := app.runTx(runTxModePrepareProposal, txBz)
+	return err
+}
+
+// ProcessProposalVerifyTx runs the AnteHandler for the given transaction
+// against the ProcessProposal state. Messages are not executed.
+func (app *BaseApp) ProcessProposalVerifyTx(txBz []byte) error {
+	_, _, err := app.runTx(runTxModeProcessProposal, txBz)
+	return err
+}
+
+// proposalLimits returns the maximum block bytes and maximum block gas from
+// the consensus params set on the given context. A non-positive value means
+// there is no limit.
+func proposalLimits(ctx sdk.Context) (maxBytes int64, maxGas uint64) {
+	cp := ctx.ConsensusParams()
+	if cp == nil || cp.Block == nil {
+		return 0, 0
+	}
+
+	if cp.Block.MaxGas > 0 {
+		maxGas = uint64(cp.Block.MaxGas)
+	}
+
+	return cp.Block.MaxBytes, maxGas
+}
+
+// txGasLimit returns the gas limit of the transaction if it implements GasTx
+// and zero otherwise.
+func txGasLimit(tx sdk.Tx) uint64 {
+	if gasTx, ok := tx.(GasTx); ok {
+		return gasTx.GetGas()
+	}
+
+	return 0
+}
@@ -0,0 +1,69 @@
+package baseapp
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// StateOverrideHandler applies a state override of a simulation which BaseApp
+// cannot apply by itself, i.e. any override other than a raw KV store entry.
+// It must return an error for the overrides it does not support.
+type StateOverrideHandler func(ctx sdk.Context, override txtypes.StateOverride) error
+
+// applyStateOverrides applies the given state overrides, in order, to the
+// branched state of the context. The gas consumed and the events emitted while
+// applying the overrides are discarded.
+func (app *BaseApp) applyStateOverrides(ctx sdk.Context, overrides []txtypes.StateOverride) error {
+	ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter()).WithEventManager(sdk.NewEventManager())
+
+	for i, override := range overrides {
+		var err error
+		switch o := override.Sum.(type) {
+		case *txtypes.StateOverride_KvStore:
+			err = app.applyKVStoreOverride(ctx, o.KvStore)
+
+		case nil:
+			err = sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "empty state override")
+
+		default:
+			if app.stateOverrideHandler == nil {
+				err = sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "unsupported state override %T", o)
+				break
+			}
+
+			err = app.stateOverrideHandler(ctx, override)
+		}
+
+		if err != nil {
+			return sdkerrors.Wrapf(err, "failed to apply state override %d", i)
+		}
+	}
+
+	return nil
+}
+
+// applyKVStoreOverride sets the entry of a KVStoreOverride in the mounted
+// store with the given name, or deletes it if the value is empty.
+func (app *BaseApp) applyKVStoreOverride(ctx sdk.Context, override *txtypes.KVStoreOverride) error {
+	if override == nil || len(override.Key) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "empty KV store override key")
+	}
+
+	for _, key := range app.storeKeys {
+		if key.Name() != override.StoreKey {
+			continue
+		}
+
+		store := ctx.KVStore(key)
+		if len(override.Value) == 0 {
+			store.Delete(override.Key)
+		} else {
+			store.Set(override.Key, override.Value)
+		}
+
+		return nil
+	}
+
+	return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "unknown store %q", override.StoreKey)
+}
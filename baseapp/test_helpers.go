@@ -5,6 +5,7 @@ import (
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 )
 
 func (app *BaseApp) Check(txEncoder sdk.TxEncoder, tx sdk.Tx) (sdk.GasInfo, *sdk.Result, error) {
@@ -18,8 +19,15 @@ func (app *BaseApp) Check(txEncoder sdk.TxEncoder, tx sdk.Tx) (sdk.GasInfo, *sdk
 	return app.runTx(runTxModeCheck, bz)
 }
 
-func (app *BaseApp) Simulate(txBytes []byte) (sdk.GasInfo, *sdk.Result, error) {
-	return app.runTx(runTxModeSimulate, txBytes)
+// Simulate simulates the tx on a branch of the check state, to which the
+// given state overrides are applied first.
+func (app *BaseApp) Simulate(txBytes []byte, overrides ...txtypes.StateOverride) (sdk.GasInfo, *sdk.Result, error) {
+	ctx := app.getContextForTx(runTxModeSimulate, txBytes)
+	if err := app.applyStateOverrides(ctx, overrides); err != nil {
+		return sdk.GasInfo{}, nil, err
+	}
+
+	return app.runTxWithContext(ctx, runTxModeSimulate, txBytes)
 }
 
 func (app *BaseApp) Deliver(txEncoder sdk.TxEncoder, tx sdk.Tx) (sdk.GasInfo, *sdk.Result, error) {
@@ -35,7 +43,7 @@ func (app *BaseApp) Deliver(txEncoder sdk.TxEncoder, tx sdk.Tx) (sdk.GasInfo, *s
 func (app *BaseApp) NewContext(isCheckTx bool, header tmproto.Header) sdk.Context {
 	if isCheckTx {
 		return sdk.NewContext(app.checkState.ms, header, true, app.logger).
-			WithMinGasPrices(app.minGasPrices)
+			WithMinGasPrices(app.getMinGasPrices())
 	}
 
 	return sdk.NewContext(app.deliverState.ms, header, false, app.logger)
@@ -0,0 +1,144 @@
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/store/tracekv"
+	"github.com/cosmos/cosmos-sdk/version"
+)
+
+const flagHeight = "height"
+
+// storeTraceSummary counts the traced operations on a store.
+type storeTraceSummary struct {
+	store, module                             string
+	reads, writes, deletes, iterKeys, iterVal int
+	bytesRead, bytesWritten                   int
+}
+
+func TraceReadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trace-read [file]",
+		Short: "Summarize a KVStore trace file",
+		Long: fmt.Sprintf(`Summarize a KVStore trace file written with --trace-store: the number of records,
+the range of block heights and the number of txs traced, and the operations and bytes
+read and written per store. Traces in the json and binary formats of --trace-store-format
+are read, as well as traces written without a format.
+
+Example:
+$ %s debug trace-read trace.log --height 42
+			`, version.AppName),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := cmd.Flags().GetInt64(flagHeight)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			return summarizeTrace(cmd.OutOrStdout(), f, height)
+		},
+	}
+
+	cmd.Flags().Int64(flagHeight, 0, "Only summarize the records of the given block height")
+
+	return cmd
+}
+
+// summarizeTrace writes the summary of the trace read from r, or of the
+// records of the given height only if it is not 0.
+func summarizeTrace(w io.Writer, r io.Reader, height int64) error {
+	var (
+		records, minHeight, maxHeight int64
+		txs                           = make(map[[2]int64]bool)
+		stores                        = make(map[string]*storeTraceSummary)
+	)
+
+	rr := tracekv.NewRecordReader(r)
+	for {
+		rec, err := rr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read record %d: %w", records+1, err)
+		}
+		if height != 0 && rec.BlockHeight != height {
+			continue
+		}
+
+		records++
+		if rec.BlockHeight != 0 {
+			if minHeight == 0 || rec.BlockHeight < minHeight {
+				minHeight = rec.BlockHeight
+			}
+			if rec.BlockHeight > maxHeight {
+				maxHeight = rec.BlockHeight
+			}
+		}
+		if rec.TxIndex >= 0 {
+			txs[[2]int64{rec.BlockHeight, rec.TxIndex}] = true
+		}
+
+		summary, ok := stores[rec.Store]
+		if !ok {
+			summary = &storeTraceSummary{store: rec.Store, module: rec.Module}
+			stores[rec.Store] = summary
+		}
+
+		switch rec.Operation {
+		case "read":
+			summary.reads++
+			summary.bytesRead += len(rec.Key) + len(rec.Value)
+		case "write":
+			summary.writes++
+			summary.bytesWritten += len(rec.Key) + len(rec.Value)
+		case "delete":
+			summary.deletes++
+			summary.bytesWritten += len(rec.Key)
+		case "iterKey":
+			summary.iterKeys++
+			summary.bytesRead += len(rec.Key)
+		case "iterValue":
+			summary.iterVal++
+			summary.bytesRead += len(rec.Value)
+		}
+	}
+
+	fmt.Fprintf(w, "records: %d\n", records)
+	if maxHeight != 0 {
+		fmt.Fprintf(w, "blocks:  %d-%d\n", minHeight, maxHeight)
+	}
+	fmt.Fprintf(w, "txs:     %d\n\n", len(txs))
+
+	names := make([]string, 0, len(stores))
+	for name := range stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STORE\tMODULE\tREADS\tWRITES\tDELETES\tITER KEYS\tITER VALUES\tBYTES READ\tBYTES WRITTEN")
+	for _, name := range names {
+		s := stores[name]
+		if s.store == "" {
+			s.store, s.module = "-", "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			s.store, s.module, s.reads, s.writes, s.deletes, s.iterKeys, s.iterVal, s.bytesRead, s.bytesWritten)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/cosmos/cosmos-sdk/server/config"
+)
+
+// grpcHealthInterval is the interval at which the status of the gRPC health
+// service is updated.
+const grpcHealthInterval = time.Second
+
+// LatestBlockProvider is implemented by the apps embedding BaseApp, and reports
+// their latest committed block.
+type LatestBlockProvider interface {
+	LastBlockHeight() int64
+	LastBlockTime() time.Time
+}
+
+// ReadinessStatus is the readiness of the node, with the reasons it is not
+// ready if it is not.
+type ReadinessStatus struct {
+	Ready             bool      `json:"ready"`
+	LatestBlockHeight int64     `json:"latest_block_height"`
+	LatestBlockTime   time.Time `json:"latest_block_time"`
+	CatchingUp        bool      `json:"catching_up"`
+	GRPCServing       bool      `json:"grpc_serving"`
+	Reasons           []string  `json:"reasons,omitempty"`
+}
+
+// HealthChecker reports the readiness of the node: it is ready when it has
+// committed a recent enough block, is not catching up with the network if
+// required, and its gRPC server is serving if it is enabled. The thresholds
+// are defined by the [health] section of app.toml.
+type HealthChecker struct {
+	cfg         config.HealthConfig
+	requireGRPC int32
+	app         LatestBlockProvider
+	client      rpcclient.StatusClient
+	grpcServing int32
+	now         func() time.Time
+}
+
+// NewHealthChecker returns the HealthChecker of a node, which reads the latest
+// block of the app and the catch-up status of the node from the client.
+func NewHealthChecker(cfg config.Config, app LatestBlockProvider, client rpcclient.StatusClient) *HealthChecker {
+	c := &HealthChecker{
+		cfg:    cfg.Health,
+		app:    app,
+		client: client,
+		now:    time.Now,
+	}
+	c.SetGRPCEnabled(cfg.GRPC.Enable)
+
+	return c
+}
+
+// SetGRPCEnabled records whether the gRPC server of the node is enabled, in
+// which case the node is only ready while it is serving.
+func (c *HealthChecker) SetGRPCEnabled(enabled bool) {
+	atomic.StoreInt32(&c.requireGRPC, boolToInt32(enabled))
+}
+
+// SetGRPCServing records whether the gRPC server of the node is serving.
+func (c *HealthChecker) SetGRPCServing(serving bool) {
+	atomic.StoreInt32(&c.grpcServing, boolToInt32(serving))
+}
+
+// Readiness returns the readiness of the node.
+func (c *HealthChecker) Readiness(ctx context.Context) ReadinessStatus {
+	status := ReadinessStatus{
+		LatestBlockHeight: c.app.LastBlockHeight(),
+		LatestBlockTime:   c.app.LastBlockTime(),
+		GRPCServing:       atomic.LoadInt32(&c.grpcServing) == 1,
+	}
+
+	if c.cfg.MaxBlockAge > 0 {
+		switch age := c.now().Sub(status.LatestBlockTime); {
+		case status.LatestBlockTime.IsZero():
+			status.Reasons = append(status.Reasons, "no block committed since the node started")
+		case age > c.cfg.MaxBlockAge:
+			status.Reasons = append(status.Reasons, fmt.Sprintf(
+				"latest block is %s old, more than the max block age of %s", age.Truncate(time.Second), c.cfg.MaxBlockAge,
+			))
+		}
+	}
+
+	if c.cfg.RequireSynced {
+		res, err := c.client.Status(ctx)
+		switch {
+		case err != nil:
+			status.Reasons = append(status.Reasons, fmt.Sprintf("failed to get the node status: %s", err))
+		case res.SyncInfo.CatchingUp:
+			status.CatchingUp = true
+			status.Reasons = append(status.Reasons, "node is catching up")
+		}
+	}
+
+	if atomic.LoadInt32(&c.requireGRPC) == 1 && !status.GRPCServing {
+		status.Reasons = append(status.Reasons, "gRPC server is not serving")
+	}
+
+	status.Ready = len(status.Reasons) == 0
+	return status
+}
+
+// RegisterGRPCHealthServer registers the grpc.health.v1.Health service on a
+// gRPC server, whose status is the readiness of the node, updated until the
+// returned function is called.
+func (c *HealthChecker) RegisterGRPCHealthServer(grpcSrv *grpc.Server) (stop func()) {
+	healthSrv := health.NewServer()
+	healthpb.RegisterHealthServer(grpcSrv, healthSrv)
+	c.updateGRPCHealth(healthSrv)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(grpcHealthInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				healthSrv.Shutdown()
+				return
+
+			case <-ticker.C:
+				c.updateGRPCHealth(healthSrv)
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (c *HealthChecker) updateGRPCHealth(healthSrv *health.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcHealthInterval)
+	defer cancel()
+
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if c.Readiness(ctx).Ready {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	healthSrv.SetServingStatus("", status)
+}
+
+func (s *Server) registerHealthRoutes() {
+	// /health only reports that the API server is up.
+	s.Router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		writeHealthResponse(w, http.StatusOK, map[string]string{"status": "ok"})
+	}).Methods("GET")
+
+	s.Router.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		status := s.Health.Readiness(r.Context())
+
+		code := http.StatusOK
+		if !status.Ready {
+			code = http.StatusServiceUnavailable
+		}
+		writeHealthResponse(w, code, status)
+	}).Methods("GET")
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func writeHealthResponse(w http.ResponseWriter, code int, v interface{}) {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(bz)
+}
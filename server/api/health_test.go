@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/server/config"
+)
+
+type fakeBlockProvider struct {
+	height int64
+	time   time.Time
+}
+
+func (p *fakeBlockProvider) LastBlockHeight() int64   { return p.height }
+func (p *fakeBlockProvider) LastBlockTime() time.Time { return p.time }
+
+type fakeStatusClient struct {
+	catchingUp bool
+	err        error
+}
+
+func (c *fakeStatusClient) Status(context.Context) (*ctypes.ResultStatus, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{CatchingUp: c.catchingUp}}, nil
+}
+
+func newTestHealthChecker(grpcEnable bool) (*HealthChecker, *fakeBlockProvider, *fakeStatusClient) {
+	cfg := config.DefaultConfig()
+	cfg.Health.MaxBlockAge = time.Minute
+	cfg.GRPC.Enable = grpcEnable
+
+	now := time.Unix(1_000_000, 0)
+	app := &fakeBlockProvider{height: 10, time: now.Add(-10 * time.Second)}
+	client := &fakeStatusClient{}
+
+	c := NewHealthChecker(*cfg, app, client)
+	c.now = func() time.Time { return now }
+
+	return c, app, client
+}
+
+func TestHealthCheckerReadiness(t *testing.T) {
+	c, app, client := newTestHealthChecker(true)
+
+	status := c.Readiness(context.Background())
+	require.False(t, status.Ready)
+	require.Equal(t, []string{"gRPC server is not serving"}, status.Reasons)
+
+	// the gRPC server is only required while it is enabled
+	c.SetGRPCEnabled(false)
+	require.True(t, c.Readiness(context.Background()).Ready)
+	c.SetGRPCEnabled(true)
+
+	c.SetGRPCServing(true)
+	status = c.Readiness(context.Background())
+	require.True(t, status.Ready)
+	require.Empty(t, status.Reasons)
+	require.Equal(t, int64(10), status.LatestBlockHeight)
+	require.Equal(t, app.time, status.LatestBlockTime)
+
+	app.time = c.now().Add(-2 * time.Minute)
+	client.catchingUp = true
+	status = c.Readiness(context.Background())
+	require.False(t, status.Ready)
+	require.True(t, status.CatchingUp)
+	require.Equal(t, []string{
+		"latest block is 2m0s old, more than the max block age of 1m0s",
+		"node is catching up",
+	}, status.Reasons)
+
+	app.time = time.Time{}
+	client.catchingUp, client.err = false, errors.New("boom")
+	status = c.Readiness(context.Background())
+	require.False(t, status.Ready)
+	require.Equal(t, []string{
+		"no block committed since the node started",
+		"failed to get the node status: boom",
+	}, status.Reasons)
+
+	// the checks can be disabled
+	c.cfg = config.HealthConfig{MaxBlockAge: 0, RequireSynced: false}
+	require.True(t, c.Readiness(context.Background()).Ready)
+}
+
+func TestHealthRoutes(t *testing.T) {
+	c, app, _ := newTestHealthChecker(false)
+	s := New(client.Context{}, log.NewNopLogger())
+	s.Health = c
+	s.registerHealthRoutes()
+
+	get := func(path string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		s.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return rec.Code, res
+	}
+
+	code, res := get("/health")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", res["status"])
+
+	code, res = get("/ready")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, true, res["ready"])
+	require.Equal(t, float64(10), res["latest_block_height"])
+
+	app.time = time.Time{}
+	code, res = get("/ready")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, false, res["ready"])
+}
+
+func TestGRPCHealth(t *testing.T) {
+	c, app, _ := newTestHealthChecker(false)
+	healthSrv := health.NewServer()
+
+	c.updateGRPCHealth(healthSrv)
+	res, err := healthSrv.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+
+	app.time = time.Time{}
+	c.updateGRPCHealth(healthSrv)
+	res, err = healthSrv.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, res.Status)
+}
@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gogo/gateway"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/server/config"
+	"github.com/cosmos/cosmos-sdk/server/ratelimit"
 	"github.com/cosmos/cosmos-sdk/telemetry"
 	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
 	"github.com/cosmos/cosmos-sdk/types/rest"
@@ -30,9 +32,16 @@ type Server struct {
 	GRPCGatewayRouter *runtime.ServeMux
 	ClientCtx         client.Context
 
-	logger   log.Logger
-	metrics  *telemetry.Metrics
-	listener net.Listener
+	// Limiter limits the requests of the server, if it is set.
+	Limiter *ratelimit.Limiter
+
+	// Health serves the /health and /ready endpoints, if it is set.
+	Health *HealthChecker
+
+	logger     log.Logger
+	metricsMtx sync.RWMutex
+	metrics    *telemetry.Metrics
+	listener   net.Listener
 }
 
 // CustomGRPCHeaderMatcher for mapping request headers to
@@ -83,15 +92,15 @@ func New(clientCtx client.Context, logger log.Logger) *Server {
 // and are delegated to the Tendermint JSON RPC server. The process is
 // non-blocking, so an external signal handler must be used.
 func (s *Server) Start(cfg config.Config) error {
-	if cfg.Telemetry.Enabled {
+	if cfg.Telemetry.Enabled && s.getMetrics() == nil {
 		m, err := telemetry.New(cfg.Telemetry)
 		if err != nil {
 			return err
 		}
 
-		s.metrics = m
-		s.registerMetrics()
+		s.SetMetrics(m)
 	}
+	s.registerMetrics()
 
 	tmCfg := tmrpcserver.DefaultConfig()
 	tmCfg.MaxOpenConnections = int(cfg.API.MaxOpenConnections)
@@ -104,10 +113,13 @@ func (s *Server) Start(cfg config.Config) error {
 		return err
 	}
 
+	if s.Health != nil {
+		s.registerHealthRoutes()
+	}
 	s.registerGRPCGatewayRoutes()
 
 	s.listener = listener
-	var h http.Handler = s.Router
+	var h http.Handler = s.Limiter.HTTPHandler(s.Router)
 
 	if cfg.API.EnableUnsafeCORS {
 		allowAllCORS := handlers.CORS(handlers.AllowedHeaders([]string{"Content-Type"}))
@@ -115,7 +127,7 @@ func (s *Server) Start(cfg config.Config) error {
 	}
 
 	s.logger.Info("starting API server...")
-	return tmrpcserver.Serve(s.listener, s.Router, s.logger, tmCfg)
+	return tmrpcserver.Serve(s.listener, h, s.logger, tmCfg)
 }
 
 // Close closes the API server.
@@ -127,11 +139,32 @@ func (s *Server) registerGRPCGatewayRoutes() {
 	s.Router.PathPrefix("/").Handler(s.GRPCGatewayRouter)
 }
 
+// SetMetrics sets the metrics served by the /metrics endpoint, which is not
+// found while they are nil. Start sets them from the telemetry config if they
+// are not set.
+func (s *Server) SetMetrics(m *telemetry.Metrics) {
+	s.metricsMtx.Lock()
+	defer s.metricsMtx.Unlock()
+	s.metrics = m
+}
+
+func (s *Server) getMetrics() *telemetry.Metrics {
+	s.metricsMtx.RLock()
+	defer s.metricsMtx.RUnlock()
+	return s.metrics
+}
+
 func (s *Server) registerMetrics() {
 	metricsHandler := func(w http.ResponseWriter, r *http.Request) {
+		m := s.getMetrics()
+		if m == nil {
+			http.NotFound(w, r)
+			return
+		}
+
 		format := strings.TrimSpace(r.FormValue("format"))
 
-		gr, err := s.metrics.Gather(format)
+		gr, err := m.Gather(format)
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("failed to gather metrics: %s", err))
 			return
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+
+	"github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/store/cache"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+)
+
+// The app.toml options of the inter-block cache.
+const (
+	OptInterBlockCacheSize           = "store.inter-block-cache-size"
+	OptInterBlockCacheStoreSizes     = "store.inter-block-cache-store-sizes"
+	OptInterBlockCacheWarmupPrefixes = "store.inter-block-cache-warmup-prefixes"
+)
+
+// GetInterBlockCacheFromFlags parses the app options and returns the
+// inter-block cache, or nil if inter-block caching is disabled.
+func GetInterBlockCacheFromFlags(appOpts types.AppOptions) (storetypes.MultiStorePersistentCache, error) {
+	if !cast.ToBool(appOpts.Get(FlagInterBlockCache)) {
+		return nil, nil
+	}
+
+	size := cast.ToUint64(appOpts.Get(OptInterBlockCacheSize))
+	if size == 0 {
+		size = cache.DefaultCommitKVStoreCacheSize
+	}
+	mngr := cache.NewCommitKVStoreCacheManager(size)
+
+	for _, spec := range cast.ToStringSlice(appOpts.Get(OptInterBlockCacheStoreSizes)) {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid inter-block cache store size %q, expected {storeKey}={bytes}", spec)
+		}
+		storeSize, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inter-block cache store size %q: %w", spec, err)
+		}
+
+		mngr.SetStoreCacheSize(parts[0], storeSize)
+	}
+
+	for _, spec := range cast.ToStringSlice(appOpts.Get(OptInterBlockCacheWarmupPrefixes)) {
+		parts := strings.SplitN(spec, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid inter-block cache warmup prefix %q, expected {storeKey}:{hex prefix}", spec)
+		}
+		prefix, err := hex.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid inter-block cache warmup prefix %q: %w", spec, err)
+		}
+
+		mngr.AddWarmupPrefix(parts[0], prefix)
+	}
+
+	return mngr, nil
+}
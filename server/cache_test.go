@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/store/cache"
+	"github.com/cosmos/cosmos-sdk/store/dbadapter"
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+// commitStore is a CommitKVStore over a memory database.
+type commitStore struct {
+	dbadapter.Store
+	types.Committer
+}
+
+func TestGetInterBlockCacheFromFlags(t *testing.T) {
+	v := viper.New()
+	cacheMngr, err := GetInterBlockCacheFromFlags(v)
+	require.NoError(t, err)
+	require.Nil(t, cacheMngr)
+
+	v.Set(FlagInterBlockCache, true)
+	v.Set(OptInterBlockCacheSize, 1000)
+	v.Set(OptInterBlockCacheStoreSizes, []string{"bank=2000"})
+	v.Set(OptInterBlockCacheWarmupPrefixes, []string{"bank:01"})
+	cacheMngr, err = GetInterBlockCacheFromFlags(v)
+	require.NoError(t, err)
+
+	// the protected segment of the bank store cache holds 1600 bytes, that is
+	// 21 entries of 75 bytes
+	store := commitStore{Store: dbadapter.Store{DB: dbm.NewMemDB()}}
+	for i := 0; i < 30; i++ {
+		store.Set([]byte(fmt.Sprintf("\x01key%02d", i)), []byte("value"))
+		store.Set([]byte(fmt.Sprintf("\x02key%02d", i)), []byte("value"))
+	}
+	bankCache := cacheMngr.GetStoreCache(types.NewKVStoreKey("bank"), store).(*cache.CommitKVStoreCache)
+	require.Equal(t, uint64(21*75), bankCache.Stats().Size)
+
+	stakingCache := cacheMngr.GetStoreCache(types.NewKVStoreKey("staking"), store).(*cache.CommitKVStoreCache)
+	require.Equal(t, uint64(0), stakingCache.Stats().Size)
+
+	for _, opt := range []struct{ key, value string }{
+		{OptInterBlockCacheStoreSizes, "bank"},
+		{OptInterBlockCacheStoreSizes, "bank=large"},
+		{OptInterBlockCacheWarmupPrefixes, "bank"},
+		{OptInterBlockCacheWarmupPrefixes, "bank:zz"},
+	} {
+		v := viper.New()
+		v.Set(FlagInterBlockCache, true)
+		v.Set(opt.key, []string{opt.value})
+
+		_, err := GetInterBlockCacheFromFlags(v)
+		require.Error(t, err, opt.value)
+	}
+}
@@ -3,9 +3,11 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 
+	"github.com/cosmos/cosmos-sdk/store/cache"
 	storetypes "github.com/cosmos/cosmos-sdk/store/types"
 	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -15,6 +17,9 @@ import (
 const (
 	defaultMinGasPrices = ""
 
+	// DefaultAPIAddress defines the default address to bind the API server to.
+	DefaultAPIAddress = "tcp://0.0.0.0:1317"
+
 	// DefaultGRPCAddress defines the default address to bind the gRPC server to.
 	DefaultGRPCAddress = "0.0.0.0:9090"
 
@@ -69,6 +74,27 @@ type BaseConfig struct {
 	// IndexEvents defines the set of events in the form {eventType}.{attributeKey},
 	// which informs Tendermint what to index. If empty, all events will be indexed.
 	IndexEvents []string `mapstructure:"index-events"`
+
+	// QueryGasLimit defines the maximum gas a single gRPC or ABCI query may
+	// consume before it is aborted. A value of 0 indicates that queries are not
+	// gas limited.
+	QueryGasLimit uint64 `mapstructure:"query-gas-limit"`
+
+	// ParallelTxWorkers defines the number of workers executing the txs of a
+	// block in parallel. A value below 2 disables parallel execution.
+	ParallelTxWorkers uint `mapstructure:"parallel-tx-workers"`
+
+	// PruningAsync enables the pruning of heights in a background goroutine
+	// instead of during commit.
+	PruningAsync bool `mapstructure:"pruning-async"`
+
+	// PruningAsyncMaxPending defines the maximum number of heights waiting to
+	// be pruned in the background. Commits block while the backlog is full.
+	PruningAsyncMaxPending uint64 `mapstructure:"pruning-async-max-pending"`
+
+	// PruningAsyncDelay defines the pause between the pruning of two heights in
+	// the background, which limits the load that pruning puts on the database.
+	PruningAsyncDelay time.Duration `mapstructure:"pruning-async-delay"`
 }
 
 // APIConfig defines the API listener configuration.
@@ -157,6 +183,99 @@ type StateSyncConfig struct {
 	SnapshotKeepRecent uint32 `mapstructure:"snapshot-keep-recent"`
 }
 
+// StoreConfig defines application configuration for state streaming and other
+// storage related operations.
+type StoreConfig struct {
+	// Streamers defines the names of the streaming services to enable.
+	Streamers []string `mapstructure:"streamers"`
+
+	// InterBlockCacheSize defines the size in bytes of the inter-block cache
+	// of each store. 0 uses the default size.
+	InterBlockCacheSize uint64 `mapstructure:"inter-block-cache-size"`
+
+	// InterBlockCacheStoreSizes overrides the inter-block cache size of
+	// specific stores, in the form {storeKey}={bytes}.
+	InterBlockCacheStoreSizes []string `mapstructure:"inter-block-cache-store-sizes"`
+
+	// InterBlockCacheWarmupPrefixes defines the key prefixes whose entries are
+	// loaded into the inter-block cache of their store on startup, in the form
+	// {storeKey}:{hex prefix}.
+	InterBlockCacheWarmupPrefixes []string `mapstructure:"inter-block-cache-warmup-prefixes"`
+}
+
+// StreamersConfig defines the configuration of the state streaming services.
+type StreamersConfig struct {
+	File FileStreamerConfig `mapstructure:"file"`
+}
+
+// FileStreamerConfig defines the configuration of the file streaming service.
+type FileStreamerConfig struct {
+	// Keys defines the store keys to stream, "*" streams all stores.
+	Keys []string `mapstructure:"keys"`
+
+	// WriteDir defines the directory the block files are written to.
+	WriteDir string `mapstructure:"write-dir"`
+
+	// Prefix defines an optional prefix prepended to the block file names.
+	Prefix string `mapstructure:"prefix"`
+
+	// StopNodeOnError halts the node if the streaming service fails to write a
+	// block file.
+	StopNodeOnError bool `mapstructure:"stop-node-on-error"`
+
+	// Fsync forces a file sync after every block file is written.
+	Fsync bool `mapstructure:"fsync"`
+}
+
+// RateLimitConfig defines the limits of the requests served by the gRPC,
+// gRPC-web and API servers. The methods are matched by prefix against the full
+// gRPC method name, e.g. /cosmos.bank.v1beta1.Query/Balance, and against the
+// URL path of the API requests, e.g. /cosmos/bank/v1beta1/balances.
+type RateLimitConfig struct {
+	// Enable defines if the requests should be limited.
+	Enable bool `mapstructure:"enable"`
+
+	// RequestsPerSecond defines the rate of requests allowed per client IP. 0
+	// disables the limit.
+	RequestsPerSecond float64 `mapstructure:"requests-per-second"`
+
+	// Burst defines the number of requests allowed at once above the rates
+	// of the client IPs.
+	Burst uint `mapstructure:"burst"`
+
+	// MethodBurst defines the number of requests allowed at once above the
+	// rates of the methods, over all clients. 0 uses Burst.
+	MethodBurst uint `mapstructure:"method-burst"`
+
+	// MethodRequestsPerSecond defines the rate of requests allowed per method,
+	// over all clients, in the form {method prefix}={requests per second}.
+	MethodRequestsPerSecond []string `mapstructure:"method-requests-per-second"`
+
+	// MaxInFlight defines the maximum number of requests served at once. 0
+	// disables the limit.
+	MaxInFlight uint `mapstructure:"max-in-flight"`
+
+	// AllowMethods defines the prefixes of the methods allowed. All methods
+	// are allowed if it is empty.
+	AllowMethods []string `mapstructure:"allow-methods"`
+
+	// DenyMethods defines the prefixes of the methods denied, which take
+	// precedence over AllowMethods.
+	DenyMethods []string `mapstructure:"deny-methods"`
+}
+
+// HealthConfig defines the thresholds of the readiness of the node, reported
+// by the /ready endpoint of the API server and by the gRPC health service.
+type HealthConfig struct {
+	// MaxBlockAge defines the maximum age of the latest committed block for the
+	// node to be ready. 0 disables the check.
+	MaxBlockAge time.Duration `mapstructure:"max-block-age"`
+
+	// RequireSynced defines if the node must not be catching up with the
+	// network to be ready.
+	RequireSynced bool `mapstructure:"require-synced"`
+}
+
 // Config defines the server's top level configuration
 type Config struct {
 	BaseConfig `mapstructure:",squash"`
@@ -168,6 +287,10 @@ type Config struct {
 	Rosetta   RosettaConfig    `mapstructure:"rosetta"`
 	GRPCWeb   GRPCWebConfig    `mapstructure:"grpc-web"`
 	StateSync StateSyncConfig  `mapstructure:"state-sync"`
+	Store     StoreConfig      `mapstructure:"store"`
+	Streamers StreamersConfig  `mapstructure:"streamers"`
+	RateLimit RateLimitConfig  `mapstructure:"rate-limit"`
+	Health    HealthConfig     `mapstructure:"health"`
 }
 
 // SetMinGasPrices sets the validator's minimum gas prices.
@@ -209,6 +332,12 @@ func DefaultConfig() *Config {
 			PruningInterval:   "0",
 			MinRetainBlocks:   0,
 			IndexEvents:       make([]string, 0),
+			QueryGasLimit:     0,
+			ParallelTxWorkers: 0,
+
+			PruningAsync:           false,
+			PruningAsyncMaxPending: 1000,
+			PruningAsyncDelay:      0,
 		},
 		Telemetry: telemetry.Config{
 			Enabled:      false,
@@ -217,7 +346,7 @@ func DefaultConfig() *Config {
 		API: APIConfig{
 			Enable:             false,
 			Swagger:            false,
-			Address:            "tcp://0.0.0.0:1317",
+			Address:            DefaultAPIAddress,
 			MaxOpenConnections: 1000,
 			RPCReadTimeout:     10,
 			RPCMaxBodyBytes:    1000000,
@@ -242,6 +371,33 @@ func DefaultConfig() *Config {
 			SnapshotInterval:   0,
 			SnapshotKeepRecent: 2,
 		},
+		Store: StoreConfig{
+			Streamers:                     []string{},
+			InterBlockCacheSize:           cache.DefaultCommitKVStoreCacheSize,
+			InterBlockCacheStoreSizes:     []string{},
+			InterBlockCacheWarmupPrefixes: []string{},
+		},
+		Streamers: StreamersConfig{
+			File: FileStreamerConfig{
+				Keys:            []string{"*"},
+				WriteDir:        "data/file_streamer",
+				StopNodeOnError: true,
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enable:                  false,
+			RequestsPerSecond:       50,
+			Burst:                   100,
+			MethodBurst:             0,
+			MethodRequestsPerSecond: []string{},
+			MaxInFlight:             1000,
+			AllowMethods:            []string{},
+			DenyMethods:             []string{},
+		},
+		Health: HealthConfig{
+			MaxBlockAge:   time.Minute,
+			RequireSynced: true,
+		},
 	}
 }
 
@@ -268,6 +424,12 @@ func GetConfig(v *viper.Viper) Config {
 			HaltTime:          v.GetUint64("halt-time"),
 			IndexEvents:       v.GetStringSlice("index-events"),
 			MinRetainBlocks:   v.GetUint64("min-retain-blocks"),
+			QueryGasLimit:     v.GetUint64("query-gas-limit"),
+			ParallelTxWorkers: v.GetUint("parallel-tx-workers"),
+
+			PruningAsync:           v.GetBool("pruning-async"),
+			PruningAsyncMaxPending: v.GetUint64("pruning-async-max-pending"),
+			PruningAsyncDelay:      v.GetDuration("pruning-async-delay"),
 		},
 		Telemetry: telemetry.Config{
 			ServiceName:             v.GetString("telemetry.service-name"),
@@ -309,6 +471,35 @@ func GetConfig(v *viper.Viper) Config {
 			SnapshotInterval:   v.GetUint64("state-sync.snapshot-interval"),
 			SnapshotKeepRecent: v.GetUint32("state-sync.snapshot-keep-recent"),
 		},
+		Store: StoreConfig{
+			Streamers:                     v.GetStringSlice("store.streamers"),
+			InterBlockCacheSize:           v.GetUint64("store.inter-block-cache-size"),
+			InterBlockCacheStoreSizes:     v.GetStringSlice("store.inter-block-cache-store-sizes"),
+			InterBlockCacheWarmupPrefixes: v.GetStringSlice("store.inter-block-cache-warmup-prefixes"),
+		},
+		Streamers: StreamersConfig{
+			File: FileStreamerConfig{
+				Keys:            v.GetStringSlice("streamers.file.keys"),
+				WriteDir:        v.GetString("streamers.file.write-dir"),
+				Prefix:          v.GetString("streamers.file.prefix"),
+				StopNodeOnError: v.GetBool("streamers.file.stop-node-on-error"),
+				Fsync:           v.GetBool("streamers.file.fsync"),
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enable:                  v.GetBool("rate-limit.enable"),
+			RequestsPerSecond:       v.GetFloat64("rate-limit.requests-per-second"),
+			Burst:                   v.GetUint("rate-limit.burst"),
+			MethodBurst:             v.GetUint("rate-limit.method-burst"),
+			MethodRequestsPerSecond: v.GetStringSlice("rate-limit.method-requests-per-second"),
+			MaxInFlight:             v.GetUint("rate-limit.max-in-flight"),
+			AllowMethods:            v.GetStringSlice("rate-limit.allow-methods"),
+			DenyMethods:             v.GetStringSlice("rate-limit.deny-methods"),
+		},
+		Health: HealthConfig{
+			MaxBlockAge:   v.GetDuration("health.max-block-age"),
+			RequireSynced: v.GetBool("health.require-synced"),
+		},
 	}
 }
 
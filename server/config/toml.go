@@ -31,6 +31,18 @@ pruning-keep-recent = "{{ .BaseConfig.PruningKeepRecent }}"
 pruning-keep-every = "{{ .BaseConfig.PruningKeepEvery }}"
 pruning-interval = "{{ .BaseConfig.PruningInterval }}"
 
+# PruningAsync enables the pruning of heights in a background goroutine instead
+# of during commit, which avoids latency spikes at every pruning interval.
+pruning-async = {{ .BaseConfig.PruningAsync }}
+
+# PruningAsyncMaxPending defines the maximum number of heights waiting to be
+# pruned in the background. Commits block while the backlog is full.
+pruning-async-max-pending = {{ .BaseConfig.PruningAsyncMaxPending }}
+
+# PruningAsyncDelay defines the pause between the pruning of two heights in the
+# background, which limits the load that pruning puts on the database (e.g. "10ms").
+pruning-async-delay = "{{ .BaseConfig.PruningAsyncDelay }}"
+
 # HaltHeight contains a non-zero block height at which a node will gracefully
 # halt and shutdown that can be used to assist upgrades and testing.
 #
@@ -70,6 +82,17 @@ inter-block-cache = {{ .BaseConfig.InterBlockCache }}
 # ["message.sender", "message.recipient"]
 index-events = {{ .BaseConfig.IndexEvents }}
 
+# QueryGasLimit defines the maximum gas a single gRPC or ABCI query may consume
+# before it is aborted with an out of gas error. A value of 0 indicates that
+# queries are not gas limited.
+query-gas-limit = {{ .BaseConfig.QueryGasLimit }}
+
+# ParallelTxWorkers defines the number of workers executing the txs of a block in
+# parallel, with the same result as sequential execution. Only the blocks whose
+# proposal was processed by the node are executed in parallel, not the blocks
+# fetched by block sync. A value below 2 disables parallel execution.
+parallel-tx-workers = {{ .BaseConfig.ParallelTxWorkers }}
+
 ###############################################################################
 ###                         Telemetry Configuration                         ###
 ###############################################################################
@@ -187,6 +210,70 @@ address = "{{ .GRPCWeb.Address }}"
 # EnableUnsafeCORS defines if CORS should be enabled (unsafe - use it at your own risk).
 enable-unsafe-cors = {{ .GRPCWeb.EnableUnsafeCORS }}
 
+###############################################################################
+###                        Rate Limit Configuration                         ###
+###############################################################################
+
+# The rate limits apply to the requests of the gRPC, gRPC-web and API servers.
+# Methods are matched by prefix against the full gRPC method name, e.g.
+# "/cosmos.bank.v1beta1.Query/Balance", and against the URL path of the API
+# requests, e.g. "/cosmos/bank/v1beta1/balances". As the gRPC and API names of a
+# method differ, a method must be listed under both names to be limited or
+# denied on both servers, e.g. denying "/cosmos.tx.v1beta1.Service/Simulate"
+# does not deny "/cosmos/tx/v1beta1/simulate". Rejected requests fail with the
+# RESOURCE_EXHAUSTED or PERMISSION_DENIED gRPC status codes, or the 429 or 403
+# HTTP status codes. The health checks, /health, /ready and the
+# grpc.health.v1.Health service, are never limited.
+[rate-limit]
+
+# enable defines if the requests should be limited.
+enable = {{ .RateLimit.Enable }}
+
+# requests-per-second defines the rate of requests allowed per client IP (0 to disable).
+requests-per-second = {{ .RateLimit.RequestsPerSecond }}
+
+# burst defines the number of requests allowed at once above the rates of the
+# client IPs.
+burst = {{ .RateLimit.Burst }}
+
+# method-requests-per-second defines the rate of requests allowed per method over
+# all clients, in the form {method prefix}={requests per second}, e.g.
+# ["/cosmos.tx.v1beta1.Service/Simulate=10"]. The longest matching prefix applies.
+method-requests-per-second = [{{ range .RateLimit.MethodRequestsPerSecond }}{{ printf "%q, " . }}{{end}}]
+
+# method-burst defines the number of requests allowed at once above the rates of
+# the methods, over all clients (0 to use burst).
+method-burst = {{ .RateLimit.MethodBurst }}
+
+# max-in-flight defines the maximum number of requests served at once (0 to disable).
+max-in-flight = {{ .RateLimit.MaxInFlight }}
+
+# allow-methods defines the prefixes of the methods allowed, all methods are
+# allowed if it is empty.
+allow-methods = [{{ range .RateLimit.AllowMethods }}{{ printf "%q, " . }}{{end}}]
+
+# deny-methods defines the prefixes of the methods denied, which take precedence
+# over allow-methods.
+deny-methods = [{{ range .RateLimit.DenyMethods }}{{ printf "%q, " . }}{{end}}]
+
+###############################################################################
+###                          Health Configuration                           ###
+###############################################################################
+
+# The readiness of the node is reported by the /ready endpoint of the API server
+# and by the grpc.health.v1.Health service of the gRPC server. The node is ready
+# when it has committed a recent enough block, is not catching up with the
+# network if required, and its gRPC server is serving if it is enabled.
+[health]
+
+# max-block-age defines the maximum age of the latest committed block for the
+# node to be ready, e.g. "1m" (0 to disable).
+max-block-age = "{{ .Health.MaxBlockAge }}"
+
+# require-synced defines if the node must not be catching up with the network to
+# be ready.
+require-synced = {{ .Health.RequireSynced }}
+
 ###############################################################################
 ###                        State Sync Configuration                         ###
 ###############################################################################
@@ -201,6 +288,46 @@ snapshot-interval = {{ .StateSync.SnapshotInterval }}
 
 # snapshot-keep-recent specifies the number of recent snapshots to keep and serve (0 to keep all).
 snapshot-keep-recent = {{ .StateSync.SnapshotKeepRecent }}
+
+###############################################################################
+###                         Store / State Streaming                         ###
+###############################################################################
+
+[store]
+
+# streamers defines the list of state streaming services to enable, e.g. ["file"].
+streamers = [{{ range .Store.Streamers }}{{ printf "%q, " . }}{{end}}]
+
+# inter-block-cache-size defines the size in bytes of the inter-block cache of
+# each store, when inter-block-cache is enabled.
+inter-block-cache-size = {{ .Store.InterBlockCacheSize }}
+
+# inter-block-cache-store-sizes overrides the inter-block cache size of specific
+# stores, in the form {storeKey}={bytes}, e.g. ["bank=67108864"].
+inter-block-cache-store-sizes = [{{ range .Store.InterBlockCacheStoreSizes }}{{ printf "%q, " . }}{{end}}]
+
+# inter-block-cache-warmup-prefixes defines the key prefixes whose entries are
+# loaded into the inter-block cache of their store on startup, in the form
+# {storeKey}:{hex prefix}, e.g. ["acc:01"].
+inter-block-cache-warmup-prefixes = [{{ range .Store.InterBlockCacheWarmupPrefixes }}{{ printf "%q, " . }}{{end}}]
+
+[streamers.file]
+
+# keys defines the store keys whose state changes are streamed, "*" streams all stores.
+keys = [{{ range .Streamers.File.Keys }}{{ printf "%q, " . }}{{end}}]
+
+# write-dir defines the directory the block files are written to. Relative paths
+# are resolved against the node's home directory.
+write-dir = "{{ .Streamers.File.WriteDir }}"
+
+# prefix defines an optional prefix prepended to the block file names.
+prefix = "{{ .Streamers.File.Prefix }}"
+
+# stop-node-on-error halts the node if a block file fails to be written.
+stop-node-on-error = {{ .Streamers.File.StopNodeOnError }}
+
+# fsync forces a file sync after every block file is written.
+fsync = {{ .Streamers.File.Fsync }}
 `
 
 var configTemplate *template.Template
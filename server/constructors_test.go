@@ -1,10 +1,13 @@
 package server
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/store/tracekv"
 )
 
 func Test_openDB(t *testing.T) {
@@ -26,3 +29,36 @@ func Test_openTraceWriter(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, w)
 }
+
+func Test_openStoreTracer(t *testing.T) {
+	t.Parallel()
+
+	fname := filepath.Join(t.TempDir(), "logfile")
+	newCtx := func(format string, include ...string) *Context {
+		ctx := NewDefaultContext()
+		ctx.Viper.Set(flagTraceStore, fname)
+		ctx.Viper.Set(flagTraceStoreFormat, format)
+		ctx.Viper.Set(flagTraceStoreInclude, include)
+		return ctx
+	}
+
+	// the filters require a trace format
+	_, err := openStoreTracer(newCtx("", "bank"))
+	require.Error(t, err)
+	_, err = os.Stat(fname)
+	require.True(t, os.IsNotExist(err))
+
+	_, err = openStoreTracer(newCtx("json", "bank:zz"))
+	require.Error(t, err)
+	_, err = openStoreTracer(newCtx("yaml"))
+	require.Error(t, err)
+
+	w, err := openStoreTracer(newCtx(""))
+	require.NoError(t, err)
+	require.IsType(t, &os.File{}, w)
+	require.NoError(t, w.(*os.File).Close())
+
+	w, err = openStoreTracer(newCtx("json", "bank:01"))
+	require.NoError(t, err)
+	require.IsType(t, &tracekv.Tracer{}, w)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	tmjson "github.com/tendermint/tendermint/libs/json"
@@ -21,6 +22,9 @@ const (
 	FlagHeight           = "height"
 	FlagForZeroHeight    = "for-zero-height"
 	FlagJailAllowedAddrs = "jail-allowed-addrs"
+
+	FlagOutputDir           = "output-dir"
+	FlagGenesisStreamFormat = "genesis-stream-format"
 )
 
 // ExportCmd dumps app state to JSON.
@@ -67,6 +71,13 @@ func ExportCmd(appExporter types.AppExporter, defaultNodeHome string) *cobra.Com
 			height, _ := cmd.Flags().GetInt64(FlagHeight)
 			forZeroHeight, _ := cmd.Flags().GetBool(FlagForZeroHeight)
 			jailAllowedAddrs, _ := cmd.Flags().GetStringSlice(FlagJailAllowedAddrs)
+			outputDir, _ := cmd.Flags().GetString(FlagOutputDir)
+
+			if outputDir != "" {
+				if err := os.MkdirAll(outputDir, 0o755); err != nil {
+					return err
+				}
+			}
 
 			exported, err := appExporter(serverCtx.Logger, db, traceWriter, height, forZeroHeight, jailAllowedAddrs, serverCtx.Viper)
 			if err != nil {
@@ -105,6 +116,10 @@ func ExportCmd(appExporter types.AppExporter, defaultNodeHome string) *cobra.Com
 				return err
 			}
 
+			if outputDir != "" {
+				return ioutil.WriteFile(filepath.Join(outputDir, "genesis.json"), sdk.MustSortJSON(encoded), 0o644)
+			}
+
 			cmd.Println(string(sdk.MustSortJSON(encoded)))
 			return nil
 		},
@@ -114,6 +129,8 @@ func ExportCmd(appExporter types.AppExporter, defaultNodeHome string) *cobra.Com
 	cmd.Flags().Int64(FlagHeight, -1, "Export state from a particular height (-1 means latest height)")
 	cmd.Flags().Bool(FlagForZeroHeight, false, "Export state to start at height zero (perform preproccessing)")
 	cmd.Flags().StringSlice(FlagJailAllowedAddrs, []string{}, "Comma-separated list of operator addresses of jailed validators to unjail")
+	cmd.Flags().String(FlagOutputDir, "", "Write genesis.json to the given directory instead of stdout, along with the genesis states of the modules which support streaming them to separate files")
+	cmd.Flags().String(FlagGenesisStreamFormat, "json", "The format of the streamed genesis states of --output-dir (json|proto)")
 
 	return cmd
 }
@@ -14,9 +14,27 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
-// StartGRPCServer starts a gRPC server on the given address.
-func StartGRPCServer(clientCtx client.Context, app types.Application, address string) (*grpc.Server, error) {
-	grpcSrv := grpc.NewServer()
+// StartGRPCServer starts a gRPC server on the given address, with the given
+// server options, e.g. the interceptors of a rate limiter.
+func StartGRPCServer(
+	clientCtx client.Context, app types.Application, address string, opts ...grpc.ServerOption,
+) (*grpc.Server, error) {
+	grpcSrv, err := NewGRPCServer(clientCtx, app, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ServeGRPC(grpcSrv, address); err != nil {
+		return nil, err
+	}
+
+	return grpcSrv, nil
+}
+
+// NewGRPCServer returns a gRPC server with the given server options, serving
+// the services of the app and the reflection services. More services can be
+// registered on it before it is started with ServeGRPC.
+func NewGRPCServer(clientCtx client.Context, app types.Application, opts ...grpc.ServerOption) (*grpc.Server, error) {
+	grpcSrv := grpc.NewServer(opts...)
 	app.RegisterGRPCServer(grpcSrv)
 	// reflection allows consumers to build dynamic clients that can write
 	// to any cosmos-sdk application without relying on application packages at compile time
@@ -38,14 +56,20 @@ func StartGRPCServer(clientCtx client.Context, app types.Application, address st
 	// Reflection allows external clients to see what services and methods
 	// the gRPC server exposes.
 	gogoreflection.Register(grpcSrv)
+
+	return grpcSrv, nil
+}
+
+// ServeGRPC starts serving a gRPC server on the given address.
+func ServeGRPC(grpcSrv *grpc.Server, address string) error {
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	errCh := make(chan error)
 	go func() {
-		err = grpcSrv.Serve(listener)
+		err := grpcSrv.Serve(listener)
 		if err != nil {
 			errCh <- fmt.Errorf("failed to serve: %w", err)
 		}
@@ -53,8 +77,8 @@ func StartGRPCServer(clientCtx client.Context, app types.Application, address st
 
 	select {
 	case err := <-errCh:
-		return nil, err
+		return err
 	case <-time.After(types.ServerStartTime): // assume server started successfully
-		return grpcSrv, nil
+		return nil
 	}
 }
@@ -95,6 +95,9 @@ func (s *IntegrationTestSuite) TestGRPCServer_BankBalance() {
 	)
 	blockHeight := header.Get(grpctypes.GRPCBlockHeightHeader)
 	s.Require().NotEmpty(blockHeight[0]) // Should contain the block height
+	gasUsed := header.Get(grpctypes.GRPCQueryGasUsedHeader)
+	s.Require().Len(gasUsed, 1)
+	s.Require().NotEqual("0", gasUsed[0]) // Should contain the gas consumed by the query
 
 	// Request metadata should work
 	bankRes, err = bankClient.Balance(
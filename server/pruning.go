@@ -8,6 +8,7 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/server/types"
 	"github.com/cosmos/cosmos-sdk/store"
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
 	storetypes "github.com/cosmos/cosmos-sdk/store/types"
 )
 
@@ -38,3 +39,22 @@ func GetPruningOptionsFromFlags(appOpts types.AppOptions) (storetypes.PruningOpt
 		return store.PruningOptions{}, fmt.Errorf("unknown pruning strategy %s", strategy)
 	}
 }
+
+// GetAsyncPruningOptionsFromFlags parses command flags and returns the options
+// of background pruning. The zero options are returned if background pruning
+// is disabled.
+func GetAsyncPruningOptionsFromFlags(appOpts types.AppOptions) (rootmulti.AsyncPruningOptions, error) {
+	if !cast.ToBool(appOpts.Get(FlagPruningAsync)) {
+		return rootmulti.AsyncPruningOptions{}, nil
+	}
+
+	opts := rootmulti.AsyncPruningOptions{
+		MaxPending: cast.ToUint64(appOpts.Get(FlagPruningAsyncMaxPending)),
+		Delay:      cast.ToDuration(appOpts.Get(FlagPruningAsyncDelay)),
+	}
+	if err := opts.Validate(); err != nil {
+		return opts, fmt.Errorf("invalid async pruning options: %w", err)
+	}
+
+	return opts, nil
+}
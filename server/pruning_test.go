@@ -2,10 +2,12 @@ package server
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
 	"github.com/cosmos/cosmos-sdk/store/types"
 )
 
@@ -71,3 +73,21 @@ func TestGetPruningOptionsFromFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAsyncPruningOptionsFromFlags(t *testing.T) {
+	v := viper.New()
+	opts, err := GetAsyncPruningOptionsFromFlags(v)
+	require.NoError(t, err)
+	require.Equal(t, rootmulti.AsyncPruningOptions{}, opts)
+
+	v.Set(FlagPruningAsync, true)
+	v.Set(FlagPruningAsyncMaxPending, 100)
+	v.Set(FlagPruningAsyncDelay, "10ms")
+	opts, err = GetAsyncPruningOptionsFromFlags(v)
+	require.NoError(t, err)
+	require.Equal(t, rootmulti.AsyncPruningOptions{MaxPending: 100, Delay: 10 * time.Millisecond}, opts)
+
+	v.Set(FlagPruningAsyncMaxPending, 0)
+	_, err = GetAsyncPruningOptionsFromFlags(v)
+	require.Error(t, err)
+}
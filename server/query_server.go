@@ -0,0 +1,312 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/libs/service"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+	"google.golang.org/grpc"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server/api"
+	"github.com/cosmos/cosmos-sdk/server/config"
+	servergrpc "github.com/cosmos/cosmos-sdk/server/grpc"
+	"github.com/cosmos/cosmos-sdk/server/ratelimit"
+	"github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// API-related flags.
+const (
+	flagAPIEnable  = "api.enable"
+	flagAPIAddress = "api.address"
+)
+
+// errNoNode is returned by the queries of the query server which require a
+// Tendermint node.
+var errNoNode = errors.New("not supported by the query server, which runs without a node")
+
+// QueryServerCmd serves the gRPC query services and the REST API of the app
+// over the application state of a stopped node, without Tendermint.
+func QueryServerCmd(appCreator types.AppCreator, defaultNodeHome string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query-server",
+		Short: "Serve the queries of the app over the data directory of a stopped node",
+		Long: `Serve the gRPC query services and the REST API of the application over the
+application database of the data directory, opened read-only and without Tendermint,
+e.g. to run analytics jobs over a copy of the data directory of a node.
+
+The queries are served at the latest height of the application state, or at any
+height which has not been pruned, given by the x-cosmos-block-height header of the
+gRPC and REST requests. The queries which require a node, like the ones of blocks
+and txs, are not supported.
+
+The servers are configured by the [grpc] and [api] sections of app.toml, and the
+database must be a goleveldb database. The node must be stopped.`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			serverCtx := GetServerContextFromCmd(cmd)
+
+			// Bind flags to the Context's Viper so the app construction can set
+			// options accordingly.
+			return serverCtx.Viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			serverCtx := GetServerContextFromCmd(cmd)
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			err = startQueryServer(cmd.Context(), serverCtx, clientCtx, appCreator)
+			errCode, ok := err.(ErrorCode)
+			if !ok {
+				return err
+			}
+
+			serverCtx.Logger.Debug(fmt.Sprintf("received quit signal: %d", errCode.Code))
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flags.FlagHome, defaultNodeHome, "The application home directory")
+	cmd.Flags().Bool(flagGRPCEnable, true, "Define if the gRPC server should be enabled")
+	cmd.Flags().String(flagGRPCAddress, config.DefaultGRPCAddress, "the gRPC server address to listen on")
+	cmd.Flags().Bool(flagAPIEnable, true, "Define if the API server should be enabled")
+	cmd.Flags().String(flagAPIAddress, config.DefaultAPIAddress, "the API server address to listen on")
+
+	return cmd
+}
+
+func startQueryServer(cmdCtx context.Context, ctx *Context, clientCtx client.Context, appCreator types.AppCreator) error {
+	home := ctx.Config.RootDir
+
+	config := config.GetConfig(ctx.Viper)
+	if !config.API.Enable && !config.GRPC.Enable {
+		return errors.New("neither the API nor the gRPC server is enabled")
+	}
+
+	db, err := openReadOnlyDB(home)
+	if err != nil {
+		return err
+	}
+
+	app := appCreator(ctx.Logger, db, nil, ctx.Viper)
+	clientCtx = clientCtx.
+		WithHomeDir(home).
+		WithClient(newQueryServerClient(app))
+
+	limiter, err := ratelimit.NewLimiter(config.RateLimit)
+	if err != nil {
+		return err
+	}
+
+	var apiSrv *api.Server
+	if config.API.Enable {
+		apiSrv = api.New(clientCtx, ctx.Logger.With("module", "api-server"))
+		apiSrv.Limiter = limiter
+		app.RegisterAPIRoutes(apiSrv, config.API)
+		errCh := make(chan error)
+
+		go func() {
+			if err := apiSrv.Start(config); err != nil {
+				errCh <- err
+			}
+		}()
+
+		select {
+		case err := <-errCh:
+			return err
+		case <-time.After(types.ServerStartTime): // assume server started successfully
+		}
+	}
+
+	var grpcSrv *grpc.Server
+	if config.GRPC.Enable {
+		grpcSrv, err = servergrpc.StartGRPCServer(clientCtx, app, config.GRPC.Address, limiter.ServerOptions()...)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx.Logger.Info("serving queries", "height", app.Info(abci.RequestInfo{}).LastBlockHeight)
+
+	defer func() {
+		if apiSrv != nil {
+			_ = apiSrv.Close()
+		}
+
+		if grpcSrv != nil {
+			grpcSrv.Stop()
+		}
+
+		if err := app.Close(); err != nil {
+			ctx.Logger.Error("failed to close application", "err", err)
+		}
+
+		ctx.Logger.Info("exiting...")
+	}()
+
+	// Wait for SIGINT or SIGTERM signal, or for the command to be canceled
+	return waitForQuitSignals(cmdCtx)
+}
+
+func openReadOnlyDB(rootDir string) (dbm.DB, error) {
+	dataDir := filepath.Join(rootDir, "data")
+	return sdk.NewReadOnlyLevelDB("application", dataDir)
+}
+
+// queryServerClient is the Tendermint RPC client of the query server, which
+// runs the ABCI queries on the app. All the other methods require a node and
+// fail with errNoNode.
+type queryServerClient struct {
+	*service.BaseService
+
+	app abci.Application
+}
+
+var _ rpcclient.Client = queryServerClient{}
+
+func newQueryServerClient(app abci.Application) queryServerClient {
+	c := queryServerClient{app: app}
+	c.BaseService = service.NewBaseService(nil, "QueryServerClient", c)
+	return c
+}
+
+func (c queryServerClient) ABCIInfo(context.Context) (*ctypes.ResultABCIInfo, error) {
+	return &ctypes.ResultABCIInfo{Response: c.app.Info(abci.RequestInfo{})}, nil
+}
+
+func (c queryServerClient) ABCIQuery(ctx context.Context, path string, data tmbytes.HexBytes) (*ctypes.ResultABCIQuery, error) {
+	return c.ABCIQueryWithOptions(ctx, path, data, rpcclient.DefaultABCIQueryOptions)
+}
+
+func (c queryServerClient) ABCIQueryWithOptions(
+	_ context.Context, path string, data tmbytes.HexBytes, opts rpcclient.ABCIQueryOptions,
+) (*ctypes.ResultABCIQuery, error) {
+	res := c.app.Query(abci.RequestQuery{Path: path, Data: data, Height: opts.Height, Prove: opts.Prove})
+	return &ctypes.ResultABCIQuery{Response: res}, nil
+}
+
+func (queryServerClient) BroadcastTxCommit(context.Context, tmtypes.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) BroadcastTxAsync(context.Context, tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) BroadcastTxSync(context.Context, tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) Subscribe(context.Context, string, string, ...int) (<-chan ctypes.ResultEvent, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) Unsubscribe(context.Context, string, string) error {
+	return errNoNode
+}
+
+func (queryServerClient) UnsubscribeAll(context.Context, string) error {
+	return errNoNode
+}
+
+func (queryServerClient) Genesis(context.Context) (*ctypes.ResultGenesis, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) GenesisChunked(context.Context, uint) (*ctypes.ResultGenesisChunk, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) BlockchainInfo(context.Context, int64, int64) (*ctypes.ResultBlockchainInfo, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) NetInfo(context.Context) (*ctypes.ResultNetInfo, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) DumpConsensusState(context.Context) (*ctypes.ResultDumpConsensusState, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) ConsensusState(context.Context) (*ctypes.ResultConsensusState, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) ConsensusParams(context.Context, *int64) (*ctypes.ResultConsensusParams, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) Health(context.Context) (*ctypes.ResultHealth, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) Block(context.Context, *int64) (*ctypes.ResultBlock, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) BlockByHash(context.Context, []byte) (*ctypes.ResultBlock, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) BlockResults(context.Context, *int64) (*ctypes.ResultBlockResults, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) Commit(context.Context, *int64) (*ctypes.ResultCommit, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) DataCommitment(context.Context, string) (*ctypes.ResultDataCommitment, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) Validators(context.Context, *int64, *int, *int) (*ctypes.ResultValidators, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) Tx(context.Context, []byte, bool) (*ctypes.ResultTx, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) TxSearch(context.Context, string, bool, *int, *int, string) (*ctypes.ResultTxSearch, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) BlockSearch(context.Context, string, *int, *int, string) (*ctypes.ResultBlockSearch, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) Status(context.Context) (*ctypes.ResultStatus, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) BroadcastEvidence(context.Context, tmtypes.Evidence) (*ctypes.ResultBroadcastEvidence, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) UnconfirmedTxs(context.Context, *int) (*ctypes.ResultUnconfirmedTxs, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) NumUnconfirmedTxs(context.Context) (*ctypes.ResultUnconfirmedTxs, error) {
+	return nil, errNoNode
+}
+
+func (queryServerClient) CheckTx(context.Context, tmtypes.Tx) (*ctypes.ResultCheckTx, error) {
+	return nil, errNoNode
+}
@@ -0,0 +1,210 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/server/config"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	simappparams "github.com/cosmos/cosmos-sdk/simapp/params"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+)
+
+// newQueryServerContext returns the context of the query server command with
+// the default app.toml in the given home.
+func newQueryServerContext(t *testing.T, home string) context.Context {
+	serverCtx := server.NewDefaultContext()
+	serverCtx.Config.RootDir = home
+	serverCtx.Viper.Set(flags.FlagHome, home)
+	serverCtx.Logger = log.NewNopLogger()
+	appConfigPath := filepath.Join(home, "app.toml")
+	config.WriteConfigFile(appConfigPath, config.DefaultConfig())
+	serverCtx.Viper.SetConfigFile(appConfigPath)
+	require.NoError(t, serverCtx.Viper.ReadInConfig())
+
+	return context.WithValue(context.Background(), server.ServerContextKey, serverCtx)
+}
+
+// writeQueryServerState commits two blocks of a simapp in the given home. The
+// coin returned is minted at height 2.
+func writeQueryServerState(t *testing.T, home string, encCfg simappparams.EncodingConfig) (sdk.Coin, storetypes.CommitID) {
+	db, err := sdk.NewLevelDB("application", filepath.Join(home, "data"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	app := simapp.NewSimApp(log.NewNopLogger(), db, nil, true, map[int64]bool{}, home, 0, encCfg, simapp.EmptyAppOptions{})
+	app.InitChain(abci.RequestInitChain{
+		Validators:      []abci.ValidatorUpdate{},
+		ConsensusParams: simapp.DefaultConsensusParams,
+		AppStateBytes:   newDefaultGenesisDoc(encCfg.Marshaler).AppState,
+	})
+	app.Commit()
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 2}})
+	minted := sdk.NewInt64Coin("minted", 10)
+	require.NoError(t, app.BankKeeper.MintCoins(app.NewContext(false, tmproto.Header{Height: 2}), minttypes.ModuleName, sdk.NewCoins(minted)))
+	app.Commit()
+
+	return minted, app.LastCommitID()
+}
+
+func TestQueryServerReadOnlyState(t *testing.T) {
+	home := t.TempDir()
+	encCfg := simapp.MakeTestEncodingConfig()
+	logger := log.NewNopLogger()
+	ctx := newQueryServerContext(t, home)
+
+	queryServer := func(args ...string) error {
+		cmd := server.QueryServerCmd(nil, home)
+		cmd.SetOut(ioutil.Discard)
+		cmd.SetErr(ioutil.Discard)
+		cmd.SetArgs(args)
+		return cmd.ExecuteContext(ctx)
+	}
+
+	// the database must exist
+	require.Error(t, queryServer("--grpc.address", "127.0.0.1:0", "--api.enable=false"))
+	require.EqualError(t, queryServer("--grpc.enable=false", "--api.enable=false"), "neither the API nor the gRPC server is enabled")
+
+	minted, commitID := writeQueryServerState(t, home, encCfg)
+
+	// the application can be loaded and queried at every height from a
+	// read-only database
+	db, err := sdk.NewReadOnlyLevelDB("application", filepath.Join(home, "data"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.Error(t, db.Set([]byte("key"), []byte("value")))
+
+	app := simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, home, 0, encCfg, simapp.EmptyAppOptions{})
+	require.Equal(t, commitID, app.LastCommitID())
+
+	supplyOf := func(height int64) sdk.Coin {
+		req := encCfg.Marshaler.MustMarshal(&banktypes.QuerySupplyOfRequest{Denom: minted.Denom})
+		res := app.Query(abci.RequestQuery{Path: "/cosmos.bank.v1beta1.Query/SupplyOf", Data: req, Height: height})
+		require.True(t, res.IsOK(), res.Log)
+
+		var supply banktypes.QuerySupplyOfResponse
+		encCfg.Marshaler.MustUnmarshal(res.Value, &supply)
+		return supply.Amount
+	}
+	require.Equal(t, sdk.NewInt64Coin(minted.Denom, 0), supplyOf(1))
+	require.Equal(t, minted, supplyOf(2))
+	require.Equal(t, minted, supplyOf(0))
+}
+
+func TestQueryServerServers(t *testing.T) {
+	home := t.TempDir()
+	encCfg := simapp.MakeTestEncodingConfig()
+	clientCtx := client.Context{}.
+		WithCodec(encCfg.Marshaler).
+		WithInterfaceRegistry(encCfg.InterfaceRegistry).
+		WithTxConfig(encCfg.TxConfig).
+		WithLegacyAmino(encCfg.Amino)
+	ctx := context.WithValue(newQueryServerContext(t, home), client.ClientContextKey, &clientCtx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	minted, _ := writeQueryServerState(t, home, encCfg)
+
+	_, grpcPort, err := server.FreeTCPAddr()
+	require.NoError(t, err)
+	_, apiPort, err := server.FreeTCPAddr()
+	require.NoError(t, err)
+
+	appCreator := func(logger log.Logger, db dbm.DB, _ io.Writer, _ servertypes.AppOptions) servertypes.Application {
+		return simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, home, 0, encCfg, simapp.EmptyAppOptions{})
+	}
+	cmd := server.QueryServerCmd(appCreator, home)
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	cmd.SetArgs([]string{
+		"--grpc.address", "127.0.0.1:" + grpcPort,
+		"--api.enable=true",
+		"--api.address", "tcp://127.0.0.1:" + apiPort,
+	})
+	errCh := make(chan error, 1)
+	go func() { errCh <- cmd.ExecuteContext(ctx) }()
+
+	// gRPC
+	conn, err := grpc.Dial("127.0.0.1:"+grpcPort, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	bankClient := banktypes.NewQueryClient(conn)
+
+	supplyOf := func(height string) sdk.Coin {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if height != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, height)
+		}
+		res, err := bankClient.SupplyOf(ctx, &banktypes.QuerySupplyOfRequest{Denom: minted.Denom}, grpc.WaitForReady(true))
+		require.NoError(t, err)
+		return res.Amount
+	}
+	require.Equal(t, minted, supplyOf(""))
+	require.Equal(t, sdk.NewInt64Coin(minted.Denom, 0), supplyOf("1"))
+
+	// REST, which is started before the gRPC server
+	get := func(path string, header ...string) (int, []byte) {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:"+apiPort+path, nil)
+		require.NoError(t, err)
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err, path)
+		defer res.Body.Close()
+
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, body
+	}
+
+	code, body := get("/cosmos/bank/v1beta1/supply/" + minted.Denom)
+	require.Equal(t, http.StatusOK, code, string(body))
+	var supply banktypes.QuerySupplyOfResponse
+	require.NoError(t, encCfg.Marshaler.UnmarshalJSON(body, &supply))
+	require.Equal(t, minted, supply.Amount)
+
+	code, body = get("/cosmos/bank/v1beta1/supply/"+minted.Denom, grpctypes.GRPCBlockHeightHeader, "1")
+	require.Equal(t, http.StatusOK, code, string(body))
+	require.NoError(t, encCfg.Marshaler.UnmarshalJSON(body, &supply))
+	require.True(t, supply.Amount.IsZero())
+
+	// the routes which require a node fail instead of panicking
+	for _, path := range []string{
+		"/node_info",
+		"/syncing",
+		"/blocks/latest",
+		"/validatorsets/latest",
+		"/txs?message.action=send",
+		"/txs/" + strings.Repeat("AB", 32),
+	} {
+		code, body := get(path)
+		require.Equal(t, http.StatusInternalServerError, code, path)
+		require.Contains(t, string(body), "not supported by the query server", path)
+	}
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
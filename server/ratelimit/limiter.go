@@ -0,0 +1,303 @@
+// Package ratelimit limits the requests served by the gRPC, gRPC-web and API
+// servers, by client IP, by method and by number of requests in flight.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cosmos/cosmos-sdk/server/config"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+// sweepInterval is the interval at which the buckets of the client IPs which
+// have not made requests for long enough to be full again are removed.
+const sweepInterval = time.Minute
+
+// The reasons of the rejected requests, which label the rejected requests
+// counter.
+const (
+	reasonDenied     = "method_denied"
+	reasonClientRate = "client_rate"
+	reasonMethodRate = "method_rate"
+	reasonInFlight   = "in_flight"
+)
+
+var (
+	// ErrMethodDenied is returned for the requests of the methods which are
+	// not allowed.
+	ErrMethodDenied = status.Error(codes.PermissionDenied, "method is not allowed")
+	// ErrRateLimited is returned for the requests exceeding the rate of their
+	// client IP or of their method.
+	ErrRateLimited = status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	// ErrTooManyInFlight is returned for the requests made while the maximum
+	// number of requests are in flight.
+	ErrTooManyInFlight = status.Error(codes.ResourceExhausted, "too many requests in flight")
+)
+
+// healthCheckService is the prefix of the methods of the gRPC health service.
+const healthCheckService = "/grpc.health.v1.Health/"
+
+// isHealthCheck reports whether a method is a health check, of the
+// grpc.health.v1.Health service or of the /health and /ready endpoints of the
+// API server. They are never limited, so that a loaded node is not taken out
+// of service by its orchestrator for failing its probes.
+func isHealthCheck(method string) bool {
+	return method == "/health" || method == "/ready" || strings.HasPrefix(method, healthCheckService)
+}
+
+// Limiter limits the requests by client IP, by method and by number of
+// requests in flight, and rejects the requests of the methods which are not
+// allowed. A nil Limiter allows all requests. It is safe for concurrent use.
+type Limiter struct {
+	clientRate  float64
+	burst       float64
+	methodBurst float64
+	methodRates []methodRate
+	allow       []string
+	deny        []string
+	inFlight    chan struct{}
+
+	mtx       sync.Mutex
+	clients   map[string]*bucket
+	methods   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// methodRate is the rate of requests allowed for the methods with a prefix.
+type methodRate struct {
+	prefix string
+	rate   float64
+}
+
+// NewLimiter returns the Limiter of the given configuration, or nil if rate
+// limiting is disabled.
+func NewLimiter(cfg config.RateLimitConfig) (*Limiter, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+	if cfg.RequestsPerSecond < 0 {
+		return nil, fmt.Errorf("invalid rate limit requests per second %v", cfg.RequestsPerSecond)
+	}
+
+	l := &Limiter{
+		clientRate: cfg.RequestsPerSecond,
+		burst:      math.Max(1, float64(cfg.Burst)),
+		allow:      cfg.AllowMethods,
+		deny:       cfg.DenyMethods,
+		clients:    make(map[string]*bucket),
+		methods:    make(map[string]*bucket),
+		now:        time.Now,
+	}
+
+	for _, spec := range cfg.MethodRequestsPerSecond {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid method rate limit %q, expected {method prefix}={requests per second}", spec)
+		}
+		rate, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid method rate limit %q: expected a non-negative number of requests per second", spec)
+		}
+
+		l.methodRates = append(l.methodRates, methodRate{prefix: parts[0], rate: rate})
+	}
+	// the longest matching prefix applies
+	sort.SliceStable(l.methodRates, func(i, j int) bool {
+		return len(l.methodRates[i].prefix) > len(l.methodRates[j].prefix)
+	})
+
+	// the methods use the burst of the clients unless they have their own
+	l.methodBurst = l.burst
+	if cfg.MethodBurst > 0 {
+		l.methodBurst = float64(cfg.MethodBurst)
+	}
+
+	if cfg.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, cfg.MaxInFlight)
+	}
+
+	return l, nil
+}
+
+// Acquire admits a request of a client IP to a method of a server, and returns
+// the function to call once the request is served. The server only labels the
+// rejected requests counter. It returns ErrMethodDenied, ErrRateLimited or
+// ErrTooManyInFlight if the request is rejected. The health checks are always
+// admitted.
+func (l *Limiter) Acquire(server, clientIP, method string) (release func(), err error) {
+	if l == nil || isHealthCheck(method) {
+		return func() {}, nil
+	}
+
+	if reason, err := l.admit(clientIP, method); err != nil {
+		countRejected(server, reason)
+		return nil, err
+	}
+
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.inFlight <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-l.inFlight }) }, nil
+
+	default:
+		countRejected(server, reasonInFlight)
+		return nil, ErrTooManyInFlight
+	}
+}
+
+func countRejected(server, reason string) {
+	telemetry.IncrCounterWithLabels(
+		[]string{"server", "requests", "rejected"},
+		1,
+		[]metrics.Label{telemetry.NewLabel("server", server), telemetry.NewLabel("reason", reason)},
+	)
+}
+
+// admit checks a request against the allowed and denied methods and the rates,
+// and returns the reason it is rejected for. The tokens of the client and of
+// the method are only taken once both of their buckets admit the request, so
+// that the requests rejected by the rate of their method do not use up the
+// rate of their client, and conversely.
+func (l *Limiter) admit(clientIP, method string) (string, error) {
+	if !l.allowed(method) {
+		return reasonDenied, ErrMethodDenied
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	var clientBucket, methodBucket *bucket
+
+	if l.clientRate > 0 {
+		b, ok := l.clients[clientIP]
+		if !ok {
+			b = newBucket(l.burst, now)
+			l.clients[clientIP] = b
+		}
+		if !b.refill(l.clientRate, l.burst, now) {
+			return reasonClientRate, ErrRateLimited
+		}
+		clientBucket = b
+	}
+
+	for _, mr := range l.methodRates {
+		if !strings.HasPrefix(method, mr.prefix) {
+			continue
+		}
+
+		b, ok := l.methods[mr.prefix]
+		if !ok {
+			b = newBucket(l.methodBurst, now)
+			l.methods[mr.prefix] = b
+		}
+		if !b.refill(mr.rate, l.methodBurst, now) {
+			return reasonMethodRate, ErrRateLimited
+		}
+		methodBucket = b
+		break
+	}
+
+	if clientBucket != nil {
+		clientBucket.take()
+	}
+	if methodBucket != nil {
+		methodBucket.take()
+	}
+
+	return "", nil
+}
+
+// allowed returns whether a method is allowed by the allowed and denied method
+// prefixes.
+func (l *Limiter) allowed(method string) bool {
+	if hasPrefix(method, l.deny) {
+		return false
+	}
+	return len(l.allow) == 0 || hasPrefix(method, l.allow)
+}
+
+func hasPrefix(method string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sweep removes the buckets of the client IPs which are full again, so that
+// they do not accumulate. It must be called with mtx held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for ip, b := range l.clients {
+		if b.tokensAt(l.clientRate, l.burst, now) >= l.burst {
+			delete(l.clients, ip)
+		}
+	}
+}
+
+// bucket is a token bucket, refilled at a rate up to a burst of tokens.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(burst float64, now time.Time) *bucket {
+	return &bucket{tokens: burst, last: now}
+}
+
+func (b *bucket) tokensAt(rate, burst float64, now time.Time) float64 {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed <= 0 {
+		return b.tokens
+	}
+	return math.Min(burst, b.tokens+elapsed*rate)
+}
+
+// refill refills the bucket up to now, and returns false if it is empty.
+func (b *bucket) refill(rate, burst float64, now time.Time) bool {
+	b.tokens = b.tokensAt(rate, burst, now)
+	if now.After(b.last) {
+		b.last = now
+	}
+
+	return b.tokens >= 1
+}
+
+// take takes a token from a bucket which is not empty.
+func (b *bucket) take() {
+	b.tokens--
+}
+
+// hostIP returns the host of a network address, or the address itself if it
+// has no port.
+func hostIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
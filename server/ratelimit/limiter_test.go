@@ -0,0 +1,250 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/cosmos/cosmos-sdk/server/config"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestLimiter(t *testing.T, cfg config.RateLimitConfig) (*Limiter, *fakeClock) {
+	cfg.Enable = true
+	l, err := NewLimiter(cfg)
+	require.NoError(t, err)
+
+	clock := &fakeClock{now: time.Unix(1_000_000, 0)}
+	l.now = func() time.Time { return clock.now }
+
+	return l, clock
+}
+
+func TestNewLimiter(t *testing.T) {
+	l, err := NewLimiter(config.DefaultConfig().RateLimit)
+	require.NoError(t, err)
+	require.Nil(t, l)
+
+	release, err := l.Acquire(serverGRPC, "1.2.3.4", "/cosmos.bank.v1beta1.Query/Balance")
+	require.NoError(t, err)
+	release()
+
+	for _, spec := range []string{"/cosmos", "/cosmos=", "/cosmos=fast", "/cosmos=-1"} {
+		_, err := NewLimiter(config.RateLimitConfig{Enable: true, MethodRequestsPerSecond: []string{spec}})
+		require.Error(t, err, spec)
+	}
+}
+
+func TestLimiterClientRate(t *testing.T) {
+	l, clock := newTestLimiter(t, config.RateLimitConfig{RequestsPerSecond: 2, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		_, err := l.Acquire(serverGRPC, "1.2.3.4", "/a")
+		require.NoError(t, err)
+	}
+	_, err := l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.Equal(t, ErrRateLimited, err)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// other clients have their own rate
+	_, err = l.Acquire(serverGRPC, "5.6.7.8", "/a")
+	require.NoError(t, err)
+
+	clock.advance(500 * time.Millisecond)
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.NoError(t, err)
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.Equal(t, ErrRateLimited, err)
+
+	// the buckets which are full again are swept
+	clock.advance(sweepInterval)
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.NoError(t, err)
+	require.Len(t, l.clients, 1)
+}
+
+func TestLimiterMethodRate(t *testing.T) {
+	l, clock := newTestLimiter(t, config.RateLimitConfig{
+		Burst:                   1,
+		MethodRequestsPerSecond: []string{"/cosmos.=100", "/cosmos.tx.=1"},
+	})
+
+	// the longest prefix applies, over all clients
+	_, err := l.Acquire(serverGRPC, "1.2.3.4", "/cosmos.tx.v1beta1.Service/Simulate")
+	require.NoError(t, err)
+	_, err = l.Acquire(serverGRPC, "5.6.7.8", "/cosmos.tx.v1beta1.Service/Simulate")
+	require.Equal(t, ErrRateLimited, err)
+	_, err = l.Acquire(serverGRPC, "5.6.7.8", "/cosmos.bank.v1beta1.Query/Balance")
+	require.NoError(t, err)
+
+	// the methods without a rate are not limited
+	for i := 0; i < 10; i++ {
+		_, err = l.Acquire(serverGRPC, "5.6.7.8", "/other")
+		require.NoError(t, err)
+	}
+
+	clock.advance(time.Second)
+	_, err = l.Acquire(serverGRPC, "5.6.7.8", "/cosmos.tx.v1beta1.Service/Simulate")
+	require.NoError(t, err)
+}
+
+func TestLimiterMethodBurst(t *testing.T) {
+	l, _ := newTestLimiter(t, config.RateLimitConfig{
+		Burst:                   1,
+		MethodBurst:             3,
+		MethodRequestsPerSecond: []string{"/a=1"},
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := l.Acquire(serverGRPC, "1.2.3.4", "/a")
+		require.NoError(t, err)
+	}
+	_, err := l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.Equal(t, ErrRateLimited, err)
+}
+
+func TestLimiterClientAndMethodRates(t *testing.T) {
+	l, _ := newTestLimiter(t, config.RateLimitConfig{
+		RequestsPerSecond:       1,
+		Burst:                   2,
+		MethodBurst:             1,
+		MethodRequestsPerSecond: []string{"/a=1"},
+	})
+
+	_, err := l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.NoError(t, err)
+
+	// a request rejected by the rate of its method does not take a token of
+	// its client
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.Equal(t, ErrRateLimited, err)
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/b")
+	require.NoError(t, err)
+
+	// a request rejected by the rate of its client does not take a token of
+	// its method
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/b")
+	require.Equal(t, ErrRateLimited, err)
+	l.methods["/a"].tokens = 1
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.Equal(t, ErrRateLimited, err)
+	_, err = l.Acquire(serverGRPC, "5.6.7.8", "/a")
+	require.NoError(t, err)
+}
+
+func TestLimiterMaxInFlight(t *testing.T) {
+	l, _ := newTestLimiter(t, config.RateLimitConfig{MaxInFlight: 2})
+
+	release1, err := l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.NoError(t, err)
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/a")
+	require.NoError(t, err)
+	_, err = l.Acquire(serverAPI, "5.6.7.8", "/b")
+	require.Equal(t, ErrTooManyInFlight, err)
+
+	// releasing twice frees a single request
+	release1()
+	release1()
+	_, err = l.Acquire(serverAPI, "5.6.7.8", "/b")
+	require.NoError(t, err)
+	_, err = l.Acquire(serverAPI, "5.6.7.8", "/b")
+	require.Equal(t, ErrTooManyInFlight, err)
+}
+
+func TestLimiterAllowDenyMethods(t *testing.T) {
+	l, _ := newTestLimiter(t, config.RateLimitConfig{
+		AllowMethods: []string{"/cosmos.bank.", "/cosmos/bank/"},
+		DenyMethods:  []string{"/cosmos.bank.v1beta1.Query/AllBalances"},
+	})
+
+	_, err := l.Acquire(serverGRPC, "1.2.3.4", "/cosmos.bank.v1beta1.Query/Balance")
+	require.NoError(t, err)
+	_, err = l.Acquire(serverAPI, "1.2.3.4", "/cosmos/bank/v1beta1/balances/addr")
+	require.NoError(t, err)
+
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/cosmos.bank.v1beta1.Query/AllBalances")
+	require.Equal(t, ErrMethodDenied, err)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = l.Acquire(serverGRPC, "1.2.3.4", "/cosmos.staking.v1beta1.Query/Validators")
+	require.Equal(t, ErrMethodDenied, err)
+}
+
+func TestLimiterHealthChecks(t *testing.T) {
+	l, _ := newTestLimiter(t, config.RateLimitConfig{
+		RequestsPerSecond: 1,
+		Burst:             1,
+		MaxInFlight:       1,
+		AllowMethods:      []string{"/cosmos."},
+		DenyMethods:       []string{"/"},
+	})
+
+	for i := 0; i < 3; i++ {
+		for _, method := range []string{"/health", "/ready", "/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Watch"} {
+			_, err := l.Acquire(serverGRPC, "1.2.3.4", method)
+			require.NoError(t, err, method)
+		}
+	}
+
+	// only the exact paths of the API server are health checks
+	_, err := l.Acquire(serverAPI, "1.2.3.4", "/healthz")
+	require.Equal(t, ErrMethodDenied, err)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	l, _ := newTestLimiter(t, config.RateLimitConfig{RequestsPerSecond: 1, Burst: 1})
+	interceptor := l.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/cosmos.bank.v1beta1.Query/Balance"}
+	handler := func(context.Context, interface{}) (interface{}, error) { return "ok", nil }
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 1000}})
+	res, err := interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", res)
+
+	// the port of the client is ignored
+	ctx = peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 2000}})
+	_, err = interceptor(ctx, nil, info, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestHTTPHandler(t *testing.T) {
+	l, _ := newTestLimiter(t, config.RateLimitConfig{
+		RequestsPerSecond: 1,
+		Burst:             1,
+		DenyMethods:       []string{"/denied"},
+	})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := l.HTTPHandler(ok)
+
+	serve := func(path, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, serve("/allowed", "1.2.3.4:1000"))
+	require.Equal(t, http.StatusTooManyRequests, serve("/allowed", "1.2.3.4:2000"))
+	require.Equal(t, http.StatusOK, serve("/allowed", "5.6.7.8:1000"))
+	require.Equal(t, http.StatusForbidden, serve("/denied", "9.9.9.9:1000"))
+	require.Equal(t, http.StatusOK, serve("/ready", "1.2.3.4:1000"))
+
+	var nilLimiter *Limiter
+	h = nilLimiter.HTTPHandler(ok)
+	require.Equal(t, http.StatusOK, serve("/denied", "9.9.9.9:1000"))
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/cosmos/cosmos-sdk/types/rest"
+)
+
+// The names of the servers which label the rejected requests counter.
+const (
+	serverGRPC = "grpc"
+	serverAPI  = "api"
+)
+
+// ServerOptions returns the options of a gRPC server limiting its requests,
+// or none if l is nil.
+func (l *Limiter) ServerOptions() []grpc.ServerOption {
+	if l == nil {
+		return nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(l.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(l.StreamServerInterceptor()),
+	}
+}
+
+// UnaryServerInterceptor returns the gRPC interceptor limiting the unary
+// requests.
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		release, err := l.Acquire(serverGRPC, peerIP(ctx), info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the gRPC interceptor limiting the streams,
+// which are in flight until they end.
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, err := l.Acquire(serverGRPC, peerIP(ss.Context()), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		return handler(srv, ss)
+	}
+}
+
+// HTTPHandler returns the handler limiting the requests of h, with the URL
+// path as the method. The rejected requests fail with the HTTP status code of
+// their gRPC status code.
+func (l *Limiter) HTTPHandler(h http.Handler) http.Handler {
+	if l == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release, err := l.Acquire(serverAPI, hostIP(r.RemoteAddr), r.URL.Path)
+		if err != nil {
+			st := status.Convert(err)
+			rest.WriteErrorResponse(w, runtime.HTTPStatusFromCode(st.Code()), st.Message())
+			return
+		}
+		defer release()
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return hostIP(p.Addr.String())
+}
@@ -0,0 +1,319 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// appConfigPollInterval is the interval at which app.toml is checked for
+// changes.
+const appConfigPollInterval = 5 * time.Second
+
+// reloadableApp is implemented by the apps embedding BaseApp, whose settings
+// of reloadableSettings can be updated while they are running.
+type reloadableApp interface {
+	UpdateMinGasPrices(sdk.DecCoins)
+	UpdateHaltHeight(uint64)
+	UpdateHaltTime(uint64)
+	UpdateMinRetainBlocks(uint64)
+	UpdateQueryGasLimit(uint64)
+}
+
+// reloadableSetting validates the value of a setting of app.toml, and returns
+// the function which applies it to the app.
+type reloadableSetting func(app reloadableApp, value interface{}) (func(), error)
+
+// reloadableSettings are the settings of app.toml which can be updated while
+// the app is running.
+var reloadableSettings = map[string]reloadableSetting{
+	FlagMinGasPrices: func(app reloadableApp, value interface{}) (func(), error) {
+		gasPrices, err := sdk.ParseDecCoins(cast.ToString(value))
+		if err != nil {
+			return nil, err
+		}
+		return func() { app.UpdateMinGasPrices(gasPrices) }, nil
+	},
+	FlagHaltHeight:      uintSetting(reloadableApp.UpdateHaltHeight),
+	FlagHaltTime:        uintSetting(reloadableApp.UpdateHaltTime),
+	FlagMinRetainBlocks: uintSetting(reloadableApp.UpdateMinRetainBlocks),
+	FlagQueryGasLimit:   uintSetting(reloadableApp.UpdateQueryGasLimit),
+}
+
+func uintSetting(update func(reloadableApp, uint64)) reloadableSetting {
+	return func(app reloadableApp, value interface{}) (func(), error) {
+		v, err := cast.ToUint64E(value)
+		if err != nil {
+			return nil, err
+		}
+		return func() { update(app, v) }, nil
+	}
+}
+
+// reloadableServers are the API and gRPC servers and the telemetry of a node
+// started in process, whose settings of reloadableServerSettings can be
+// updated while it is running.
+type reloadableServers interface {
+	SetAPIEnabled(bool) error
+	SetGRPCEnabled(bool) error
+	SetTelemetryEnabled(bool) error
+	SetTelemetryGlobalLabels([][]string)
+}
+
+// reloadableServerSetting validates the value of a setting of app.toml, and
+// returns the function which applies it to the servers, which may fail, e.g.
+// when a server cannot listen on its address.
+type reloadableServerSetting func(servers reloadableServers, value interface{}) (func() error, error)
+
+// reloadableServerSettings are the settings of app.toml which can be updated
+// while the API and gRPC servers of the node are running. The other settings
+// of the servers, e.g. their addresses, are applied when they are started.
+var reloadableServerSettings = map[string]reloadableServerSetting{
+	"api.enable":        boolSetting(reloadableServers.SetAPIEnabled),
+	flagGRPCEnable:      boolSetting(reloadableServers.SetGRPCEnabled),
+	"telemetry.enabled": boolSetting(reloadableServers.SetTelemetryEnabled),
+	"telemetry.global-labels": func(servers reloadableServers, value interface{}) (func() error, error) {
+		labels, err := parseGlobalLabels(value)
+		if err != nil {
+			return nil, err
+		}
+		return func() error {
+			servers.SetTelemetryGlobalLabels(labels)
+			return nil
+		}, nil
+	},
+}
+
+func boolSetting(update func(reloadableServers, bool) error) reloadableServerSetting {
+	return func(servers reloadableServers, value interface{}) (func() error, error) {
+		v, err := cast.ToBoolE(value)
+		if err != nil {
+			return nil, err
+		}
+		return func() error { return update(servers, v) }, nil
+	}
+}
+
+// parseGlobalLabels parses the telemetry global labels of app.toml, a list of
+// [name, value] tuples.
+func parseGlobalLabels(value interface{}) ([][]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list of labels, got %T", value)
+	}
+
+	labels := make([][]string, len(raw))
+	for i, l := range raw {
+		tuple, ok := l.([]interface{})
+		if !ok || len(tuple) != 2 {
+			return nil, fmt.Errorf("expected a [name, value] label, got %v", l)
+		}
+		name, nameOk := tuple[0].(string)
+		v, valueOk := tuple[1].(string)
+		if !nameOk || !valueOk {
+			return nil, fmt.Errorf("expected a [name, value] label of strings, got %v", l)
+		}
+		labels[i] = []string{name, v}
+	}
+
+	return labels, nil
+}
+
+// appConfigReloader applies the changes of app.toml to the settings of the app
+// and of its servers which can be updated while they are running.
+type appConfigReloader struct {
+	logger  log.Logger
+	app     reloadableApp
+	servers reloadableServers // nil if the node runs no API and gRPC servers
+	path    string
+
+	settings map[string]interface{}
+	modTime  time.Time
+}
+
+func newAppConfigReloader(
+	logger log.Logger, app reloadableApp, servers reloadableServers, path string,
+) (*appConfigReloader, error) {
+	r := &appConfigReloader{logger: logger, app: app, servers: servers, path: path}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	r.modTime = info.ModTime()
+
+	if r.settings, err = readAppConfigSettings(path); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// readAppConfigSettings returns the settings of an app.toml file by key.
+func readAppConfigSettings(path string) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]interface{})
+	for _, key := range v.AllKeys() {
+		settings[key] = v.Get(key)
+	}
+
+	return settings, nil
+}
+
+// reload reads app.toml and applies the changed settings which can be updated
+// at runtime. All of them are validated first, and none is applied if one is
+// invalid. The settings which fail to be applied keep their previous value,
+// so that they are applied again by the next reload. The other changed
+// settings are logged as requiring a restart.
+func (r *appConfigReloader) reload() error {
+	settings, err := readAppConfigSettings(r.path)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	for key := range r.settings {
+		if _, ok := settings[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var updated, restart []string
+	var updates []func() error
+	for _, key := range keys {
+		if reflect.DeepEqual(r.settings[key], settings[key]) {
+			continue
+		}
+
+		update, ok, err := r.settingUpdate(key, settings[key])
+		if !ok {
+			restart = append(restart, key)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s %v: %w", key, settings[key], err)
+		}
+		updates = append(updates, update)
+		updated = append(updated, key)
+	}
+
+	for i, key := range updated {
+		if err := updates[i](); err != nil {
+			r.logger.Error("failed to update app config setting", "key", key, "new", settings[key], "err", err)
+			settings[key] = r.settings[key]
+			continue
+		}
+		r.logger.Info("updated app config setting", "key", key, "old", r.settings[key], "new", settings[key])
+	}
+	for _, key := range restart {
+		r.logger.Info("changed app config setting requires a restart", "key", key, "old", r.settings[key], "new", settings[key])
+	}
+
+	r.settings = settings
+	return nil
+}
+
+// settingUpdate validates the new value of a setting, and returns the function
+// which applies it, or false if the setting cannot be updated at runtime.
+func (r *appConfigReloader) settingUpdate(key string, value interface{}) (func() error, bool, error) {
+	if setting, ok := reloadableSettings[key]; ok {
+		update, err := setting(r.app, value)
+		if err != nil {
+			return nil, true, err
+		}
+		return func() error {
+			update()
+			return nil
+		}, true, nil
+	}
+
+	if setting, ok := reloadableServerSettings[key]; ok && r.servers != nil {
+		update, err := setting(r.servers, value)
+		return update, true, err
+	}
+
+	return nil, false, nil
+}
+
+// run reloads app.toml on SIGHUP and when its modification time changes,
+// until done is closed.
+func (r *appConfigReloader) run(done <-chan struct{}) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	ticker := time.NewTicker(appConfigPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+
+		case <-sighup:
+			r.reloadAndLog()
+
+		case <-ticker.C:
+			info, err := os.Stat(r.path)
+			if err != nil || info.ModTime().Equal(r.modTime) {
+				continue
+			}
+			r.modTime = info.ModTime()
+			r.reloadAndLog()
+		}
+	}
+}
+
+func (r *appConfigReloader) reloadAndLog() {
+	r.logger.Info("reloading app config", "path", r.path)
+	if err := r.reload(); err != nil {
+		r.logger.Error("failed to reload app config, no setting was updated", "err", err)
+	}
+}
+
+// startAppConfigReloader reloads app.toml into the app and its servers, if
+// any, on SIGHUP and when the file changes, until the returned function is
+// called.
+func startAppConfigReloader(ctx *Context, app types.Application, servers reloadableServers) (stop func()) {
+	reloadable, ok := app.(reloadableApp)
+	if !ok {
+		return func() {}
+	}
+
+	logger := ctx.Logger.With("module", "server")
+	path := filepath.Join(ctx.Config.RootDir, "config", "app.toml")
+	r, err := newAppConfigReloader(logger, reloadable, servers, path)
+	if err != nil {
+		logger.Error("app config will not be reloaded", "err", err)
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go r.run(done)
+
+	return func() { close(done) }
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/server/config"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+type fakeReloadableApp struct {
+	minGasPrices                                         sdk.DecCoins
+	haltHeight, haltTime, minRetainBlocks, queryGasLimit uint64
+}
+
+func (app *fakeReloadableApp) UpdateMinGasPrices(gasPrices sdk.DecCoins) {
+	app.minGasPrices = gasPrices
+}
+func (app *fakeReloadableApp) UpdateHaltHeight(haltHeight uint64)  { app.haltHeight = haltHeight }
+func (app *fakeReloadableApp) UpdateHaltTime(haltTime uint64)      { app.haltTime = haltTime }
+func (app *fakeReloadableApp) UpdateMinRetainBlocks(blocks uint64) { app.minRetainBlocks = blocks }
+func (app *fakeReloadableApp) UpdateQueryGasLimit(limit uint64)    { app.queryGasLimit = limit }
+
+type fakeReloadableServers struct {
+	api, grpc, telemetry bool
+	globalLabels         [][]string
+	grpcErr              error
+}
+
+func (s *fakeReloadableServers) SetAPIEnabled(enabled bool) error {
+	s.api = enabled
+	return nil
+}
+
+func (s *fakeReloadableServers) SetGRPCEnabled(enabled bool) error {
+	if s.grpcErr != nil {
+		return s.grpcErr
+	}
+	s.grpc = enabled
+	return nil
+}
+
+func (s *fakeReloadableServers) SetTelemetryEnabled(enabled bool) error {
+	s.telemetry = enabled
+	return nil
+}
+
+func (s *fakeReloadableServers) SetTelemetryGlobalLabels(labels [][]string) {
+	s.globalLabels = labels
+}
+
+func TestAppConfigReloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	cfg := config.DefaultConfig()
+	config.WriteConfigFile(path, cfg)
+
+	app := &fakeReloadableApp{}
+	r, err := newAppConfigReloader(log.NewNopLogger(), app, nil, path)
+	require.NoError(t, err)
+
+	// nothing changed
+	require.NoError(t, r.reload())
+	require.Equal(t, &fakeReloadableApp{}, app)
+
+	cfg.MinGasPrices = "0.1stake"
+	cfg.HaltHeight = 100
+	cfg.QueryGasLimit = 5000
+	// without servers, api.enable requires a restart
+	cfg.API.Enable = !cfg.API.Enable
+	config.WriteConfigFile(path, cfg)
+	require.NoError(t, r.reload())
+	require.Equal(t, &fakeReloadableApp{
+		minGasPrices:  sdk.NewDecCoins(sdk.NewDecCoinFromDec("stake", sdk.NewDecWithPrec(1, 1))),
+		haltHeight:    100,
+		queryGasLimit: 5000,
+	}, app)
+
+	// no setting is updated if one is invalid
+	cfg.HaltTime = 1700000000
+	cfg.MinGasPrices = "invalid"
+	config.WriteConfigFile(path, cfg)
+	require.Error(t, r.reload())
+	require.Equal(t, uint64(0), app.haltTime)
+
+	cfg.MinGasPrices = ""
+	cfg.HaltHeight = 0
+	config.WriteConfigFile(path, cfg)
+	require.NoError(t, r.reload())
+	require.Equal(t, &fakeReloadableApp{
+		haltTime:      1700000000,
+		queryGasLimit: 5000,
+	}, app)
+}
+
+func TestAppConfigReloaderServers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	cfg := config.DefaultConfig()
+	config.WriteConfigFile(path, cfg)
+
+	app := &fakeReloadableApp{}
+	servers := &fakeReloadableServers{api: cfg.API.Enable, grpc: cfg.GRPC.Enable}
+	r, err := newAppConfigReloader(log.NewNopLogger(), app, servers, path)
+	require.NoError(t, err)
+
+	cfg.API.Enable = !cfg.API.Enable
+	cfg.Telemetry.Enabled = true
+	cfg.Telemetry.GlobalLabels = [][]string{{"chain_id", "test-chain"}}
+	config.WriteConfigFile(path, cfg)
+	require.NoError(t, r.reload())
+	require.Equal(t, &fakeReloadableServers{
+		api:          cfg.API.Enable,
+		grpc:         cfg.GRPC.Enable,
+		telemetry:    true,
+		globalLabels: [][]string{{"chain_id", "test-chain"}},
+	}, servers)
+
+	// a setting which fails to be applied is applied again by the next reload
+	servers.grpcErr = errors.New("address already in use")
+	cfg.GRPC.Enable = !cfg.GRPC.Enable
+	config.WriteConfigFile(path, cfg)
+	require.NoError(t, r.reload())
+	require.Equal(t, !cfg.GRPC.Enable, servers.grpc)
+
+	servers.grpcErr = nil
+	cfg.MinGasPrices = "0.1stake"
+	config.WriteConfigFile(path, cfg)
+	require.NoError(t, r.reload())
+	require.Equal(t, cfg.GRPC.Enable, servers.grpc)
+
+	cfg.Telemetry.GlobalLabels = nil
+	config.WriteConfigFile(path, cfg)
+	require.NoError(t, r.reload())
+	require.Empty(t, servers.globalLabels)
+
+	_, err = parseGlobalLabels([]interface{}{[]interface{}{"chain_id"}})
+	require.Error(t, err)
+}
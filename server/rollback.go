@@ -0,0 +1,118 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmcfg "github.com/tendermint/tendermint/config"
+	tmstate "github.com/tendermint/tendermint/state"
+	tmstore "github.com/tendermint/tendermint/store"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/server/types"
+)
+
+// RollbackCmd rolls back the application and the Tendermint state by one
+// height.
+func RollbackCmd(appCreator types.AppCreator) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rollback",
+		Short: "Rollback the application and Tendermint state by one height",
+		Long: `A state rollback is performed to recover from an incorrect application state transition,
+when Tendermint has persisted an incorrect app hash and is thus unable to make progress.
+Rollback overwrites the application and Tendermint state at height n with the state at
+height n - 1. No blocks are removed, so upon restarting the node the transactions of
+block n are executed again.
+
+The state at height n - 1 must not have been pruned. The node must be stopped.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			serverCtx := GetServerContextFromCmd(cmd)
+
+			db, err := openDB(serverCtx.Config.RootDir)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			blockStoreDB, stateDB, err := openTendermintDBs(serverCtx.Config)
+			if err != nil {
+				return err
+			}
+			defer blockStoreDB.Close()
+			defer stateDB.Close()
+
+			app := appCreator(serverCtx.Logger, db, nil, serverCtx.Viper)
+			height, appHash, err := rollback(app, tmstore.NewBlockStore(blockStoreDB), tmstate.NewStore(stateDB))
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("rolled back state to height %d and app hash %X\n", height, appHash)
+			return nil
+		},
+	}
+}
+
+// rollback rolls back the application and the Tendermint state by one height.
+// All the preconditions of the rollback are checked before any state is
+// modified.
+//
+// The application state is rolled back first: if the Tendermint state failed
+// to be rolled back afterwards, the last block would just be executed again
+// when the node is restarted.
+func rollback(app types.Application, blockStore *tmstore.BlockStore, stateStore tmstate.Store) (int64, []byte, error) {
+	state, err := stateStore.Load()
+	if err != nil {
+		return 0, nil, err
+	}
+	if state.IsEmpty() {
+		return 0, nil, fmt.Errorf("no tendermint state found")
+	}
+
+	height := app.Info(abci.RequestInfo{}).LastBlockHeight
+	if height != state.LastBlockHeight {
+		return 0, nil, fmt.Errorf("application state at height %d does not match tendermint state at height %d",
+			height, state.LastBlockHeight)
+	}
+	if blockStore.LoadBlockMeta(height) == nil {
+		return 0, nil, fmt.Errorf("block at height %d not found", height)
+	}
+	if _, err := stateStore.LoadValidators(height - 1); err != nil {
+		return 0, nil, err
+	}
+	if _, err := stateStore.LoadConsensusParams(height); err != nil {
+		return 0, nil, err
+	}
+
+	if err := app.CommitMultiStore().RollbackToVersion(height - 1); err != nil {
+		return 0, nil, fmt.Errorf("failed to rollback application state: %w", err)
+	}
+
+	rollbackHeight, appHash, err := tmstate.Rollback(blockStore, stateStore)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to rollback tendermint state: %w", err)
+	}
+
+	return rollbackHeight, appHash, nil
+}
+
+// openTendermintDBs opens the databases of the Tendermint block store and
+// state store.
+func openTendermintDBs(cfg *tmcfg.Config) (dbm.DB, dbm.DB, error) {
+	dbType := dbm.BackendType(cfg.DBBackend)
+
+	blockStoreDB, err := dbm.NewDB("blockstore", dbType, cfg.DBDir())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stateDB, err := dbm.NewDB("state", dbType, cfg.DBDir())
+	if err != nil {
+		blockStoreDB.Close()
+		return nil, nil, err
+	}
+
+	return blockStoreDB, stateDB, nil
+}
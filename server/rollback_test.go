@@ -0,0 +1,63 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestRollbackCmdWithoutTendermintState(t *testing.T) {
+	home := t.TempDir()
+	encCfg := simapp.MakeTestEncodingConfig()
+	logger := log.NewNopLogger()
+	appCreator := func(logger log.Logger, db dbm.DB, _ io.Writer, appOpts types.AppOptions) types.Application {
+		return simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, home, 0, encCfg, appOpts)
+	}
+
+	db, err := sdk.NewLevelDB("application", filepath.Join(home, "data"))
+	require.NoError(t, err)
+	app := simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, home, 0, encCfg, simapp.EmptyAppOptions{})
+	app.InitChain(abci.RequestInitChain{
+		Validators:      []abci.ValidatorUpdate{},
+		ConsensusParams: simapp.DefaultConsensusParams,
+		AppStateBytes:   newDefaultGenesisDoc(encCfg.Marshaler).AppState,
+	})
+	app.Commit()
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 2}})
+	app.Commit()
+	commitID := app.LastCommitID()
+	require.NoError(t, db.Close())
+
+	serverCtx := server.NewDefaultContext()
+	serverCtx.Config.RootDir = home
+	serverCtx.Viper.Set(flags.FlagHome, home)
+	serverCtx.Logger = logger
+	ctx := context.WithValue(context.Background(), server.ServerContextKey, serverCtx)
+
+	cmd := server.RollbackCmd(appCreator)
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	cmd.SetArgs([]string{})
+	require.EqualError(t, cmd.ExecuteContext(ctx), "no tendermint state found")
+
+	// the application state is left untouched
+	db, err = sdk.NewLevelDB("application", filepath.Join(home, "data"))
+	require.NoError(t, err)
+	app = simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, home, 0, encCfg, simapp.EmptyAppOptions{})
+	require.Equal(t, commitID, app.LastCommitID())
+	require.NoError(t, db.Close())
+}
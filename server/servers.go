@@ -0,0 +1,254 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/node"
+	"google.golang.org/grpc"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/server/api"
+	"github.com/cosmos/cosmos-sdk/server/config"
+	servergrpc "github.com/cosmos/cosmos-sdk/server/grpc"
+	"github.com/cosmos/cosmos-sdk/server/ratelimit"
+	"github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+// inProcessServers are the API and gRPC servers and the telemetry of a node
+// started in process. They implement reloadableServers, so that they are
+// enabled and disabled when app.toml is reloaded.
+type inProcessServers struct {
+	mtx sync.Mutex
+
+	logger         log.Logger
+	clientCtx      client.Context
+	app            types.Application
+	cfg            config.Config
+	home           string
+	genDocProvider node.GenesisDocProvider
+	limiter        *ratelimit.Limiter
+	health         *api.HealthChecker
+	metrics        *telemetry.Metrics
+
+	apiSrv     *api.Server
+	grpcSrv    *grpc.Server
+	grpcWebSrv *http.Server
+	stopHealth func()
+}
+
+var _ reloadableServers = (*inProcessServers)(nil)
+
+// startInProcessServers sets up the telemetry, and starts the API and gRPC
+// servers which are enabled by the config.
+func startInProcessServers(
+	logger log.Logger, clientCtx client.Context, app types.Application, cfg config.Config, home string,
+	genDocProvider node.GenesisDocProvider, limiter *ratelimit.Limiter, health *api.HealthChecker,
+) (*inProcessServers, error) {
+	s := &inProcessServers{
+		logger:         logger,
+		clientCtx:      clientCtx,
+		app:            app,
+		cfg:            cfg,
+		home:           home,
+		genDocProvider: genDocProvider,
+		limiter:        limiter,
+		health:         health,
+	}
+
+	var err error
+	if s.metrics, err = telemetry.New(cfg.Telemetry); err != nil {
+		return nil, err
+	}
+
+	if cfg.API.Enable {
+		if err := s.startAPI(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.GRPC.Enable {
+		if err := s.startGRPC(); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// SetAPIEnabled starts or stops the API server.
+func (s *inProcessServers) SetAPIEnabled(enabled bool) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.cfg.API.Enable = enabled
+	switch {
+	case enabled && s.apiSrv == nil:
+		return s.startAPI()
+	case !enabled && s.apiSrv != nil:
+		s.stopAPI()
+	}
+
+	return nil
+}
+
+// SetGRPCEnabled starts or stops the gRPC server, and the gRPC-Web server
+// along with it if it is enabled.
+func (s *inProcessServers) SetGRPCEnabled(enabled bool) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.cfg.GRPC.Enable = enabled
+	if s.health != nil {
+		s.health.SetGRPCEnabled(enabled)
+	}
+	switch {
+	case enabled && s.grpcSrv == nil:
+		return s.startGRPC()
+	case !enabled && s.grpcSrv != nil:
+		s.stopGRPC()
+	}
+
+	return nil
+}
+
+// SetTelemetryEnabled enables or disables the telemetry, and the /metrics
+// endpoint of the API server along with it.
+func (s *inProcessServers) SetTelemetryEnabled(enabled bool) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.cfg.Telemetry.Enabled = enabled
+	switch {
+	case enabled && s.metrics == nil:
+		m, err := telemetry.New(s.cfg.Telemetry)
+		if err != nil {
+			return err
+		}
+		s.metrics = m
+
+	case s.metrics != nil:
+		s.metrics.SetEnabled(enabled)
+	}
+
+	if s.apiSrv != nil {
+		s.apiSrv.SetMetrics(s.enabledMetrics())
+	}
+
+	return nil
+}
+
+// SetTelemetryGlobalLabels replaces the global labels of the telemetry.
+func (s *inProcessServers) SetTelemetryGlobalLabels(labels [][]string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.cfg.Telemetry.GlobalLabels = labels
+	telemetry.SetGlobalLabels(labels)
+}
+
+// Close stops the API and gRPC servers.
+func (s *inProcessServers) Close() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.apiSrv != nil {
+		s.stopAPI()
+	}
+	if s.grpcSrv != nil {
+		s.stopGRPC()
+	}
+}
+
+func (s *inProcessServers) enabledMetrics() *telemetry.Metrics {
+	if !s.cfg.Telemetry.Enabled {
+		return nil
+	}
+	return s.metrics
+}
+
+func (s *inProcessServers) startAPI() error {
+	genDoc, err := s.genDocProvider()
+	if err != nil {
+		return err
+	}
+
+	clientCtx := s.clientCtx.
+		WithHomeDir(s.home).
+		WithChainID(genDoc.ChainID)
+
+	apiSrv := api.New(clientCtx, s.logger.With("module", "api-server"))
+	apiSrv.Limiter = s.limiter
+	apiSrv.Health = s.health
+	apiSrv.SetMetrics(s.enabledMetrics())
+	s.app.RegisterAPIRoutes(apiSrv, s.cfg.API)
+	errCh := make(chan error, 1)
+
+	go func() {
+		if err := apiSrv.Start(s.cfg); err != nil {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(types.ServerStartTime): // assume server started successfully
+	}
+
+	s.apiSrv = apiSrv
+	return nil
+}
+
+func (s *inProcessServers) stopAPI() {
+	_ = s.apiSrv.Close()
+	s.apiSrv = nil
+}
+
+func (s *inProcessServers) startGRPC() error {
+	grpcSrv, err := servergrpc.NewGRPCServer(s.clientCtx, s.app, s.limiter.ServerOptions()...)
+	if err != nil {
+		return err
+	}
+	stopHealth := func() {}
+	if s.health != nil {
+		stopHealth = s.health.RegisterGRPCHealthServer(grpcSrv)
+	}
+	if err := servergrpc.ServeGRPC(grpcSrv, s.cfg.GRPC.Address); err != nil {
+		stopHealth()
+		return err
+	}
+
+	var grpcWebSrv *http.Server
+	if s.cfg.GRPCWeb.Enable {
+		grpcWebSrv, err = servergrpc.StartGRPCWeb(grpcSrv, s.cfg)
+		if err != nil {
+			s.logger.Error("failed to start grpc-web http server: ", err)
+			stopHealth()
+			grpcSrv.Stop()
+			return err
+		}
+	}
+
+	if s.health != nil {
+		s.health.SetGRPCServing(true)
+	}
+	s.grpcSrv, s.grpcWebSrv, s.stopHealth = grpcSrv, grpcWebSrv, stopHealth
+	return nil
+}
+
+func (s *inProcessServers) stopGRPC() {
+	if s.health != nil {
+		s.health.SetGRPCServing(false)
+	}
+	s.stopHealth()
+	if s.grpcWebSrv != nil {
+		s.grpcWebSrv.Close()
+	}
+	s.grpcSrv.Stop()
+
+	s.grpcSrv, s.grpcWebSrv, s.stopHealth = nil, nil, nil
+}
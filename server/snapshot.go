@@ -0,0 +1,640 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmcfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/light"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	tmstate "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/statesync"
+	tmstore "github.com/tendermint/tendermint/store"
+	tmtypes "github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/snapshots"
+	snapshottypes "github.com/cosmos/cosmos-sdk/snapshots/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	FlagOutput      = "output"
+	FlagAppOnly     = "app-only"
+	FlagRPCServers  = "rpc-servers"
+	FlagTrustHeight = "trust-height"
+	FlagTrustHash   = "trust-hash"
+
+	// snapshotArchiveMetadata is the name of the archive entry containing the
+	// snapshot metadata, which is followed by the chunks named by their index.
+	snapshotArchiveMetadata = "metadata"
+)
+
+// GetSnapshotStore opens the snapshot store in the data directory of the
+// application home. The store remains open for the lifetime of the process.
+func GetSnapshotStore(appOpts types.AppOptions) (*snapshots.Store, error) {
+	snapshotStore, _, err := openSnapshotStore(appOpts)
+	return snapshotStore, err
+}
+
+// openSnapshotStore opens the snapshot store in the data directory of the
+// application home, and returns the database of its metadata, which must be
+// closed by the caller.
+func openSnapshotStore(appOpts types.AppOptions) (*snapshots.Store, dbm.DB, error) {
+	snapshotDir := filepath.Join(cast.ToString(appOpts.Get(flags.FlagHome)), "data", "snapshots")
+	snapshotDB, err := sdk.NewLevelDB("metadata", snapshotDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshotStore, err := snapshots.NewStore(snapshotDB, snapshotDir)
+	if err != nil {
+		snapshotDB.Close()
+		return nil, nil, err
+	}
+
+	return snapshotStore, snapshotDB, nil
+}
+
+// SnapshotCmd returns the snapshots command group, which manages the state sync
+// snapshots of the local node while it is stopped.
+func SnapshotCmd(appCreator types.AppCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshots",
+		Short: "Manage local state sync snapshots",
+		Long: `Manage the state sync snapshots in the data directory of the node while the node is stopped.
+Snapshots can be created from the local state, dumped to and loaded from portable archives, and
+restored into the state of a new node.`,
+	}
+
+	cmd.AddCommand(
+		ListSnapshotsCmd(),
+		ExportSnapshotCmd(appCreator),
+		DumpSnapshotCmd(),
+		LoadSnapshotCmd(),
+		RestoreSnapshotCmd(appCreator),
+		DeleteSnapshotCmd(),
+	)
+
+	return cmd
+}
+
+// ListSnapshotsCmd lists the local snapshots.
+func ListSnapshotsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List local snapshots",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			snapshotStore, snapshotDB, err := openSnapshotStore(GetServerContextFromCmd(cmd).Viper)
+			if err != nil {
+				return err
+			}
+			defer snapshotDB.Close()
+
+			snapshots, err := snapshotStore.List()
+			if err != nil {
+				return fmt.Errorf("failed to list snapshots: %w", err)
+			}
+
+			for _, snapshot := range snapshots {
+				cmd.Printf("height: %d format: %d chunks: %d hash: %X\n",
+					snapshot.Height, snapshot.Format, snapshot.Chunks, snapshot.Hash)
+			}
+
+			return nil
+		},
+	}
+}
+
+// ExportSnapshotCmd creates a snapshot of the local application state.
+func ExportSnapshotCmd(appCreator types.AppCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Create a snapshot of the local application state",
+		Long: `Create a snapshot of the application state at the given height, which must not be pruned,
+or at the latest height, without running the node.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			height, _ := cmd.Flags().GetUint64(FlagHeight)
+
+			return withSnapshotManager(cmd, appCreator, func(app types.Application, manager *snapshots.Manager) error {
+				if height == 0 {
+					height = uint64(app.Info(abci.RequestInfo{}).LastBlockHeight)
+				}
+
+				snapshot, err := manager.Create(height)
+				if err != nil {
+					return fmt.Errorf("failed to create snapshot at height %d: %w", height, err)
+				}
+
+				cmd.Printf("created snapshot at height %d format %d with %d chunks\n",
+					snapshot.Height, snapshot.Format, snapshot.Chunks)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().Uint64(FlagHeight, 0, "Height of the snapshot (0 means latest height)")
+
+	return cmd
+}
+
+// RestoreSnapshotCmd restores a local snapshot into the empty application
+// state of a new node, and bootstraps its Tendermint state at the height of
+// the snapshot.
+func RestoreSnapshotCmd(appCreator types.AppCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <height> <format>",
+		Short: "Restore the application and Tendermint state from a local snapshot",
+		Long: `Restore the application state of a new node from a local snapshot, and bootstrap its Tendermint
+state at the height of the snapshot, so that the node can be started. The application and Tendermint
+state must be empty.
+
+As done by state sync, the Tendermint state, block and commit of the snapshot height are fetched from
+RPC servers and verified by a light client, using the [statesync] section of config.toml. The RPC
+servers and the trusted height and hash can be overridden with flags. The header following the
+snapshot height must be available, and its app hash must match the restored application state.
+
+With --app-only, only the application state is restored and no RPC server is needed, but the node
+cannot be started: Tendermint fails the handshake as its state is not at the height of the snapshot.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, format, err := parseSnapshotArgs(args)
+			if err != nil {
+				return err
+			}
+
+			serverCtx := GetServerContextFromCmd(cmd)
+			appOnly, _ := cmd.Flags().GetBool(FlagAppOnly)
+
+			var bootstrap *tendermintBootstrap
+			if !appOnly {
+				bootstrap, err = newTendermintBootstrap(cmd, serverCtx.Config, serverCtx.Logger, height)
+				if err != nil {
+					return err
+				}
+				defer bootstrap.close()
+			}
+
+			return withSnapshotManager(cmd, appCreator, func(app types.Application, manager *snapshots.Manager) error {
+				if lastHeight := app.Info(abci.RequestInfo{}).LastBlockHeight; lastHeight != 0 {
+					return fmt.Errorf("cannot restore snapshot into an application state at height %d", lastHeight)
+				}
+
+				if err := manager.RestoreLocalSnapshot(height, format); err != nil {
+					return fmt.Errorf("failed to restore snapshot: %w", err)
+				}
+
+				if bootstrap == nil {
+					cmd.Printf("restored snapshot at height %d format %d, without the Tendermint state\n", height, format)
+					return nil
+				}
+
+				if err := bootstrap.write(app.Info(abci.RequestInfo{}).LastBlockAppHash); err != nil {
+					return fmt.Errorf("failed to bootstrap Tendermint state: %w", err)
+				}
+
+				cmd.Printf("restored snapshot at height %d format %d\n", height, format)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().Bool(FlagAppOnly, false, "Only restore the application state, which leaves the node unable to start")
+	cmd.Flags().StringSlice(FlagRPCServers, nil, "RPC servers to fetch the Tendermint state from (defaults to statesync.rpc_servers)")
+	cmd.Flags().Int64(FlagTrustHeight, 0, "Trusted height of the light client (defaults to statesync.trust_height)")
+	cmd.Flags().String(FlagTrustHash, "", "Trusted hash of the light client (defaults to statesync.trust_hash)")
+
+	return cmd
+}
+
+// tendermintBootstrap holds the Tendermint state, block and commit of a
+// snapshot height, verified by a light client, to be written to the empty
+// Tendermint stores of the node once the application state is restored.
+type tendermintBootstrap struct {
+	state      tmstate.State
+	block      *tmtypes.Block
+	blockParts *tmtypes.PartSet
+	commit     *tmtypes.Commit
+	stateStore tmstate.Store
+	blockStore *tmstore.BlockStore
+
+	blockStoreDB dbm.DB
+	stateDB      dbm.DB
+}
+
+// newTendermintBootstrap fetches the Tendermint state, block and commit of a
+// height from the configured RPC servers, before any state is modified.
+func newTendermintBootstrap(cmd *cobra.Command, cfg *tmcfg.Config, logger log.Logger, height uint64) (*tendermintBootstrap, error) {
+	servers, trustOptions := cfg.StateSync.RPCServers, light.TrustOptions{
+		Period: cfg.StateSync.TrustPeriod,
+		Height: cfg.StateSync.TrustHeight,
+		Hash:   cfg.StateSync.TrustHashBytes(),
+	}
+	if cmd.Flags().Changed(FlagRPCServers) {
+		servers, _ = cmd.Flags().GetStringSlice(FlagRPCServers)
+	}
+	if cmd.Flags().Changed(FlagTrustHeight) {
+		trustOptions.Height, _ = cmd.Flags().GetInt64(FlagTrustHeight)
+	}
+	if cmd.Flags().Changed(FlagTrustHash) {
+		trustHash, _ := cmd.Flags().GetString(FlagTrustHash)
+		hash, err := hex.DecodeString(trustHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust hash: %w", err)
+		}
+		trustOptions.Hash = hash
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no RPC servers to bootstrap the Tendermint state from: set --%s or statesync.rpc_servers, "+
+			"or pass --%s to only restore the application state", FlagRPCServers, FlagAppOnly)
+	}
+
+	genesisState, err := tmstate.MakeGenesisStateFromFile(cfg.GenesisFile())
+	if err != nil {
+		return nil, err
+	}
+
+	blockStoreDB, stateDB, err := openTendermintDBs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	b := &tendermintBootstrap{
+		stateStore:   tmstate.NewStore(stateDB),
+		blockStore:   tmstore.NewBlockStore(blockStoreDB),
+		blockStoreDB: blockStoreDB,
+		stateDB:      stateDB,
+	}
+
+	if err := b.fetch(cmd.Context(), genesisState, servers, trustOptions, logger, height); err != nil {
+		b.close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *tendermintBootstrap) fetch(
+	ctx context.Context, genesisState tmstate.State, servers []string, trustOptions light.TrustOptions, logger log.Logger, height uint64,
+) error {
+	state, err := b.stateStore.Load()
+	if err != nil {
+		return err
+	}
+	if !state.IsEmpty() && state.LastBlockHeight != 0 {
+		return fmt.Errorf("cannot bootstrap a Tendermint state at height %d", state.LastBlockHeight)
+	}
+	if b.blockStore.Height() != 0 {
+		return fmt.Errorf("cannot bootstrap a Tendermint state with blocks up to height %d", b.blockStore.Height())
+	}
+
+	stateProvider, err := statesync.NewLightClientStateProvider(ctx, genesisState.ChainID, genesisState.Version,
+		genesisState.InitialHeight, servers, trustOptions, logger.With("module", "light"))
+	if err != nil {
+		return fmt.Errorf("failed to set up light client state provider: %w", err)
+	}
+
+	if b.state, err = stateProvider.State(ctx, height); err != nil {
+		return fmt.Errorf("failed to fetch the Tendermint state at height %d: %w", height, err)
+	}
+	if b.commit, err = stateProvider.Commit(ctx, height); err != nil {
+		return fmt.Errorf("failed to fetch the commit at height %d: %w", height, err)
+	}
+
+	// Unlike state sync, which starts the node with an empty block store, the
+	// block of the height is saved so that the block store is at the height of
+	// the state when the node is started. Its hash is checked against the
+	// commit verified by the light client.
+	server := servers[0]
+	if !strings.Contains(server, "://") {
+		server = "http://" + server
+	}
+	client, err := rpchttp.New(server, "/websocket")
+	if err != nil {
+		return err
+	}
+	blockHeight := int64(height)
+	res, err := client.Block(ctx, &blockHeight)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the block at height %d: %w", height, err)
+	}
+	if err := res.Block.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid block at height %d: %w", height, err)
+	}
+	b.block, b.blockParts = res.Block, res.Block.MakePartSet(tmtypes.BlockPartSizeBytes)
+	if blockID := (tmtypes.BlockID{Hash: b.block.Hash(), PartSetHeader: b.blockParts.Header()}); !blockID.Equals(b.commit.BlockID) {
+		return fmt.Errorf("ID %s of the block at height %d does not match the block ID %s of its commit",
+			blockID, height, b.commit.BlockID)
+	}
+
+	return nil
+}
+
+// write checks the app hash of the restored application state against the
+// Tendermint state, and writes the block with its commit and the state.
+func (b *tendermintBootstrap) write(appHash []byte) error {
+	if !bytes.Equal(appHash, b.state.AppHash) {
+		return fmt.Errorf("app hash %X of the restored state does not match the app hash %X of the Tendermint state",
+			appHash, b.state.AppHash)
+	}
+
+	b.blockStore.SaveBlock(b.block, b.blockParts, b.commit)
+
+	return b.stateStore.Bootstrap(b.state)
+}
+
+func (b *tendermintBootstrap) close() {
+	b.blockStoreDB.Close()
+	b.stateDB.Close()
+}
+
+// DumpSnapshotCmd writes a local snapshot to a portable archive.
+func DumpSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dump <height> <format>",
+		Short: "Dump a local snapshot to a portable archive",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, format, err := parseSnapshotArgs(args)
+			if err != nil {
+				return err
+			}
+
+			output, _ := cmd.Flags().GetString(FlagOutput)
+			if output == "" {
+				output = fmt.Sprintf("%d-%d.tar.gz", height, format)
+			}
+
+			snapshotStore, snapshotDB, err := openSnapshotStore(GetServerContextFromCmd(cmd).Viper)
+			if err != nil {
+				return err
+			}
+			defer snapshotDB.Close()
+
+			if err := dumpSnapshot(snapshotStore, height, format, output); err != nil {
+				return err
+			}
+
+			cmd.Printf("dumped snapshot at height %d format %d to %s\n", height, format, output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP(FlagOutput, "o", "", "Output file (defaults to <height>-<format>.tar.gz)")
+
+	return cmd
+}
+
+// LoadSnapshotCmd adds the snapshot of a portable archive to the local
+// snapshots.
+func LoadSnapshotCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "load <archive-file>",
+		Short: "Load a snapshot from a portable archive into the local snapshots",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshotStore, snapshotDB, err := openSnapshotStore(GetServerContextFromCmd(cmd).Viper)
+			if err != nil {
+				return err
+			}
+			defer snapshotDB.Close()
+
+			snapshot, err := loadSnapshot(snapshotStore, args[0])
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("loaded snapshot at height %d format %d\n", snapshot.Height, snapshot.Format)
+			return nil
+		},
+	}
+}
+
+// DeleteSnapshotCmd deletes a local snapshot.
+func DeleteSnapshotCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <height> <format>",
+		Short: "Delete a local snapshot",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, format, err := parseSnapshotArgs(args)
+			if err != nil {
+				return err
+			}
+
+			snapshotStore, snapshotDB, err := openSnapshotStore(GetServerContextFromCmd(cmd).Viper)
+			if err != nil {
+				return err
+			}
+			defer snapshotDB.Close()
+
+			snapshot, err := snapshotStore.Get(height, format)
+			if err != nil {
+				return err
+			}
+			if snapshot == nil {
+				return fmt.Errorf("snapshot at height %d format %d does not exist", height, format)
+			}
+
+			return snapshotStore.Delete(height, format)
+		},
+	}
+}
+
+// withSnapshotManager creates the application from the local state and calls
+// fn with its snapshot manager.
+func withSnapshotManager(
+	cmd *cobra.Command, appCreator types.AppCreator, fn func(types.Application, *snapshots.Manager) error,
+) error {
+	serverCtx := GetServerContextFromCmd(cmd)
+
+	db, err := openDB(serverCtx.Config.RootDir)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	app := appCreator(serverCtx.Logger, db, nil, serverCtx.Viper)
+	manager := app.SnapshotManager()
+	if manager == nil {
+		return fmt.Errorf("the application has no snapshot store")
+	}
+
+	return fn(app, manager)
+}
+
+// dumpSnapshot writes the snapshot with the given height and format to a
+// gzipped tar archive, containing the snapshot metadata followed by the
+// chunks.
+func dumpSnapshot(snapshotStore *snapshots.Store, height uint64, format uint32, output string) error {
+	snapshot, chunks, err := snapshotStore.Load(height, format)
+	if err != nil {
+		return err
+	}
+	if snapshot == nil {
+		return fmt.Errorf("snapshot at height %d format %d does not exist", height, format)
+	}
+	defer snapshots.DrainChunks(chunks)
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := writeSnapshotArchive(file, snapshot, chunks); err != nil {
+		file.Close()
+		os.Remove(output)
+		return err
+	}
+
+	return file.Close()
+}
+
+func writeSnapshotArchive(w io.Writer, snapshot *snapshottypes.Snapshot, chunks <-chan io.ReadCloser) error {
+	zWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(zWriter)
+
+	metadata, err := snapshot.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := writeArchiveEntry(tarWriter, snapshotArchiveMetadata, metadata); err != nil {
+		return err
+	}
+
+	index := 0
+	for chunk := range chunks {
+		body, err := ioutil.ReadAll(chunk)
+		chunk.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot chunk %d: %w", index, err)
+		}
+		if err := writeArchiveEntry(tarWriter, strconv.Itoa(index), body); err != nil {
+			return err
+		}
+		index++
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return zWriter.Close()
+}
+
+func writeArchiveEntry(tarWriter *tar.Writer, name string, body []byte) error {
+	err := tarWriter.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(body)),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tarWriter.Write(body)
+	return err
+}
+
+// loadSnapshot saves the snapshot of the given archive to the snapshot store.
+// The chunks are verified against the hashes of the snapshot metadata.
+func loadSnapshot(snapshotStore *snapshots.Store, archive string) (*snapshottypes.Snapshot, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	zReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot archive: %w", err)
+	}
+	tarReader := tar.NewReader(zReader)
+
+	metadata, err := readArchiveEntry(tarReader, snapshotArchiveMetadata)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot snapshottypes.Snapshot
+	if err := snapshot.Unmarshal(metadata); err != nil {
+		return nil, fmt.Errorf("invalid snapshot metadata: %w", err)
+	}
+
+	var savedSnapshot *snapshottypes.Snapshot
+	chunks := make(chan io.ReadCloser)
+	saved := make(chan error, 1)
+	go func() {
+		var err error
+		savedSnapshot, err = snapshotStore.Save(snapshot.Height, snapshot.Format, chunks)
+		saved <- err
+	}()
+
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		body, err := readArchiveEntry(tarReader, strconv.FormatUint(uint64(i), 10))
+		if err != nil {
+			close(chunks)
+			if <-saved == nil {
+				_ = snapshotStore.Delete(snapshot.Height, snapshot.Format)
+			}
+			return nil, err
+		}
+		chunks <- ioutil.NopCloser(bytes.NewReader(body))
+	}
+	close(chunks)
+
+	if err := <-saved; err != nil {
+		return nil, err
+	}
+
+	if !reflect.DeepEqual(savedSnapshot, &snapshot) {
+		_ = snapshotStore.Delete(snapshot.Height, snapshot.Format)
+		return nil, fmt.Errorf("invalid snapshot archive: the chunks do not match the snapshot metadata")
+	}
+
+	return savedSnapshot, nil
+}
+
+func readArchiveEntry(tarReader *tar.Reader, name string) ([]byte, error) {
+	header, err := tarReader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot archive, failed to read %s: %w", name, err)
+	}
+	if header.Name != name {
+		return nil, fmt.Errorf("invalid snapshot archive, expected %s, got %s", name, header.Name)
+	}
+
+	return ioutil.ReadAll(tarReader)
+}
+
+func parseSnapshotArgs(args []string) (uint64, uint32, error) {
+	height, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid height %q: %w", args[0], err)
+	}
+
+	format, err := strconv.ParseUint(args[1], 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid format %q: %w", args[1], err)
+	}
+
+	return height, uint32(format), nil
+}
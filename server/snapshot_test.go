@@ -0,0 +1,184 @@
+package server_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	"github.com/cosmos/cosmos-sdk/snapshots"
+	snapshottypes "github.com/cosmos/cosmos-sdk/snapshots/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestSnapshotCmd(t *testing.T) {
+	source, target := t.TempDir(), t.TempDir()
+	archive := filepath.Join(t.TempDir(), "snapshot.tar.gz")
+	encCfg := simapp.MakeTestEncodingConfig()
+	logger := log.NewNopLogger()
+
+	// the snapshot database of the app is closed after every command, so that
+	// the following commands can open it again
+	var snapshotDB dbm.DB
+	appCreator := func(logger log.Logger, db dbm.DB, _ io.Writer, appOpts types.AppOptions) types.Application {
+		home := appOpts.Get(flags.FlagHome).(string)
+		snapshotDir := filepath.Join(home, "data", "snapshots")
+		var err error
+		snapshotDB, err = sdk.NewLevelDB("metadata", snapshotDir)
+		require.NoError(t, err)
+		snapshotStore, err := snapshots.NewStore(snapshotDB, snapshotDir)
+		require.NoError(t, err)
+
+		return simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, home, 0, encCfg, appOpts,
+			baseapp.SetSnapshotStore(snapshotStore))
+	}
+
+	execute := func(home string, args ...string) (string, error) {
+		serverCtx := server.NewDefaultContext()
+		serverCtx.Config.RootDir = home
+		serverCtx.Viper.Set(flags.FlagHome, home)
+		serverCtx.Logger = logger
+		ctx := context.WithValue(context.Background(), server.ServerContextKey, serverCtx)
+
+		snapshotDB = nil
+		defer func() {
+			if snapshotDB != nil {
+				snapshotDB.Close()
+			}
+		}()
+
+		output := &bytes.Buffer{}
+		cmd := server.SnapshotCmd(appCreator)
+		cmd.SetOut(output)
+		cmd.SetErr(ioutil.Discard)
+		cmd.SetArgs(args)
+		err := cmd.ExecuteContext(ctx)
+		return output.String(), err
+	}
+
+	// commit a few blocks in the source
+	db, err := sdk.NewLevelDB("application", filepath.Join(source, "data"))
+	require.NoError(t, err)
+	app := simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, source, 0, encCfg, simapp.EmptyAppOptions{})
+	app.InitChain(abci.RequestInitChain{
+		Validators:      []abci.ValidatorUpdate{},
+		ConsensusParams: simapp.DefaultConsensusParams,
+		AppStateBytes:   newDefaultGenesisDoc(encCfg.Marshaler).AppState,
+	})
+	app.Commit()
+	for height := int64(2); height <= 3; height++ {
+		app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: height}})
+		app.Commit()
+	}
+	commitID := app.LastCommitID()
+	require.NoError(t, db.Close())
+
+	format := fmt.Sprint(snapshottypes.CurrentFormat)
+	output, err := execute(source, "export")
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("created snapshot at height 3 format %s with 1 chunks\n", format), output)
+
+	output, err = execute(source, "list")
+	require.NoError(t, err)
+	require.Contains(t, output, fmt.Sprintf("height: 3 format: %s chunks: 1", format))
+
+	_, err = execute(source, "dump", "3", format, "--output", archive)
+	require.NoError(t, err)
+	_, err = execute(source, "dump", "2", format, "--output", archive)
+	require.Error(t, err)
+
+	// the archive is loaded into the empty target and restored
+	output, err = execute(target, "load", archive)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("loaded snapshot at height 3 format %s\n", format), output)
+	_, err = execute(target, "load", archive)
+	require.Error(t, err)
+
+	// the Tendermint state cannot be bootstrapped without RPC servers
+	_, err = execute(target, "restore", "3", format)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no RPC servers")
+
+	output, err = execute(target, "restore", "3", format, "--app-only")
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("restored snapshot at height 3 format %s, without the Tendermint state\n", format), output)
+	_, err = execute(target, "restore", "3", format, "--app-only")
+	require.EqualError(t, err, "cannot restore snapshot into an application state at height 3")
+
+	db, err = sdk.NewLevelDB("application", filepath.Join(target, "data"))
+	require.NoError(t, err)
+	app = simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, target, 0, encCfg, simapp.EmptyAppOptions{})
+	require.Equal(t, commitID, app.LastCommitID())
+	require.NoError(t, db.Close())
+
+	_, err = execute(target, "delete", "3", format)
+	require.NoError(t, err)
+	output, err = execute(target, "list")
+	require.NoError(t, err)
+	require.Empty(t, output)
+	_, err = execute(target, "delete", "3", format)
+	require.Error(t, err)
+
+	// an archive whose chunks do not match the metadata is rejected
+	tampered := filepath.Join(t.TempDir(), "tampered.tar.gz")
+	tamperSnapshotArchive(t, archive, tampered)
+	_, err = execute(target, "load", tampered)
+	require.Error(t, err)
+	output, err = execute(target, "list")
+	require.NoError(t, err)
+	require.Empty(t, output)
+}
+
+// tamperSnapshotArchive copies the snapshot archive, replacing the body of its
+// first chunk.
+func tamperSnapshotArchive(t *testing.T, src, dst string) {
+	srcFile, err := os.Open(src)
+	require.NoError(t, err)
+	defer srcFile.Close()
+	zReader, err := gzip.NewReader(srcFile)
+	require.NoError(t, err)
+	tarReader := tar.NewReader(zReader)
+
+	dstFile, err := os.Create(dst)
+	require.NoError(t, err)
+	defer dstFile.Close()
+	zWriter := gzip.NewWriter(dstFile)
+	tarWriter := tar.NewWriter(zWriter)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(tarReader)
+		require.NoError(t, err)
+		if header.Name == "0" {
+			body = []byte("foo")
+			header.Size = int64(len(body))
+		}
+		require.NoError(t, tarWriter.WriteHeader(header))
+		_, err = tarWriter.Write(body)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, zWriter.Close())
+}
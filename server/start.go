@@ -4,7 +4,6 @@ package server
 
 import (
 	"fmt"
-	"net/http"
 	"os"
 	"runtime/pprof"
 	"time"
@@ -12,7 +11,6 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc"
 
 	"github.com/tendermint/tendermint/abci/server"
 	tcmd "github.com/tendermint/tendermint/cmd/tendermint/commands"
@@ -30,7 +28,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/server/api"
 	"github.com/cosmos/cosmos-sdk/server/config"
-	servergrpc "github.com/cosmos/cosmos-sdk/server/grpc"
+	"github.com/cosmos/cosmos-sdk/server/ratelimit"
 	"github.com/cosmos/cosmos-sdk/server/types"
 	storetypes "github.com/cosmos/cosmos-sdk/store/types"
 )
@@ -41,6 +39,9 @@ const (
 	flagAddress            = "address"
 	flagTransport          = "transport"
 	flagTraceStore         = "trace-store"
+	flagTraceStoreFormat   = "trace-store-format"
+	flagTraceStoreInclude  = "trace-store-include"
+	flagTraceStoreExclude  = "trace-store-exclude"
 	flagCPUProfile         = "cpu-profile"
 	FlagMinGasPrices       = "minimum-gas-prices"
 	FlagHaltHeight         = "halt-height"
@@ -56,6 +57,12 @@ const (
 	FlagPruningInterval   = "pruning-interval"
 	FlagIndexEvents       = "index-events"
 	FlagMinRetainBlocks   = "min-retain-blocks"
+	FlagQueryGasLimit     = "query-gas-limit"
+	FlagParallelTxWorkers = "parallel-tx-workers"
+
+	FlagPruningAsync           = "pruning-async"
+	FlagPruningAsyncMaxPending = "pruning-async-max-pending"
+	FlagPruningAsyncDelay      = "pruning-async-delay"
 )
 
 // GRPC-related flags.
@@ -142,6 +149,9 @@ which accepts a path for the resulting pprof file.
 	cmd.Flags().String(flagAddress, "tcp://0.0.0.0:26658", "Listen address")
 	cmd.Flags().String(flagTransport, "socket", "Transport protocol: socket, grpc")
 	cmd.Flags().String(flagTraceStore, "", "Enable KVStore tracing to an output file")
+	cmd.Flags().String(flagTraceStoreFormat, "", "Write structured KVStore trace records in the given format (json|binary)")
+	cmd.Flags().StringSlice(flagTraceStoreInclude, nil, "Only trace the operations on the given stores, as <store>[:<hex key prefix>] (requires a trace format)")
+	cmd.Flags().StringSlice(flagTraceStoreExclude, nil, "Do not trace the operations on the given stores, as <store>[:<hex key prefix>] (requires a trace format)")
 	cmd.Flags().String(FlagMinGasPrices, "", "Minimum gas prices to accept for transactions; Any fee in a tx must meet this minimum (e.g. 0.01photino;0.0001stake)")
 	cmd.Flags().IntSlice(FlagUnsafeSkipUpgrades, []int{}, "Skip a set of upgrade heights to continue the old binary")
 	cmd.Flags().Uint64(FlagHaltHeight, 0, "Block height at which to gracefully halt the chain and shutdown the node")
@@ -153,8 +163,13 @@ which accepts a path for the resulting pprof file.
 	cmd.Flags().Uint64(FlagPruningKeepRecent, 0, "Number of recent heights to keep on disk (ignored if pruning is not 'custom')")
 	cmd.Flags().Uint64(FlagPruningKeepEvery, 0, "Offset heights to keep on disk after 'keep-every' (ignored if pruning is not 'custom')")
 	cmd.Flags().Uint64(FlagPruningInterval, 0, "Height interval at which pruned heights are removed from disk (ignored if pruning is not 'custom')")
+	cmd.Flags().Bool(FlagPruningAsync, false, "Prune heights in the background instead of during commit")
+	cmd.Flags().Uint64(FlagPruningAsyncMaxPending, 1000, "Maximum number of heights waiting to be pruned in the background, commits block beyond it")
+	cmd.Flags().Duration(FlagPruningAsyncDelay, 0, "Pause between the pruning of two heights in the background")
 	cmd.Flags().Uint(FlagInvCheckPeriod, 0, "Assert registered invariants every N blocks")
 	cmd.Flags().Uint64(FlagMinRetainBlocks, 0, "Minimum block height offset during ABCI commit to prune Tendermint blocks")
+	cmd.Flags().Uint64(FlagQueryGasLimit, 0, "Maximum gas a single query may consume (0 means unlimited)")
+	cmd.Flags().Uint(FlagParallelTxWorkers, 0, "Number of workers executing the txs of a block in parallel (below 2 disables parallel execution)")
 
 	cmd.Flags().Bool(flagGRPCEnable, true, "Define if the gRPC server should be enabled")
 	cmd.Flags().String(flagGRPCAddress, config.DefaultGRPCAddress, "the gRPC server address to listen on")
@@ -180,13 +195,13 @@ func startStandAlone(ctx *Context, appCreator types.AppCreator) error {
 		return err
 	}
 
-	traceWriterFile := ctx.Viper.GetString(flagTraceStore)
-	traceWriter, err := openTraceWriter(traceWriterFile)
+	traceWriter, err := openStoreTracer(ctx)
 	if err != nil {
 		return err
 	}
 
 	app := appCreator(ctx.Logger, db, traceWriter, ctx.Viper)
+	stopReloader := startAppConfigReloader(ctx, app, nil)
 
 	svr, err := server.NewServer(addr, transport, app)
 	if err != nil {
@@ -201,9 +216,13 @@ func startStandAlone(ctx *Context, appCreator types.AppCreator) error {
 	}
 
 	defer func() {
+		stopReloader()
 		if err = svr.Stop(); err != nil {
 			tmos.Exit(err.Error())
 		}
+		if err = app.Close(); err != nil {
+			ctx.Logger.Error("failed to close application", "err", err)
+		}
 	}()
 
 	// Wait for SIGINT or SIGTERM signal
@@ -234,13 +253,12 @@ func startInProcess(ctx *Context, clientCtx client.Context, appCreator types.App
 		}
 	}
 
-	traceWriterFile := ctx.Viper.GetString(flagTraceStore)
 	db, err := openDB(home)
 	if err != nil {
 		return err
 	}
 
-	traceWriter, err := openTraceWriter(traceWriterFile)
+	traceWriter, err := openStoreTracer(ctx)
 	if err != nil {
 		return err
 	}
@@ -280,61 +298,32 @@ func startInProcess(ctx *Context, clientCtx client.Context, appCreator types.App
 	}
 	ctx.Logger.Debug("initialization: tmNode started")
 
-	// Add the tx service to the gRPC router. We only need to register this
-	// service if API or gRPC is enabled, and avoid doing so in the general
-	// case, because it spawns a new local tendermint RPC client.
-	if config.API.Enable || config.GRPC.Enable {
-		clientCtx = clientCtx.WithClient(local.New(tmNode))
-
-		app.RegisterTxService(clientCtx)
-		app.RegisterTendermintService(clientCtx)
-	}
-
-	var apiSrv *api.Server
-	if config.API.Enable {
-		genDoc, err := genDocProvider()
-		if err != nil {
-			return err
-		}
+	// Add the tx service to the gRPC router. It is registered even if neither
+	// the API nor gRPC is enabled, as they can be enabled by reloading
+	// app.toml, while the router cannot be modified once the node runs.
+	clientCtx = clientCtx.WithClient(local.New(tmNode))
 
-		clientCtx := clientCtx.
-			WithHomeDir(home).
-			WithChainID(genDoc.ChainID)
-
-		apiSrv = api.New(clientCtx, ctx.Logger.With("module", "api-server"))
-		app.RegisterAPIRoutes(apiSrv, config.API)
-		errCh := make(chan error)
+	app.RegisterTxService(clientCtx)
+	app.RegisterTendermintService(clientCtx)
 
-		go func() {
-			if err := apiSrv.Start(config); err != nil {
-				errCh <- err
-			}
-		}()
+	limiter, err := ratelimit.NewLimiter(config.RateLimit)
+	if err != nil {
+		return err
+	}
 
-		select {
-		case err := <-errCh:
-			return err
-		case <-time.After(types.ServerStartTime): // assume server started successfully
-		}
+	// The health checks read the latest block of the apps embedding BaseApp.
+	var healthChecker *api.HealthChecker
+	if blocks, ok := app.(api.LatestBlockProvider); ok {
+		healthChecker = api.NewHealthChecker(config, blocks, clientCtx.Client)
 	}
 
-	var (
-		grpcSrv    *grpc.Server
-		grpcWebSrv *http.Server
+	servers, err := startInProcessServers(
+		ctx.Logger, clientCtx, app, config, home, genDocProvider, limiter, healthChecker,
 	)
-	if config.GRPC.Enable {
-		grpcSrv, err = servergrpc.StartGRPCServer(clientCtx, app, config.GRPC.Address)
-		if err != nil {
-			return err
-		}
-		if config.GRPCWeb.Enable {
-			grpcWebSrv, err = servergrpc.StartGRPCWeb(grpcSrv, config)
-			if err != nil {
-				ctx.Logger.Error("failed to start grpc-web http server: ", err)
-				return err
-			}
-		}
+	if err != nil {
+		return err
 	}
+	stopReloader := startAppConfigReloader(ctx, app, servers)
 
 	var rosettaSrv crgserver.Server
 	if config.Rosetta.Enable {
@@ -373,24 +362,21 @@ func startInProcess(ctx *Context, clientCtx client.Context, appCreator types.App
 	}
 
 	defer func() {
+		stopReloader()
+
 		if tmNode.IsRunning() {
 			_ = tmNode.Stop()
 		}
 
-		if cpuProfileCleanup != nil {
-			cpuProfileCleanup()
+		if err := app.Close(); err != nil {
+			ctx.Logger.Error("failed to close application", "err", err)
 		}
 
-		if apiSrv != nil {
-			_ = apiSrv.Close()
+		if cpuProfileCleanup != nil {
+			cpuProfileCleanup()
 		}
 
-		if grpcSrv != nil {
-			grpcSrv.Stop()
-			if grpcWebSrv != nil {
-				grpcWebSrv.Close()
-			}
-		}
+		servers.Close()
 
 		ctx.Logger.Info("exiting...")
 	}()
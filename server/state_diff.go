@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/kv"
+	"github.com/cosmos/cosmos-sdk/types/module"
+)
+
+const flagStores = "stores"
+
+// StateDiffCmd prints the keys of the application state which were added,
+// changed or deleted between two heights.
+func StateDiffCmd(appCreator types.AppCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state-diff <height1> <height2>",
+		Short: "Print the application state changes between two heights",
+		Long: `Print the keys of every persisted store of the application which were added (+),
+changed (~) or deleted (-) between two heights. The values of the stores whose module
+registers a store decoder in the simulation manager of the application are decoded,
+the other values are printed in hex.
+
+Both heights must not have been pruned. The node must be stopped.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %q: %w", args[0], err)
+			}
+			to, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %q: %w", args[1], err)
+			}
+
+			stores, err := cmd.Flags().GetStringSlice(flagStores)
+			if err != nil {
+				return err
+			}
+
+			serverCtx := GetServerContextFromCmd(cmd)
+
+			db, err := openDB(serverCtx.Config.RootDir)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			app := appCreator(serverCtx.Logger, db, nil, serverCtx.Viper)
+			ms, ok := app.CommitMultiStore().(*rootmulti.Store)
+			if !ok {
+				return fmt.Errorf("state diffs are not supported by %T", app.CommitMultiStore())
+			}
+
+			var decoders sdk.StoreDecoderRegistry
+			if simApp, ok := app.(interface {
+				SimulationManager() *module.SimulationManager
+			}); ok && simApp.SimulationManager() != nil {
+				decoders = simApp.SimulationManager().StoreDecoders
+			}
+
+			return writeStateDiff(cmd.OutOrStdout(), ms, from, to, stores, decoders)
+		},
+	}
+
+	cmd.Flags().StringSlice(flagStores, nil, "Only print the changes of the given stores")
+
+	return cmd
+}
+
+// writeStateDiff writes the changes of the stores between two versions, or of
+// the given stores only if any.
+func writeStateDiff(w io.Writer, ms *rootmulti.Store, from, to int64, stores []string, decoders sdk.StoreDecoderRegistry) error {
+	return ms.DiffVersions(from, to, stores, func(storeName string, diff rootmulti.KVDiff) error {
+		var op string
+		switch {
+		case diff.Before == nil:
+			op = "+"
+		case diff.After == nil:
+			op = "-"
+		default:
+			op = "~"
+		}
+
+		if decoded, ok := decodeDiff(decoders[storeName], diff); ok {
+			_, err := fmt.Fprintf(w, "%s %s %X\n  %s\n", op, storeName, diff.Key, strings.ReplaceAll(decoded, "\n", "\n  "))
+			return err
+		}
+
+		var err error
+		switch op {
+		case "+":
+			_, err = fmt.Fprintf(w, "%s %s %X: %X\n", op, storeName, diff.Key, diff.After)
+		case "-":
+			_, err = fmt.Fprintf(w, "%s %s %X: %X\n", op, storeName, diff.Key, diff.Before)
+		default:
+			_, err = fmt.Fprintf(w, "%s %s %X: %X -> %X\n", op, storeName, diff.Key, diff.Before, diff.After)
+		}
+		return err
+	})
+}
+
+// decodeDiff decodes the values of the diff with the store decoder, if any.
+// Store decoders panic on the keys they do not know, in which case the values
+// are not decoded.
+func decodeDiff(decoder func(kvA, kvB kv.Pair) string, diff rootmulti.KVDiff) (decoded string, ok bool) {
+	if decoder == nil {
+		return "", false
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			decoded, ok = "", false
+		}
+	}()
+
+	return decoder(kv.Pair{Key: diff.Key, Value: diff.Before}, kv.Pair{Key: diff.Key, Value: diff.After}), true
+}
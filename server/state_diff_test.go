@@ -0,0 +1,77 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestStateDiffCmd(t *testing.T) {
+	home := t.TempDir()
+	encCfg := simapp.MakeTestEncodingConfig()
+	logger := log.NewNopLogger()
+	appCreator := func(logger log.Logger, db dbm.DB, _ io.Writer, appOpts types.AppOptions) types.Application {
+		return simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, home, 0, encCfg, appOpts)
+	}
+
+	db, err := sdk.NewLevelDB("application", filepath.Join(home, "data"))
+	require.NoError(t, err)
+	app := simapp.NewSimApp(logger, db, nil, true, map[int64]bool{}, home, 0, encCfg, simapp.EmptyAppOptions{})
+	app.InitChain(abci.RequestInitChain{
+		Validators:      []abci.ValidatorUpdate{},
+		ConsensusParams: simapp.DefaultConsensusParams,
+		AppStateBytes:   newDefaultGenesisDoc(encCfg.Marshaler).AppState,
+	})
+	app.Commit()
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: 2}})
+	app.Commit()
+	require.NoError(t, db.Close())
+
+	serverCtx := server.NewDefaultContext()
+	serverCtx.Config.RootDir = home
+	serverCtx.Viper.Set(flags.FlagHome, home)
+	serverCtx.Logger = logger
+	ctx := context.WithValue(context.Background(), server.ServerContextKey, serverCtx)
+
+	stateDiff := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := server.StateDiffCmd(appCreator)
+		cmd.SetOut(&out)
+		cmd.SetErr(ioutil.Discard)
+		cmd.SetArgs(args)
+		err := cmd.ExecuteContext(ctx)
+		return out.String(), err
+	}
+
+	out, err := stateDiff("1", "2")
+	require.NoError(t, err)
+	// the minter is decoded by the mint store decoder
+	require.Contains(t, out, "~ mint 00\n  {0.130000000000000000 0.000000000000000000}\n  {0.13")
+	// the staking store decoder does not know historical infos
+	require.Contains(t, out, "+ staking 5032: ")
+
+	out, err = stateDiff("1", "2", "--stores", "staking")
+	require.NoError(t, err)
+	require.NotContains(t, out, "mint")
+	require.Contains(t, out, "+ staking 5032: ")
+
+	_, err = stateDiff("1", "3")
+	require.Error(t, err)
+	_, err = stateDiff("1", "latest")
+	require.Error(t, err)
+}
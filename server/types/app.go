@@ -15,6 +15,8 @@ import (
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/server/api"
 	"github.com/cosmos/cosmos-sdk/server/config"
+	"github.com/cosmos/cosmos-sdk/snapshots"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 // ServerStartTime defines the time duration that the server need to stay running after startup
@@ -51,6 +53,17 @@ type (
 
 		// RegisterTendermintService registers the gRPC Query service for tendermint queries.
 		RegisterTendermintService(clientCtx client.Context)
+
+		// SnapshotManager returns the snapshot manager of the application, or
+		// nil if it has no snapshot store.
+		SnapshotManager() *snapshots.Manager
+
+		// CommitMultiStore returns the root multi-store of the application.
+		CommitMultiStore() sdk.CommitMultiStore
+
+		// Close is called once the application is no longer used, to release
+		// its resources.
+		Close() error
 	}
 
 	// AppCreator is a function that allows us to lazily initialize an
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -26,6 +27,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/server/config"
 	"github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/store/tracekv"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/version"
 )
@@ -279,6 +281,9 @@ func AddCommands(rootCmd *cobra.Command, defaultNodeHome string, appCreator type
 		UnsafeResetAllCmd(),
 		tendermintCmd,
 		ExportCmd(appExport, defaultNodeHome),
+		SnapshotCmd(appCreator),
+		RollbackCmd(appCreator),
+		QueryServerCmd(appCreator, defaultNodeHome),
 		version.NewVersionCommand(),
 	)
 }
@@ -341,10 +346,22 @@ func TrapSignal(cleanupFunc func()) {
 
 // WaitForQuitSignals waits for SIGINT and SIGTERM and returns.
 func WaitForQuitSignals() ErrorCode {
+	return waitForQuitSignals(context.Background())
+}
+
+// waitForQuitSignals waits for SIGINT or SIGTERM like WaitForQuitSignals, or
+// for the context to be done, in which case the error code is zero.
+func waitForQuitSignals(ctx context.Context) ErrorCode {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-	sig := <-sigs
-	return ErrorCode{Code: int(sig.(syscall.Signal)) + 128}
+	defer signal.Stop(sigs)
+
+	select {
+	case sig := <-sigs:
+		return ErrorCode{Code: int(sig.(syscall.Signal)) + 128}
+	case <-ctx.Done():
+		return ErrorCode{}
+	}
 }
 
 func skipInterface(iface net.Interface) bool {
@@ -386,3 +403,51 @@ func openTraceWriter(traceWriterFile string) (w io.Writer, err error) {
 		0666,
 	)
 }
+
+// openStoreTracer opens the trace writer of the --trace-store flag. The writer
+// is wrapped in a tracekv.Tracer if a structured trace format is set, which
+// the trace filters require.
+func openStoreTracer(ctx *Context) (io.Writer, error) {
+	format := ctx.Viper.GetString(flagTraceStoreFormat)
+	include := ctx.Viper.GetStringSlice(flagTraceStoreInclude)
+	exclude := ctx.Viper.GetStringSlice(flagTraceStoreExclude)
+	if format == "" && (len(include) > 0 || len(exclude) > 0) {
+		return nil, fmt.Errorf("--%s and --%s require --%s", flagTraceStoreInclude, flagTraceStoreExclude, flagTraceStoreFormat)
+	}
+
+	w, err := openTraceWriter(ctx.Viper.GetString(flagTraceStore))
+	if err != nil || w == nil || format == "" {
+		return w, err
+	}
+
+	opts := tracekv.TracerOptions{Format: tracekv.TraceFormat(format)}
+	if opts.Include, err = parseTraceFilters(include); err == nil {
+		opts.Exclude, err = parseTraceFilters(exclude)
+	}
+
+	var tracer *tracekv.Tracer
+	if err == nil {
+		tracer, err = tracekv.NewTracer(w, opts)
+	}
+	if err != nil {
+		if c, ok := w.(io.Closer); ok {
+			c.Close()
+		}
+		return nil, err
+	}
+
+	return tracer, nil
+}
+
+func parseTraceFilters(specs []string) ([]tracekv.Filter, error) {
+	filters := make([]tracekv.Filter, 0, len(specs))
+	for _, spec := range specs {
+		filter, err := tracekv.ParseFilter(spec)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
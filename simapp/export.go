@@ -8,6 +8,7 @@ import (
 
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
@@ -17,6 +18,26 @@ import (
 // file.
 func (app *SimApp) ExportAppStateAndValidators(
 	forZeroHeight bool, jailAllowedAddrs []string,
+) (servertypes.ExportedApp, error) {
+	return app.exportAppStateAndValidators(forZeroHeight, jailAllowedAddrs, func(ctx sdk.Context) (map[string]json.RawMessage, error) {
+		return app.mm.ExportGenesis(ctx, app.appCodec), nil
+	})
+}
+
+// ExportAppStateAndValidatorsToDir exports the state of the application for a
+// genesis file like ExportAppStateAndValidators, except that the modules which
+// support it stream their genesis state to files of the given directory, in the
+// given format.
+func (app *SimApp) ExportAppStateAndValidatorsToDir(
+	forZeroHeight bool, jailAllowedAddrs []string, dir string, format module.GenesisStreamFormat,
+) (servertypes.ExportedApp, error) {
+	return app.exportAppStateAndValidators(forZeroHeight, jailAllowedAddrs, func(ctx sdk.Context) (map[string]json.RawMessage, error) {
+		return app.mm.ExportGenesisToDir(ctx, app.appCodec, dir, format)
+	})
+}
+
+func (app *SimApp) exportAppStateAndValidators(
+	forZeroHeight bool, jailAllowedAddrs []string, exportGenesis func(sdk.Context) (map[string]json.RawMessage, error),
 ) (servertypes.ExportedApp, error) {
 	// as if they could withdraw from the start of the next block
 	ctx := app.NewContext(true, tmproto.Header{Height: app.LastBlockHeight()})
@@ -29,7 +50,10 @@ func (app *SimApp) ExportAppStateAndValidators(
 		app.prepForZeroHeightGenesis(ctx, jailAllowedAddrs)
 	}
 
-	genState := app.mm.ExportGenesis(ctx, app.appCodec)
+	genState, err := exportGenesis(ctx)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
 	appState, err := json.MarshalIndent(genState, "", "  ")
 	if err != nil {
 		return servertypes.ExportedApp{}, err
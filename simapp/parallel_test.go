@@ -0,0 +1,187 @@
+package simapp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/simapp/helpers"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+const parallelTestAccounts = 16
+
+type parallelTestAccount struct {
+	priv   *secp256k1.PrivKey
+	addr   sdk.AccAddress
+	accNum uint64
+	seq    uint64
+}
+
+// setupParallelTestApp returns a SimApp with the given genesis accounts and
+// consensus params after committing the genesis block.
+func setupParallelTestApp(t *testing.T, accounts []*parallelTestAccount, cp *abci.ConsensusParams, opts ...func(*baseapp.BaseApp)) *SimApp {
+	encCfg := MakeTestEncodingConfig()
+	app := NewSimApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, map[int64]bool{}, DefaultNodeHome, 0, encCfg, EmptyAppOptions{}, opts...)
+
+	genAccs := make([]authtypes.GenesisAccount, len(accounts))
+	balances := make([]banktypes.Balance, len(accounts))
+	totalSupply := sdk.NewCoins()
+	for i, acc := range accounts {
+		genAccs[i] = authtypes.NewBaseAccount(acc.addr, nil, acc.accNum, 0)
+		balances[i] = banktypes.Balance{
+			Address: acc.addr.String(),
+			Coins:   sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1000)),
+		}
+		totalSupply = totalSupply.Add(balances[i].Coins...)
+	}
+
+	genesisState := NewDefaultGenesisState(encCfg.Marshaler)
+	genesisState[authtypes.ModuleName] = app.AppCodec().MustMarshalJSON(authtypes.NewGenesisState(authtypes.DefaultParams(), genAccs))
+	genesisState[banktypes.ModuleName] = app.AppCodec().MustMarshalJSON(
+		banktypes.NewGenesisState(banktypes.DefaultGenesisState().Params, balances, totalSupply, []banktypes.Metadata{}),
+	)
+
+	stateBytes, err := json.Marshal(genesisState)
+	require.NoError(t, err)
+
+	app.InitChain(abci.RequestInitChain{
+		Validators:      []abci.ValidatorUpdate{},
+		ConsensusParams: cp,
+		AppStateBytes:   stateBytes,
+	})
+	app.Commit()
+
+	return app
+}
+
+// genParallelTestBlock generates a block of bank sends which mixes independent
+// txs with txs that conflict on balances, sequences and account numbers, and
+// txs that fail.
+func genParallelTestBlock(t *testing.T, txCfg client.TxConfig, accounts []*parallelTestAccount) [][]byte {
+	var txs [][]byte
+
+	send := func(from *parallelTestAccount, to sdk.AccAddress, amount int64, fee sdk.Coins) {
+		msg := banktypes.NewMsgSend(from.addr, to, sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, amount)))
+		tx, err := helpers.GenTx(txCfg, []sdk.Msg{msg}, fee, 200000, "", []uint64{from.accNum}, []uint64{from.seq}, from.priv)
+		require.NoError(t, err)
+
+		bz, err := txCfg.TxEncoder()(tx)
+		require.NoError(t, err)
+
+		txs = append(txs, bz)
+		from.seq++
+	}
+
+	half := len(accounts) / 2
+
+	// independent sends between disjoint pairs of accounts
+	for i := 0; i < half; i++ {
+		send(accounts[i], accounts[i+half].addr, 1, nil)
+	}
+
+	// a chain of sends where every tx depends on the previous one
+	for i := 0; i < half; i++ {
+		send(accounts[i], accounts[i+1].addr, 2, nil)
+	}
+
+	// many sends from a single account, the last ones run out of funds
+	for i := 0; i < 4; i++ {
+		send(accounts[half], accounts[0].addr, 400, nil)
+	}
+
+	// sends with fees, which all write the balance of the fee collector
+	for i := half + 1; i < len(accounts); i++ {
+		send(accounts[i], accounts[half-1].addr, 3, sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1)))
+	}
+
+	// sends to new accounts, which all increment the global account number
+	for i := 0; i < 3; i++ {
+		send(accounts[i], secp256k1.GenPrivKey().PubKey().Address().Bytes(), 1, nil)
+	}
+
+	// a tx with an invalid sequence
+	accounts[1].seq += 5
+	send(accounts[1], accounts[2].addr, 1, nil)
+	accounts[1].seq -= 6
+
+	return txs
+}
+
+// testParallelDeliverTxDeterminism executes the same blocks sequentially and
+// in parallel and returns the fraction of successful txs.
+func testParallelDeliverTxDeterminism(t *testing.T, cp *abci.ConsensusParams) float64 {
+	accounts := make([]*parallelTestAccount, parallelTestAccounts)
+	for i := range accounts {
+		priv := secp256k1.GenPrivKey()
+		accounts[i] = &parallelTestAccount{priv: priv, addr: priv.PubKey().Address().Bytes(), accNum: uint64(i)}
+	}
+
+	txCfg := MakeTestEncodingConfig().TxConfig
+	seqApp := setupParallelTestApp(t, accounts, cp)
+	parApp := setupParallelTestApp(t, accounts, cp, baseapp.SetParallelTxExecution(4))
+	require.Equal(t, seqApp.LastCommitID(), parApp.LastCommitID())
+
+	var total, successful int
+	for block := 0; block < 5; block++ {
+		txs := genParallelTestBlock(t, txCfg, accounts)
+		header := tmproto.Header{Height: seqApp.LastBlockHeight() + 1}
+
+		seqApp.BeginBlock(abci.RequestBeginBlock{Header: header})
+		parApp.BeginBlock(abci.RequestBeginBlock{Header: header})
+
+		reqs := make([]abci.RequestDeliverTx, len(txs))
+		seqRes := make([]abci.ResponseDeliverTx, len(txs))
+		for i, tx := range txs {
+			reqs[i] = abci.RequestDeliverTx{Tx: tx}
+			seqRes[i] = seqApp.DeliverTx(reqs[i])
+		}
+		parRes := parApp.DeliverTxBatch(reqs)
+
+		require.Equal(t, seqRes, parRes, "block %d", block)
+		require.False(t, seqRes[len(seqRes)-1].IsOK())
+
+		for _, res := range seqRes {
+			if res.IsOK() {
+				successful++
+			}
+		}
+		total += len(seqRes)
+		require.Equal(t,
+			seqApp.EndBlock(abci.RequestEndBlock{Height: header.Height}),
+			parApp.EndBlock(abci.RequestEndBlock{Height: header.Height}),
+		)
+		require.Equal(t, seqApp.Commit(), parApp.Commit(), "block %d", block)
+	}
+
+	return float64(successful) / float64(total)
+}
+
+func TestParallelDeliverTxDeterminism(t *testing.T) {
+	successful := testParallelDeliverTxDeterminism(t, &abci.ConsensusParams{
+		Block:     &abci.BlockParams{MaxBytes: 200000, MaxGas: -1},
+		Evidence:  DefaultConsensusParams.Evidence,
+		Validator: DefaultConsensusParams.Validator,
+	})
+	require.Greater(t, successful, 0.5)
+}
+
+func TestParallelDeliverTxDeterminismBlockGasLimit(t *testing.T) {
+	// only part of every block fits into the block gas limit, so most txs of
+	// the later blocks fail due to invalid sequences
+	testParallelDeliverTxDeterminism(t, &abci.ConsensusParams{
+		Block:     &abci.BlockParams{MaxBytes: 200000, MaxGas: 1000000},
+		Evidence:  DefaultConsensusParams.Evidence,
+		Validator: DefaultConsensusParams.Validator,
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	protoio "github.com/gogo/protobuf/io"
 	"github.com/stretchr/testify/require"
 	db "github.com/tendermint/tm-db"
 
@@ -57,45 +58,113 @@ func readChunks(chunks <-chan io.ReadCloser) [][]byte {
 	return bodies
 }
 
+// snapshotItems serializes the given payloads as snapshot chunks, in the same way as the
+// Manager serializes the items written by the Snapshotter.
+func snapshotItems(items [][]byte) [][]byte {
+	ch := make(chan io.ReadCloser)
+	go func() {
+		streamWriter := snapshots.NewStreamWriter(ch)
+		for _, item := range items {
+			err := streamWriter.WriteMsg(payloadItem(item))
+			if err != nil {
+				streamWriter.CloseWithError(err)
+				return
+			}
+		}
+		_ = streamWriter.Close()
+	}()
+	return readChunks(ch)
+}
+
+func payloadItem(payload []byte) *types.SnapshotItem {
+	return &types.SnapshotItem{
+		Item: &types.SnapshotItem_ExtensionPayload{
+			ExtensionPayload: &types.SnapshotExtensionPayload{
+				Payload: payload,
+			},
+		},
+	}
+}
+
 type mockSnapshotter struct {
-	chunks [][]byte
+	items [][]byte
 }
 
 func (m *mockSnapshotter) Restore(
-	height uint64, format uint32, chunks <-chan io.ReadCloser, ready chan<- struct{},
-) error {
+	height uint64, format uint32, protoReader protoio.Reader,
+) (types.SnapshotItem, error) {
 	if format == 0 {
-		return types.ErrUnknownFormat
+		return types.SnapshotItem{}, types.ErrUnknownFormat
 	}
-	if m.chunks != nil {
-		return errors.New("already has contents")
+	if m.items != nil {
+		return types.SnapshotItem{}, errors.New("already has contents")
 	}
-	if ready != nil {
-		close(ready)
+
+	m.items = [][]byte{}
+	for {
+		item := types.SnapshotItem{}
+		err := protoReader.ReadMsg(&item)
+		if err == io.EOF {
+			return types.SnapshotItem{}, nil
+		} else if err != nil {
+			return types.SnapshotItem{}, err
+		}
+		payload := item.GetExtensionPayload()
+		if payload == nil {
+			return item, nil
+		}
+		m.items = append(m.items, payload.Payload)
 	}
+}
 
-	m.chunks = [][]byte{}
-	for reader := range chunks {
-		chunk, err := ioutil.ReadAll(reader)
-		if err != nil {
+func (m *mockSnapshotter) Snapshot(height uint64, protoWriter protoio.Writer) error {
+	for _, item := range m.items {
+		if err := protoWriter.WriteMsg(payloadItem(item)); err != nil {
 			return err
 		}
-		m.chunks = append(m.chunks, chunk)
 	}
-
 	return nil
 }
 
-func (m *mockSnapshotter) Snapshot(height uint64, format uint32) (<-chan io.ReadCloser, error) {
-	if format == 0 {
-		return nil, types.ErrUnknownFormat
+// mockExtension is an ExtensionSnapshotter which snapshots a list of payloads.
+type mockExtension struct {
+	name     string
+	payloads [][]byte
+	restored [][]byte
+}
+
+func (m *mockExtension) SnapshotName() string {
+	return m.name
+}
+
+func (m *mockExtension) SnapshotFormat() uint32 {
+	return 1
+}
+
+func (m *mockExtension) SupportedFormats() []uint32 {
+	return []uint32{1}
+}
+
+func (m *mockExtension) SnapshotExtension(height uint64, payloadWriter types.ExtensionPayloadWriter) error {
+	for _, payload := range m.payloads {
+		if err := payloadWriter(payload); err != nil {
+			return err
+		}
 	}
-	ch := make(chan io.ReadCloser, len(m.chunks))
-	for _, chunk := range m.chunks {
-		ch <- ioutil.NopCloser(bytes.NewReader(chunk))
+	return nil
+}
+
+func (m *mockExtension) RestoreExtension(height uint64, format uint32, payloadReader types.ExtensionPayloadReader) error {
+	m.restored = [][]byte{}
+	for {
+		payload, err := payloadReader()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		m.restored = append(m.restored, payload)
 	}
-	close(ch)
-	return ch, nil
 }
 
 // setupBusyManager creates a manager with an empty store that is busy creating a snapshot at height 1.
@@ -138,15 +207,13 @@ func (m *hungSnapshotter) Close() {
 	close(m.ch)
 }
 
-func (m *hungSnapshotter) Snapshot(height uint64, format uint32) (<-chan io.ReadCloser, error) {
+func (m *hungSnapshotter) Snapshot(height uint64, protoWriter protoio.Writer) error {
 	<-m.ch
-	ch := make(chan io.ReadCloser, 1)
-	ch <- ioutil.NopCloser(bytes.NewReader([]byte{}))
-	return ch, nil
+	return nil
 }
 
 func (m *hungSnapshotter) Restore(
-	height uint64, format uint32, chunks <-chan io.ReadCloser, ready chan<- struct{},
-) error {
+	height uint64, format uint32, protoReader protoio.Reader,
+) (types.SnapshotItem, error) {
 	panic("not implemented")
 }
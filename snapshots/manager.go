@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"io"
 	"io/ioutil"
+	"sort"
 	"sync"
 
 	"github.com/cosmos/cosmos-sdk/snapshots/types"
@@ -42,9 +43,14 @@ type restoreDone struct {
 //
 // 2) io.ReadCloser streams automatically propagate IO errors, and can pass arbitrary
 //    errors via io.Pipe.CloseWithError().
+//
+// A snapshot consists of the items of the multistore, followed by the items of every
+// registered ExtensionSnapshotter in the order of their names: the SnapshotExtensionMeta of
+// the extension and its payloads. The items are serialized into a single chunk stream.
 type Manager struct {
-	store  *Store
-	target types.Snapshotter
+	store      *Store
+	multistore types.Snapshotter
+	extensions map[string]types.ExtensionSnapshotter
 
 	mtx                sync.Mutex
 	operation          operation
@@ -55,13 +61,27 @@ type Manager struct {
 }
 
 // NewManager creates a new manager.
-func NewManager(store *Store, target types.Snapshotter) *Manager {
+func NewManager(store *Store, multistore types.Snapshotter) *Manager {
 	return &Manager{
-		store:  store,
-		target: target,
+		store:      store,
+		multistore: multistore,
+		extensions: make(map[string]types.ExtensionSnapshotter),
 	}
 }
 
+// RegisterExtensions registers the given extension snapshotters. It errors if an extension
+// with the same name is already registered.
+func (m *Manager) RegisterExtensions(extensions ...types.ExtensionSnapshotter) error {
+	for _, extension := range extensions {
+		name := extension.SnapshotName()
+		if _, ok := m.extensions[name]; ok {
+			return sdkerrors.Wrapf(sdkerrors.ErrLogic, "duplicate snapshot extension %q", name)
+		}
+		m.extensions[name] = extension
+	}
+	return nil
+}
+
 // begin starts an operation, or errors if one is in progress. It manages the mutex itself.
 func (m *Manager) begin(op operation) error {
 	m.mtx.Lock()
@@ -120,11 +140,67 @@ func (m *Manager) Create(height uint64) (*types.Snapshot, error) {
 			"a more recent snapshot already exists at height %v", latest.Height)
 	}
 
-	chunks, err := m.target.Snapshot(height, types.CurrentFormat)
-	if err != nil {
-		return nil, err
+	ch := make(chan io.ReadCloser)
+	go m.createSnapshot(height, ch)
+
+	return m.store.Save(height, types.CurrentFormat, ch)
+}
+
+// createSnapshot writes the snapshot at the given height as chunks to the channel, and closes
+// the channel, with an error if the snapshot fails.
+func (m *Manager) createSnapshot(height uint64, ch chan<- io.ReadCloser) {
+	streamWriter := NewStreamWriter(ch)
+	if streamWriter == nil {
+		return
 	}
-	return m.store.Save(height, types.CurrentFormat, chunks)
+
+	if err := m.multistore.Snapshot(height, streamWriter); err != nil {
+		streamWriter.CloseWithError(err)
+		return
+	}
+
+	for _, name := range m.sortedExtensionNames() {
+		extension := m.extensions[name]
+		err := streamWriter.WriteMsg(&types.SnapshotItem{
+			Item: &types.SnapshotItem_Extension{
+				Extension: &types.SnapshotExtensionMeta{
+					Name:   name,
+					Format: extension.SnapshotFormat(),
+				},
+			},
+		})
+		if err != nil {
+			streamWriter.CloseWithError(err)
+			return
+		}
+
+		payloadWriter := func(payload []byte) error {
+			return streamWriter.WriteMsg(&types.SnapshotItem{
+				Item: &types.SnapshotItem_ExtensionPayload{
+					ExtensionPayload: &types.SnapshotExtensionPayload{
+						Payload: payload,
+					},
+				},
+			})
+		}
+		if err := extension.SnapshotExtension(height, payloadWriter); err != nil {
+			streamWriter.CloseWithError(sdkerrors.Wrapf(err, "snapshot extension %q", name))
+			return
+		}
+	}
+
+	// the error is passed to the chunk reader
+	_ = streamWriter.Close()
+}
+
+// sortedExtensionNames returns the names of the registered extensions in sorted order.
+func (m *Manager) sortedExtensionNames() []string {
+	names := make([]string, 0, len(m.extensions))
+	for name := range m.extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // List lists snapshots, mirroring ABCI ListSnapshots. It can be concurrent with other operations.
@@ -168,6 +244,9 @@ func (m *Manager) Restore(snapshot types.Snapshot) error {
 			uint32(len(snapshot.Metadata.ChunkHashes)),
 			snapshot.Chunks)
 	}
+	if snapshot.Format != types.CurrentFormat {
+		return sdkerrors.Wrapf(types.ErrUnknownFormat, "format %v", snapshot.Format)
+	}
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 	err := m.beginLocked(opRestore)
@@ -177,10 +256,9 @@ func (m *Manager) Restore(snapshot types.Snapshot) error {
 
 	// Start an asynchronous snapshot restoration, passing chunks and completion status via channels.
 	chChunks := make(chan io.ReadCloser, chunkBufferSize)
-	chReady := make(chan struct{}, 1)
 	chDone := make(chan restoreDone, 1)
 	go func() {
-		err := m.target.Restore(snapshot.Height, snapshot.Format, chChunks, chReady)
+		err := m.restoreSnapshot(snapshot, chChunks)
 		chDone <- restoreDone{
 			complete: err == nil,
 			err:      err,
@@ -188,17 +266,6 @@ func (m *Manager) Restore(snapshot types.Snapshot) error {
 		close(chDone)
 	}()
 
-	// Check for any initial errors from the restore, before any chunks are fed.
-	select {
-	case done := <-chDone:
-		m.endLocked()
-		if done.err != nil {
-			return done.err
-		}
-		return sdkerrors.Wrap(sdkerrors.ErrLogic, "restore ended unexpectedly")
-	case <-chReady:
-	}
-
 	m.chRestore = chChunks
 	m.chRestoreDone = chDone
 	m.restoreChunkHashes = snapshot.Metadata.ChunkHashes
@@ -206,6 +273,31 @@ func (m *Manager) Restore(snapshot types.Snapshot) error {
 	return nil
 }
 
+// RestoreLocalSnapshot restores the snapshot with the given height and format from the
+// snapshot store, e.g. to bootstrap a node from a snapshot loaded from an archive. Unlike
+// Restore, it restores the snapshot synchronously.
+func (m *Manager) RestoreLocalSnapshot(height uint64, format uint32) error {
+	snapshot, chunks, err := m.store.Load(height, format)
+	if err != nil {
+		return err
+	}
+	if snapshot == nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrNotFound, "snapshot for height %v format %v", height, format)
+	}
+	defer DrainChunks(chunks)
+
+	if snapshot.Format != types.CurrentFormat {
+		return sdkerrors.Wrapf(types.ErrUnknownFormat, "format %v", snapshot.Format)
+	}
+	err = m.begin(opRestore)
+	if err != nil {
+		return err
+	}
+	defer m.end()
+
+	return m.restoreSnapshot(*snapshot, chunks)
+}
+
 // RestoreChunk adds a chunk to an active snapshot restoration, mirroring ABCI ApplySnapshotChunk.
 // Chunks must be given until the restore is complete, returning true, or a chunk errors.
 func (m *Manager) RestoreChunk(chunk []byte) (bool, error) {
@@ -257,3 +349,81 @@ func (m *Manager) RestoreChunk(chunk []byte) (bool, error) {
 	}
 	return false, nil
 }
+
+// restoreSnapshot restores the multistore and the extensions from the snapshot chunks. The
+// items of every extension are routed to the registered extension of the same name.
+func (m *Manager) restoreSnapshot(snapshot types.Snapshot, chChunks <-chan io.ReadCloser) error {
+	streamReader, err := NewStreamReader(chChunks)
+	if err != nil {
+		return err
+	}
+	defer streamReader.Close()
+
+	next, err := m.multistore.Restore(snapshot.Height, snapshot.Format, streamReader)
+	if err != nil {
+		return sdkerrors.Wrap(err, "multistore restore")
+	}
+
+	for next.Item != nil {
+		meta := next.GetExtension()
+		if meta == nil {
+			return sdkerrors.Wrapf(sdkerrors.ErrLogic, "unknown snapshot item %T", next.Item)
+		}
+		extension, ok := m.extensions[meta.Name]
+		if !ok {
+			return sdkerrors.Wrapf(sdkerrors.ErrLogic, "snapshot extension %q is not registered", meta.Name)
+		}
+		if !isFormatSupported(extension, meta.Format) {
+			return sdkerrors.Wrapf(types.ErrUnknownFormat, "format %v for snapshot extension %q", meta.Format, meta.Name)
+		}
+
+		// The payload reader reads the payloads of the extension up to the next item which is
+		// not a payload, which is left in next.
+		done := false
+		payloadReader := func() ([]byte, error) {
+			if done {
+				return nil, io.EOF
+			}
+
+			next = types.SnapshotItem{}
+			err := streamReader.ReadMsg(&next)
+			if err == io.EOF {
+				done = true
+				return nil, io.EOF
+			} else if err != nil {
+				return nil, sdkerrors.Wrap(err, "invalid protobuf message")
+			}
+
+			payload := next.GetExtensionPayload()
+			if payload == nil {
+				done = true
+				return nil, io.EOF
+			}
+			return payload.Payload, nil
+		}
+
+		if err := extension.RestoreExtension(snapshot.Height, meta.Format, payloadReader); err != nil {
+			return sdkerrors.Wrapf(err, "snapshot extension %q", meta.Name)
+		}
+		if !done {
+			_, err := payloadReader()
+			if err == nil {
+				return sdkerrors.Wrapf(sdkerrors.ErrLogic, "snapshot extension %q did not read all of its payloads", meta.Name)
+			} else if err != io.EOF {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isFormatSupported returns true if the extension can restore payloads of the given format.
+func isFormatSupported(extension types.ExtensionSnapshotter, format uint32) bool {
+	for _, supported := range extension.SupportedFormats() {
+		if supported == format {
+			return true
+		}
+	}
+	return false
+}
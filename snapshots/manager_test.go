@@ -53,12 +53,13 @@ func TestManager_LoadChunk(t *testing.T) {
 
 func TestManager_Take(t *testing.T) {
 	store := setupStore(t)
+	items := [][]byte{
+		{1, 2, 3},
+		{4, 5, 6},
+		{7, 8, 9},
+	}
 	snapshotter := &mockSnapshotter{
-		chunks: [][]byte{
-			{1, 2, 3},
-			{4, 5, 6},
-			{7, 8, 9},
-		},
+		items: items,
 	}
 	manager := snapshots.NewManager(store, snapshotter)
 
@@ -73,21 +74,21 @@ func TestManager_Take(t *testing.T) {
 	// creating a snapshot at a higher height should be fine, and should return it
 	snapshot, err := manager.Create(5)
 	require.NoError(t, err)
+	expectChunks := snapshotItems(items)
 	assert.Equal(t, &types.Snapshot{
 		Height: 5,
 		Format: types.CurrentFormat,
-		Chunks: 3,
-		Hash:   []uint8{0x47, 0xe4, 0xee, 0x7f, 0x21, 0x1f, 0x73, 0x26, 0x5d, 0xd1, 0x76, 0x58, 0xf6, 0xe2, 0x1c, 0x13, 0x18, 0xbd, 0x6c, 0x81, 0xf3, 0x75, 0x98, 0xe2, 0xa, 0x27, 0x56, 0x29, 0x95, 0x42, 0xef, 0xcf},
+		Chunks: 1,
+		Hash:   hash(expectChunks),
 		Metadata: types.Metadata{
-			ChunkHashes: checksums([][]byte{
-				{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}),
+			ChunkHashes: checksums(expectChunks),
 		},
 	}, snapshot)
 
 	storeSnapshot, chunks, err := store.Load(snapshot.Height, snapshot.Format)
 	require.NoError(t, err)
 	assert.Equal(t, snapshot, storeSnapshot)
-	assert.Equal(t, [][]byte{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, readChunks(chunks))
+	assert.Equal(t, expectChunks, readChunks(chunks))
 
 	// creating a snapshot while a different snapshot is being created should error
 	manager = setupBusyManager(t)
@@ -118,11 +119,12 @@ func TestManager_Restore(t *testing.T) {
 	target := &mockSnapshotter{}
 	manager := snapshots.NewManager(store, target)
 
-	chunks := [][]byte{
+	expectItems := [][]byte{
 		{1, 2, 3},
 		{4, 5, 6},
 		{7, 8, 9},
 	}
+	chunks := snapshotItems(expectItems)
 
 	// Restore errors on invalid format
 	err := manager.Restore(types.Snapshot{
@@ -133,16 +135,16 @@ func TestManager_Restore(t *testing.T) {
 		Metadata: types.Metadata{ChunkHashes: checksums(chunks)},
 	})
 	require.Error(t, err)
-	require.Equal(t, types.ErrUnknownFormat, err)
+	require.True(t, errors.Is(err, types.ErrUnknownFormat))
 
 	// Restore errors on no chunks
-	err = manager.Restore(types.Snapshot{Height: 3, Format: 1, Hash: []byte{1, 2, 3}})
+	err = manager.Restore(types.Snapshot{Height: 3, Format: types.CurrentFormat, Hash: []byte{1, 2, 3}})
 	require.Error(t, err)
 
 	// Restore errors on chunk and chunkhashes mismatch
 	err = manager.Restore(types.Snapshot{
 		Height:   3,
-		Format:   1,
+		Format:   types.CurrentFormat,
 		Hash:     []byte{1, 2, 3},
 		Chunks:   4,
 		Metadata: types.Metadata{ChunkHashes: checksums(chunks)},
@@ -152,9 +154,9 @@ func TestManager_Restore(t *testing.T) {
 	// Starting a restore works
 	err = manager.Restore(types.Snapshot{
 		Height:   3,
-		Format:   1,
+		Format:   types.CurrentFormat,
 		Hash:     []byte{1, 2, 3},
-		Chunks:   3,
+		Chunks:   uint32(len(chunks)),
 		Metadata: types.Metadata{ChunkHashes: checksums(chunks)},
 	})
 	require.NoError(t, err)
@@ -182,28 +184,94 @@ func TestManager_Restore(t *testing.T) {
 		}
 	}
 
-	assert.Equal(t, chunks, target.chunks)
+	assert.Equal(t, expectItems, target.items)
 
 	// Starting a new restore should fail now, because the target already has contents.
 	err = manager.Restore(types.Snapshot{
 		Height:   3,
-		Format:   1,
+		Format:   types.CurrentFormat,
 		Hash:     []byte{1, 2, 3},
-		Chunks:   3,
+		Chunks:   uint32(len(chunks)),
 		Metadata: types.Metadata{ChunkHashes: checksums(chunks)},
 	})
+	require.NoError(t, err)
+	_, err = manager.RestoreChunk(chunks[0])
 	require.Error(t, err)
 
-	// But if we clear out the target we should be able to start a new restore. This time we'll
-	// fail it with a checksum error. That error should stop the operation, so that we can do
-	// a prune operation right after.
-	target.chunks = nil
+	// But if we clear out the target we should be able to start a new restore.
+	target.items = nil
 	err = manager.Restore(types.Snapshot{
 		Height:   3,
-		Format:   1,
+		Format:   types.CurrentFormat,
 		Hash:     []byte{1, 2, 3},
-		Chunks:   3,
+		Chunks:   uint32(len(chunks)),
 		Metadata: types.Metadata{ChunkHashes: checksums(chunks)},
 	})
 	require.NoError(t, err)
 }
+
+func TestManager_Extensions(t *testing.T) {
+	store := setupStore(t)
+	multistore := &mockSnapshotter{items: [][]byte{{1, 2, 3}}}
+	manager := snapshots.NewManager(store, multistore)
+
+	extensionB := &mockExtension{name: "b", payloads: [][]byte{{7, 8}}}
+	extensionA := &mockExtension{name: "a", payloads: [][]byte{{4, 5}, {6}}}
+	require.NoError(t, manager.RegisterExtensions(extensionB, extensionA))
+	require.Error(t, manager.RegisterExtensions(&mockExtension{name: "a"}))
+
+	snapshot, err := manager.Create(5)
+	require.NoError(t, err)
+	_, chunks, err := store.Load(snapshot.Height, snapshot.Format)
+	require.NoError(t, err)
+	bodies := readChunks(chunks)
+
+	// the extensions are restored from the payloads following their metadata
+	target := &mockSnapshotter{}
+	targetA := &mockExtension{name: "a"}
+	targetB := &mockExtension{name: "b"}
+	manager = snapshots.NewManager(setupStore(t), target)
+	require.NoError(t, manager.RegisterExtensions(targetA, targetB))
+
+	require.NoError(t, manager.Restore(*snapshot))
+	for _, body := range bodies {
+		_, err = manager.RestoreChunk(body)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, multistore.items, target.items)
+	assert.Equal(t, extensionA.payloads, targetA.restored)
+	assert.Equal(t, extensionB.payloads, targetB.restored)
+
+	// restoring a snapshot with an unregistered extension fails
+	manager = snapshots.NewManager(setupStore(t), &mockSnapshotter{})
+	require.NoError(t, manager.RegisterExtensions(&mockExtension{name: "a"}))
+
+	require.NoError(t, manager.Restore(*snapshot))
+	for _, body := range bodies {
+		_, err = manager.RestoreChunk(body)
+	}
+	require.Error(t, err)
+}
+
+func TestManager_RestoreLocalSnapshot(t *testing.T) {
+	store := setupStore(t)
+	items := [][]byte{{1, 2, 3}, {4, 5, 6}}
+	manager := snapshots.NewManager(store, &mockSnapshotter{items: items})
+	snapshot, err := manager.Create(5)
+	require.NoError(t, err)
+
+	target := &mockSnapshotter{}
+	manager = snapshots.NewManager(store, target)
+
+	// missing snapshots and snapshots of unknown formats cannot be restored
+	require.Error(t, manager.RestoreLocalSnapshot(4, types.CurrentFormat))
+	err = manager.RestoreLocalSnapshot(1, 1)
+	require.True(t, errors.Is(err, types.ErrUnknownFormat), err)
+
+	require.NoError(t, manager.RestoreLocalSnapshot(snapshot.Height, snapshot.Format))
+	assert.Equal(t, items, target.items)
+
+	// the manager is not busy once the restore has completed
+	_, err = manager.Prune(1)
+	require.NoError(t, err)
+}
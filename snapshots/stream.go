@@ -0,0 +1,119 @@
+package snapshots
+
+import (
+	"bufio"
+	"compress/zlib"
+	"io"
+
+	protoio "github.com/gogo/protobuf/io"
+	"github.com/gogo/protobuf/proto"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+const (
+	// Do not change the chunk size or the compression level without bumping the snapshot
+	// format, as the output must be identical across nodes.
+	snapshotChunkSize        = uint64(10e6)
+	snapshotBufferSize       = int(snapshotChunkSize)
+	snapshotCompressionLevel = 7
+
+	// SDK has no key/value size limit, so we set an arbitrary limit
+	snapshotMaxItemSize = int(64e6)
+)
+
+// StreamWriter serializes snapshot items into snapshot chunks through the pipeline:
+// delimited Protobuf -> zlib -> buffer -> ChunkWriter -> chan io.ReadCloser
+type StreamWriter struct {
+	chunkWriter *ChunkWriter
+	bufWriter   *bufio.Writer
+	zWriter     *zlib.Writer
+	protoWriter protoio.WriteCloser
+}
+
+var _ protoio.WriteCloser = (*StreamWriter)(nil)
+
+// NewStreamWriter creates a new StreamWriter which sends the chunks to the given channel. It
+// returns nil, and closes the channel with an error, if the pipeline cannot be set up.
+func NewStreamWriter(ch chan<- io.ReadCloser) *StreamWriter {
+	chunkWriter := NewChunkWriter(ch, snapshotChunkSize)
+	bufWriter := bufio.NewWriterSize(chunkWriter, snapshotBufferSize)
+	zWriter, err := zlib.NewWriterLevel(bufWriter, snapshotCompressionLevel)
+	if err != nil {
+		chunkWriter.CloseWithError(sdkerrors.Wrap(err, "zlib failure"))
+		return nil
+	}
+
+	return &StreamWriter{
+		chunkWriter: chunkWriter,
+		bufWriter:   bufWriter,
+		zWriter:     zWriter,
+		protoWriter: protoio.NewDelimitedWriter(zWriter),
+	}
+}
+
+// WriteMsg implements protoio.Writer.
+func (sw *StreamWriter) WriteMsg(msg proto.Message) error {
+	return sw.protoWriter.WriteMsg(msg)
+}
+
+// Close implements io.Closer. It flushes the pipeline and closes the chunk channel.
+func (sw *StreamWriter) Close() error {
+	// closing the protobuf writer closes the zlib writer
+	if err := sw.protoWriter.Close(); err != nil {
+		sw.chunkWriter.CloseWithError(err)
+		return err
+	}
+	if err := sw.bufWriter.Flush(); err != nil {
+		sw.chunkWriter.CloseWithError(err)
+		return err
+	}
+
+	return sw.chunkWriter.Close()
+}
+
+// CloseWithError closes the chunk channel and passes the error to the chunk reader.
+func (sw *StreamWriter) CloseWithError(err error) {
+	sw.chunkWriter.CloseWithError(err)
+}
+
+// StreamReader deserializes snapshot items from snapshot chunks through the pipeline:
+// chan io.ReadCloser -> ChunkReader -> zlib -> delimited Protobuf
+type StreamReader struct {
+	chunkReader *ChunkReader
+	protoReader protoio.ReadCloser
+}
+
+var _ protoio.ReadCloser = (*StreamReader)(nil)
+
+// NewStreamReader creates a new StreamReader which reads the chunks from the given channel.
+// It blocks until the first chunk is received.
+func NewStreamReader(chunks <-chan io.ReadCloser) (*StreamReader, error) {
+	chunkReader := NewChunkReader(chunks)
+	zReader, err := zlib.NewReader(chunkReader)
+	if err != nil {
+		chunkReader.Close()
+		return nil, sdkerrors.Wrap(err, "zlib failure")
+	}
+
+	return &StreamReader{
+		chunkReader: chunkReader,
+		protoReader: protoio.NewDelimitedReader(zReader, snapshotMaxItemSize),
+	}, nil
+}
+
+// ReadMsg implements protoio.Reader.
+func (sr *StreamReader) ReadMsg(msg proto.Message) error {
+	return sr.protoReader.ReadMsg(msg)
+}
+
+// Close implements io.Closer.
+func (sr *StreamReader) Close() error {
+	// closing the protobuf reader closes the zlib reader
+	err := sr.protoReader.Close()
+	if cerr := sr.chunkReader.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
@@ -1,16 +1,49 @@
 package types
 
-import "io"
+import (
+	protoio "github.com/gogo/protobuf/io"
+)
 
-// Snapshotter is something that can create and restore snapshots, consisting of streamed binary
-// chunks - all of which must be read from the channel and closed. If an unsupported format is
-// given, it must return ErrUnknownFormat (possibly wrapped with fmt.Errorf).
+// Snapshotter is something that can create and restore snapshots, consisting of a stream of
+// SnapshotItems. If an unsupported format is given, it must return ErrUnknownFormat (possibly
+// wrapped with fmt.Errorf).
 type Snapshotter interface {
-	// Snapshot creates a state snapshot, returning a channel of snapshot chunk readers.
-	Snapshot(height uint64, format uint32) (<-chan io.ReadCloser, error)
+	// Snapshot writes the snapshot items of the state at the given height to the protobuf
+	// writer.
+	Snapshot(height uint64, protoWriter protoio.Writer) error
 
-	// Restore restores a state snapshot, taking snapshot chunk readers as input.
-	// If the ready channel is non-nil, it returns a ready signal (by being closed) once the
-	// restorer is ready to accept chunks.
-	Restore(height uint64, format uint32, chunks <-chan io.ReadCloser, ready chan<- struct{}) error
+	// Restore restores a state snapshot from the protobuf items read from the reader. It
+	// returns the first item which is not part of its own snapshot, e.g. the metadata of a
+	// snapshot extension, or an empty item once the end of the stream is reached.
+	Restore(height uint64, format uint32, protoReader protoio.Reader) (SnapshotItem, error)
+}
+
+// ExtensionPayloadReader reads the next payload of a snapshot extension. It returns io.EOF
+// once the end of the stream or the end of the payloads of the extension is reached.
+type ExtensionPayloadReader = func() ([]byte, error)
+
+// ExtensionPayloadWriter writes a payload of a snapshot extension to the snapshot stream.
+type ExtensionPayloadWriter = func([]byte) error
+
+// ExtensionSnapshotter is a snapshotter for state which is not kept in the multistore, e.g.
+// files on disk. The payloads of every extension are appended to the snapshot stream after
+// the multistore items. Every extension has a unique name and manages its own payload
+// formats.
+type ExtensionSnapshotter interface {
+	// SnapshotName returns the name of the extension, which must be unique in the manager.
+	SnapshotName() string
+
+	// SnapshotFormat returns the format in which the extension encodes its payloads when
+	// taking a snapshot.
+	SnapshotFormat() uint32
+
+	// SupportedFormats returns the formats of the payloads the extension can restore.
+	SupportedFormats() []uint32
+
+	// SnapshotExtension writes the payloads of the state at the given height.
+	SnapshotExtension(height uint64, payloadWriter ExtensionPayloadWriter) error
+
+	// RestoreExtension restores the state at the given height from the payloads of the given
+	// format. The payload reader returns io.EOF once all payloads of the extension are read.
+	RestoreExtension(height uint64, format uint32, payloadReader ExtensionPayloadReader) error
 }
@@ -1,33 +1,51 @@
 package cache
 
 import (
-	"fmt"
+	"sync"
+
+	"github.com/armon/go-metrics"
 
 	"github.com/cosmos/cosmos-sdk/store/cachekv"
 	"github.com/cosmos/cosmos-sdk/store/types"
-
-	lru "github.com/hashicorp/golang-lru"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 )
 
 var (
 	_ types.CommitKVStore             = (*CommitKVStoreCache)(nil)
 	_ types.MultiStorePersistentCache = (*CommitKVStoreCacheManager)(nil)
 
-	// DefaultCommitKVStoreCacheSize defines the persistent ARC cache size for a
-	// CommitKVStoreCache.
-	DefaultCommitKVStoreCacheSize uint = 1000
+	// DefaultCommitKVStoreCacheSize defines the size in bytes of the entries
+	// held by a CommitKVStoreCache.
+	DefaultCommitKVStoreCacheSize uint64 = 8 << 20
 )
 
 type (
 	// CommitKVStoreCache implements an inter-block (persistent) cache that wraps a
-	// CommitKVStore. Reads first hit the internal ARC (Adaptive Replacement Cache).
-	// During a cache miss, the read is delegated to the underlying CommitKVStore
-	// and cached. Deletes and writes always happen to both the cache and the
-	// CommitKVStore in a write-through manner. Caching performed in the
-	// CommitKVStore and below is completely irrelevant to this layer.
+	// CommitKVStore. Reads first hit the internal segmented LRU cache, which is
+	// bounded by the size in bytes of the keys and values it holds and resists
+	// scans. During a cache miss, the read is delegated to the underlying
+	// CommitKVStore and cached. Deletes and writes always happen to both the
+	// cache and the CommitKVStore in a write-through manner. Caching performed
+	// in the CommitKVStore and below is completely irrelevant to this layer.
+	//
+	// The hits, misses and evictions are reported by the store_cache_hit,
+	// store_cache_miss and store_cache_eviction metrics, labelled by store.
 	CommitKVStoreCache struct {
 		types.CommitKVStore
-		cache *lru.ARCCache
+
+		mtx    sync.Mutex
+		cache  *segmentedLRU
+		stats  CacheStats
+		labels []metrics.Label
+	}
+
+	// CacheStats are the statistics of a CommitKVStoreCache.
+	CacheStats struct {
+		Hits      uint64
+		Misses    uint64
+		Evictions uint64
+		// Size is the size in bytes of the entries held by the cache.
+		Size uint64
 	}
 
 	// CommitKVStoreCacheManager maintains a mapping from a StoreKey to a
@@ -35,36 +53,61 @@ type (
 	// in an inter-block (persistent) manner and typically provided by a
 	// CommitMultiStore.
 	CommitKVStoreCacheManager struct {
-		cacheSize uint
-		caches    map[string]types.CommitKVStore
+		cacheSize      uint64
+		storeSizes     map[string]uint64
+		warmupPrefixes map[string][][]byte
+		caches         map[string]types.CommitKVStore
 	}
 )
 
-func NewCommitKVStoreCache(store types.CommitKVStore, size uint) *CommitKVStoreCache {
-	cache, err := lru.NewARC(int(size))
-	if err != nil {
-		panic(fmt.Errorf("failed to create KVStore cache: %s", err))
-	}
-
+// NewCommitKVStoreCache returns a CommitKVStoreCache of the given size in bytes
+// wrapping the store of the given name.
+func NewCommitKVStoreCache(store types.CommitKVStore, storeName string, size uint64) *CommitKVStoreCache {
 	return &CommitKVStoreCache{
 		CommitKVStore: store,
-		cache:         cache,
+		cache:         newSegmentedLRU(size),
+		labels:        []metrics.Label{telemetry.NewLabel("store", storeName)},
 	}
 }
 
-func NewCommitKVStoreCacheManager(size uint) *CommitKVStoreCacheManager {
+// NewCommitKVStoreCacheManager returns a CommitKVStoreCacheManager whose
+// caches hold up to the given size in bytes each.
+func NewCommitKVStoreCacheManager(size uint64) *CommitKVStoreCacheManager {
 	return &CommitKVStoreCacheManager{
-		cacheSize: size,
-		caches:    make(map[string]types.CommitKVStore),
+		cacheSize:      size,
+		storeSizes:     make(map[string]uint64),
+		warmupPrefixes: make(map[string][][]byte),
+		caches:         make(map[string]types.CommitKVStore),
 	}
 }
 
+// SetStoreCacheSize overrides the size in bytes of the cache of the store of
+// the given key name. It applies to the caches created afterwards.
+func (cmgr *CommitKVStoreCacheManager) SetStoreCacheSize(storeName string, size uint64) {
+	cmgr.storeSizes[storeName] = size
+}
+
+// AddWarmupPrefix registers a key prefix of the store of the given key name
+// whose entries are loaded into the cache of the store when it is created,
+// that is when the store is loaded. The entries are loaded in the protected
+// segment of the cache until it is full.
+func (cmgr *CommitKVStoreCacheManager) AddWarmupPrefix(storeName string, prefix []byte) {
+	cmgr.warmupPrefixes[storeName] = append(cmgr.warmupPrefixes[storeName], prefix)
+}
+
 // GetStoreCache returns a Cache from the CommitStoreCacheManager for a given
-// StoreKey. If no Cache exists for the StoreKey, then one is created and set.
-// The returned Cache is meant to be used in a persistent manner.
+// StoreKey. If no Cache exists for the StoreKey, then one is created, warmed
+// up and set. The returned Cache is meant to be used in a persistent manner.
 func (cmgr *CommitKVStoreCacheManager) GetStoreCache(key types.StoreKey, store types.CommitKVStore) types.CommitKVStore {
 	if cmgr.caches[key.Name()] == nil {
-		cmgr.caches[key.Name()] = NewCommitKVStoreCache(store, cmgr.cacheSize)
+		size, ok := cmgr.storeSizes[key.Name()]
+		if !ok {
+			size = cmgr.cacheSize
+		}
+
+		ckv := NewCommitKVStoreCache(store, key.Name(), size)
+		ckv.warmup(cmgr.warmupPrefixes[key.Name()])
+		cmgr.caches[key.Name()] = ckv
 	}
 
 	return cmgr.caches[key.Name()]
@@ -89,6 +132,42 @@ func (cmgr *CommitKVStoreCacheManager) Reset() {
 	}
 }
 
+// Stats returns the statistics of the cache.
+func (ckv *CommitKVStoreCache) Stats() CacheStats {
+	ckv.mtx.Lock()
+	defer ckv.mtx.Unlock()
+
+	stats := ckv.stats
+	stats.Size = ckv.cache.size
+	return stats
+}
+
+// warmup loads the entries of the given prefixes of the underlying store into
+// the protected segment of the cache, until it is full.
+func (ckv *CommitKVStoreCache) warmup(prefixes [][]byte) {
+	ckv.mtx.Lock()
+	defer ckv.mtx.Unlock()
+
+	for _, prefix := range prefixes {
+		if !ckv.warmupPrefix(prefix) {
+			return
+		}
+	}
+}
+
+func (ckv *CommitKVStoreCache) warmupPrefix(prefix []byte) bool {
+	iter := types.KVStorePrefixIterator(ckv.CommitKVStore, prefix)
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		if !ckv.cache.addProtected(string(iter.Key()), iter.Value()) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // CacheWrap implements the CacheWrapper interface
 func (ckv *CommitKVStoreCache) CacheWrap() types.CacheWrap {
 	return cachekv.NewStore(ckv)
@@ -101,15 +180,23 @@ func (ckv *CommitKVStoreCache) Get(key []byte) []byte {
 	types.AssertValidKey(key)
 
 	keyStr := string(key)
-	valueI, ok := ckv.cache.Get(keyStr)
+
+	ckv.mtx.Lock()
+	value, ok := ckv.cache.get(keyStr)
 	if ok {
 		// cache hit
-		return valueI.([]byte)
+		ckv.stats.Hits++
+		ckv.mtx.Unlock()
+		telemetry.IncrCounterWithLabels([]string{"store", "cache", "hit"}, 1, ckv.labels)
+		return value
 	}
+	ckv.stats.Misses++
+	ckv.mtx.Unlock()
+	telemetry.IncrCounterWithLabels([]string{"store", "cache", "miss"}, 1, ckv.labels)
 
 	// cache miss; write to cache
-	value := ckv.CommitKVStore.Get(key)
-	ckv.cache.Add(keyStr, value)
+	value = ckv.CommitKVStore.Get(key)
+	ckv.add(keyStr, value)
 
 	return value
 }
@@ -120,13 +207,27 @@ func (ckv *CommitKVStoreCache) Set(key, value []byte) {
 	types.AssertValidKey(key)
 	types.AssertValidValue(value)
 
-	ckv.cache.Add(string(key), value)
+	ckv.add(string(key), value)
 	ckv.CommitKVStore.Set(key, value)
 }
 
 // Delete removes a key/value pair from both the write-through cache and the
 // underlying CommitKVStore.
 func (ckv *CommitKVStoreCache) Delete(key []byte) {
-	ckv.cache.Remove(string(key))
+	ckv.mtx.Lock()
+	ckv.cache.remove(string(key))
+	ckv.mtx.Unlock()
+
 	ckv.CommitKVStore.Delete(key)
 }
+
+func (ckv *CommitKVStoreCache) add(key string, value []byte) {
+	ckv.mtx.Lock()
+	evicted := ckv.cache.add(key, value)
+	ckv.stats.Evictions += uint64(evicted)
+	ckv.mtx.Unlock()
+
+	if evicted > 0 {
+		telemetry.IncrCounterWithLabels([]string{"store", "cache", "eviction"}, float32(evicted), ckv.labels)
+	}
+}
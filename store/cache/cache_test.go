@@ -51,7 +51,7 @@ func TestStoreCache(t *testing.T) {
 	store := iavlstore.UnsafeNewStore(tree)
 	kvStore := mngr.GetStoreCache(sKey, store)
 
-	for i := uint(0); i < cache.DefaultCommitKVStoreCacheSize*2; i++ {
+	for i := 0; i < 2000; i++ {
 		key := []byte(fmt.Sprintf("key_%d", i))
 		value := []byte(fmt.Sprintf("value_%d", i))
 
@@ -67,3 +67,87 @@ func TestStoreCache(t *testing.T) {
 		require.Nil(t, store.Get(key))
 	}
 }
+
+func newTestStore(t *testing.T) types.CommitKVStore {
+	tree, err := iavl.NewMutableTree(dbm.NewMemDB(), 100)
+	require.NoError(t, err)
+	return iavlstore.UnsafeNewStore(tree)
+}
+
+func TestStoreCacheSize(t *testing.T) {
+	// every entry takes 64 bytes of overhead, 6 of key and 8 of value
+	kvStore := cache.NewCommitKVStoreCache(newTestStore(t), "test", 780)
+
+	for i := 0; i < 20; i++ {
+		kvStore.Set([]byte(fmt.Sprintf("key_%02d", i)), []byte(fmt.Sprintf("value_%02d", i)))
+	}
+
+	stats := kvStore.Stats()
+	require.Equal(t, uint64(10), stats.Evictions)
+	require.Equal(t, uint64(780), stats.Size)
+
+	// evicted entries are read from the underlying store
+	require.Equal(t, []byte("value_00"), kvStore.Get([]byte("key_00")))
+	require.Equal(t, []byte("value_19"), kvStore.Get([]byte("key_19")))
+
+	stats = kvStore.Stats()
+	require.Equal(t, uint64(1), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+	require.Equal(t, uint64(11), stats.Evictions)
+
+	// values larger than the cache are not cached
+	kvStore.Set([]byte("large"), make([]byte, 1000))
+	require.Equal(t, uint64(780), kvStore.Stats().Size)
+}
+
+func TestStoreCacheScanResistance(t *testing.T) {
+	store := newTestStore(t)
+	for i := 0; i < 100; i++ {
+		store.Set([]byte(fmt.Sprintf("key_%02d", i)), []byte(fmt.Sprintf("value_%02d", i)))
+	}
+	kvStore := cache.NewCommitKVStoreCache(store, "test", 780)
+
+	// the hot keys are read twice, and promoted to the protected segment
+	for j := 0; j < 2; j++ {
+		for i := 0; i < 5; i++ {
+			kvStore.Get([]byte(fmt.Sprintf("key_%02d", i)))
+		}
+	}
+
+	// a scan reads every other key once
+	for i := 5; i < 100; i++ {
+		kvStore.Get([]byte(fmt.Sprintf("key_%02d", i)))
+	}
+
+	hits := kvStore.Stats().Hits
+	for i := 0; i < 5; i++ {
+		kvStore.Get([]byte(fmt.Sprintf("key_%02d", i)))
+	}
+	require.Equal(t, hits+5, kvStore.Stats().Hits)
+}
+
+func TestStoreCacheWarmup(t *testing.T) {
+	sKey := types.NewKVStoreKey("test")
+	store := newTestStore(t)
+	for i := 0; i < 20; i++ {
+		store.Set([]byte(fmt.Sprintf("a_%02d", i)), []byte("value"))
+		store.Set([]byte(fmt.Sprintf("b_%02d", i)), []byte("value"))
+	}
+
+	mngr := cache.NewCommitKVStoreCacheManager(cache.DefaultCommitKVStoreCacheSize)
+	mngr.SetStoreCacheSize(sKey.Name(), 1000)
+	mngr.AddWarmupPrefix(sKey.Name(), []byte("b_"))
+	kvStore := mngr.GetStoreCache(sKey, store).(*cache.CommitKVStoreCache)
+
+	// the protected segment holds 800 bytes, that is 10 entries of 73 bytes
+	require.Equal(t, uint64(10*73), kvStore.Stats().Size)
+
+	for i := 0; i < 10; i++ {
+		kvStore.Get([]byte(fmt.Sprintf("b_%02d", i)))
+	}
+	kvStore.Get([]byte("a_00"))
+
+	stats := kvStore.Stats()
+	require.Equal(t, uint64(10), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"container/list"
+)
+
+const (
+	// entryOverhead estimates the memory used by the bookkeeping of an entry,
+	// which is accounted for in the size of the entry.
+	entryOverhead = 64
+
+	// protectedRatio is the share of the capacity of a segmentedLRU reserved
+	// to the protected segment.
+	protectedRatio = 0.8
+)
+
+// segmentedLRU is an LRU cache bounded by the size in bytes of its entries,
+// split in two segments to resist scans. New entries enter the probationary
+// segment and are promoted to the protected segment when they are hit, so that
+// a scan of keys which are read once only evicts other keys read once. The
+// entries evicted from the protected segment are demoted to the probationary
+// segment, which is evicted first. It is not safe for concurrent use.
+type segmentedLRU struct {
+	capacity          uint64
+	protectedCapacity uint64
+
+	size          uint64
+	protectedSize uint64
+
+	probation *list.List
+	protected *list.List
+	entries   map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	protected bool
+}
+
+func (e *lruEntry) size() uint64 {
+	return uint64(len(e.key)+len(e.value)) + entryOverhead
+}
+
+func newSegmentedLRU(capacity uint64) *segmentedLRU {
+	return &segmentedLRU{
+		capacity:          capacity,
+		protectedCapacity: uint64(float64(capacity) * protectedRatio),
+		probation:         list.New(),
+		protected:         list.New(),
+		entries:           make(map[string]*list.Element),
+	}
+}
+
+// get returns the value of a key and promotes it to the protected segment.
+func (c *segmentedLRU) get(key string) ([]byte, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if entry.protected {
+		c.protected.MoveToFront(elem)
+		return entry.value, true
+	}
+
+	c.probation.Remove(elem)
+	c.entries[key] = c.pushProtected(entry)
+	c.demote()
+
+	return entry.value, true
+}
+
+// add sets the value of a key, and returns the number of entries evicted to
+// make room for it. Entries larger than the capacity are not cached.
+func (c *segmentedLRU) add(key string, value []byte) (evicted int) {
+	entry := &lruEntry{key: key, value: value}
+	if entry.size() > c.capacity {
+		c.remove(key)
+		return 0
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		old := elem.Value.(*lruEntry)
+		c.size = c.size - old.size() + entry.size()
+		if old.protected {
+			c.protectedSize = c.protectedSize - old.size() + entry.size()
+			c.protected.MoveToFront(elem)
+		} else {
+			c.probation.MoveToFront(elem)
+		}
+		old.value = value
+		c.demote()
+	} else {
+		c.entries[key] = c.probation.PushFront(entry)
+		c.size += entry.size()
+	}
+
+	return c.evict()
+}
+
+// addProtected adds a key to the protected segment if it has room for it, and
+// returns whether it did.
+func (c *segmentedLRU) addProtected(key string, value []byte) bool {
+	entry := &lruEntry{key: key, value: value}
+	if _, ok := c.entries[key]; ok || c.protectedSize+entry.size() > c.protectedCapacity {
+		return false
+	}
+
+	c.entries[key] = c.pushProtected(entry)
+	c.size += entry.size()
+	c.evict()
+
+	return true
+}
+
+// remove deletes a key from the cache.
+func (c *segmentedLRU) remove(key string) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if entry.protected {
+		c.protected.Remove(elem)
+		c.protectedSize -= entry.size()
+	} else {
+		c.probation.Remove(elem)
+	}
+	c.size -= entry.size()
+	delete(c.entries, key)
+}
+
+func (c *segmentedLRU) pushProtected(entry *lruEntry) *list.Element {
+	entry.protected = true
+	c.protectedSize += entry.size()
+	return c.protected.PushFront(entry)
+}
+
+// demote moves the least recently used entries of the protected segment to
+// the probationary segment until the protected segment fits its capacity.
+func (c *segmentedLRU) demote() {
+	for c.protectedSize > c.protectedCapacity {
+		elem := c.protected.Back()
+		entry := c.protected.Remove(elem).(*lruEntry)
+		entry.protected = false
+		c.protectedSize -= entry.size()
+		c.entries[entry.key] = c.probation.PushFront(entry)
+	}
+}
+
+// evict removes the least recently used entries, of the probationary segment
+// first, until the cache fits its capacity.
+func (c *segmentedLRU) evict() (evicted int) {
+	for c.size > c.capacity {
+		elem := c.probation.Back()
+		if elem == nil {
+			elem = c.protected.Back()
+		}
+		c.remove(elem.Value.(*lruEntry).key)
+		evicted++
+	}
+
+	return evicted
+}
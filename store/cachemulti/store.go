@@ -8,6 +8,8 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/store/cachekv"
 	"github.com/cosmos/cosmos-sdk/store/dbadapter"
+	"github.com/cosmos/cosmos-sdk/store/listenkv"
+	"github.com/cosmos/cosmos-sdk/store/tracekv"
 	"github.com/cosmos/cosmos-sdk/store/types"
 )
 
@@ -26,18 +28,20 @@ type Store struct {
 	traceWriter  io.Writer
 	traceContext types.TraceContext
 
-	listeners map[types.StoreKey][]types.WriteListener
+	listeners  map[types.StoreKey][]types.WriteListener
+	gasConfigs map[types.StoreKey]types.GasConfig
 }
 
 var _ types.CacheMultiStore = Store{}
 
 // NewFromKVStore creates a new Store object from a mapping of store keys to
 // CacheWrapper objects and a KVStore as the database. Each CacheWrapper store
-// is a branched store.
+// is a branched store. The gas configs override the default ones of the
+// branched stores, and may be nil.
 func NewFromKVStore(
 	store types.KVStore, stores map[types.StoreKey]types.CacheWrapper,
 	keys map[string]types.StoreKey, traceWriter io.Writer, traceContext types.TraceContext,
-	listeners map[types.StoreKey][]types.WriteListener,
+	listeners map[types.StoreKey][]types.WriteListener, gasConfigs map[types.StoreKey]types.GasConfig,
 ) Store {
 	cms := Store{
 		db:           cachekv.NewStore(store),
@@ -46,20 +50,19 @@ func NewFromKVStore(
 		traceWriter:  traceWriter,
 		traceContext: traceContext,
 		listeners:    listeners,
+		gasConfigs:   gasConfigs,
 	}
 
 	for key, store := range stores {
-		var cacheWrapped types.CacheWrap
 		if cms.TracingEnabled() {
-			cacheWrapped = store.CacheWrapWithTrace(cms.traceWriter, cms.traceContext)
-		} else {
-			cacheWrapped = store.CacheWrap()
+			store = tracekv.NewStoreWithKey(store.(types.KVStore), cms.traceWriter, cms.traceContext, key)
 		}
+		// Listeners are placed beneath the branch so that they only observe
+		// writes once the branch is written to its parent.
 		if cms.ListeningEnabled(key) {
-			cms.stores[key] = cacheWrapped.CacheWrapWithListeners(key, cms.listeners[key])
-		} else {
-			cms.stores[key] = cacheWrapped
+			store = listenkv.NewStore(store.(types.KVStore), key, cms.listeners[key])
 		}
+		cms.stores[key] = cachekv.NewStore(store.(types.KVStore))
 	}
 
 	return cms
@@ -70,9 +73,10 @@ func NewFromKVStore(
 func NewStore(
 	db dbm.DB, stores map[types.StoreKey]types.CacheWrapper, keys map[string]types.StoreKey,
 	traceWriter io.Writer, traceContext types.TraceContext, listeners map[types.StoreKey][]types.WriteListener,
+	gasConfigs map[types.StoreKey]types.GasConfig,
 ) Store {
 
-	return NewFromKVStore(dbadapter.Store{DB: db}, stores, keys, traceWriter, traceContext, listeners)
+	return NewFromKVStore(dbadapter.Store{DB: db}, stores, keys, traceWriter, traceContext, listeners, gasConfigs)
 }
 
 func newCacheMultiStoreFromCMS(cms Store) Store {
@@ -81,7 +85,11 @@ func newCacheMultiStoreFromCMS(cms Store) Store {
 		stores[k] = v
 	}
 
-	return NewFromKVStore(cms.db, stores, nil, cms.traceWriter, cms.traceContext, cms.listeners)
+	// Listeners are not propagated to nested branches. Writes made to a nested
+	// branch are observed by the listeners of the top-level branch once they
+	// have been written through, which ensures that discarded writes are never
+	// observed and that every write is observed at most once.
+	return NewFromKVStore(cms.db, stores, nil, cms.traceWriter, cms.traceContext, make(map[types.StoreKey][]types.WriteListener), cms.gasConfigs)
 }
 
 // SetTracer sets the tracer for the MultiStore that the underlying
@@ -93,12 +101,17 @@ func (cms Store) SetTracer(w io.Writer) types.MultiStore {
 
 // SetTracingContext updates the tracing context for the MultiStore by merging
 // the given context with the existing context by key. Any existing keys will
-// be overwritten. It is implied that the caller should update the context when
-// necessary between tracing operations. It returns a modified MultiStore.
+// be overwritten, and the keys with a nil value removed. It is implied that the
+// caller should update the context when necessary between tracing operations.
+// It returns a modified MultiStore.
 func (cms Store) SetTracingContext(tc types.TraceContext) types.MultiStore {
 	if cms.traceContext != nil {
 		for k, v := range tc {
-			cms.traceContext[k] = v
+			if v == nil {
+				delete(cms.traceContext, k)
+			} else {
+				cms.traceContext[k] = v
+			}
 		}
 	} else {
 		cms.traceContext = tc
@@ -129,6 +142,19 @@ func (cms Store) ListeningEnabled(key types.StoreKey) bool {
 	return false
 }
 
+// GetGasConfig returns the gas config overriding the default one of a specific
+// KVStore, if any.
+func (cms Store) GetGasConfig(key types.StoreKey) (types.GasConfig, bool) {
+	config, ok := cms.gasConfigs[key]
+	return config, ok
+}
+
+// WithGasConfigs implements CacheMultiStore.
+func (cms Store) WithGasConfigs(configs map[types.StoreKey]types.GasConfig) types.CacheMultiStore {
+	cms.gasConfigs = configs
+	return cms
+}
+
 // GetStoreType returns the type of the store.
 func (cms Store) GetStoreType() types.StoreType {
 	return types.StoreTypeMulti
@@ -0,0 +1,155 @@
+package iavl
+
+import (
+	"bytes"
+	"fmt"
+
+	ics23 "github.com/confio/ics23/go"
+	"github.com/cosmos/iavl"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmcrypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
+
+	"github.com/cosmos/cosmos-sdk/store/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/kv"
+)
+
+// MaxBatchProofKeys is the maximum number of keys of a "/keys" query, and of
+// the keys within the range of a "/range" query.
+const MaxBatchProofKeys = 1000
+
+// queryKeys answers a "/keys" query, whose data is the kv.Pairs of the keys to
+// get. The value of the response is the kv.Pairs of the keys which exist, in
+// the order of the request, and its proof a batch proof of the existence or of
+// the absence of every key.
+func queryKeys(tree *iavl.ImmutableTree, req abci.RequestQuery, res abci.ResponseQuery) abci.ResponseQuery {
+	var request kv.Pairs
+	if err := request.Unmarshal(req.Data); err != nil {
+		return sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrTxDecode, err.Error()))
+	}
+	if len(request.Pairs) == 0 || len(request.Pairs) > MaxBatchProofKeys {
+		return sdkerrors.QueryResult(sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest,
+			"the number of keys must be between 1 and %d, got %d", MaxBatchProofKeys, len(request.Pairs)))
+	}
+
+	var (
+		pairs  = kv.Pairs{Pairs: make([]kv.Pair, 0, len(request.Pairs))}
+		proofs = make([]*ics23.CommitmentProof, 0, len(request.Pairs))
+	)
+	for _, pair := range request.Pairs {
+		_, value := tree.Get(pair.Key)
+		if value != nil {
+			pairs.Pairs = append(pairs.Pairs, kv.Pair{Key: pair.Key, Value: value})
+		}
+		if !req.Prove {
+			continue
+		}
+
+		proof, err := getCommitmentProof(tree, pair.Key, value != nil)
+		if err != nil {
+			return sdkerrors.QueryResult(err)
+		}
+		proofs = append(proofs, proof)
+	}
+
+	return batchProofResponse(res, pairs, proofs)
+}
+
+// queryRange answers a "/range" query, whose data is a kv.Pair of the start
+// and end of the range, an empty end meaning the end of the store. The value
+// of the response is the kv.Pairs of the keys within the range, and its proof
+// a batch proof which also proves that the range contains no other key: it
+// includes the absence of the start if it does not exist, and the existence or
+// absence of the end.
+func queryRange(tree *iavl.ImmutableTree, req abci.RequestQuery, res abci.ResponseQuery) abci.ResponseQuery {
+	var request kv.Pair
+	if err := request.Unmarshal(req.Data); err != nil {
+		return sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrTxDecode, err.Error()))
+	}
+
+	start, end := request.Key, request.Value
+	if len(end) == 0 {
+		end = nil
+	}
+	if end != nil && bytes.Compare(start, end) >= 0 {
+		return sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "the start of the range must be before its end"))
+	}
+
+	pairs := kv.Pairs{Pairs: make([]kv.Pair, 0)}
+	tree.IterateRange(start, end, true, func(key, value []byte) bool {
+		pairs.Pairs = append(pairs.Pairs, kv.Pair{Key: key, Value: value})
+		return len(pairs.Pairs) > MaxBatchProofKeys
+	})
+	if len(pairs.Pairs) > MaxBatchProofKeys {
+		return sdkerrors.QueryResult(sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest,
+			"the range contains more than %d keys", MaxBatchProofKeys))
+	}
+	if !req.Prove {
+		return batchProofResponse(res, pairs, nil)
+	}
+
+	proofs := make([]*ics23.CommitmentProof, 0, len(pairs.Pairs)+2)
+	if len(start) > 0 && (len(pairs.Pairs) == 0 || !bytes.Equal(pairs.Pairs[0].Key, start)) {
+		proof, err := getCommitmentProof(tree, start, false)
+		if err != nil {
+			return sdkerrors.QueryResult(err)
+		}
+		proofs = append(proofs, proof)
+	}
+	for _, pair := range pairs.Pairs {
+		proof, err := getCommitmentProof(tree, pair.Key, true)
+		if err != nil {
+			return sdkerrors.QueryResult(err)
+		}
+		proofs = append(proofs, proof)
+	}
+	if end != nil {
+		proof, err := getCommitmentProof(tree, end, tree.Has(end))
+		if err != nil {
+			return sdkerrors.QueryResult(err)
+		}
+		proofs = append(proofs, proof)
+	}
+
+	return batchProofResponse(res, pairs, proofs)
+}
+
+func getCommitmentProof(tree *iavl.ImmutableTree, key []byte, exists bool) (*ics23.CommitmentProof, error) {
+	var (
+		proof *ics23.CommitmentProof
+		err   error
+	)
+	if exists {
+		proof, err = tree.GetMembershipProof(key)
+	} else {
+		proof, err = tree.GetNonMembershipProof(key)
+	}
+	if err != nil {
+		return nil, sdkerrors.Wrapf(types.ErrInvalidProof, "failed to prove key %X: %s", key, err)
+	}
+
+	return proof, nil
+}
+
+// batchProofResponse sets the pairs as the value of the response, and the
+// proofs combined into a compressed batch proof as its proof.
+func batchProofResponse(res abci.ResponseQuery, pairs kv.Pairs, proofs []*ics23.CommitmentProof) abci.ResponseQuery {
+	bz, err := pairs.Marshal()
+	if err != nil {
+		panic(fmt.Errorf("failed to marshal KV pairs: %w", err))
+	}
+	res.Value = bz
+
+	if len(proofs) == 0 {
+		return res
+	}
+
+	proof, err := ics23.CombineProofs(proofs)
+	if err != nil {
+		return sdkerrors.QueryResult(sdkerrors.Wrap(types.ErrInvalidProof, err.Error()))
+	}
+	op := types.NewIavlCommitmentOp(nil, proof)
+	res.ProofOps = &tmcrypto.ProofOps{Ops: []tmcrypto.ProofOp{op.ProofOp()}}
+
+	return res
+}
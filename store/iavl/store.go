@@ -198,6 +198,13 @@ func (st *Store) DeleteVersions(versions ...int64) error {
 	return st.tree.DeleteVersions(versions...)
 }
 
+// LoadVersionForOverwriting loads the given version of the MutableTree, or the
+// latest version below it, and deletes all versions above it, so that the next
+// commit overwrites them. It returns the loaded version.
+func (st *Store) LoadVersionForOverwriting(targetVersion int64) (int64, error) {
+	return st.tree.LoadVersionForOverwriting(targetVersion)
+}
+
 // Implements types.KVStore.
 func (st *Store) Iterator(start, end []byte) types.Iterator {
 	var iTree *iavl.ImmutableTree
@@ -278,7 +285,8 @@ func getHeight(tree Tree, req abci.RequestQuery) int64 {
 func (st *Store) Query(req abci.RequestQuery) (res abci.ResponseQuery) {
 	defer telemetry.MeasureSince(time.Now(), "store", "iavl", "query")
 
-	if len(req.Data) == 0 {
+	// the data of a "/range" query of the whole store is empty
+	if len(req.Data) == 0 && req.Path != "/range" {
 		return sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrTxDecode, "query cannot be zero length"))
 	}
 
@@ -317,6 +325,22 @@ func (st *Store) Query(req abci.RequestQuery) (res abci.ResponseQuery) {
 		// get proof from tree and convert to merkle.Proof before adding to result
 		res.ProofOps = getProofFromTree(mtree, req.Data, res.Value != nil)
 
+	case "/keys", "/range": // get a set or a range of keys
+		res.Key = req.Data
+		if !st.VersionExists(res.Height) {
+			res.Log = iavl.ErrVersionDoesNotExist.Error()
+			break
+		}
+
+		iTree, err := tree.GetImmutable(res.Height)
+		if err != nil {
+			panic(fmt.Sprintf("version exists in store but could not retrieve corresponding versioned tree in store, %s", err.Error()))
+		}
+		if req.Path == "/keys" {
+			return queryKeys(iTree, req, res)
+		}
+		return queryRange(iTree, req, res)
+
 	case "/subspace":
 		pairs := kv.Pairs{
 			Pairs: make([]kv.Pair, 0),
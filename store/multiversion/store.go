@@ -0,0 +1,293 @@
+package multiversion
+
+import (
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/internal/conv"
+	"github.com/cosmos/cosmos-sdk/store/cachekv"
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+// Store is a multi-version key-value store that holds the writes of the
+// transactions of a block that are executed concurrently. Every write is
+// indexed by the position of the transaction in the block, so that a
+// transaction only observes the writes of the transactions that precede it.
+// Keys that have not been written by any preceding transaction are read from
+// the parent store, which must not be written to while the Store is in use.
+type Store struct {
+	mtx sync.RWMutex
+	// data maps every written key to its versions, sorted by index
+	data map[string][]version
+	// writesets maps every index to the keys written by the transaction
+	writesets map[int][]string
+
+	parent lockedStore
+}
+
+// version is a value written by the transaction at the given index. A nil
+// value represents a delete.
+type version struct {
+	index int
+	value []byte
+}
+
+// NewStore returns a new multi-version Store on top of the given parent store.
+func NewStore(parent types.KVStore) *Store {
+	return &Store{
+		data:      make(map[string][]version),
+		writesets: make(map[int][]string),
+		parent:    lockedStore{parent: parent, mtx: new(sync.Mutex)},
+	}
+}
+
+// SetParent replaces the parent store, e.g. to validate the executions of the
+// transactions against a newer version of the state. It must not be called
+// while transactions are executed or validated.
+func (s *Store) SetParent(parent types.KVStore) {
+	s.parent = lockedStore{parent: parent, mtx: new(sync.Mutex)}
+}
+
+// get returns the value of the key as observed by the transaction at the
+// given index, that is the latest value written by a preceding transaction
+// or the value of the parent store if there is none.
+func (s *Store) get(index int, key []byte) []byte {
+	s.mtx.RLock()
+	v, ok := s.latest(index, conv.UnsafeBytesToStr(key))
+	s.mtx.RUnlock()
+
+	if ok {
+		return v.value
+	}
+
+	return s.parent.Get(key)
+}
+
+// latest returns the latest version of the key written by a transaction that
+// precedes the given index. The caller must hold the read lock.
+func (s *Store) latest(index int, key string) (version, bool) {
+	versions := s.data[key]
+	i := sort.Search(len(versions), func(i int) bool { return versions[i].index >= index })
+	if i == 0 {
+		return version{}, false
+	}
+
+	return versions[i-1], true
+}
+
+// SetWriteset records the writes of the transaction at the given index,
+// replacing the writes of any previous execution of the transaction. A nil
+// value in the writeset represents a delete.
+func (s *Store) SetWriteset(index int, writeset map[string][]byte) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.removeWriteset(index)
+
+	keys := make([]string, 0, len(writeset))
+	for key, value := range writeset {
+		keys = append(keys, key)
+
+		versions := s.data[key]
+		i := sort.Search(len(versions), func(i int) bool { return versions[i].index >= index })
+		versions = append(versions, version{})
+		copy(versions[i+1:], versions[i:])
+		versions[i] = version{index: index, value: value}
+		s.data[key] = versions
+	}
+
+	sort.Strings(keys)
+	s.writesets[index] = keys
+}
+
+// removeWriteset removes the writes of the transaction at the given index.
+// The caller must hold the write lock.
+func (s *Store) removeWriteset(index int) {
+	for _, key := range s.writesets[index] {
+		versions := s.data[key]
+		i := sort.Search(len(versions), func(i int) bool { return versions[i].index >= index })
+		if i < len(versions) && versions[i].index == index {
+			versions = append(versions[:i], versions[i+1:]...)
+		}
+
+		if len(versions) == 0 {
+			delete(s.data, key)
+		} else {
+			s.data[key] = versions
+		}
+	}
+
+	delete(s.writesets, index)
+}
+
+// WriteTo writes the writes of the transaction at the given index to the
+// given store in ascending key order.
+func (s *Store) WriteTo(index int, store types.KVStore) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	for _, key := range s.writesets[index] {
+		versions := s.data[key]
+		i := sort.Search(len(versions), func(i int) bool { return versions[i].index >= index })
+
+		if value := versions[i].value; value != nil {
+			store.Set([]byte(key), value)
+		} else {
+			store.Delete([]byte(key))
+		}
+	}
+}
+
+// iterator returns an iterator over the domain as observed by the transaction
+// at the given index, with the given writes of the transaction itself applied
+// on top.
+func (s *Store) iterator(index int, start, end []byte, ascending bool, writeset map[string][]byte) types.Iterator {
+	overlay := cachekv.NewStore(s.parent)
+
+	s.mtx.RLock()
+	for key := range s.data {
+		if !inDomain(key, start, end) {
+			continue
+		}
+		if v, ok := s.latest(index, key); ok {
+			set(overlay, key, v.value)
+		}
+	}
+	s.mtx.RUnlock()
+
+	for key, value := range writeset {
+		if inDomain(key, start, end) {
+			set(overlay, key, value)
+		}
+	}
+
+	if ascending {
+		return overlay.Iterator(start, end)
+	}
+
+	return overlay.ReverseIterator(start, end)
+}
+
+func set(store types.KVStore, key string, value []byte) {
+	if value != nil {
+		store.Set([]byte(key), value)
+	} else {
+		store.Delete([]byte(key))
+	}
+}
+
+func inDomain(key string, start, end []byte) bool {
+	return (start == nil || key >= conv.UnsafeBytesToStr(start)) &&
+		(end == nil || key < conv.UnsafeBytesToStr(end))
+}
+
+var _ types.KVStore = lockedStore{}
+
+// lockedStore serialises the reads of the parent store, as the underlying
+// stores are not guaranteed to be safe for concurrent use. It is read only.
+type lockedStore struct {
+	parent types.KVStore
+	mtx    *sync.Mutex
+}
+
+func (ls lockedStore) GetStoreType() types.StoreType {
+	return ls.parent.GetStoreType()
+}
+
+func (ls lockedStore) Get(key []byte) []byte {
+	ls.mtx.Lock()
+	defer ls.mtx.Unlock()
+
+	return ls.parent.Get(key)
+}
+
+func (ls lockedStore) Has(key []byte) bool {
+	return ls.Get(key) != nil
+}
+
+func (ls lockedStore) Set(_, _ []byte) {
+	panic("cannot Set a read only store")
+}
+
+func (ls lockedStore) Delete(_ []byte) {
+	panic("cannot Delete from a read only store")
+}
+
+func (ls lockedStore) Iterator(start, end []byte) types.Iterator {
+	ls.mtx.Lock()
+	defer ls.mtx.Unlock()
+
+	return lockedIterator{parent: ls.parent.Iterator(start, end), mtx: ls.mtx}
+}
+
+func (ls lockedStore) ReverseIterator(start, end []byte) types.Iterator {
+	ls.mtx.Lock()
+	defer ls.mtx.Unlock()
+
+	return lockedIterator{parent: ls.parent.ReverseIterator(start, end), mtx: ls.mtx}
+}
+
+func (ls lockedStore) CacheWrap() types.CacheWrap {
+	panic("cannot CacheWrap a read only store")
+}
+
+func (ls lockedStore) CacheWrapWithTrace(_ io.Writer, _ types.TraceContext) types.CacheWrap {
+	panic("cannot CacheWrapWithTrace a read only store")
+}
+
+func (ls lockedStore) CacheWrapWithListeners(_ types.StoreKey, _ []types.WriteListener) types.CacheWrap {
+	panic("cannot CacheWrapWithListeners a read only store")
+}
+
+// lockedIterator serialises the calls to an iterator of the parent store.
+type lockedIterator struct {
+	parent types.Iterator
+	mtx    *sync.Mutex
+}
+
+func (li lockedIterator) Domain() ([]byte, []byte) {
+	return li.parent.Domain()
+}
+
+func (li lockedIterator) Valid() bool {
+	li.mtx.Lock()
+	defer li.mtx.Unlock()
+
+	return li.parent.Valid()
+}
+
+func (li lockedIterator) Next() {
+	li.mtx.Lock()
+	defer li.mtx.Unlock()
+
+	li.parent.Next()
+}
+
+func (li lockedIterator) Key() []byte {
+	li.mtx.Lock()
+	defer li.mtx.Unlock()
+
+	return li.parent.Key()
+}
+
+func (li lockedIterator) Value() []byte {
+	li.mtx.Lock()
+	defer li.mtx.Unlock()
+
+	return li.parent.Value()
+}
+
+func (li lockedIterator) Error() error {
+	li.mtx.Lock()
+	defer li.mtx.Unlock()
+
+	return li.parent.Error()
+}
+
+func (li lockedIterator) Close() error {
+	li.mtx.Lock()
+	defer li.mtx.Unlock()
+
+	return li.parent.Close()
+}
@@ -0,0 +1,183 @@
+package multiversion_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/store/dbadapter"
+	"github.com/cosmos/cosmos-sdk/store/multiversion"
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+func newParent() types.KVStore {
+	parent := dbadapter.Store{DB: dbm.NewMemDB()}
+	parent.Set([]byte("a"), []byte("parent-a"))
+	parent.Set([]byte("b"), []byte("parent-b"))
+	parent.Set([]byte("c"), []byte("parent-c"))
+
+	return parent
+}
+
+func iterate(it types.Iterator) (keys []string) {
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+
+	return keys
+}
+
+func TestVersionIndexedStoreReadsPrecedingWrites(t *testing.T) {
+	parent := newParent()
+	mvs := multiversion.NewStore(parent)
+
+	tx0 := multiversion.NewVersionIndexedStore(mvs, 0)
+	tx0.Set([]byte("a"), []byte("tx0-a"))
+	tx0.Delete([]byte("b"))
+	tx0.Publish()
+
+	tx2 := multiversion.NewVersionIndexedStore(mvs, 2)
+	tx2.Set([]byte("a"), []byte("tx2-a"))
+	tx2.Publish()
+
+	tx1 := multiversion.NewVersionIndexedStore(mvs, 1)
+	require.Equal(t, []byte("tx0-a"), tx1.Get([]byte("a")))
+	require.Nil(t, tx1.Get([]byte("b")))
+	require.False(t, tx1.Has([]byte("b")))
+	require.Equal(t, []byte("parent-c"), tx1.Get([]byte("c")))
+
+	tx1.Set([]byte("d"), []byte("tx1-d"))
+	require.Equal(t, []byte("tx1-d"), tx1.Get([]byte("d")))
+	require.Equal(t, []string{"a", "c", "d"}, iterate(tx1.Iterator(nil, nil)))
+	require.Equal(t, []string{"d", "c", "a"}, iterate(tx1.ReverseIterator(nil, nil)))
+	require.Equal(t, []string{"c"}, iterate(tx1.Iterator([]byte("b"), []byte("d"))))
+	require.True(t, tx1.Validate())
+
+	// the parent store is not written to
+	tx1.Publish()
+	require.Equal(t, []byte("parent-a"), parent.Get([]byte("a")))
+	require.False(t, parent.Has([]byte("d")))
+
+	tx3 := multiversion.NewVersionIndexedStore(mvs, 3)
+	require.Equal(t, []byte("tx2-a"), tx3.Get([]byte("a")))
+	require.Equal(t, []byte("tx1-d"), tx3.Get([]byte("d")))
+}
+
+func TestVersionIndexedStoreValidate(t *testing.T) {
+	mvs := multiversion.NewStore(newParent())
+
+	tx1 := multiversion.NewVersionIndexedStore(mvs, 1)
+	tx1.Get([]byte("a"))
+	require.True(t, tx1.Validate())
+
+	// a write to a key that was read invalidates the execution
+	tx0 := multiversion.NewVersionIndexedStore(mvs, 0)
+	tx0.Set([]byte("a"), []byte("tx0-a"))
+	tx0.Publish()
+	require.False(t, tx1.Validate())
+
+	// writing the same value does not
+	tx0 = multiversion.NewVersionIndexedStore(mvs, 0)
+	tx0.Set([]byte("a"), []byte("parent-a"))
+	tx0.Publish()
+	require.True(t, tx1.Validate())
+
+	// an empty value is distinct from an absent key
+	tx1 = multiversion.NewVersionIndexedStore(mvs, 1)
+	require.Nil(t, tx1.Get([]byte("e")))
+	tx0.Set([]byte("e"), []byte{})
+	tx0.Publish()
+	require.False(t, tx1.Validate())
+
+	// writes of succeeding txs are ignored
+	tx1 = multiversion.NewVersionIndexedStore(mvs, 1)
+	tx1.Get([]byte("b"))
+	tx2 := multiversion.NewVersionIndexedStore(mvs, 2)
+	tx2.Set([]byte("b"), []byte("tx2-b"))
+	tx2.Publish()
+	require.True(t, tx1.Validate())
+}
+
+func TestVersionIndexedStoreValidateIteration(t *testing.T) {
+	mvs := multiversion.NewStore(newParent())
+
+	tx2 := multiversion.NewVersionIndexedStore(mvs, 2)
+	it := tx2.Iterator(nil, nil)
+	require.Equal(t, "a", string(it.Key()))
+	it.Next()
+	it.Close()
+	require.True(t, tx2.Validate())
+
+	// a write beyond the observed items does not invalidate the iteration
+	tx0 := multiversion.NewVersionIndexedStore(mvs, 0)
+	tx0.Set([]byte("c"), []byte("tx0-c"))
+	tx0.Publish()
+	require.True(t, tx2.Validate())
+
+	// a new key within the observed items does
+	tx1 := multiversion.NewVersionIndexedStore(mvs, 1)
+	tx1.Set([]byte("aa"), []byte("tx1-aa"))
+	tx1.Publish()
+	require.False(t, tx2.Validate())
+
+	// an exhausted iteration observes new keys at its end
+	tx2 = multiversion.NewVersionIndexedStore(mvs, 2)
+	require.Equal(t, []string{"a", "aa", "b", "c"}, iterate(tx2.Iterator(nil, nil)))
+	require.True(t, tx2.Validate())
+
+	tx1.Set([]byte("z"), []byte("tx1-z"))
+	tx1.Publish()
+	require.False(t, tx2.Validate())
+
+	// the re-execution of a tx replaces its previous writes
+	tx1 = multiversion.NewVersionIndexedStore(mvs, 1)
+	tx1.Set([]byte("aa"), []byte("tx1-aa"))
+	tx1.Publish()
+	require.True(t, tx2.Validate())
+}
+
+func TestStoreWriteTo(t *testing.T) {
+	parent := newParent()
+	mvs := multiversion.NewStore(parent)
+
+	tx0 := multiversion.NewVersionIndexedStore(mvs, 0)
+	tx0.Set([]byte("a"), []byte("tx0-a"))
+	tx0.Delete([]byte("b"))
+	tx0.Publish()
+
+	tx1 := multiversion.NewVersionIndexedStore(mvs, 1)
+	tx1.Set([]byte("a"), []byte("tx1-a"))
+	tx1.Set([]byte("d"), []byte("tx1-d"))
+	tx1.Publish()
+
+	mvs.WriteTo(0, parent)
+	require.Equal(t, []byte("tx0-a"), parent.Get([]byte("a")))
+	require.False(t, parent.Has([]byte("b")))
+	require.False(t, parent.Has([]byte("d")))
+
+	mvs.WriteTo(1, parent)
+	require.Equal(t, []byte("tx1-a"), parent.Get([]byte("a")))
+	require.Equal(t, []byte("tx1-d"), parent.Get([]byte("d")))
+}
+
+func TestStoreSetParent(t *testing.T) {
+	mvs := multiversion.NewStore(newParent())
+
+	tx0 := multiversion.NewVersionIndexedStore(mvs, 0)
+	require.Equal(t, []byte("parent-a"), tx0.Get([]byte("a")))
+	require.Equal(t, []byte("parent-b"), tx0.Get([]byte("b")))
+	tx0.Publish()
+
+	// the executions are validated against the new parent
+	parent := newParent()
+	parent.Set([]byte("b"), []byte("new-b"))
+	mvs.SetParent(parent)
+	require.False(t, tx0.Validate())
+
+	tx0 = multiversion.NewVersionIndexedStore(mvs, 0)
+	require.Equal(t, []byte("new-b"), tx0.Get([]byte("b")))
+	require.True(t, tx0.Validate())
+}
@@ -0,0 +1,216 @@
+package multiversion
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/cosmos/cosmos-sdk/internal/conv"
+	"github.com/cosmos/cosmos-sdk/store/cachekv"
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+var _ types.KVStore = &VersionIndexedStore{}
+
+// VersionIndexedStore is the view of a multi-version Store of a single
+// execution of the transaction at the given index. Reads observe the writes
+// of the preceding transactions and are recorded, so that the execution can
+// be validated once the preceding transactions have been finalized. Writes
+// are buffered and published to the Store with Publish.
+//
+// A VersionIndexedStore is not safe for concurrent use.
+type VersionIndexedStore struct {
+	store *Store
+	index int
+
+	// readset holds the first value read of every key, nil if absent
+	readset map[string][]byte
+	// writeset holds the written value of every key, nil if deleted
+	writeset map[string][]byte
+	// iterations holds every iteration performed by the transaction
+	iterations []*iteration
+}
+
+// iteration records the domain of an iterator together with the writes of
+// the transaction it was created with and the items it observed.
+type iteration struct {
+	start, end []byte
+	ascending  bool
+	writeset   map[string][]byte
+
+	keys, values [][]byte
+	exhausted    bool
+}
+
+// NewVersionIndexedStore returns a new VersionIndexedStore for the transaction
+// at the given index.
+func NewVersionIndexedStore(store *Store, index int) *VersionIndexedStore {
+	return &VersionIndexedStore{
+		store:    store,
+		index:    index,
+		readset:  make(map[string][]byte),
+		writeset: make(map[string][]byte),
+	}
+}
+
+// GetStoreType implements the Store interface.
+func (s *VersionIndexedStore) GetStoreType() types.StoreType {
+	return s.store.parent.GetStoreType()
+}
+
+// Get implements the KVStore interface.
+func (s *VersionIndexedStore) Get(key []byte) []byte {
+	types.AssertValidKey(key)
+
+	keyStr := conv.UnsafeBytesToStr(key)
+	if value, ok := s.writeset[keyStr]; ok {
+		return value
+	}
+	if value, ok := s.readset[keyStr]; ok {
+		return value
+	}
+
+	value := s.store.get(s.index, key)
+	s.readset[string(key)] = value
+
+	return value
+}
+
+// Has implements the KVStore interface.
+func (s *VersionIndexedStore) Has(key []byte) bool {
+	return s.Get(key) != nil
+}
+
+// Set implements the KVStore interface.
+func (s *VersionIndexedStore) Set(key []byte, value []byte) {
+	types.AssertValidKey(key)
+	types.AssertValidValue(value)
+
+	s.writeset[string(key)] = append([]byte{}, value...)
+}
+
+// Delete implements the KVStore interface.
+func (s *VersionIndexedStore) Delete(key []byte) {
+	types.AssertValidKey(key)
+
+	s.writeset[string(key)] = nil
+}
+
+// Iterator implements the KVStore interface.
+func (s *VersionIndexedStore) Iterator(start, end []byte) types.Iterator {
+	return s.iterator(start, end, true)
+}
+
+// ReverseIterator implements the KVStore interface.
+func (s *VersionIndexedStore) ReverseIterator(start, end []byte) types.Iterator {
+	return s.iterator(start, end, false)
+}
+
+func (s *VersionIndexedStore) iterator(start, end []byte, ascending bool) types.Iterator {
+	writeset := make(map[string][]byte)
+	for key, value := range s.writeset {
+		if inDomain(key, start, end) {
+			writeset[key] = value
+		}
+	}
+
+	it := &iteration{
+		start:     start,
+		end:       end,
+		ascending: ascending,
+		writeset:  writeset,
+	}
+	s.iterations = append(s.iterations, it)
+
+	return newTrackedIterator(s.store.iterator(s.index, start, end, ascending, writeset), it)
+}
+
+// Publish makes the writes of the transaction visible to the succeeding
+// transactions, replacing the writes of any previous execution.
+func (s *VersionIndexedStore) Publish() {
+	s.store.SetWriteset(s.index, s.writeset)
+}
+
+// Validate returns true if every read and iteration of the transaction still
+// observes the same values, that is if executing the transaction again would
+// yield the same result.
+func (s *VersionIndexedStore) Validate() bool {
+	for key, value := range s.readset {
+		if !equal(s.store.get(s.index, []byte(key)), value) {
+			return false
+		}
+	}
+
+	for _, it := range s.iterations {
+		if !s.validateIteration(it) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *VersionIndexedStore) validateIteration(it *iteration) bool {
+	iter := s.store.iterator(s.index, it.start, it.end, it.ascending, it.writeset)
+	defer iter.Close()
+
+	for i := range it.keys {
+		if !iter.Valid() || !bytes.Equal(iter.Key(), it.keys[i]) || !bytes.Equal(iter.Value(), it.values[i]) {
+			return false
+		}
+		iter.Next()
+	}
+
+	return !it.exhausted || !iter.Valid()
+}
+
+// equal reports whether a and b are equal, treating a nil value, i.e. an
+// absent key, as distinct from an empty value.
+func equal(a, b []byte) bool {
+	return (a == nil) == (b == nil) && bytes.Equal(a, b)
+}
+
+// CacheWrap implements the CacheWrapper interface.
+func (s *VersionIndexedStore) CacheWrap() types.CacheWrap {
+	return cachekv.NewStore(s)
+}
+
+// CacheWrapWithTrace implements the CacheWrapper interface. Tracing is not
+// supported.
+func (s *VersionIndexedStore) CacheWrapWithTrace(_ io.Writer, _ types.TraceContext) types.CacheWrap {
+	return s.CacheWrap()
+}
+
+// CacheWrapWithListeners implements the CacheWrapper interface. Listening is
+// not supported.
+func (s *VersionIndexedStore) CacheWrapWithListeners(_ types.StoreKey, _ []types.WriteListener) types.CacheWrap {
+	return s.CacheWrap()
+}
+
+// trackedIterator records the items observed through an iterator.
+type trackedIterator struct {
+	types.Iterator
+	it *iteration
+}
+
+func newTrackedIterator(parent types.Iterator, it *iteration) types.Iterator {
+	ti := &trackedIterator{Iterator: parent, it: it}
+	ti.track()
+
+	return ti
+}
+
+// Next implements the Iterator interface.
+func (ti *trackedIterator) Next() {
+	ti.Iterator.Next()
+	ti.track()
+}
+
+func (ti *trackedIterator) track() {
+	if !ti.Iterator.Valid() {
+		ti.it.exhausted = true
+		return
+	}
+
+	ti.it.keys = append(ti.it.keys, ti.Iterator.Key())
+	ti.it.values = append(ti.it.values, ti.Iterator.Value())
+}
@@ -0,0 +1,143 @@
+package rootmulti
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/cosmos/cosmos-sdk/store/iavl"
+	"github.com/cosmos/cosmos-sdk/store/smt"
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+// KVDiff is a key whose value differs between two versions of a store. Before
+// is nil if the key was added, and After is nil if it was deleted.
+type KVDiff struct {
+	Key    []byte
+	Before []byte
+	After  []byte
+}
+
+// DiffVersions calls fn with every key which was added, changed or deleted
+// between two versions of the persisted stores, that is the IAVL and SMT
+// stores, or of the given stores only if any. The other stores are not read.
+// The stores are walked in the order of their names, and the keys of each
+// store in ascending order. An error is returned if a given store is not a
+// persisted store, or if any of the versions does not exist or has been
+// pruned.
+func (rs *Store) DiffVersions(from, to int64, storeNames []string, fn func(storeName string, diff KVDiff) error) error {
+	selected := make(map[string]bool, len(storeNames))
+	for _, name := range storeNames {
+		key, ok := rs.keysByName[name]
+		if !ok || !isPersisted(rs.stores[key].GetStoreType()) {
+			return fmt.Errorf("store %s does not exist or is not persisted", name)
+		}
+		selected[name] = true
+	}
+
+	names := make([]string, 0, len(rs.keysByName))
+	for name, key := range rs.keysByName {
+		if len(selected) > 0 && !selected[name] {
+			continue
+		}
+		if isPersisted(rs.stores[key].GetStoreType()) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := rs.keysByName[name]
+
+		before, err := rs.getStoreAtVersion(key, from)
+		if err != nil {
+			return err
+		}
+		after, err := rs.getStoreAtVersion(key, to)
+		if err != nil {
+			return err
+		}
+
+		err = diffStores(before, after, func(diff KVDiff) error {
+			return fn(name, diff)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isPersisted returns whether the stores of a type are versioned and can be
+// diffed.
+func isPersisted(storeType types.StoreType) bool {
+	return storeType == types.StoreTypeIAVL || storeType == types.StoreTypeSMT
+}
+
+// getStoreAtVersion returns a read-only view of a persisted store at the given
+// version.
+func (rs *Store) getStoreAtVersion(key types.StoreKey, version int64) (types.KVStore, error) {
+	switch store := rs.GetCommitKVStore(key).(type) {
+	case *iavl.Store:
+		// GetImmutable falls back to an empty tree for missing versions
+		if !store.VersionExists(version) {
+			return nil, fmt.Errorf("version %d of store %s does not exist or has been pruned", version, key.Name())
+		}
+
+		return store.GetImmutable(version)
+
+	case *smt.Store:
+		view, err := store.GetImmutable(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load version %d of store %s: %w", version, key.Name(), err)
+		}
+
+		return view, nil
+
+	default:
+		return nil, fmt.Errorf("store %s of type %v is not versioned", key.Name(), store.GetStoreType())
+	}
+}
+
+// diffStores merges the iterators of both stores and calls fn with each key
+// whose value differs.
+func diffStores(before, after types.KVStore, fn func(KVDiff) error) error {
+	itBefore := before.Iterator(nil, nil)
+	defer itBefore.Close()
+	itAfter := after.Iterator(nil, nil)
+	defer itAfter.Close()
+
+	for itBefore.Valid() || itAfter.Valid() {
+		cmp := -1
+		switch {
+		case !itBefore.Valid():
+			cmp = 1
+		case itAfter.Valid():
+			cmp = bytes.Compare(itBefore.Key(), itAfter.Key())
+		}
+
+		var err error
+		switch {
+		case cmp < 0:
+			err = fn(KVDiff{Key: itBefore.Key(), Before: itBefore.Value()})
+			itBefore.Next()
+
+		case cmp > 0:
+			err = fn(KVDiff{Key: itAfter.Key(), After: itAfter.Value()})
+			itAfter.Next()
+
+		default:
+			if !bytes.Equal(itBefore.Value(), itAfter.Value()) {
+				err = fn(KVDiff{Key: itBefore.Key(), Before: itBefore.Value(), After: itAfter.Value()})
+			}
+			itBefore.Next()
+			itAfter.Next()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
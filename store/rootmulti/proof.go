@@ -1,18 +1,26 @@
 package rootmulti
 
 import (
+	"bytes"
+	"sort"
+
+	ics23 "github.com/confio/ics23/go"
 	"github.com/tendermint/tendermint/crypto/merkle"
+	tmcrypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
 
 	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/kv"
 )
 
 // RequireProof returns whether proof is required for the subpath.
 func RequireProof(subpath string) bool {
 	// XXX: create a better convention.
-	// Currently, only when query subpath is "/key", will proof be included in
-	// response. If there are some changes about proof building in iavlstore.go,
-	// we must change code here to keep consistency with iavlStore#Query.
-	return subpath == "/key"
+	// Currently, only when query subpath is "/key", "/keys" or "/range", will
+	// proof be included in response. If there are some changes about proof
+	// building in iavlstore.go, we must change code here to keep consistency
+	// with iavlStore#Query.
+	return subpath == "/key" || subpath == "/keys" || subpath == "/range"
 }
 
 //-----------------------------------------------------------------------------
@@ -23,5 +31,198 @@ func DefaultProofRuntime() (prt *merkle.ProofRuntime) {
 	prt = merkle.NewProofRuntime()
 	prt.RegisterOpDecoder(storetypes.ProofOpIAVLCommitment, storetypes.CommitmentOpDecoder)
 	prt.RegisterOpDecoder(storetypes.ProofOpSimpleMerkleCommitment, storetypes.CommitmentOpDecoder)
+	prt.RegisterOpDecoder(storetypes.ProofOpSMTCommitment, storetypes.CommitmentOpDecoder)
 	return
 }
+
+//-----------------------------------------------------------------------------
+
+// KeysQueryData returns the data of a "/keys" query of the given keys, which
+// returns the keys which exist with a single batch proof.
+func KeysQueryData(keys [][]byte) ([]byte, error) {
+	pairs := kv.Pairs{Pairs: make([]kv.Pair, len(keys))}
+	for i, key := range keys {
+		pairs.Pairs[i].Key = key
+	}
+
+	return pairs.Marshal()
+}
+
+// RangeQueryData returns the data of a "/range" query of the keys from start
+// (inclusive) to end (exclusive), which returns the keys within the range with
+// a single batch proof. A nil end means the end of the store.
+func RangeQueryData(start, end []byte) ([]byte, error) {
+	pair := kv.Pair{Key: start, Value: end}
+	return pair.Marshal()
+}
+
+// VerifyKeysProof verifies the proof of a "/keys" query of the given store
+// against the app hash. The pairs are the value of the response: the keys
+// which exist with their values. All the other keys must be proven absent.
+func VerifyKeysProof(proofOps *tmcrypto.ProofOps, appHash []byte, storeName string, keys [][]byte, pairs kv.Pairs) error {
+	proof, root, err := verifyBatchProofOps(proofOps, appHash, storeName)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string][]byte, len(pairs.Pairs))
+	for _, pair := range pairs.Pairs {
+		values[string(pair.Key)] = pair.Value
+	}
+
+	requested := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		requested[string(key)] = true
+		if value, ok := values[string(key)]; ok {
+			if !ics23.VerifyMembership(ics23.IavlSpec, root, proof, key, value) {
+				return sdkerrors.Wrapf(storetypes.ErrInvalidProof, "invalid proof of key %X", key)
+			}
+		} else if !ics23.VerifyNonMembership(ics23.IavlSpec, root, proof, key) {
+			return sdkerrors.Wrapf(storetypes.ErrInvalidProof, "invalid proof of the absence of key %X", key)
+		}
+	}
+	for key := range values {
+		if !requested[key] {
+			return sdkerrors.Wrapf(storetypes.ErrInvalidProof, "unexpected key %X", key)
+		}
+	}
+
+	return nil
+}
+
+// VerifyRangeProof verifies the proof of a "/range" query of the given store
+// against the app hash. The pairs are the value of the response, which must be
+// all the keys from start (inclusive) to end (exclusive) with their values. A
+// nil end means the end of the store.
+//
+// The proof proves that there is no other key within the range with a chain
+// of adjacent keys, which starts at the start of the range, before it, or at
+// the left-most key of the store, and ends after the range, or at its
+// right-most key.
+func VerifyRangeProof(proofOps *tmcrypto.ProofOps, appHash []byte, storeName string, start, end []byte, pairs kv.Pairs) error {
+	proof, root, err := verifyBatchProofOps(proofOps, appHash, storeName)
+	if err != nil {
+		return err
+	}
+	spec := ics23.IavlSpec
+
+	// collect the leaves proven by the batch
+	leaves := make(map[string]*ics23.ExistenceProof)
+	for _, entry := range proof.GetBatch().GetEntries() {
+		if exist := entry.GetExist(); exist != nil {
+			if err := exist.Verify(spec, root, exist.Key, exist.Value); err != nil {
+				return sdkerrors.Wrap(storetypes.ErrInvalidProof, err.Error())
+			}
+			leaves[string(exist.Key)] = exist
+		} else if nonexist := entry.GetNonexist(); nonexist != nil {
+			if err := nonexist.Verify(spec, root, nonexist.Key); err != nil {
+				return sdkerrors.Wrap(storetypes.ErrInvalidProof, err.Error())
+			}
+			for _, neighbor := range []*ics23.ExistenceProof{nonexist.Left, nonexist.Right} {
+				if neighbor != nil {
+					leaves[string(neighbor.Key)] = neighbor
+				}
+			}
+		}
+	}
+	if len(leaves) == 0 {
+		return sdkerrors.Wrap(storetypes.ErrInvalidProof, "proof does not contain any key")
+	}
+
+	sorted := make([]*ics23.ExistenceProof, 0, len(leaves))
+	for _, leaf := range leaves {
+		sorted = append(sorted, leaf)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Key, sorted[j].Key) < 0
+	})
+
+	// the chain starts at the start of the range, at the last key before it, or
+	// at the left-most key
+	first := sort.Search(len(sorted), func(i int) bool {
+		return bytes.Compare(sorted[i].Key, start) >= 0
+	})
+	switch {
+	case first < len(sorted) && bytes.Equal(sorted[first].Key, start) && start != nil:
+	case first > 0:
+		first--
+	case !ics23.IsLeftMost(spec.InnerSpec, sorted[first].Path):
+		return sdkerrors.Wrap(storetypes.ErrInvalidProof, "proof does not cover the start of the range")
+	}
+
+	var inRange []kv.Pair
+	for i := first; ; i++ {
+		leaf := sorted[i]
+		if end != nil && bytes.Compare(leaf.Key, end) >= 0 {
+			break
+		}
+		if bytes.Compare(leaf.Key, start) >= 0 {
+			inRange = append(inRange, kv.Pair{Key: leaf.Key, Value: leaf.Value})
+		}
+
+		if i+1 == len(sorted) {
+			if !ics23.IsRightMost(spec.InnerSpec, leaf.Path) {
+				return sdkerrors.Wrap(storetypes.ErrInvalidProof, "proof does not cover the end of the range")
+			}
+			break
+		}
+		if !ics23.IsLeftNeighbor(spec.InnerSpec, leaf.Path, sorted[i+1].Path) {
+			return sdkerrors.Wrapf(storetypes.ErrInvalidProof, "keys %X and %X are not adjacent", leaf.Key, sorted[i+1].Key)
+		}
+	}
+
+	if len(inRange) != len(pairs.Pairs) {
+		return sdkerrors.Wrapf(storetypes.ErrInvalidProof, "expected %d keys within the range, got %d", len(inRange), len(pairs.Pairs))
+	}
+	for i, pair := range pairs.Pairs {
+		if !bytes.Equal(pair.Key, inRange[i].Key) || !bytes.Equal(pair.Value, inRange[i].Value) {
+			return sdkerrors.Wrapf(storetypes.ErrInvalidProof, "unexpected key %X", pair.Key)
+		}
+	}
+
+	return nil
+}
+
+// verifyBatchProofOps verifies that the batch proof of a store, i.e. the first
+// proof op, commits to the app hash through the commit info proof, i.e. the
+// second proof op. It returns the decompressed batch proof and the root of the
+// store.
+func verifyBatchProofOps(proofOps *tmcrypto.ProofOps, appHash []byte, storeName string) (*ics23.CommitmentProof, []byte, error) {
+	if proofOps == nil || len(proofOps.Ops) != 2 {
+		return nil, nil, sdkerrors.Wrap(storetypes.ErrInvalidProof, "expected a store proof and a commit info proof")
+	}
+
+	op, err := storetypes.CommitmentOpDecoder(proofOps.Ops[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	storeOp, ok := op.(storetypes.CommitmentOp)
+	if !ok || storeOp.Type != storetypes.ProofOpIAVLCommitment {
+		return nil, nil, sdkerrors.Wrapf(storetypes.ErrInvalidProof, "unexpected store proof type %s", proofOps.Ops[0].Type)
+	}
+	proof := ics23.Decompress(storeOp.Proof)
+	if proof.GetBatch() == nil {
+		return nil, nil, sdkerrors.Wrap(storetypes.ErrInvalidProof, "store proof is not a batch proof")
+	}
+	root, err := proof.Calculate()
+	if err != nil {
+		return nil, nil, sdkerrors.Wrap(storetypes.ErrInvalidProof, err.Error())
+	}
+
+	commitOp, err := storetypes.CommitmentOpDecoder(proofOps.Ops[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(commitOp.GetKey(), []byte(storeName)) {
+		return nil, nil, sdkerrors.Wrapf(storetypes.ErrInvalidProof, "commit info proof is for store %q, not %q", commitOp.GetKey(), storeName)
+	}
+	hashes, err := commitOp.Run([][]byte{root})
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(hashes[0], appHash) {
+		return nil, nil, sdkerrors.Wrapf(storetypes.ErrInvalidProof, "proof commits to app hash %X, not %X", hashes[0], appHash)
+	}
+
+	return proof, root, nil
+}
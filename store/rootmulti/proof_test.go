@@ -1,14 +1,22 @@
 package rootmulti
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sort"
 	"testing"
 
+	ics23 "github.com/confio/ics23/go"
 	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	tmcrypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
 	dbm "github.com/tendermint/tm-db"
 
 	"github.com/cosmos/cosmos-sdk/store/iavl"
 	"github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/types/kv"
 )
 
 func TestVerifyIAVLStoreQueryProof(t *testing.T) {
@@ -146,3 +154,278 @@ func TestVerifyMultiStoreQueryProofAbsence(t *testing.T) {
 	err = prt.VerifyValue(res.ProofOps, cid.Hash, "/iavlStoreKey/MYABSENTKEY", []byte(""))
 	require.NotNil(t, err)
 }
+
+func TestVerifyMultiStoreQueryProofSMT(t *testing.T) {
+	db := dbm.NewMemDB()
+	store := NewStore(db)
+	smtStoreKey := types.NewKVStoreKey("smtStoreKey")
+
+	store.MountStoreWithDB(smtStoreKey, types.StoreTypeSMT, nil)
+	store.MountStoreWithDB(types.NewKVStoreKey("iavlStoreKey"), types.StoreTypeIAVL, nil)
+	require.NoError(t, store.LoadVersion(0))
+
+	smtStore := store.GetCommitKVStore(smtStoreKey)
+	smtStore.Set([]byte("MYKEY"), []byte("MYVALUE"))
+	smtStore.Set([]byte("OTHERKEY"), []byte("OTHERVALUE"))
+	cid := store.Commit()
+
+	prt := DefaultProofRuntime()
+
+	res := store.Query(abci.RequestQuery{
+		Path:  "/smtStoreKey/key",
+		Data:  []byte("MYKEY"),
+		Prove: true,
+	})
+	require.NotNil(t, res.ProofOps)
+	require.NoError(t, prt.VerifyValue(res.ProofOps, cid.Hash, smtKeyPath("smtStoreKey", "MYKEY"), []byte("MYVALUE")))
+	require.Error(t, prt.VerifyValue(res.ProofOps, cid.Hash, smtKeyPath("smtStoreKey", "MYKEY"), []byte("MYVALUE_NOT")))
+	require.Error(t, prt.VerifyValue(res.ProofOps, cid.Hash, smtKeyPath("iavlStoreKey", "MYKEY"), []byte("MYVALUE")))
+	// the proofs are keyed by the hash of the key
+	require.Error(t, prt.VerifyValue(res.ProofOps, cid.Hash, "/smtStoreKey/MYKEY", []byte("MYVALUE")))
+
+	res = store.Query(abci.RequestQuery{
+		Path:  "/smtStoreKey/key",
+		Data:  []byte("MYABSENTKEY"),
+		Prove: true,
+	})
+	require.NotNil(t, res.ProofOps)
+	require.NoError(t, prt.VerifyAbsence(res.ProofOps, cid.Hash, smtKeyPath("smtStoreKey", "MYABSENTKEY")))
+	require.Error(t, prt.VerifyAbsence(res.ProofOps, cid.Hash, smtKeyPath("smtStoreKey", "MYKEY")))
+	require.Error(t, prt.VerifyValue(res.ProofOps, cid.Hash, smtKeyPath("smtStoreKey", "MYABSENTKEY"), []byte("")))
+}
+
+func TestVerifyMultiStoreQueryProofSMTNonExistence(t *testing.T) {
+	store := NewStore(dbm.NewMemDB())
+	smtStoreKey := types.NewKVStoreKey("smtStoreKey")
+	store.MountStoreWithDB(smtStoreKey, types.StoreTypeSMT, nil)
+	require.NoError(t, store.LoadVersion(0))
+
+	// enough keys for the neighbors of the absent keys to be several levels
+	// deep, in other subtrees than the absent keys
+	smtStore := store.GetCommitKVStore(smtStoreKey)
+	keys := make(map[string]string) // by path
+	paths := make([][]byte, 0, 200)
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("key%03d", i)
+		smtStore.Set([]byte(key), []byte(fmt.Sprintf("value%03d", i)))
+		path := sha256.Sum256([]byte(key))
+		keys[string(path[:])] = key
+		paths = append(paths, path[:])
+	}
+	sort.Slice(paths, func(i, j int) bool { return bytes.Compare(paths[i], paths[j]) < 0 })
+	cid := store.Commit()
+	prt := DefaultProofRuntime()
+
+	query := func(key string) (*tmcrypto.ProofOps, *ics23.CommitmentProof, []byte) {
+		res := store.Query(abci.RequestQuery{Path: "/smtStoreKey/key", Data: []byte(key), Prove: true})
+		require.Zero(t, res.Code, res.Log)
+		require.Len(t, res.ProofOps.Ops, 2)
+
+		op, err := types.CommitmentOpDecoder(res.ProofOps.Ops[0])
+		require.NoError(t, err)
+		proof := op.(types.CommitmentOp).Proof
+		root, err := proof.Calculate()
+		require.NoError(t, err)
+		return res.ProofOps, proof, root
+	}
+	verifyNonexist := func(nonexist *ics23.NonExistenceProof, root, path []byte) bool {
+		return ics23.VerifyNonMembership(ics23.SmtSpec, root, &ics23.CommitmentProof{
+			Proof: &ics23.CommitmentProof_Nonexist{Nonexist: nonexist},
+		}, path)
+	}
+
+	// absent keys between two existing keys, and before the first and after
+	// the last existing key
+	var (
+		absentKeys  []string
+		first, last string
+	)
+	for i := 0; len(absentKeys) < 50 || first == "" || last == ""; i++ {
+		key := fmt.Sprintf("absent%d", i)
+		path := sha256.Sum256([]byte(key))
+		switch {
+		case bytes.Compare(path[:], paths[0]) < 0:
+			if first == "" {
+				first = key
+			}
+		case bytes.Compare(path[:], paths[len(paths)-1]) > 0:
+			if last == "" {
+				last = key
+			}
+		case len(absentKeys) < 50:
+			absentKeys = append(absentKeys, key)
+		}
+	}
+	absentKeys = append(absentKeys, first, last)
+
+	for _, key := range absentKeys {
+		path := sha256.Sum256([]byte(key))
+		proofOps, proof, root := query(key)
+		require.NoError(t, prt.VerifyAbsence(proofOps, cid.Hash, smtKeyPath("smtStoreKey", key)), key)
+		require.Error(t, prt.VerifyAbsence(proofOps, cid.Hash, smtKeyPath("smtStoreKey", "key000")), key)
+
+		// the proofs are verified by the stock ics23 functions
+		require.True(t, ics23.VerifyNonMembership(ics23.SmtSpec, root, proof, path[:]), key)
+		nonexist := proof.GetNonexist()
+		require.NotNil(t, nonexist)
+
+		idx := sort.Search(len(paths), func(i int) bool { return bytes.Compare(paths[i], path[:]) > 0 })
+		switch key {
+		case first:
+			require.Nil(t, nonexist.Left)
+			require.Equal(t, paths[0], nonexist.Right.Key)
+		case last:
+			require.Nil(t, nonexist.Right)
+			require.Equal(t, paths[len(paths)-1], nonexist.Left.Key)
+		default:
+			require.Equal(t, paths[idx-1], nonexist.Left.Key)
+			require.Equal(t, paths[idx], nonexist.Right.Key)
+			require.Greater(t, len(nonexist.Left.Path), 1)
+			require.Greater(t, len(nonexist.Right.Path), 1)
+
+			// dropping a neighbor which is not at an edge of the tree
+			forged := *nonexist
+			forged.Left = nil
+			require.False(t, verifyNonexist(&forged, root, path[:]), key)
+			forged = *nonexist
+			forged.Right = nil
+			require.False(t, verifyNonexist(&forged, root, path[:]), key)
+		}
+
+		// neighbors replaced by other existing leaves, which are not adjacent
+		if idx >= 2 {
+			_, other, _ := query(keys[string(paths[idx-2])])
+			forged := *nonexist
+			forged.Left = other.GetExist()
+			require.False(t, verifyNonexist(&forged, root, path[:]), key)
+
+			// through the proof runtime as well
+			forgedOps := &tmcrypto.ProofOps{Ops: []tmcrypto.ProofOp{
+				types.NewSmtCommitmentOp(path[:], &ics23.CommitmentProof{
+					Proof: &ics23.CommitmentProof_Nonexist{Nonexist: &forged},
+				}).ProofOp(),
+				proofOps.Ops[1],
+			}}
+			require.Error(t, prt.VerifyAbsence(forgedOps, cid.Hash, smtKeyPath("smtStoreKey", key)), key)
+		}
+		if idx+1 < len(paths) {
+			_, other, _ := query(keys[string(paths[idx+1])])
+			forged := *nonexist
+			forged.Right = other.GetExist()
+			require.False(t, verifyNonexist(&forged, root, path[:]), key)
+		}
+	}
+}
+
+// smtKeyPath returns the key path of the proof of a key of an SMT store, which
+// is keyed by the hash of the key.
+func smtKeyPath(storeName, key string) string {
+	path := sha256.Sum256([]byte(key))
+	return merkle.KeyPath{}.
+		AppendKey([]byte(storeName), merkle.KeyEncodingURL).
+		AppendKey(path[:], merkle.KeyEncodingHex).
+		String()
+}
+
+func newBatchProofStore(t *testing.T) (*Store, types.CommitID) {
+	store := NewStore(dbm.NewMemDB())
+	iavlStoreKey := types.NewKVStoreKey("iavlStoreKey")
+	store.MountStoreWithDB(iavlStoreKey, types.StoreTypeIAVL, nil)
+	require.NoError(t, store.LoadVersion(0))
+
+	iavlStore := store.GetCommitKVStore(iavlStoreKey)
+	for i := 0; i < 100; i += 2 {
+		iavlStore.Set([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("value%03d", i)))
+	}
+	store.Commit()
+	iavlStore.Set([]byte("key100"), []byte("value100"))
+
+	return store, store.Commit()
+}
+
+func queryBatchProof(t *testing.T, store *Store, path string, data []byte) (abci.ResponseQuery, kv.Pairs) {
+	res := store.Query(abci.RequestQuery{Path: path, Data: data, Height: 2, Prove: true})
+	require.Zero(t, res.Code, res.Log)
+	require.NotNil(t, res.ProofOps)
+
+	var pairs kv.Pairs
+	require.NoError(t, pairs.Unmarshal(res.Value))
+	return res, pairs
+}
+
+func TestVerifyMultiStoreKeysProof(t *testing.T) {
+	store, cid := newBatchProofStore(t)
+
+	keys := [][]byte{[]byte("key010"), []byte("key011"), []byte("key100"), []byte("absent")}
+	data, err := KeysQueryData(keys)
+	require.NoError(t, err)
+	res, pairs := queryBatchProof(t, store, "/iavlStoreKey/keys", data)
+	require.Equal(t, []kv.Pair{
+		{Key: []byte("key010"), Value: []byte("value010")},
+		{Key: []byte("key100"), Value: []byte("value100")},
+	}, pairs.Pairs)
+
+	require.NoError(t, VerifyKeysProof(res.ProofOps, cid.Hash, "iavlStoreKey", keys, pairs))
+
+	// Verify (bad) proofs.
+	require.Error(t, VerifyKeysProof(res.ProofOps, cid.Hash, "otherStoreKey", keys, pairs))
+	require.Error(t, VerifyKeysProof(res.ProofOps, []byte("badhash"), "iavlStoreKey", keys, pairs))
+	require.Error(t, VerifyKeysProof(res.ProofOps, cid.Hash, "iavlStoreKey", keys, kv.Pairs{Pairs: pairs.Pairs[:1]}))
+	require.Error(t, VerifyKeysProof(res.ProofOps, cid.Hash, "iavlStoreKey", keys[:1], pairs))
+	require.Error(t, VerifyKeysProof(res.ProofOps, cid.Hash, "iavlStoreKey", append(keys, []byte("key012")), pairs))
+
+	tampered := kv.Pairs{Pairs: []kv.Pair{pairs.Pairs[0], {Key: []byte("key100"), Value: []byte("other")}}}
+	require.Error(t, VerifyKeysProof(res.ProofOps, cid.Hash, "iavlStoreKey", keys, tampered))
+}
+
+func TestVerifyMultiStoreRangeProof(t *testing.T) {
+	store, cid := newBatchProofStore(t)
+
+	testCases := []struct {
+		name       string
+		start, end []byte
+		keys       int
+	}{
+		{"existing start and end", []byte("key010"), []byte("key020"), 5},
+		{"absent start and end", []byte("key011"), []byte("key021"), 5},
+		{"empty range", []byte("key011"), []byte("key012"), 0},
+		{"start of the store", []byte("a"), []byte("key004"), 2},
+		{"end of the store", []byte("key095"), nil, 3},
+		{"whole store", nil, nil, 51},
+		{"after the store", []byte("z"), nil, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := RangeQueryData(tc.start, tc.end)
+			require.NoError(t, err)
+			res, pairs := queryBatchProof(t, store, "/iavlStoreKey/range", data)
+			require.Len(t, pairs.Pairs, tc.keys)
+
+			require.NoError(t, VerifyRangeProof(res.ProofOps, cid.Hash, "iavlStoreKey", tc.start, tc.end, pairs))
+			require.Error(t, VerifyRangeProof(res.ProofOps, []byte("badhash"), "iavlStoreKey", tc.start, tc.end, pairs))
+			if tc.keys > 1 {
+				// a key within the range is omitted
+				omitted := kv.Pairs{Pairs: append([]kv.Pair{pairs.Pairs[0]}, pairs.Pairs[2:]...)}
+				require.Error(t, VerifyRangeProof(res.ProofOps, cid.Hash, "iavlStoreKey", tc.start, tc.end, omitted))
+			}
+		})
+	}
+
+	// the proof of a range does not prove a wider range
+	data, err := RangeQueryData([]byte("key010"), []byte("key020"))
+	require.NoError(t, err)
+	res, pairs := queryBatchProof(t, store, "/iavlStoreKey/range", data)
+	require.Error(t, VerifyRangeProof(res.ProofOps, cid.Hash, "iavlStoreKey", []byte("key010"), []byte("key030"), pairs))
+	require.Error(t, VerifyRangeProof(res.ProofOps, cid.Hash, "iavlStoreKey", []byte("key000"), []byte("key020"), pairs))
+
+	// the number of keys within the range is bounded
+	iavlStore := store.getStoreByName("iavlStoreKey").(types.KVStore)
+	for i := 0; i <= iavl.MaxBatchProofKeys; i++ {
+		iavlStore.Set([]byte(fmt.Sprintf("many%04d", i)), []byte{1})
+	}
+	store.Commit()
+	data, err = RangeQueryData([]byte("many"), nil)
+	require.NoError(t, err)
+	res = store.Query(abci.RequestQuery{Path: "/iavlStoreKey/range", Data: data, Height: 3, Prove: true})
+	require.NotZero(t, res.Code)
+}
@@ -0,0 +1,178 @@
+package rootmulti
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+// AsyncPruningOptions defines the pruning of a Store in a background goroutine.
+type AsyncPruningOptions struct {
+	// MaxPending is the maximum number of heights waiting to be pruned. Commit
+	// blocks while the backlog is full.
+	MaxPending uint64
+
+	// Delay is the pause between the pruning of two heights, which limits the
+	// load that pruning puts on the database.
+	Delay time.Duration
+}
+
+// DefaultAsyncPruningOptions returns the default options of background pruning.
+func DefaultAsyncPruningOptions() AsyncPruningOptions {
+	return AsyncPruningOptions{
+		MaxPending: 1000,
+	}
+}
+
+// Validate validates the background pruning options.
+func (opts AsyncPruningOptions) Validate() error {
+	if opts.MaxPending == 0 {
+		return errors.New("the maximum number of pending heights must be positive")
+	}
+	if opts.Delay < 0 {
+		return errors.New("the delay between pruned heights must not be negative")
+	}
+
+	return nil
+}
+
+// pruner prunes the heights of a Store in a background goroutine. The heights
+// are pruned one at a time while holding the lock of the store, so that a
+// commit waits at most for the pruning of a single height.
+type pruner struct {
+	opts AsyncPruningOptions
+
+	// cond is bound to the lock of the store, which guards the fields below.
+	// It is signalled whenever heights are added to or removed from the
+	// backlog, and when the pruner is stopped.
+	cond    *sync.Cond
+	pending []int64
+	stopped bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// SetAsyncPruning enables the pruning of the store in a background goroutine.
+// Instead of pruning the stores when the pruning interval is reached, Commit
+// hands the heights to be pruned over to the goroutine. It must be called at
+// most once, and the store must be closed once it is no longer used.
+func (rs *Store) SetAsyncPruning(opts AsyncPruningOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	rs.mtx.Lock()
+	defer rs.mtx.Unlock()
+	if rs.pruner != nil {
+		return errors.New("async pruning is already enabled")
+	}
+
+	rs.pruner = &pruner{
+		opts: opts,
+		cond: sync.NewCond(&rs.mtx),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go rs.runPruner(rs.pruner)
+
+	return nil
+}
+
+// Close stops the background pruning, if enabled. The pruning of the current
+// height is completed, and the heights still waiting to be pruned are
+// persisted, so that they are pruned once the store is loaded again.
+func (rs *Store) Close() error {
+	rs.mtx.Lock()
+	p := rs.pruner
+	if p == nil || p.stopped {
+		rs.mtx.Unlock()
+		return nil
+	}
+	p.stopped = true
+	close(p.stop)
+	p.cond.Broadcast()
+	rs.mtx.Unlock()
+
+	<-p.done
+
+	rs.mtx.Lock()
+	defer rs.mtx.Unlock()
+
+	batch := rs.db.NewBatch()
+	defer batch.Close()
+	setPruningHeights(batch, rs.allPruneHeights())
+
+	return batch.WriteSync()
+}
+
+// runPruner prunes the heights of the backlog of the pruner until it is
+// stopped.
+func (rs *Store) runPruner(p *pruner) {
+	defer close(p.done)
+
+	rs.mtx.Lock()
+	defer rs.mtx.Unlock()
+
+	for {
+		for len(p.pending) == 0 && !p.stopped {
+			p.cond.Wait()
+		}
+		if p.stopped {
+			return
+		}
+
+		height := p.pending[0]
+		p.pending = p.pending[1:]
+		start := time.Now()
+		rs.deleteVersions(height)
+		telemetry.MeasureSince(start, "store", "rootmulti", "prune")
+		telemetry.SetGauge(float32(len(p.pending)), "store", "rootmulti", "prune", "pending")
+		p.cond.Broadcast()
+
+		// release the lock between two heights, so that commits can proceed
+		rs.mtx.Unlock()
+		if p.opts.Delay > 0 {
+			select {
+			case <-time.After(p.opts.Delay):
+			case <-p.stop:
+			}
+		}
+		rs.mtx.Lock()
+	}
+}
+
+// schedulePruning hands the heights to be pruned over to the background
+// pruner, waiting while its backlog is full. If the pruner has been stopped,
+// the heights are pruned synchronously instead. It must be called with the
+// lock of the store held.
+func (rs *Store) schedulePruning() {
+	p := rs.pruner
+	for !p.stopped && len(p.pending) > 0 && uint64(len(p.pending)+len(rs.pruneHeights)) > p.opts.MaxPending {
+		p.cond.Wait()
+	}
+	if p.stopped {
+		rs.pruneStores()
+		return
+	}
+
+	p.pending = append(p.pending, rs.pruneHeights...)
+	rs.pruneHeights = make([]int64, 0)
+	telemetry.SetGauge(float32(len(p.pending)), "store", "rootmulti", "prune", "pending")
+	p.cond.Broadcast()
+}
+
+// allPruneHeights returns the heights which have not been pruned yet, whether
+// they are waiting for the next pruning interval or in the backlog of the
+// background pruner. It must be called with the lock of the store held.
+func (rs *Store) allPruneHeights() []int64 {
+	if rs.pruner == nil || len(rs.pruner.pending) == 0 {
+		return rs.pruneHeights
+	}
+
+	heights := make([]int64, 0, len(rs.pruner.pending)+len(rs.pruneHeights))
+	heights = append(heights, rs.pruner.pending...)
+	return append(heights, rs.pruneHeights...)
+}
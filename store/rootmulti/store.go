@@ -1,14 +1,14 @@
 package rootmulti
 
 import (
-	"bufio"
-	"compress/zlib"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"math"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	iavltree "github.com/cosmos/iavl"
 	protoio "github.com/gogo/protobuf/io"
@@ -17,16 +17,17 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	dbm "github.com/tendermint/tm-db"
 
-	"github.com/cosmos/cosmos-sdk/snapshots"
 	snapshottypes "github.com/cosmos/cosmos-sdk/snapshots/types"
 	"github.com/cosmos/cosmos-sdk/store/cachemulti"
 	"github.com/cosmos/cosmos-sdk/store/dbadapter"
 	"github.com/cosmos/cosmos-sdk/store/iavl"
 	"github.com/cosmos/cosmos-sdk/store/listenkv"
 	"github.com/cosmos/cosmos-sdk/store/mem"
+	"github.com/cosmos/cosmos-sdk/store/smt"
 	"github.com/cosmos/cosmos-sdk/store/tracekv"
 	"github.com/cosmos/cosmos-sdk/store/transient"
 	"github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 )
 
@@ -34,11 +35,6 @@ const (
 	latestVersionKey = "s/latest"
 	pruneHeightsKey  = "s/pruneheights"
 	commitInfoKeyFmt = "s/%d" // s/<version>
-
-	// Do not change chunk size without new snapshot format (must be uniform across nodes)
-	snapshotChunkSize   = uint64(10e6)
-	snapshotBufferSize  = int(snapshotChunkSize)
-	snapshotMaxItemSize = int(64e6) // SDK has no key/value size limit, so we set an arbitrary limit
 )
 
 // Store is composed of many CommitStores. Name contrasts with
@@ -55,12 +51,22 @@ type Store struct {
 	pruneHeights   []int64
 	initialVersion int64
 
+	// mtx serializes the writes of Commit and of the background pruner to the
+	// underlying stores.
+	mtx    sync.Mutex
+	pruner *pruner
+
 	traceWriter  io.Writer
 	traceContext types.TraceContext
 
 	interBlockCache types.MultiStorePersistentCache
 
 	listeners map[types.StoreKey][]types.WriteListener
+
+	// gasConfigs is replaced rather than modified, so that it can be shared
+	// with the branches of the store.
+	gasConfigsMtx sync.RWMutex
+	gasConfigs    map[types.StoreKey]types.GasConfig
 }
 
 var (
@@ -307,17 +313,27 @@ func (rs *Store) SetInterBlockCache(c types.MultiStorePersistentCache) {
 // stores will utilize to trace operations. A MultiStore is returned.
 func (rs *Store) SetTracer(w io.Writer) types.MultiStore {
 	rs.traceWriter = w
+	// the branches share the tracing context, which must then exist before
+	// the first branch is created for later updates to reach them
+	if w != nil && rs.traceContext == nil {
+		rs.traceContext = make(types.TraceContext)
+	}
 	return rs
 }
 
 // SetTracingContext updates the tracing context for the MultiStore by merging
 // the given context with the existing context by key. Any existing keys will
-// be overwritten. It is implied that the caller should update the context when
-// necessary between tracing operations. It returns a modified MultiStore.
+// be overwritten, and the keys with a nil value removed. It is implied that the
+// caller should update the context when necessary between tracing operations.
+// It returns a modified MultiStore.
 func (rs *Store) SetTracingContext(tc types.TraceContext) types.MultiStore {
 	if rs.traceContext != nil {
 		for k, v := range tc {
-			rs.traceContext[k] = v
+			if v == nil {
+				delete(rs.traceContext, k)
+			} else {
+				rs.traceContext[k] = v
+			}
 		}
 	} else {
 		rs.traceContext = tc
@@ -348,6 +364,32 @@ func (rs *Store) ListeningEnabled(key types.StoreKey) bool {
 	return false
 }
 
+// SetGasConfigs replaces the gas configs overriding the default ones of
+// specific KVStores. The branches created afterwards use the new gas configs.
+func (rs *Store) SetGasConfigs(configs map[types.StoreKey]types.GasConfig) {
+	gasConfigs := make(map[types.StoreKey]types.GasConfig, len(configs))
+	for key, config := range configs {
+		gasConfigs[key] = config
+	}
+
+	rs.gasConfigsMtx.Lock()
+	defer rs.gasConfigsMtx.Unlock()
+	rs.gasConfigs = gasConfigs
+}
+
+// GetGasConfig returns the gas config overriding the default one of a specific
+// KVStore, if any.
+func (rs *Store) GetGasConfig(key types.StoreKey) (types.GasConfig, bool) {
+	config, ok := rs.getGasConfigs()[key]
+	return config, ok
+}
+
+func (rs *Store) getGasConfigs() map[types.StoreKey]types.GasConfig {
+	rs.gasConfigsMtx.RLock()
+	defer rs.gasConfigsMtx.RUnlock()
+	return rs.gasConfigs
+}
+
 // LastCommitID implements Committer/CommitStore.
 func (rs *Store) LastCommitID() types.CommitID {
 	if rs.lastCommitInfo == nil {
@@ -361,6 +403,9 @@ func (rs *Store) LastCommitID() types.CommitID {
 
 // Commit implements Committer/CommitStore.
 func (rs *Store) Commit() types.CommitID {
+	rs.mtx.Lock()
+	defer rs.mtx.Unlock()
+
 	var previousHeight, version int64
 	if rs.lastCommitInfo.GetVersion() == 0 && rs.initialVersion > 1 {
 		// This case means that no commit has been made in the store, we
@@ -395,10 +440,14 @@ func (rs *Store) Commit() types.CommitID {
 
 	// batch prune if the current height is a pruning interval height
 	if rs.pruningOpts.Interval > 0 && version%int64(rs.pruningOpts.Interval) == 0 {
-		rs.pruneStores()
+		if rs.pruner != nil {
+			rs.schedulePruning()
+		} else {
+			rs.pruneStores()
+		}
 	}
 
-	flushMetadata(rs.db, version, rs.lastCommitInfo, rs.pruneHeights)
+	flushMetadata(rs.db, version, rs.lastCommitInfo, rs.allPruneHeights())
 
 	return types.CommitID{
 		Version: version,
@@ -406,6 +455,63 @@ func (rs *Store) Commit() types.CommitID {
 	}
 }
 
+// RollbackToVersion implements CommitMultiStore. All the stores must still
+// have the version, which is checked before any store is modified.
+func (rs *Store) RollbackToVersion(version int64) error {
+	rs.mtx.Lock()
+	defer rs.mtx.Unlock()
+
+	latest := getLatestVersion(rs.db)
+	if version <= 0 || version >= latest {
+		return fmt.Errorf("cannot rollback to version %d, the latest version is %d", version, latest)
+	}
+
+	cInfo, err := getCommitInfo(rs.db, version)
+	if err != nil {
+		return errors.Wrapf(err, "cannot rollback to version %d", version)
+	}
+
+	stores := make(map[string]*iavl.Store)
+	for key := range rs.stores {
+		switch store := rs.GetCommitKVStore(key).(type) {
+		case *iavl.Store:
+			if !store.VersionExists(version) {
+				return fmt.Errorf("cannot rollback to version %d, it has been pruned from store %s", version, key.Name())
+			}
+			stores[key.Name()] = store
+
+		case *smt.Store:
+			// SMT stores are rolled back when they are loaded at the version
+			if err := store.CheckRollbackToVersion(version); err != nil {
+				return errors.Wrapf(err, "cannot rollback store %s", key.Name())
+			}
+		}
+	}
+
+	for name, store := range stores {
+		if _, err := store.LoadVersionForOverwriting(version); err != nil {
+			return errors.Wrapf(err, "failed to rollback store %s", name)
+		}
+	}
+
+	// the heights which have not been pruned yet remain to be pruned, except
+	// for the new latest version
+	allPruneHeights := rs.allPruneHeights()
+	pruneHeights := make([]int64, 0, len(allPruneHeights))
+	for _, height := range allPruneHeights {
+		if height < version {
+			pruneHeights = append(pruneHeights, height)
+		}
+	}
+	if rs.pruner != nil {
+		rs.pruner.pending = nil
+	}
+	rs.pruneHeights = pruneHeights
+
+	flushMetadata(rs.db, version, cInfo, pruneHeights)
+	return rs.LoadLatestVersion()
+}
+
 // pruneStores will batch delete a list of heights from each mounted sub-store.
 // Afterwards, pruneHeights is reset.
 func (rs *Store) pruneStores() {
@@ -413,21 +519,35 @@ func (rs *Store) pruneStores() {
 		return
 	}
 
+	defer telemetry.MeasureSince(time.Now(), "store", "rootmulti", "prune")
+	rs.deleteVersions(rs.pruneHeights...)
+	rs.pruneHeights = make([]int64, 0)
+}
+
+// deleteVersions deletes the given heights from each mounted IAVL and SMT
+// store. Heights which do not exist are ignored.
+func (rs *Store) deleteVersions(heights ...int64) {
 	for key, store := range rs.stores {
-		if store.GetStoreType() == types.StoreTypeIAVL {
+		switch store.GetStoreType() {
+		case types.StoreTypeIAVL:
 			// If the store is wrapped with an inter-block cache, we must first unwrap
 			// it to get the underlying IAVL store.
 			store = rs.GetCommitKVStore(key)
 
-			if err := store.(*iavl.Store).DeleteVersions(rs.pruneHeights...); err != nil {
+			if err := store.(*iavl.Store).DeleteVersions(heights...); err != nil {
 				if errCause := errors.Cause(err); errCause != nil && errCause != iavltree.ErrVersionDoesNotExist {
 					panic(err)
 				}
 			}
+
+		case types.StoreTypeSMT:
+			for _, height := range heights {
+				if err := store.(*smt.Store).DeleteVersions(height); err != nil && err != smt.ErrVersionDoesNotExist {
+					panic(err)
+				}
+			}
 		}
 	}
-
-	rs.pruneHeights = make([]int64, 0)
 }
 
 // CacheWrap implements CacheWrapper/Store/CommitStore.
@@ -452,7 +572,7 @@ func (rs *Store) CacheMultiStore() types.CacheMultiStore {
 	for k, v := range rs.stores {
 		stores[k] = v
 	}
-	return cachemulti.NewStore(rs.db, stores, rs.keysByName, rs.traceWriter, rs.traceContext, rs.listeners)
+	return cachemulti.NewStore(rs.db, stores, rs.keysByName, rs.traceWriter, rs.traceContext, rs.listeners, rs.getGasConfigs())
 }
 
 // CacheMultiStoreWithVersion is analogous to CacheMultiStore except that it
@@ -477,12 +597,20 @@ func (rs *Store) CacheMultiStoreWithVersion(version int64) (types.CacheMultiStor
 
 			cachedStores[key] = iavlStore
 
+		case types.StoreTypeSMT:
+			smtStore, err := store.(*smt.Store).GetImmutable(version)
+			if err != nil {
+				return nil, err
+			}
+
+			cachedStores[key] = smtStore
+
 		default:
 			cachedStores[key] = store
 		}
 	}
 
-	return cachemulti.NewStore(rs.db, cachedStores, rs.keysByName, rs.traceWriter, rs.traceContext, rs.listeners), nil
+	return cachemulti.NewStore(rs.db, cachedStores, rs.keysByName, rs.traceWriter, rs.traceContext, rs.listeners, rs.getGasConfigs()), nil
 }
 
 // GetStore returns a mounted Store for a given StoreKey. If the StoreKey does
@@ -514,7 +642,7 @@ func (rs *Store) GetKVStore(key types.StoreKey) types.KVStore {
 	store := s.(types.KVStore)
 
 	if rs.TracingEnabled() {
-		store = tracekv.NewStore(store, rs.traceWriter, rs.traceContext)
+		store = tracekv.NewStoreWithKey(store, rs.traceWriter, rs.traceContext, key)
 	}
 	if rs.ListeningEnabled(key) {
 		store = listenkv.NewStore(store, key, rs.listeners[key])
@@ -589,19 +717,23 @@ func (rs *Store) Query(req abci.RequestQuery) abci.ResponseQuery {
 	return res
 }
 
-// SetInitialVersion sets the initial version of the IAVL tree. It is used when
-// starting a new chain at an arbitrary height.
+// SetInitialVersion sets the initial version of the IAVL and SMT trees. It is
+// used when starting a new chain at an arbitrary height.
 func (rs *Store) SetInitialVersion(version int64) error {
 	rs.initialVersion = version
 
-	// Loop through all the stores, if it's an IAVL store, then set initial
-	// version on it.
+	// Loop through all the stores, if it's an IAVL or SMT store, then set
+	// initial version on it.
 	for key, store := range rs.stores {
-		if store.GetStoreType() == types.StoreTypeIAVL {
+		switch store.GetStoreType() {
+		case types.StoreTypeIAVL:
 			// If the store is wrapped with an inter-block cache, we must first unwrap
 			// it to get the underlying IAVL store.
 			store = rs.GetCommitKVStore(key)
 			store.(*iavl.Store).SetInitialVersion(version)
+
+		case types.StoreTypeSMT:
+			store.(*smt.Store).SetInitialVersion(version)
 		}
 	}
 
@@ -632,32 +764,29 @@ func parsePath(path string) (storeName string, subpath string, err error) {
 // identical across nodes such that chunks from different sources fit together. If the output for a
 // given format changes (at the byte level), the snapshot format must be bumped - see
 // TestMultistoreSnapshot_Checksum test.
-func (rs *Store) Snapshot(height uint64, format uint32) (<-chan io.ReadCloser, error) {
-	if format != snapshottypes.CurrentFormat {
-		return nil, sdkerrors.Wrapf(snapshottypes.ErrUnknownFormat, "format %v", format)
-	}
+func (rs *Store) Snapshot(height uint64, protoWriter protoio.Writer) error {
 	if height == 0 {
-		return nil, sdkerrors.Wrap(sdkerrors.ErrLogic, "cannot snapshot height 0")
+		return sdkerrors.Wrap(sdkerrors.ErrLogic, "cannot snapshot height 0")
 	}
 	if height > uint64(rs.LastCommitID().Version) {
-		return nil, sdkerrors.Wrapf(sdkerrors.ErrLogic, "cannot snapshot future height %v", height)
+		return sdkerrors.Wrapf(sdkerrors.ErrLogic, "cannot snapshot future height %v", height)
 	}
 
-	// Collect stores to snapshot (only IAVL stores are supported)
+	// Collect stores to snapshot (only IAVL and SMT stores are supported)
 	type namedStore struct {
-		*iavl.Store
+		types.CommitKVStore
 		name string
 	}
 	stores := []namedStore{}
 	for key := range rs.stores {
 		switch store := rs.GetCommitKVStore(key).(type) {
-		case *iavl.Store:
-			stores = append(stores, namedStore{name: key.Name(), Store: store})
+		case *iavl.Store, *smt.Store:
+			stores = append(stores, namedStore{name: key.Name(), CommitKVStore: store})
 		case *transient.Store, *mem.Store:
 			// Non-persisted stores shouldn't be snapshotted
 			continue
 		default:
-			return nil, sdkerrors.Wrapf(sdkerrors.ErrLogic,
+			return sdkerrors.Wrapf(sdkerrors.ErrLogic,
 				"don't know how to snapshot store %q of type %T", key.Name(), store)
 		}
 	}
@@ -665,160 +794,165 @@ func (rs *Store) Snapshot(height uint64, format uint32) (<-chan io.ReadCloser, e
 		return strings.Compare(stores[i].name, stores[j].name) == -1
 	})
 
-	// Spawn goroutine to generate snapshot chunks and pass their io.ReadClosers through a channel
-	ch := make(chan io.ReadCloser)
-	go func() {
-		// Set up a stream pipeline to serialize snapshot nodes:
-		// ExportNode -> delimited Protobuf -> zlib -> buffer -> chunkWriter -> chan io.ReadCloser
-		chunkWriter := snapshots.NewChunkWriter(ch, snapshotChunkSize)
-		defer chunkWriter.Close()
-		bufWriter := bufio.NewWriterSize(chunkWriter, snapshotBufferSize)
-		defer func() {
-			if err := bufWriter.Flush(); err != nil {
-				chunkWriter.CloseWithError(err)
-			}
-		}()
-		zWriter, err := zlib.NewWriterLevel(bufWriter, 7)
+	// Export each store. Stores are serialized as a stream of SnapshotItem Protobuf
+	// messages. The first item contains a SnapshotStore with store metadata (i.e. name),
+	// and the following messages contain a SnapshotNode (i.e. an ExportNode). Store changes
+	// are demarcated by new SnapshotStore items.
+	for _, store := range stores {
+		var err error
+		switch s := store.CommitKVStore.(type) {
+		case *iavl.Store:
+			err = snapshotStore(s, store.name, height, protoWriter)
+		case *smt.Store:
+			err = snapshotSMTStore(s, store.name, height, protoWriter)
+		}
 		if err != nil {
-			chunkWriter.CloseWithError(sdkerrors.Wrap(err, "zlib failure"))
-			return
+			return err
 		}
-		defer func() {
-			if err := zWriter.Close(); err != nil {
-				chunkWriter.CloseWithError(err)
-			}
-		}()
-		protoWriter := protoio.NewDelimitedWriter(zWriter)
-		defer func() {
-			if err := protoWriter.Close(); err != nil {
-				chunkWriter.CloseWithError(err)
-			}
-		}()
-
-		// Export each IAVL store. Stores are serialized as a stream of SnapshotItem Protobuf
-		// messages. The first item contains a SnapshotStore with store metadata (i.e. name),
-		// and the following messages contain a SnapshotNode (i.e. an ExportNode). Store changes
-		// are demarcated by new SnapshotStore items.
-		for _, store := range stores {
-			exporter, err := store.Export(int64(height))
-			if err != nil {
-				chunkWriter.CloseWithError(err)
-				return
-			}
-			defer exporter.Close()
-			err = protoWriter.WriteMsg(&types.SnapshotItem{
-				Item: &types.SnapshotItem_Store{
-					Store: &types.SnapshotStoreItem{
-						Name: store.name,
-					},
-				},
-			})
-			if err != nil {
-				chunkWriter.CloseWithError(err)
-				return
-			}
+	}
 
-			for {
-				node, err := exporter.Next()
-				if err == iavltree.ExportDone {
-					break
-				} else if err != nil {
-					chunkWriter.CloseWithError(err)
-					return
-				}
-				err = protoWriter.WriteMsg(&types.SnapshotItem{
-					Item: &types.SnapshotItem_IAVL{
-						IAVL: &types.SnapshotIAVLItem{
-							Key:     node.Key,
-							Value:   node.Value,
-							Height:  int32(node.Height),
-							Version: node.Version,
-						},
-					},
-				})
-				if err != nil {
-					chunkWriter.CloseWithError(err)
-					return
-				}
-			}
-			exporter.Close()
+	return nil
+}
+
+// snapshotStore writes the snapshot items of the given IAVL store at the given height.
+func snapshotStore(store *iavl.Store, name string, height uint64, protoWriter protoio.Writer) error {
+	exporter, err := store.Export(int64(height))
+	if err != nil {
+		return err
+	}
+	defer exporter.Close()
+
+	err = protoWriter.WriteMsg(&snapshottypes.SnapshotItem{
+		Item: &snapshottypes.SnapshotItem_Store{
+			Store: &snapshottypes.SnapshotStoreItem{
+				Name: name,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		node, err := exporter.Next()
+		if err == iavltree.ExportDone {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		err = protoWriter.WriteMsg(&snapshottypes.SnapshotItem{
+			Item: &snapshottypes.SnapshotItem_IAVL{
+				IAVL: &snapshottypes.SnapshotIAVLItem{
+					Key:     node.Key,
+					Value:   node.Value,
+					Height:  int32(node.Height),
+					Version: node.Version,
+				},
+			},
+		})
+		if err != nil {
+			return err
 		}
-	}()
+	}
+}
 
-	return ch, nil
+// snapshotSMTStore writes the snapshot items of the given SMT store at the given
+// height. Its keys and values are written as IAVL leaf nodes.
+func snapshotSMTStore(store *smt.Store, name string, height uint64, protoWriter protoio.Writer) error {
+	if !store.VersionExists(int64(height)) {
+		return fmt.Errorf("SMT export failed for version %v: %w", height, smt.ErrVersionDoesNotExist)
+	}
+
+	err := protoWriter.WriteMsg(&snapshottypes.SnapshotItem{
+		Item: &snapshottypes.SnapshotItem_Store{
+			Store: &snapshottypes.SnapshotStoreItem{
+				Name: name,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return store.Export(int64(height), func(key, value []byte) error {
+		return protoWriter.WriteMsg(&snapshottypes.SnapshotItem{
+			Item: &snapshottypes.SnapshotItem_IAVL{
+				IAVL: &snapshottypes.SnapshotIAVLItem{
+					Key:     key,
+					Value:   value,
+					Version: int64(height),
+				},
+			},
+		})
+	})
 }
 
-// Restore implements snapshottypes.Snapshotter.
+// Restore implements snapshottypes.Snapshotter. It returns the first item which is not a
+// store or IAVL item, e.g. the metadata of a snapshot extension.
 func (rs *Store) Restore(
-	height uint64, format uint32, chunks <-chan io.ReadCloser, ready chan<- struct{},
-) error {
+	height uint64, format uint32, protoReader protoio.Reader,
+) (snapshottypes.SnapshotItem, error) {
 	if format != snapshottypes.CurrentFormat {
-		return sdkerrors.Wrapf(snapshottypes.ErrUnknownFormat, "format %v", format)
+		return snapshottypes.SnapshotItem{}, sdkerrors.Wrapf(snapshottypes.ErrUnknownFormat, "format %v", format)
 	}
 	if height == 0 {
-		return sdkerrors.Wrap(sdkerrors.ErrLogic, "cannot restore snapshot at height 0")
+		return snapshottypes.SnapshotItem{}, sdkerrors.Wrap(sdkerrors.ErrLogic, "cannot restore snapshot at height 0")
 	}
 	if height > uint64(math.MaxInt64) {
-		return sdkerrors.Wrapf(snapshottypes.ErrInvalidMetadata,
+		return snapshottypes.SnapshotItem{}, sdkerrors.Wrapf(snapshottypes.ErrInvalidMetadata,
 			"snapshot height %v cannot exceed %v", height, int64(math.MaxInt64))
 	}
 
-	// Signal readiness. Must be done before the readers below are set up, since the zlib
-	// reader reads from the stream on initialization, potentially causing deadlocks.
-	if ready != nil {
-		close(ready)
-	}
-
-	// Set up a restore stream pipeline
-	// chan io.ReadCloser -> chunkReader -> zlib -> delimited Protobuf -> ExportNode
-	chunkReader := snapshots.NewChunkReader(chunks)
-	defer chunkReader.Close()
-	zReader, err := zlib.NewReader(chunkReader)
-	if err != nil {
-		return sdkerrors.Wrap(err, "zlib failure")
-	}
-	defer zReader.Close()
-	protoReader := protoio.NewDelimitedReader(zReader, snapshotMaxItemSize)
-	defer protoReader.Close()
-
 	// Import nodes into stores. The first item is expected to be a SnapshotItem containing
 	// a SnapshotStoreItem, telling us which store to import into. The following items will contain
-	// SnapshotNodeItem (i.e. ExportNode) until we reach the next SnapshotStoreItem or EOF.
-	var importer *iavltree.Importer
+	// SnapshotNodeItem (i.e. ExportNode) until we reach the next SnapshotStoreItem, an item of
+	// another type or EOF.
+	var (
+		importer     storeImporter
+		snapshotItem snapshottypes.SnapshotItem
+	)
+loop:
 	for {
-		item := &types.SnapshotItem{}
-		err := protoReader.ReadMsg(item)
+		snapshotItem = snapshottypes.SnapshotItem{}
+		err := protoReader.ReadMsg(&snapshotItem)
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return sdkerrors.Wrap(err, "invalid protobuf message")
+			return snapshottypes.SnapshotItem{}, sdkerrors.Wrap(err, "invalid protobuf message")
 		}
 
-		switch item := item.Item.(type) {
-		case *types.SnapshotItem_Store:
+		switch item := snapshotItem.Item.(type) {
+		case *snapshottypes.SnapshotItem_Store:
 			if importer != nil {
 				err = importer.Commit()
 				if err != nil {
-					return sdkerrors.Wrap(err, "IAVL commit failed")
+					return snapshottypes.SnapshotItem{}, sdkerrors.Wrap(err, "store commit failed")
 				}
 				importer.Close()
 			}
-			store, ok := rs.getStoreByName(item.Store.Name).(*iavl.Store)
-			if !ok || store == nil {
-				return sdkerrors.Wrapf(sdkerrors.ErrLogic, "cannot import into non-IAVL store %q", item.Store.Name)
+			switch store := rs.getStoreByName(item.Store.Name).(type) {
+			case *iavl.Store:
+				var iavlImporter *iavltree.Importer
+				iavlImporter, err = store.Import(int64(height))
+				importer = iavlStoreImporter{iavlImporter}
+			case *smt.Store:
+				var smtImporter *smt.Importer
+				smtImporter, err = store.Import(int64(height))
+				importer = smtStoreImporter{smtImporter}
+			default:
+				return snapshottypes.SnapshotItem{}, sdkerrors.Wrapf(sdkerrors.ErrLogic, "cannot import into non-IAVL and non-SMT store %q", item.Store.Name)
 			}
-			importer, err = store.Import(int64(height))
 			if err != nil {
-				return sdkerrors.Wrap(err, "import failed")
+				return snapshottypes.SnapshotItem{}, sdkerrors.Wrap(err, "import failed")
 			}
 			defer importer.Close()
 
-		case *types.SnapshotItem_IAVL:
+		case *snapshottypes.SnapshotItem_IAVL:
 			if importer == nil {
-				return sdkerrors.Wrap(sdkerrors.ErrLogic, "received IAVL node item before store item")
+				return snapshottypes.SnapshotItem{}, sdkerrors.Wrap(sdkerrors.ErrLogic, "received IAVL node item before store item")
 			}
 			if item.IAVL.Height > math.MaxInt8 {
-				return sdkerrors.Wrapf(sdkerrors.ErrLogic, "node height %v cannot exceed %v",
+				return snapshottypes.SnapshotItem{}, sdkerrors.Wrapf(sdkerrors.ErrLogic, "node height %v cannot exceed %v",
 					item.IAVL.Height, math.MaxInt8)
 			}
 			node := &iavltree.ExportNode{
@@ -835,26 +969,54 @@ func (rs *Store) Restore(
 			if node.Height == 0 && node.Value == nil {
 				node.Value = []byte{}
 			}
-			err := importer.Add(node)
+			err := importer.AddNode(node)
 			if err != nil {
-				return sdkerrors.Wrap(err, "IAVL node import failed")
+				return snapshottypes.SnapshotItem{}, sdkerrors.Wrap(err, "node import failed")
 			}
 
 		default:
-			return sdkerrors.Wrapf(sdkerrors.ErrLogic, "unknown snapshot item %T", item)
+			break loop
 		}
 	}
 
 	if importer != nil {
 		err := importer.Commit()
 		if err != nil {
-			return sdkerrors.Wrap(err, "IAVL commit failed")
+			return snapshottypes.SnapshotItem{}, sdkerrors.Wrap(err, "store commit failed")
 		}
 		importer.Close()
 	}
 
 	flushMetadata(rs.db, int64(height), rs.buildCommitInfo(int64(height)), []int64{})
-	return rs.LoadLatestVersion()
+	return snapshotItem, rs.LoadLatestVersion()
+}
+
+// storeImporter imports the nodes of a snapshotted store.
+type storeImporter interface {
+	AddNode(node *iavltree.ExportNode) error
+	Commit() error
+	Close()
+}
+
+type iavlStoreImporter struct {
+	*iavltree.Importer
+}
+
+func (i iavlStoreImporter) AddNode(node *iavltree.ExportNode) error {
+	return i.Add(node)
+}
+
+// smtStoreImporter imports the leaf nodes written by snapshotSMTStore.
+type smtStoreImporter struct {
+	*smt.Importer
+}
+
+func (i smtStoreImporter) AddNode(node *iavltree.ExportNode) error {
+	if node.Height != 0 {
+		return fmt.Errorf("cannot import inner node of height %d into SMT store", node.Height)
+	}
+
+	return i.Add(node.Key, node.Value)
 }
 
 func (rs *Store) loadCommitStoreFromParams(key types.StoreKey, id types.CommitID, params storeParams) (types.CommitKVStore, error) {
@@ -894,6 +1056,19 @@ func (rs *Store) loadCommitStoreFromParams(key types.StoreKey, id types.CommitID
 
 		return store, err
 
+	case types.StoreTypeSMT:
+		// The flat index of an SMT store serves its reads, so it is not wrapped
+		// with the inter-block cache.
+		store, err := smt.LoadStore(db, id)
+		if err != nil {
+			return nil, err
+		}
+		if params.initialVersion != 0 {
+			store.SetInitialVersion(int64(params.initialVersion))
+		}
+
+		return store, nil
+
 	case types.StoreTypeDB:
 		return commitDBStoreAdapter{Store: dbadapter.Store{DB: db}}, nil
 
@@ -11,7 +11,9 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"testing"
+	"time"
 
+	protoio "github.com/gogo/protobuf/io"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/snapshots"
 	snapshottypes "github.com/cosmos/cosmos-sdk/snapshots/types"
 	"github.com/cosmos/cosmos-sdk/store/cachemulti"
 	"github.com/cosmos/cosmos-sdk/store/iavl"
@@ -552,6 +555,284 @@ func TestMultiStore_PruningRestart(t *testing.T) {
 	}
 }
 
+func TestMultiStore_AsyncPruning(t *testing.T) {
+	db := dbm.NewMemDB()
+	ms := newMultiStoreWithMounts(db, types.NewPruningOptions(0, 0, 2))
+	require.NoError(t, ms.SetAsyncPruning(AsyncPruningOptions{MaxPending: 2, Delay: time.Millisecond}))
+	require.Error(t, ms.SetAsyncPruning(DefaultAsyncPruningOptions()))
+	require.NoError(t, ms.LoadLatestVersion())
+
+	for i := 0; i < 10; i++ {
+		ms.Commit()
+
+		// the backlog is bounded, unless a single interval exceeds it
+		ms.mtx.Lock()
+		require.LessOrEqual(t, len(ms.pruner.pending), 2)
+		ms.mtx.Unlock()
+	}
+
+	require.Eventually(t, func() bool {
+		ms.mtx.Lock()
+		defer ms.mtx.Unlock()
+		return len(ms.pruner.pending) == 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, ms.Close())
+	require.NoError(t, ms.Close())
+
+	store := ms.GetCommitKVStore(testStoreKey1).(*iavl.Store)
+	for v := int64(1); v <= 9; v++ {
+		require.False(t, store.VersionExists(v), "version %d should be pruned", v)
+	}
+	require.True(t, store.VersionExists(10))
+
+	// the store is pruned synchronously once closed
+	ms.Commit()
+	ms.Commit()
+	require.False(t, store.VersionExists(10))
+	require.False(t, store.VersionExists(11))
+	require.True(t, store.VersionExists(12))
+}
+
+func TestMultiStore_AsyncPruningClose(t *testing.T) {
+	db := dbm.NewMemDB()
+	ms := newMultiStoreWithMounts(db, types.NewPruningOptions(0, 0, 5))
+	require.NoError(t, ms.SetAsyncPruning(AsyncPruningOptions{MaxPending: 10, Delay: time.Hour}))
+	require.NoError(t, ms.LoadLatestVersion())
+
+	for i := 0; i < 5; i++ {
+		ms.Commit()
+	}
+
+	// the first height is pruned before the delay, which is interrupted
+	require.Eventually(t, func() bool {
+		ms.mtx.Lock()
+		defer ms.mtx.Unlock()
+		return len(ms.pruner.pending) == 3
+	}, time.Second, time.Millisecond)
+	require.NoError(t, ms.Close())
+
+	ph, err := getPruningHeights(db)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 3, 4}, ph)
+
+	// "restart"
+	ms = newMultiStoreWithMounts(db, types.NewPruningOptions(0, 0, 5))
+	require.NoError(t, ms.LoadLatestVersion())
+	require.Equal(t, []int64{2, 3, 4}, ms.pruneHeights)
+}
+
+func TestMultiStore_RollbackToVersion(t *testing.T) {
+	db := dbm.NewMemDB()
+	ms := newMultiStoreWithMounts(db, types.PruneNothing)
+	require.NoError(t, ms.LoadLatestVersion())
+
+	commit := func(value string) types.CommitID {
+		ms.GetKVStore(testStoreKey1).Set([]byte("key"), []byte(value))
+		ms.GetKVStore(testStoreKey2).Set([]byte(value), []byte(value))
+		return ms.Commit()
+	}
+	commit("1")
+	commitID2 := commit("2")
+	commitID3 := commit("3")
+
+	require.Error(t, ms.RollbackToVersion(0))
+	require.Error(t, ms.RollbackToVersion(3))
+	require.Error(t, ms.RollbackToVersion(4))
+
+	require.NoError(t, ms.RollbackToVersion(2))
+	require.Equal(t, commitID2, ms.LastCommitID())
+	require.Equal(t, []byte("2"), ms.GetKVStore(testStoreKey1).Get([]byte("key")))
+	require.Nil(t, ms.GetKVStore(testStoreKey2).Get([]byte("3")))
+
+	// the rollback is persisted
+	ms = newMultiStoreWithMounts(db, types.PruneNothing)
+	require.NoError(t, ms.LoadLatestVersion())
+	require.Equal(t, commitID2, ms.LastCommitID())
+
+	// the rolled back version can be committed again
+	require.Equal(t, commitID3, commit("3"))
+	require.Equal(t, []byte("3"), ms.GetKVStore(testStoreKey1).Get([]byte("key")))
+}
+
+func TestMultiStore_RollbackToPrunedVersion(t *testing.T) {
+	db := dbm.NewMemDB()
+	ms := newMultiStoreWithMounts(db, types.NewPruningOptions(0, 0, 1))
+	require.NoError(t, ms.LoadLatestVersion())
+
+	for i := 0; i < 3; i++ {
+		ms.GetKVStore(testStoreKey1).Set([]byte("key"), []byte{byte(i)})
+		ms.Commit()
+	}
+	commitID := ms.LastCommitID()
+
+	// the store is left untouched when the target version has been pruned
+	require.Error(t, ms.RollbackToVersion(2))
+	require.Equal(t, commitID, ms.LastCommitID())
+	require.Equal(t, []byte{2}, ms.GetKVStore(testStoreKey1).Get([]byte("key")))
+}
+
+func TestMultiStore_RollbackSMTStore(t *testing.T) {
+	db := dbm.NewMemDB()
+	smtStoreKey := types.NewKVStoreKey("smt")
+	newStore := func() *Store {
+		ms := newMultiStoreWithMounts(db, types.PruneNothing)
+		ms.MountStoreWithDB(smtStoreKey, types.StoreTypeSMT, nil)
+		require.NoError(t, ms.LoadLatestVersion())
+		return ms
+	}
+	ms := newStore()
+
+	commit := func(value string) types.CommitID {
+		ms.GetKVStore(testStoreKey1).Set([]byte("key"), []byte(value))
+		ms.GetKVStore(smtStoreKey).Set([]byte("key"), []byte(value))
+		ms.GetKVStore(smtStoreKey).Set([]byte(value), []byte(value))
+		return ms.Commit()
+	}
+	commit("1")
+	commitID2 := commit("2")
+	commitID3 := commit("3")
+
+	// SMT stores can only revert their latest version
+	require.Error(t, ms.RollbackToVersion(1))
+	require.Equal(t, commitID3, ms.LastCommitID())
+
+	require.NoError(t, ms.RollbackToVersion(2))
+	require.Equal(t, commitID2, ms.LastCommitID())
+	require.Equal(t, []byte("2"), ms.GetKVStore(smtStoreKey).Get([]byte("key")))
+	require.Nil(t, ms.GetKVStore(smtStoreKey).Get([]byte("3")))
+
+	ms = newStore()
+	require.Equal(t, commitID2, ms.LastCommitID())
+	require.Equal(t, commitID3, commit("3"))
+}
+
+func TestMultiStore_DiffVersions(t *testing.T) {
+	ms := newMultiStoreWithMounts(dbm.NewMemDB(), types.PruneNothing)
+	smtStoreKey := types.NewKVStoreKey("smt")
+	ms.MountStoreWithDB(smtStoreKey, types.StoreTypeSMT, nil)
+	ms.MountStoreWithDB(types.NewTransientStoreKey("transient"), types.StoreTypeTransient, nil)
+	require.NoError(t, ms.LoadLatestVersion())
+
+	for _, key := range []types.StoreKey{testStoreKey1, smtStoreKey} {
+		store := ms.GetKVStore(key)
+		store.Set([]byte("changed"), []byte("1"))
+		store.Set([]byte("deleted"), []byte("1"))
+		store.Set([]byte("unchanged"), []byte("1"))
+	}
+	ms.GetKVStore(testStoreKey2).Set([]byte("unchanged"), []byte("1"))
+	ms.Commit()
+
+	for _, key := range []types.StoreKey{testStoreKey1, smtStoreKey} {
+		store := ms.GetKVStore(key)
+		store.Set([]byte("added"), []byte("2"))
+		store.Set([]byte("changed"), []byte("2"))
+		store.Delete([]byte("deleted"))
+	}
+	ms.Commit()
+
+	type storeDiff struct {
+		store string
+		diff  KVDiff
+	}
+	var diffs []storeDiff
+	collect := func(storeName string, diff KVDiff) error {
+		diffs = append(diffs, storeDiff{storeName, diff})
+		return nil
+	}
+
+	require.NoError(t, ms.DiffVersions(1, 2, nil, collect))
+	var expected []storeDiff
+	for _, name := range []string{"smt", "store1"} {
+		expected = append(expected,
+			storeDiff{name, KVDiff{Key: []byte("added"), After: []byte("2")}},
+			storeDiff{name, KVDiff{Key: []byte("changed"), Before: []byte("1"), After: []byte("2")}},
+			storeDiff{name, KVDiff{Key: []byte("deleted"), Before: []byte("1")}},
+		)
+	}
+	require.Equal(t, expected, diffs)
+
+	// the diff of a version with itself is empty
+	diffs = nil
+	require.NoError(t, ms.DiffVersions(2, 2, nil, collect))
+	require.Empty(t, diffs)
+
+	// the errors of the callback are returned
+	errStop := errors.New("stop")
+	require.ErrorIs(t, ms.DiffVersions(1, 2, nil, func(string, KVDiff) error { return errStop }), errStop)
+
+	require.Error(t, ms.DiffVersions(1, 3, nil, collect))
+
+	// only the given persisted stores are diffed
+	diffs = nil
+	require.NoError(t, ms.DiffVersions(1, 2, []string{"store1"}, collect))
+	require.Equal(t, expected[3:], diffs)
+	require.Error(t, ms.DiffVersions(1, 2, []string{"transient"}, collect))
+	require.Error(t, ms.DiffVersions(1, 2, []string{"unknown"}, collect))
+}
+
+func TestMultiStore_GasConfigs(t *testing.T) {
+	ms := newMultiStoreWithMounts(dbm.NewMemDB(), types.PruneNothing)
+	require.NoError(t, ms.LoadLatestVersion())
+	ms.Commit()
+
+	config := types.GasConfig{HasCost: 1}
+	ms.SetGasConfigs(map[types.StoreKey]types.GasConfig{testStoreKey1: config})
+	branch := ms.CacheMultiStore()
+	versionBranch, err := ms.CacheMultiStoreWithVersion(1)
+	require.NoError(t, err)
+
+	for _, store := range []types.MultiStore{ms, branch, branch.CacheMultiStore(), versionBranch} {
+		got, ok := store.GetGasConfig(testStoreKey1)
+		require.True(t, ok)
+		require.Equal(t, config, got)
+		_, ok = store.GetGasConfig(testStoreKey2)
+		require.False(t, ok)
+	}
+
+	// existing branches keep the gas configs they were created with
+	ms.SetGasConfigs(nil)
+	_, ok := ms.GetGasConfig(testStoreKey1)
+	require.False(t, ok)
+	_, ok = branch.GetGasConfig(testStoreKey1)
+	require.True(t, ok)
+}
+
+func TestMultistoreSnapshotRestoreSMT(t *testing.T) {
+	newStore := func() *Store {
+		ms := NewStore(dbm.NewMemDB())
+		ms.MountStoreWithDB(types.NewKVStoreKey("iavl"), types.StoreTypeIAVL, nil)
+		ms.MountStoreWithDB(types.NewKVStoreKey("smt"), types.StoreTypeSMT, nil)
+		require.NoError(t, ms.LoadLatestVersion())
+		return ms
+	}
+
+	source := newStore()
+	for i := 0; i < 3; i++ {
+		for _, name := range []string{"iavl", "smt"} {
+			store := source.getStoreByName(name).(types.KVStore)
+			for j := 0; j < 100; j++ {
+				store.Set([]byte(fmt.Sprintf("key%03d", j)), []byte(fmt.Sprintf("value%d-%03d", i, j)))
+			}
+			store.Delete([]byte(fmt.Sprintf("key%03d", i)))
+		}
+		source.Commit()
+	}
+	version := uint64(source.LastCommitID().Version)
+
+	target := newStore()
+	streamReader, err := snapshots.NewStreamReader(snapshotChunks(t, source, version))
+	require.NoError(t, err)
+	defer streamReader.Close()
+	_, err = target.Restore(version, snapshottypes.CurrentFormat, streamReader)
+	require.NoError(t, err)
+
+	assert.Equal(t, source.LastCommitID(), target.LastCommitID())
+	for _, name := range []string{"iavl", "smt"} {
+		assertStoresEqual(t, source.getStoreByName(name).(types.CommitKVStore),
+			target.getStoreByName(name).(types.CommitKVStore), "store %q not equal", name)
+	}
+}
+
 func TestMultistoreSnapshot_Checksum(t *testing.T) {
 	// Chunks from different nodes must fit together, so all nodes must produce identical chunks.
 	// This checksum test makes sure that the byte stream remains identical. If the test fails
@@ -564,23 +845,22 @@ func TestMultistoreSnapshot_Checksum(t *testing.T) {
 		format      uint32
 		chunkHashes []string
 	}{
-		{1, []string{
-			"503e5b51b657055b77e88169fadae543619368744ad15f1de0736c0a20482f24",
-			"e1a0daaa738eeb43e778aefd2805e3dd720798288a410b06da4b8459c4d8f72e",
-			"aa048b4ee0f484965d7b3b06822cf0772cdcaad02f3b1b9055e69f2cb365ef3c",
-			"7921eaa3ed4921341e504d9308a9877986a879fe216a099c86e8db66fcba4c63",
-			"a4a864e6c02c9fca5837ec80dc84f650b25276ed7e4820cf7516ced9f9901b86",
-			"ca2879ac6e7205d257440131ba7e72bef784cd61642e32b847729e543c1928b9",
+		{2, []string{
+			"d01aee7f62d95ce6e4220e6669adf6e0cc670b7ea52ea9f81ed4031cfbe25364",
+			"f696749162377cf15849442132e56bf403fe17806c22360b6e6b2b25b6223b43",
+			"e22342e0ffa775547cd31d1a294dc312c6929e3c92593b539b6d16cb1852b4dc",
+			"8acacdc885166707faf75a6cd8acfd834e6ffea1a9379bc4f4dbacd27513f08b",
+			"d2d3db2304d277e30eae0d1ee478d2a53ec40ef97c39ba52192f015adb97a9e3",
+			"83d9c23456adf1249630a7da7da6717a490b8a57b80883ce9bbdc0a3f97bbcf7",
 		}},
 	}
 	for _, tc := range testcases {
 		tc := tc
 		t.Run(fmt.Sprintf("Format %v", tc.format), func(t *testing.T) {
-			chunks, err := store.Snapshot(version, tc.format)
-			require.NoError(t, err)
+			require.Equal(t, snapshottypes.CurrentFormat, tc.format)
 			hashes := []string{}
 			hasher := sha256.New()
-			for chunk := range chunks {
+			for chunk := range snapshotChunks(t, store, version) {
 				hasher.Reset()
 				_, err := io.Copy(hasher, chunk)
 				require.NoError(t, err)
@@ -597,18 +877,15 @@ func TestMultistoreSnapshot_Errors(t *testing.T) {
 
 	testcases := map[string]struct {
 		height     uint64
-		format     uint32
 		expectType error
 	}{
-		"0 height":       {0, snapshottypes.CurrentFormat, nil},
-		"0 format":       {1, 0, snapshottypes.ErrUnknownFormat},
-		"unknown height": {9, snapshottypes.CurrentFormat, nil},
-		"unknown format": {1, 9, snapshottypes.ErrUnknownFormat},
+		"0 height":       {0, nil},
+		"unknown height": {9, nil},
 	}
 	for name, tc := range testcases {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			_, err := store.Snapshot(tc.height, tc.format)
+			err := store.Snapshot(tc.height, protoio.NewDelimitedWriter(ioutil.Discard))
 			require.Error(t, err)
 			if tc.expectType != nil {
 				assert.True(t, errors.Is(err, tc.expectType))
@@ -632,7 +909,7 @@ func TestMultistoreRestore_Errors(t *testing.T) {
 	for name, tc := range testcases {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			err := store.Restore(tc.height, tc.format, nil, nil)
+			_, err := store.Restore(tc.height, tc.format, nil)
 			require.Error(t, err)
 			if tc.expectType != nil {
 				assert.True(t, errors.Is(err, tc.expectType))
@@ -647,12 +924,12 @@ func TestMultistoreSnapshotRestore(t *testing.T) {
 	version := uint64(source.LastCommitID().Version)
 	require.EqualValues(t, 3, version)
 
-	chunks, err := source.Snapshot(version, snapshottypes.CurrentFormat)
+	streamReader, err := snapshots.NewStreamReader(snapshotChunks(t, source, version))
 	require.NoError(t, err)
-	ready := make(chan struct{})
-	err = target.Restore(version, snapshottypes.CurrentFormat, chunks, ready)
+	defer streamReader.Close()
+	nextItem, err := target.Restore(version, snapshottypes.CurrentFormat, streamReader)
 	require.NoError(t, err)
-	assert.EqualValues(t, struct{}{}, <-ready)
+	require.Nil(t, nextItem.Item)
 
 	assert.Equal(t, source.LastCommitID(), target.LastCommitID())
 	for key, sourceStore := range source.stores {
@@ -667,6 +944,28 @@ func TestMultistoreSnapshotRestore(t *testing.T) {
 	}
 }
 
+func TestMultistoreRestore_ExtensionItem(t *testing.T) {
+	source := newMultiStoreWithMixedMountsAndBasicData(dbm.NewMemDB())
+	target := newMultiStoreWithMixedMounts(dbm.NewMemDB())
+	version := uint64(source.LastCommitID().Version)
+
+	// the restore stops at the first item which does not belong to the multistore
+	extensionItem := snapshottypes.SnapshotItem{
+		Item: &snapshottypes.SnapshotItem_Extension{
+			Extension: &snapshottypes.SnapshotExtensionMeta{Name: "foo", Format: 1},
+		},
+	}
+	buf := &bytes.Buffer{}
+	protoWriter := protoio.NewDelimitedWriter(buf)
+	require.NoError(t, source.Snapshot(version, protoWriter))
+	require.NoError(t, protoWriter.WriteMsg(&extensionItem))
+
+	nextItem, err := target.Restore(version, snapshottypes.CurrentFormat, protoio.NewDelimitedReader(buf, 1e6))
+	require.NoError(t, err)
+	require.Equal(t, extensionItem, nextItem)
+	assert.Equal(t, source.LastCommitID(), target.LastCommitID())
+}
+
 func TestSetInitialVersion(t *testing.T) {
 	db := dbm.NewMemDB()
 	multi := newMultiStoreWithMounts(db, types.PruneNothing)
@@ -790,6 +1089,42 @@ func TestGetListenWrappedKVStore(t *testing.T) {
 	require.Equal(t, []byte{}, kvPairDelete3Bytes)
 }
 
+func TestCacheMultiStoreWithListeners(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var db dbm.DB = dbm.NewMemDB()
+	ms := newMultiStoreWithMounts(db, types.PruneNothing)
+	require.NoError(t, ms.LoadLatestVersion())
+	ms.AddListeners(testStoreKey1, []types.WriteListener{types.NewStoreKVPairWriteListener(buf, testMarshaller)})
+
+	cacheMulti := ms.CacheMultiStore()
+
+	// writes of a discarded nested branch are never observed
+	discarded := cacheMulti.CacheMultiStore()
+	discarded.GetKVStore(testStoreKey1).Set(testKey2, testValue2)
+	require.Zero(t, buf.Len())
+
+	// writes of a nested branch are observed once the top-level branch is written
+	nested := cacheMulti.CacheMultiStore()
+	nested.GetKVStore(testStoreKey1).Set(testKey1, testValue1)
+	nested.Write()
+	require.Zero(t, buf.Len())
+
+	cacheMulti.Write()
+	expected, err := testMarshaller.MarshalLengthPrefixed(&types.StoreKVPair{
+		Key:      testKey1,
+		Value:    testValue1,
+		StoreKey: testStoreKey1.Name(),
+		Delete:   false,
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected, buf.Bytes())
+
+	// written values are persisted on commit
+	ms.Commit()
+	require.Equal(t, testValue1, ms.GetKVStore(testStoreKey1).Get(testKey1))
+	require.Nil(t, ms.GetKVStore(testStoreKey1).Get(testKey2))
+}
+
 func TestCacheWraps(t *testing.T) {
 	db := dbm.NewMemDB()
 	multi := newMultiStoreWithMounts(db, types.PruneNothing)
@@ -839,9 +1174,7 @@ func benchmarkMultistoreSnapshot(b *testing.B, stores uint8, storeKeys uint64) {
 		require.NoError(b, err)
 		require.EqualValues(b, 0, target.LastCommitID().Version)
 
-		chunks, err := source.Snapshot(uint64(version), snapshottypes.CurrentFormat)
-		require.NoError(b, err)
-		for reader := range chunks {
+		for reader := range snapshotChunks(b, source, uint64(version)) {
 			_, err := io.Copy(ioutil.Discard, reader)
 			require.NoError(b, err)
 			err = reader.Close()
@@ -869,10 +1202,11 @@ func benchmarkMultistoreSnapshotRestore(b *testing.B, stores uint8, storeKeys ui
 		require.NoError(b, err)
 		require.EqualValues(b, 0, target.LastCommitID().Version)
 
-		chunks, err := source.Snapshot(version, snapshottypes.CurrentFormat)
+		streamReader, err := snapshots.NewStreamReader(snapshotChunks(b, source, version))
 		require.NoError(b, err)
-		err = target.Restore(version, snapshottypes.CurrentFormat, chunks, nil)
+		_, err = target.Restore(version, snapshottypes.CurrentFormat, streamReader)
 		require.NoError(b, err)
+		require.NoError(b, streamReader.Close())
 		require.Equal(b, source.LastCommitID(), target.LastCommitID())
 	}
 }
@@ -880,6 +1214,23 @@ func benchmarkMultistoreSnapshotRestore(b *testing.B, stores uint8, storeKeys ui
 //-----------------------------------------------------------------------
 // utils
 
+// snapshotChunks returns the snapshot chunks of the multistore at the given height, as created
+// by the snapshot manager.
+func snapshotChunks(t require.TestingT, store *Store, height uint64) <-chan io.ReadCloser {
+	ch := make(chan io.ReadCloser)
+	go func() {
+		streamWriter := snapshots.NewStreamWriter(ch)
+		require.NotNil(t, streamWriter)
+		err := store.Snapshot(height, streamWriter)
+		if err != nil {
+			streamWriter.CloseWithError(err)
+			return
+		}
+		_ = streamWriter.Close()
+	}()
+	return ch
+}
+
 var (
 	testStoreKey1 = types.NewKVStoreKey("store1")
 	testStoreKey2 = types.NewKVStoreKey("store2")
@@ -0,0 +1,161 @@
+package smt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	ics23 "github.com/confio/ics23/go"
+)
+
+// getProof returns the ics23 proof of the existence or of the absence of the
+// key at the given version.
+func (t *tree) getProof(version int64, key []byte) (*ics23.CommitmentProof, error) {
+	root, err := t.rootAt(version)
+	if err != nil {
+		return nil, err
+	}
+
+	path := hashKey(key)
+	siblings, n, err := t.lookup(root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if n != nil && bytes.Equal(n.path, path) {
+		return &ics23.CommitmentProof{
+			Proof: &ics23.CommitmentProof_Exist{Exist: existenceProof(n, siblings)},
+		}, nil
+	}
+
+	nonexist, err := t.nonExistenceProof(root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ics23.CommitmentProof{
+		Proof: &ics23.CommitmentProof_Nonexist{Nonexist: nonexist},
+	}, nil
+}
+
+// existenceProof returns the existence proof of the leaf, given its siblings
+// from the root down.
+func existenceProof(leaf *node, siblings []ref) *ics23.ExistenceProof {
+	spec := ics23.SmtSpec.LeafSpec
+	proof := &ics23.ExistenceProof{
+		Key:   leaf.path,
+		Value: leaf.value,
+		Leaf: &ics23.LeafOp{
+			Hash:         spec.Hash,
+			PrehashKey:   spec.PrehashKey,
+			PrehashValue: spec.PrehashValue,
+			Length:       spec.Length,
+			Prefix:       spec.Prefix,
+		},
+		Path: make([]*ics23.InnerOp, 0, len(siblings)),
+	}
+
+	for depth := len(siblings) - 1; depth >= 0; depth-- {
+		op := &ics23.InnerOp{Hash: ics23.HashOp_SHA256, Prefix: []byte{innerPrefix}}
+		if bit(leaf.path, depth) == 0 {
+			op.Suffix = siblings[depth].hash
+		} else {
+			op.Prefix = append(op.Prefix, siblings[depth].hash...)
+		}
+		proof.Path = append(proof.Path, op)
+	}
+
+	return proof
+}
+
+// nonExistenceProof returns the proof of the absence of the path, made of the
+// existence proofs of the leaves immediately preceding and following it.
+func (t *tree) nonExistenceProof(root ref, path []byte) (*ics23.NonExistenceProof, error) {
+	var (
+		left, right         *node
+		leftTree, rightTree ref // the closest non-empty subtrees on each side
+	)
+
+	r := root
+	for depth := 0; !r.isEmpty(); depth++ {
+		n, err := t.getNode(r)
+		if err != nil {
+			return nil, err
+		}
+		if n.leaf {
+			switch bytes.Compare(n.path, path) {
+			case -1:
+				left = n
+			case 1:
+				right = n
+			default:
+				return nil, fmt.Errorf("path %X exists", path)
+			}
+			break
+		}
+
+		if bit(path, depth) == 0 {
+			if !n.right.isEmpty() {
+				rightTree = n.right
+			}
+			r = n.left
+		} else {
+			if !n.left.isEmpty() {
+				leftTree = n.left
+			}
+			r = n.right
+		}
+	}
+
+	var err error
+	if left == nil && leftTree.hash != nil {
+		if left, err = t.edgeLeaf(leftTree, true); err != nil {
+			return nil, err
+		}
+	}
+	if right == nil && rightTree.hash != nil {
+		if right, err = t.edgeLeaf(rightTree, false); err != nil {
+			return nil, err
+		}
+	}
+	if left == nil && right == nil {
+		return nil, errors.New("cannot prove absence from an empty tree")
+	}
+
+	proof := &ics23.NonExistenceProof{Key: path}
+	for _, neighbor := range []struct {
+		leaf  *node
+		proof **ics23.ExistenceProof
+	}{{left, &proof.Left}, {right, &proof.Right}} {
+		if neighbor.leaf == nil {
+			continue
+		}
+		siblings, _, err := t.lookup(root, neighbor.leaf.path)
+		if err != nil {
+			return nil, err
+		}
+		*neighbor.proof = existenceProof(neighbor.leaf, siblings)
+	}
+
+	return proof, nil
+}
+
+// edgeLeaf returns the right-most or the left-most leaf of a non-empty subtree.
+func (t *tree) edgeLeaf(r ref, rightMost bool) (*node, error) {
+	for {
+		n, err := t.getNode(r)
+		if err != nil {
+			return nil, err
+		}
+		if n.leaf {
+			return n, nil
+		}
+
+		switch {
+		case rightMost && !n.right.isEmpty(), !rightMost && n.left.isEmpty():
+			r = n.right
+		default:
+			r = n.left
+		}
+	}
+}
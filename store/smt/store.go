@@ -0,0 +1,726 @@
+package smt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmcrypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/store/cachekv"
+	"github.com/cosmos/cosmos-sdk/store/dbadapter"
+	"github.com/cosmos/cosmos-sdk/store/listenkv"
+	"github.com/cosmos/cosmos-sdk/store/tracekv"
+	"github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/kv"
+)
+
+var (
+	_ types.KVStore                 = (*Store)(nil)
+	_ types.CommitStore             = (*Store)(nil)
+	_ types.CommitKVStore           = (*Store)(nil)
+	_ types.Queryable               = (*Store)(nil)
+	_ types.StoreWithInitialVersion = (*Store)(nil)
+)
+
+var (
+	valuePrefix = []byte("v/") // v/<key> -> value of the latest version
+
+	// The journal records how to revert the latest version: the previous values
+	// of the keys it changed, and the nodes it created.
+	journalPrefix      = []byte("j/")
+	journalHeaderKey   = []byte("j/h")  // latest version and the one before it
+	journalValuePrefix = []byte("j/v/") // j/v/<key> -> previous value
+	journalNodePrefix  = []byte("j/n/") // j/n/<hash> -> node created by the latest version
+)
+
+// importFlushSize is the number of entries of an import written per batch.
+const importFlushSize = 10000
+
+// Store is a CommitKVStore which commits to its state with a sparse Merkle
+// tree. The values of the latest version are also kept in a flat index, which
+// serves the reads and iterations of the store, while the tree serves proofs
+// and past versions.
+//
+// The latest version can be reverted once, which lets the store recover when it
+// was committed but its multistore was not.
+type Store struct {
+	db   dbm.DB
+	tree *tree
+
+	// values holds the writes since the last commit on top of the flat index,
+	// and pending the keys they changed, nil values being deletions.
+	values  *cachekv.Store
+	pending map[string][]byte
+
+	initialVersion int64
+}
+
+// LoadStore loads the SMT store from the given database at the given version,
+// which must be its latest version or the one before it.
+func LoadStore(db dbm.DB, id types.CommitID) (*Store, error) {
+	tree, err := loadTree(db)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &Store{db: db, tree: tree}
+	st.resetValues()
+
+	if id.Version != tree.version {
+		if err := st.RollbackToVersion(id.Version); err != nil {
+			return nil, fmt.Errorf("failed to load SMT store at version %d: %w", id.Version, err)
+		}
+	}
+
+	return st, nil
+}
+
+func (st *Store) resetValues() {
+	st.values = cachekv.NewStore(dbadapter.Store{DB: dbm.NewPrefixDB(st.db, valuePrefix)})
+	st.pending = make(map[string][]byte)
+}
+
+// GetImmutable returns a read-only view of the store at the given version. The
+// view of a past version reads from the tree, and must walk the whole tree to
+// iterate over its keys.
+func (st *Store) GetImmutable(version int64) (types.KVStore, error) {
+	root, err := st.tree.rootAt(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &immutableStore{store: st, version: version, root: root}, nil
+}
+
+// Commit commits the changes since the last commit to a new version of the
+// tree and to the flat index, and returns its CommitID.
+func (st *Store) Commit() types.CommitID {
+	defer telemetry.MeasureSince(time.Now(), "store", "smt", "commit")
+
+	version := st.tree.version + 1
+	if st.tree.version == 0 && st.initialVersion > 1 {
+		version = st.initialVersion
+	}
+
+	if err := st.commit(version); err != nil {
+		panic(err)
+	}
+
+	return st.LastCommitID()
+}
+
+func (st *Store) commit(version int64) error {
+	if err := st.tree.begin(version); err != nil {
+		return err
+	}
+	defer st.tree.discard()
+
+	batch := st.db.NewBatch()
+	defer batch.Close()
+
+	// the journal of the previous version is replaced
+	if err := deletePrefix(st.db, batch, journalPrefix); err != nil {
+		return err
+	}
+	header := append(uint64ToBigEndian(uint64(version)), uint64ToBigEndian(uint64(st.tree.version))...)
+	if err := batch.Set(journalHeaderKey, header); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(st.pending))
+	for key := range st.pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := st.tree.root
+	for _, key := range keys {
+		value := st.pending[key]
+
+		var err error
+		if root, err = st.tree.update(root, []byte(key), value); err != nil {
+			return err
+		}
+
+		previous, err := st.db.Get(valueKey([]byte(key)))
+		if err != nil {
+			return err
+		}
+		if err := batch.Set(prefixed(journalValuePrefix, []byte(key)), encodePrevious(previous)); err != nil {
+			return err
+		}
+
+		if value == nil {
+			err = batch.Delete(valueKey([]byte(key)))
+		} else {
+			err = batch.Set(valueKey([]byte(key)), value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	created, err := st.tree.save(root, batch)
+	if err != nil {
+		return err
+	}
+	for _, hash := range created {
+		if err := batch.Set(prefixed(journalNodePrefix, hash), []byte{}); err != nil {
+			return err
+		}
+	}
+
+	if err := batch.WriteSync(); err != nil {
+		return err
+	}
+
+	st.tree.commit(root)
+	st.resetValues()
+
+	return nil
+}
+
+// RollbackToVersion reverts the latest version of the store, which must follow
+// the given version, and discards the changes since the last commit. It
+// returns an error if the latest version has already been reverted, or if the
+// given version has been pruned.
+func (st *Store) RollbackToVersion(version int64) error {
+	if version == st.tree.version {
+		st.resetValues()
+		return nil
+	}
+
+	root, err := st.rollbackRoot(version)
+	if err != nil {
+		return err
+	}
+
+	batch := st.db.NewBatch()
+	defer batch.Close()
+
+	err = iteratePrefix(st.db, journalValuePrefix, func(key, value []byte) error {
+		key = key[len(journalValuePrefix):]
+		if len(value) == 0 {
+			return batch.Delete(valueKey(key))
+		}
+		return batch.Set(valueKey(key), value[1:])
+	})
+	if err != nil {
+		return err
+	}
+
+	err = iteratePrefix(st.db, journalNodePrefix, func(key, _ []byte) error {
+		return batch.Delete(nodeKey(ref{hash: key[len(journalNodePrefix):], version: st.tree.version}))
+	})
+	if err != nil {
+		return err
+	}
+
+	// the nodes orphaned by the latest version belong to the previous one again
+	orphans := append(append([]byte{}, orphanPrefix...), uint64ToBigEndian(uint64(st.tree.version))...)
+	if err := deletePrefix(st.db, batch, orphans); err != nil {
+		return err
+	}
+	if err := deletePrefix(st.db, batch, journalPrefix); err != nil {
+		return err
+	}
+	if err := batch.Delete(rootKey(st.tree.version)); err != nil {
+		return err
+	}
+
+	if err := batch.WriteSync(); err != nil {
+		return err
+	}
+
+	st.tree.version, st.tree.root = version, root
+	st.resetValues()
+
+	return nil
+}
+
+// CheckRollbackToVersion returns an error if RollbackToVersion cannot roll the
+// store back to the given version.
+func (st *Store) CheckRollbackToVersion(version int64) error {
+	if version == st.tree.version {
+		return nil
+	}
+
+	_, err := st.rollbackRoot(version)
+	return err
+}
+
+// rollbackRoot returns the root of the version to which the latest version is
+// reverted.
+func (st *Store) rollbackRoot(version int64) (ref, error) {
+	header, err := st.db.Get(journalHeaderKey)
+	if err != nil {
+		return ref{}, err
+	}
+	if len(header) != 16 || int64(binary.BigEndian.Uint64(header[:8])) != st.tree.version ||
+		int64(binary.BigEndian.Uint64(header[8:])) != version {
+		return ref{}, fmt.Errorf("only the latest version %d can be reverted, to the version before it", st.tree.version)
+	}
+
+	root, err := st.tree.rootAt(version)
+	if err != nil {
+		return ref{}, fmt.Errorf("version %d: %w", version, err)
+	}
+
+	return root, nil
+}
+
+// LastCommitID implements Committer.
+func (st *Store) LastCommitID() types.CommitID {
+	if st.tree.version == 0 {
+		return types.CommitID{}
+	}
+
+	return types.CommitID{
+		Version: st.tree.version,
+		Hash:    st.tree.root.hash,
+	}
+}
+
+// SetPruning panics as the versions of the store are pruned by its multistore.
+func (st *Store) SetPruning(_ types.PruningOptions) {
+	panic("cannot set pruning options on an initialized SMT store")
+}
+
+// GetPruning panics as the versions of the store are pruned by its multistore.
+func (st *Store) GetPruning() types.PruningOptions {
+	panic("cannot get pruning options on an initialized SMT store")
+}
+
+// VersionExists returns whether or not a given version is stored.
+func (st *Store) VersionExists(version int64) bool {
+	return st.tree.versionExists(version)
+}
+
+// DeleteVersions deletes the given versions of the tree, along with the nodes
+// which no longer belong to any version.
+func (st *Store) DeleteVersions(versions ...int64) error {
+	for _, version := range versions {
+		if err := st.tree.deleteVersion(version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetInitialVersion sets the version of the first commit of the store. It is
+// used when starting a new chain at an arbitrary height.
+func (st *Store) SetInitialVersion(version int64) {
+	st.initialVersion = version
+}
+
+// Implements Store.
+func (st *Store) GetStoreType() types.StoreType {
+	return types.StoreTypeSMT
+}
+
+// Implements Store.
+func (st *Store) CacheWrap() types.CacheWrap {
+	return cachekv.NewStore(st)
+}
+
+// CacheWrapWithTrace implements the Store interface.
+func (st *Store) CacheWrapWithTrace(w io.Writer, tc types.TraceContext) types.CacheWrap {
+	return cachekv.NewStore(tracekv.NewStore(st, w, tc))
+}
+
+// CacheWrapWithListeners implements the CacheWrapper interface.
+func (st *Store) CacheWrapWithListeners(storeKey types.StoreKey, listeners []types.WriteListener) types.CacheWrap {
+	return cachekv.NewStore(listenkv.NewStore(st, storeKey, listeners))
+}
+
+// Implements types.KVStore.
+func (st *Store) Get(key []byte) []byte {
+	defer telemetry.MeasureSince(time.Now(), "store", "smt", "get")
+	return st.values.Get(key)
+}
+
+// Implements types.KVStore.
+func (st *Store) Has(key []byte) bool {
+	defer telemetry.MeasureSince(time.Now(), "store", "smt", "has")
+	return st.values.Has(key)
+}
+
+// Implements types.KVStore.
+func (st *Store) Set(key, value []byte) {
+	types.AssertValidKey(key)
+	types.AssertValidValue(value)
+	st.values.Set(key, value)
+	st.pending[string(key)] = value
+}
+
+// Implements types.KVStore.
+func (st *Store) Delete(key []byte) {
+	defer telemetry.MeasureSince(time.Now(), "store", "smt", "delete")
+	st.values.Delete(key)
+	st.pending[string(key)] = nil
+}
+
+// Implements types.KVStore.
+func (st *Store) Iterator(start, end []byte) types.Iterator {
+	return valuesIterator{st.values.Iterator(start, end)}
+}
+
+// Implements types.KVStore.
+func (st *Store) ReverseIterator(start, end []byte) types.Iterator {
+	return valuesIterator{st.values.ReverseIterator(start, end)}
+}
+
+// valuesIterator iterates over the values of a Store. Unlike the iterators of
+// its cache, it does not report an error once exhausted.
+type valuesIterator struct {
+	types.Iterator
+}
+
+func (it valuesIterator) Error() error {
+	if !it.Valid() {
+		return nil
+	}
+
+	return it.Iterator.Error()
+}
+
+// Export calls fn with the keys and values of the store at the given version,
+// in the order of their paths in the tree.
+func (st *Store) Export(version int64, fn func(key, value []byte) error) error {
+	root, err := st.tree.rootAt(version)
+	if err != nil {
+		return fmt.Errorf("SMT export failed for version %v: %w", version, err)
+	}
+
+	return st.tree.walk(root, func(n *node) error {
+		return fn(n.key, n.value)
+	})
+}
+
+// Import returns an Importer which imports the keys and values of a version
+// into the store, which must be empty.
+func (st *Store) Import(version int64) (*Importer, error) {
+	if st.tree.version != 0 || len(st.pending) > 0 {
+		return nil, errors.New("SMT import failed: store is not empty")
+	}
+	if err := st.tree.begin(version); err != nil {
+		return nil, err
+	}
+
+	return &Importer{store: st, batch: st.db.NewBatch(), root: emptyRef}, nil
+}
+
+// Importer imports the keys and values of a version into an empty SMT store.
+// The nodes are written as they are added, and the version is saved by Commit.
+type Importer struct {
+	store *Store
+	batch dbm.Batch
+	root  ref
+	size  int
+}
+
+// Add adds a key and its value to the imported version.
+func (i *Importer) Add(key, value []byte) error {
+	if i.batch == nil {
+		return errors.New("importer is closed")
+	}
+	if value == nil {
+		return errors.New("value cannot be nil")
+	}
+
+	var err error
+	if i.root, err = i.store.tree.update(i.root, key, value); err != nil {
+		return err
+	}
+	if err := i.batch.Set(valueKey(key), value); err != nil {
+		return err
+	}
+
+	i.size++
+	if i.size%importFlushSize > 0 {
+		return nil
+	}
+	if err := i.store.tree.flush(i.batch); err != nil {
+		return err
+	}
+	if err := i.batch.Write(); err != nil {
+		return err
+	}
+	i.batch.Close()
+	i.batch = i.store.db.NewBatch()
+
+	return nil
+}
+
+// Commit saves the imported version, and closes the importer.
+func (i *Importer) Commit() error {
+	if i.batch == nil {
+		return errors.New("importer is closed")
+	}
+	defer i.Close()
+
+	if _, err := i.store.tree.save(i.root, i.batch); err != nil {
+		return err
+	}
+	if err := i.batch.WriteSync(); err != nil {
+		return err
+	}
+
+	i.store.tree.commit(i.root)
+	i.store.resetValues()
+
+	return nil
+}
+
+// Close discards the import if it has not been committed.
+func (i *Importer) Close() {
+	if i.batch == nil {
+		return
+	}
+
+	i.batch.Close()
+	i.batch = nil
+	i.store.tree.discard()
+}
+
+// getHeight returns the height of the query, choosing the latest height which
+// can be proven if it is 0.
+func (st *Store) getHeight(req abci.RequestQuery) int64 {
+	height := req.Height
+	if height == 0 {
+		latest := st.tree.version
+		if st.tree.versionExists(latest - 1) {
+			height = latest - 1
+		} else {
+			height = latest
+		}
+	}
+	return height
+}
+
+// Query implements ABCI interface, allows queries
+//
+// Like the IAVL store, it returns by default the values at latest height -1,
+// which can be proven against the app hash of the latest header. The proofs are
+// keyed by the SHA-256 hash of the queried key, which is the key of the leaves
+// of the tree.
+func (st *Store) Query(req abci.RequestQuery) (res abci.ResponseQuery) {
+	defer telemetry.MeasureSince(time.Now(), "store", "smt", "query")
+
+	if len(req.Data) == 0 {
+		return sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrTxDecode, "query cannot be zero length"))
+	}
+
+	// store the height we chose in the response, with 0 being changed to the
+	// latest height
+	res.Height = st.getHeight(req)
+
+	switch req.Path {
+	case "/key": // get by key
+		key := req.Data // data holds the key bytes
+
+		res.Key = key
+		if !st.VersionExists(res.Height) {
+			res.Log = ErrVersionDoesNotExist.Error()
+			break
+		}
+
+		value, err := st.tree.get(res.Height, key)
+		if err != nil {
+			return sdkerrors.QueryResult(err)
+		}
+		res.Value = value
+		if !req.Prove {
+			break
+		}
+
+		proof, err := st.tree.getProof(res.Height, key)
+		if err != nil {
+			return sdkerrors.QueryResult(sdkerrors.Wrap(types.ErrInvalidProof, err.Error()))
+		}
+		op := types.NewSmtCommitmentOp(hashKey(key), proof)
+		res.ProofOps = &tmcrypto.ProofOps{Ops: []tmcrypto.ProofOp{op.ProofOp()}}
+
+	case "/subspace":
+		pairs := kv.Pairs{
+			Pairs: make([]kv.Pair, 0),
+		}
+
+		subspace := req.Data
+		res.Key = subspace
+
+		iterator := types.KVStorePrefixIterator(st, subspace)
+		for ; iterator.Valid(); iterator.Next() {
+			pairs.Pairs = append(pairs.Pairs, kv.Pair{Key: iterator.Key(), Value: iterator.Value()})
+		}
+		iterator.Close()
+
+		bz, err := pairs.Marshal()
+		if err != nil {
+			panic(fmt.Errorf("failed to marshal KV pairs: %w", err))
+		}
+
+		res.Value = bz
+
+	default:
+		return sdkerrors.QueryResult(sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unexpected query path: %v", req.Path))
+	}
+
+	return res
+}
+
+func valueKey(key []byte) []byte {
+	return prefixed(valuePrefix, key)
+}
+
+func prefixed(prefix, key []byte) []byte {
+	return append(append(make([]byte, 0, len(prefix)+len(key)), prefix...), key...)
+}
+
+// encodePrevious encodes the previous value of a key in the journal, an empty
+// value meaning that the key did not exist.
+func encodePrevious(value []byte) []byte {
+	if value == nil {
+		return []byte{}
+	}
+
+	return append([]byte{1}, value...)
+}
+
+func iteratePrefix(db dbm.DB, prefix []byte, fn func(key, value []byte) error) error {
+	it, err := db.Iterator(prefix, prefixEnd(prefix))
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		if err := fn(append([]byte{}, it.Key()...), append([]byte{}, it.Value()...)); err != nil {
+			return err
+		}
+	}
+
+	return it.Error()
+}
+
+func deletePrefix(db dbm.DB, batch dbm.Batch, prefix []byte) error {
+	return iteratePrefix(db, prefix, func(key, _ []byte) error {
+		return batch.Delete(key)
+	})
+}
+
+// immutableStore is a read-only view of a version of a Store.
+type immutableStore struct {
+	store   *Store
+	version int64
+	root    ref
+}
+
+var _ types.KVStore = (*immutableStore)(nil)
+
+// GetStoreType implements Store.
+func (is *immutableStore) GetStoreType() types.StoreType {
+	return types.StoreTypeSMT
+}
+
+// CacheWrap implements Store.
+func (is *immutableStore) CacheWrap() types.CacheWrap {
+	return cachekv.NewStore(is)
+}
+
+// CacheWrapWithTrace implements the Store interface.
+func (is *immutableStore) CacheWrapWithTrace(w io.Writer, tc types.TraceContext) types.CacheWrap {
+	return cachekv.NewStore(tracekv.NewStore(is, w, tc))
+}
+
+// CacheWrapWithListeners implements the CacheWrapper interface.
+func (is *immutableStore) CacheWrapWithListeners(storeKey types.StoreKey, listeners []types.WriteListener) types.CacheWrap {
+	return cachekv.NewStore(listenkv.NewStore(is, storeKey, listeners))
+}
+
+// Get implements types.KVStore.
+func (is *immutableStore) Get(key []byte) []byte {
+	types.AssertValidKey(key)
+
+	path := hashKey(key)
+	_, n, err := is.store.tree.lookup(is.root, path)
+	if err != nil {
+		panic(err)
+	}
+	if n == nil || !bytes.Equal(n.path, path) {
+		return nil
+	}
+
+	return n.value
+}
+
+// Has implements types.KVStore.
+func (is *immutableStore) Has(key []byte) bool {
+	return is.Get(key) != nil
+}
+
+// Set implements types.KVStore.
+func (is *immutableStore) Set(_, _ []byte) {
+	panic("cannot write to an immutable SMT store")
+}
+
+// Delete implements types.KVStore.
+func (is *immutableStore) Delete(_ []byte) {
+	panic("cannot write to an immutable SMT store")
+}
+
+// Iterator implements types.KVStore.
+func (is *immutableStore) Iterator(start, end []byte) types.Iterator {
+	return is.iterator(start, end, true)
+}
+
+// ReverseIterator implements types.KVStore.
+func (is *immutableStore) ReverseIterator(start, end []byte) types.Iterator {
+	return is.iterator(start, end, false)
+}
+
+// iterator iterates over the flat index while the version is the latest one.
+// For a past version, it collects the leaves of the tree within the range
+// first.
+func (is *immutableStore) iterator(start, end []byte, ascending bool) types.Iterator {
+	var (
+		db  dbm.DB
+		err error
+	)
+	if is.version == is.store.tree.version {
+		db = dbm.NewPrefixDB(is.store.db, valuePrefix)
+	} else {
+		db = dbm.NewMemDB()
+		err = is.store.tree.walk(is.root, func(n *node) error {
+			if (start != nil && bytes.Compare(n.key, start) < 0) || (end != nil && bytes.Compare(n.key, end) >= 0) {
+				return nil
+			}
+			return db.Set(n.key, n.value)
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	var it types.Iterator
+	if ascending {
+		it, err = db.Iterator(start, end)
+	} else {
+		it, err = db.ReverseIterator(start, end)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	return it
+}
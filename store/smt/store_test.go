@@ -0,0 +1,289 @@
+package smt
+
+import (
+	"fmt"
+	"testing"
+
+	ics23 "github.com/confio/ics23/go"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+func newStore(t *testing.T, db dbm.DB) *Store {
+	st, err := LoadStore(db, types.CommitID{})
+	require.NoError(t, err)
+	return st
+}
+
+func setKeys(st *Store, n int, prefix string) {
+	for i := 0; i < n; i++ {
+		st.Set([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("%s%03d", prefix, i)))
+	}
+}
+
+func TestSMTStoreGetSetHasDelete(t *testing.T) {
+	st := newStore(t, dbm.NewMemDB())
+
+	st.Set([]byte("hello"), []byte("goodbye"))
+	require.True(t, st.Has([]byte("hello")))
+	require.Equal(t, []byte("goodbye"), st.Get([]byte("hello")))
+
+	id := st.Commit()
+	require.Equal(t, int64(1), id.Version)
+	require.Len(t, id.Hash, 32)
+	require.Equal(t, []byte("goodbye"), st.Get([]byte("hello")))
+
+	st.Delete([]byte("hello"))
+	require.False(t, st.Has([]byte("hello")))
+	require.Nil(t, st.Get([]byte("hello")))
+
+	id = st.Commit()
+	require.Equal(t, int64(2), id.Version)
+	require.Equal(t, placeholder, id.Hash)
+
+	require.Panics(t, func() { st.Set([]byte("hello"), nil) })
+	require.Panics(t, func() { st.Set(nil, []byte("value")) })
+}
+
+func TestSMTStoreRootIsHistoryIndependent(t *testing.T) {
+	st1 := newStore(t, dbm.NewMemDB())
+	setKeys(st1, 100, "a")
+	st1.Commit()
+	for i := 0; i < 100; i += 3 {
+		st1.Delete([]byte(fmt.Sprintf("key%03d", i)))
+	}
+	id1 := st1.Commit()
+
+	st2 := newStore(t, dbm.NewMemDB())
+	for i := 0; i < 100; i++ {
+		if i%3 != 0 {
+			st2.Set([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("a%03d", i)))
+		}
+	}
+	id2 := st2.Commit()
+
+	require.Equal(t, id1.Hash, id2.Hash)
+}
+
+func TestSMTStoreLoad(t *testing.T) {
+	db := dbm.NewMemDB()
+	st := newStore(t, db)
+	setKeys(st, 10, "a")
+	st.Commit()
+	setKeys(st, 5, "b")
+	id := st.Commit()
+
+	st, err := LoadStore(db, id)
+	require.NoError(t, err)
+	require.Equal(t, id, st.LastCommitID())
+	require.Equal(t, []byte("b004"), st.Get([]byte("key004")))
+	require.Equal(t, []byte("a009"), st.Get([]byte("key009")))
+
+	_, err = LoadStore(db, types.CommitID{Version: 3})
+	require.Error(t, err)
+}
+
+func TestSMTStoreRollback(t *testing.T) {
+	db := dbm.NewMemDB()
+	st := newStore(t, db)
+	setKeys(st, 10, "a")
+	id1 := st.Commit()
+
+	setKeys(st, 5, "b")
+	st.Set([]byte("new"), []byte("value"))
+	st.Delete([]byte("key009"))
+	st.Commit()
+
+	// the multistore was not committed at version 2
+	st, err := LoadStore(db, id1)
+	require.NoError(t, err)
+	require.Equal(t, id1, st.LastCommitID())
+	require.Equal(t, []byte("a004"), st.Get([]byte("key004")))
+	require.Equal(t, []byte("a009"), st.Get([]byte("key009")))
+	require.Nil(t, st.Get([]byte("new")))
+	require.False(t, st.VersionExists(2))
+
+	// the latest version can be reverted only once
+	require.Error(t, st.RollbackToVersion(0))
+
+	// and the next commit builds version 2 again
+	setKeys(st, 5, "c")
+	id2 := st.Commit()
+	require.Equal(t, int64(2), id2.Version)
+	require.Equal(t, []byte("c004"), st.Get([]byte("key004")))
+}
+
+func TestSMTStoreDeleteVersions(t *testing.T) {
+	db := dbm.NewMemDB()
+	st := newStore(t, db)
+	for v := 1; v <= 5; v++ {
+		setKeys(st, 20, fmt.Sprintf("v%d-", v))
+		st.Commit()
+	}
+
+	require.Error(t, st.DeleteVersions(5))
+	require.NoError(t, st.DeleteVersions(2, 3))
+	require.ErrorIs(t, st.DeleteVersions(3), ErrVersionDoesNotExist)
+	require.False(t, st.VersionExists(2))
+
+	for _, v := range []int64{1, 4, 5} {
+		view, err := st.GetImmutable(v)
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("v%d-007", v)), view.Get([]byte("key007")))
+	}
+
+	// once all the past versions are deleted, only the nodes of the latest one
+	// remain
+	require.NoError(t, st.DeleteVersions(1, 4))
+	fresh := newStore(t, dbm.NewMemDB())
+	setKeys(fresh, 20, "v5-")
+	fresh.Commit()
+	require.Equal(t, countPrefix(t, fresh.db, nodePrefix), countPrefix(t, db, nodePrefix))
+	require.Zero(t, countPrefix(t, db, orphanPrefix))
+}
+
+func countPrefix(t *testing.T, db dbm.DB, prefix []byte) int {
+	count := 0
+	require.NoError(t, iteratePrefix(db, prefix, func(_, _ []byte) error {
+		count++
+		return nil
+	}))
+	return count
+}
+
+func TestSMTStoreImmutable(t *testing.T) {
+	st := newStore(t, dbm.NewMemDB())
+	setKeys(st, 10, "a")
+	st.Commit()
+	st.Delete([]byte("key003"))
+	st.Commit()
+
+	for _, version := range []int64{1, 2} {
+		view, err := st.GetImmutable(version)
+		require.NoError(t, err)
+		require.Panics(t, func() { view.Set([]byte("key"), []byte("value")) })
+
+		var keys []string
+		it := view.Iterator([]byte("key002"), []byte("key005"))
+		for ; it.Valid(); it.Next() {
+			keys = append(keys, string(it.Key()))
+		}
+		require.NoError(t, it.Close())
+
+		if version == 1 {
+			require.Equal(t, []string{"key002", "key003", "key004"}, keys)
+		} else {
+			require.Equal(t, []string{"key002", "key004"}, keys)
+		}
+	}
+
+	_, err := st.GetImmutable(3)
+	require.ErrorIs(t, err, ErrVersionDoesNotExist)
+}
+
+func TestSMTStoreQueryProofs(t *testing.T) {
+	st := newStore(t, dbm.NewMemDB())
+	setKeys(st, 50, "a")
+	st.Commit()
+	st.Set([]byte("key100"), []byte("late"))
+	id := st.Commit()
+
+	for _, tc := range []struct {
+		key    string
+		value  []byte
+		height int64
+	}{
+		{"key007", []byte("a007"), 2},
+		{"key100", []byte("late"), 2},
+		{"missing", nil, 2},
+		{"key100", nil, 1},
+	} {
+		res := st.Query(abci.RequestQuery{Path: "/key", Data: []byte(tc.key), Height: tc.height, Prove: true})
+		require.Zero(t, res.Code, res.Log)
+		require.Equal(t, tc.value, res.Value)
+		require.Len(t, res.ProofOps.Ops, 1)
+
+		op, err := types.CommitmentOpDecoder(res.ProofOps.Ops[0])
+		require.NoError(t, err)
+
+		var args [][]byte
+		if tc.value != nil {
+			args = [][]byte{tc.value}
+		}
+		root, err := op.Run(args)
+		require.NoError(t, err)
+
+		expected := id.Hash
+		if tc.height == 1 {
+			view, err := st.tree.rootAt(1)
+			require.NoError(t, err)
+			expected = view.hash
+		}
+		require.Equal(t, [][]byte{expected}, root)
+
+		// the proof does not prove another value
+		_, err = op.Run([][]byte{[]byte("other")})
+		require.Error(t, err)
+	}
+
+	// the default height is the latest one which can be proven
+	res := st.Query(abci.RequestQuery{Path: "/key", Data: []byte("key100")})
+	require.Equal(t, int64(1), res.Height)
+	require.Nil(t, res.Value)
+}
+
+func TestSMTStoreNonExistenceProofEdges(t *testing.T) {
+	st := newStore(t, dbm.NewMemDB())
+	st.Set([]byte("only"), []byte("value"))
+	id := st.Commit()
+
+	for i := 0; i < 20; i++ {
+		key := []byte(fmt.Sprintf("missing%d", i))
+		proof, err := st.tree.getProof(id.Version, key)
+		require.NoError(t, err)
+		require.True(t, ics23.VerifyNonMembership(ics23.SmtSpec, id.Hash, proof, hashKey(key)))
+		require.False(t, ics23.VerifyNonMembership(ics23.SmtSpec, id.Hash, proof, hashKey([]byte("only"))))
+	}
+
+	empty := newStore(t, dbm.NewMemDB())
+	empty.Commit()
+	_, err := empty.tree.getProof(1, []byte("missing"))
+	require.Error(t, err)
+}
+
+func TestSMTStoreExportImport(t *testing.T) {
+	st := newStore(t, dbm.NewMemDB())
+	setKeys(st, 30, "a")
+	st.Commit()
+	st.Delete([]byte("key010"))
+	id := st.Commit()
+
+	db := dbm.NewMemDB()
+	target := newStore(t, db)
+	importer, err := target.Import(id.Version)
+	require.NoError(t, err)
+	require.NoError(t, st.Export(id.Version, importer.Add))
+	require.NoError(t, importer.Commit())
+
+	require.Equal(t, id, target.LastCommitID())
+	require.Equal(t, []byte("a020"), target.Get([]byte("key020")))
+	require.Nil(t, target.Get([]byte("key010")))
+
+	target, err = LoadStore(db, id)
+	require.NoError(t, err)
+	require.Equal(t, id, target.LastCommitID())
+
+	_, err = target.Import(id.Version + 1)
+	require.Error(t, err)
+}
+
+func TestSMTStoreInitialVersion(t *testing.T) {
+	st := newStore(t, dbm.NewMemDB())
+	st.SetInitialVersion(5)
+	st.Set([]byte("hello"), []byte("world"))
+	require.Equal(t, int64(5), st.Commit().Version)
+	require.Equal(t, int64(6), st.Commit().Version)
+}
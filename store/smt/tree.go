@@ -0,0 +1,678 @@
+package smt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	leafPrefix  = 0
+	innerPrefix = 1
+
+	// maxDepth is the number of bits of a path, i.e. the maximum depth of a
+	// leaf.
+	maxDepth = 8 * sha256.Size
+)
+
+var (
+	// placeholder is the hash of an empty subtree.
+	placeholder = make([]byte, sha256.Size)
+
+	nodePrefix   = []byte("n/") // n/<created version>/<hash> -> node
+	rootPrefix   = []byte("r/") // r/<version> -> root hash and version
+	orphanPrefix = []byte("o/") // o/<orphaned version>/<created version>/<hash>
+)
+
+// ErrVersionDoesNotExist is returned when a version does not exist, or has
+// been pruned.
+var ErrVersionDoesNotExist = errors.New("version does not exist")
+
+// ref references a node by its hash and the version at which it was created.
+// The version is not part of the hash, but locates the node in the database.
+type ref struct {
+	hash    []byte
+	version int64
+}
+
+var emptyRef = ref{hash: placeholder}
+
+func (r ref) isEmpty() bool {
+	return bytes.Equal(r.hash, placeholder)
+}
+
+// node is a node of the tree. A leaf commits to its path, i.e. the hash of its
+// key, and to the hash of its value. The key and the value are stored in the
+// leaf too, to read past versions and to build proofs.
+//
+// The tree is compact: a subtree containing a single leaf is replaced by that
+// leaf, so that leaves are stored at the depth at which their paths diverge
+// from the paths of all the other leaves.
+type node struct {
+	leaf bool
+
+	// inner nodes
+	left, right ref
+
+	// leaves
+	path, valueHash, key, value []byte
+}
+
+func newLeaf(key, value []byte) *node {
+	valueHash := sha256.Sum256(value)
+
+	return &node{
+		leaf:      true,
+		path:      hashKey(key),
+		valueHash: valueHash[:],
+		key:       key,
+		value:     value,
+	}
+}
+
+func (n *node) hash() []byte {
+	h := sha256.New()
+	if n.leaf {
+		h.Write([]byte{leafPrefix})
+		h.Write(n.path)
+		h.Write(n.valueHash)
+	} else {
+		h.Write([]byte{innerPrefix})
+		h.Write(n.left.hash)
+		h.Write(n.right.hash)
+	}
+
+	return h.Sum(nil)
+}
+
+func (n *node) encode() []byte {
+	var (
+		buf bytes.Buffer
+		bz  [binary.MaxVarintLen64]byte
+	)
+	if n.leaf {
+		buf.WriteByte(leafPrefix)
+		buf.Write(n.path)
+		buf.Write(n.valueHash)
+		buf.Write(bz[:binary.PutUvarint(bz[:], uint64(len(n.key)))])
+		buf.Write(n.key)
+		buf.Write(n.value)
+	} else {
+		buf.WriteByte(innerPrefix)
+		buf.Write(n.left.hash)
+		buf.Write(n.right.hash)
+		buf.Write(bz[:binary.PutVarint(bz[:], n.left.version)])
+		buf.Write(bz[:binary.PutVarint(bz[:], n.right.version)])
+	}
+
+	return buf.Bytes()
+}
+
+func decodeNode(bz []byte) (*node, error) {
+	if len(bz) < 1+2*sha256.Size {
+		return nil, errors.New("node is too short")
+	}
+
+	n := &node{}
+	switch bz[0] {
+	case leafPrefix:
+		n.leaf = true
+		n.path, n.valueHash = bz[1:33], bz[33:65]
+		keyLen, read := binary.Uvarint(bz[65:])
+		if read <= 0 || uint64(len(bz)-65-read) < keyLen {
+			return nil, errors.New("invalid leaf key")
+		}
+		bz = bz[65+read:]
+		n.key, n.value = bz[:keyLen], bz[keyLen:]
+
+	case innerPrefix:
+		n.left.hash, n.right.hash = bz[1:33], bz[33:65]
+		var read1, read2 int
+		n.left.version, read1 = binary.Varint(bz[65:])
+		if read1 > 0 {
+			n.right.version, read2 = binary.Varint(bz[65+read1:])
+		}
+		if read1 <= 0 || read2 <= 0 {
+			return nil, errors.New("invalid inner node children")
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown node prefix %d", bz[0])
+	}
+
+	return n, nil
+}
+
+// hashKey returns the path of a key in the tree.
+func hashKey(key []byte) []byte {
+	path := sha256.Sum256(key)
+	return path[:]
+}
+
+// bit returns the bit of the path at the given depth, 0 meaning the left child.
+func bit(path []byte, depth int) int {
+	return int(path[depth/8]>>(7-uint(depth%8))) & 1
+}
+
+func nodeKey(r ref) []byte {
+	key := append([]byte{}, nodePrefix...)
+	key = append(key, uint64ToBigEndian(uint64(r.version))...)
+	return append(key, r.hash...)
+}
+
+func rootKey(version int64) []byte {
+	return append(append([]byte{}, rootPrefix...), uint64ToBigEndian(uint64(version))...)
+}
+
+func orphanKey(orphaned int64, r ref) []byte {
+	key := append([]byte{}, orphanPrefix...)
+	key = append(key, uint64ToBigEndian(uint64(orphaned))...)
+	key = append(key, uint64ToBigEndian(uint64(r.version))...)
+	return append(key, r.hash...)
+}
+
+func encodeRef(r ref) []byte {
+	return append(append([]byte{}, r.hash...), uint64ToBigEndian(uint64(r.version))...)
+}
+
+func decodeRef(bz []byte) (ref, error) {
+	if len(bz) != sha256.Size+8 {
+		return ref{}, errors.New("invalid node reference")
+	}
+
+	return ref{
+		hash:    bz[:sha256.Size],
+		version: int64(binary.BigEndian.Uint64(bz[sha256.Size:])),
+	}, nil
+}
+
+func uint64ToBigEndian(i uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, i)
+	return b
+}
+
+// tree is a versioned sparse Merkle tree. The nodes which are no longer part of
+// the tree after a commit are recorded as orphans, and deleted once all the
+// versions they belong to have been deleted.
+type tree struct {
+	db      dbm.DB
+	version int64
+	root    ref
+
+	// working state of the version being built
+	working  int64
+	newNodes map[string]*node // hash -> node created in the working version
+	flushed  map[string]bool  // hashes of the new nodes already written
+	orphans  map[string]int64 // hash -> version at which the node was created
+}
+
+// loadTree loads the latest version of the tree.
+func loadTree(db dbm.DB) (*tree, error) {
+	t := &tree{db: db, root: emptyRef}
+
+	it, err := db.ReverseIterator(rootPrefix, prefixEnd(rootPrefix))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	if it.Valid() {
+		t.version = int64(binary.BigEndian.Uint64(it.Key()[len(rootPrefix):]))
+		if t.root, err = decodeRef(append([]byte{}, it.Value()...)); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, it.Error()
+}
+
+// rootAt returns the root of the tree at the given version. Version 0 is the
+// empty tree.
+func (t *tree) rootAt(version int64) (ref, error) {
+	switch {
+	case version == t.version:
+		return t.root, nil
+	case version == 0:
+		return emptyRef, nil
+	}
+
+	bz, err := t.db.Get(rootKey(version))
+	if err != nil {
+		return ref{}, err
+	}
+	if bz == nil {
+		return ref{}, ErrVersionDoesNotExist
+	}
+
+	return decodeRef(bz)
+}
+
+// versionExists returns whether the given version exists.
+func (t *tree) versionExists(version int64) bool {
+	if version <= 0 {
+		return false
+	}
+
+	_, err := t.rootAt(version)
+	return err == nil
+}
+
+func (t *tree) getNode(r ref) (*node, error) {
+	if r.version == t.working {
+		if n, ok := t.newNodes[string(r.hash)]; ok {
+			return n, nil
+		}
+	}
+
+	bz, err := t.db.Get(nodeKey(r))
+	if err != nil {
+		return nil, err
+	}
+	if bz == nil {
+		return nil, fmt.Errorf("node %X at version %d not found", r.hash, r.version)
+	}
+
+	return decodeNode(bz)
+}
+
+// addNode adds a node to the working version and returns its reference.
+func (t *tree) addNode(n *node) ref {
+	hash := n.hash()
+	if created, ok := t.orphans[string(hash)]; ok {
+		// the node was orphaned earlier in the working version, and is still
+		// stored
+		delete(t.orphans, string(hash))
+		return ref{hash: hash, version: created}
+	}
+
+	t.newNodes[string(hash)] = n
+	return ref{hash: hash, version: t.working}
+}
+
+// removeNode removes a node from the working version.
+func (t *tree) removeNode(r ref) {
+	if r.version != t.working {
+		t.orphans[string(r.hash)] = r.version
+		return
+	}
+
+	delete(t.newNodes, string(r.hash))
+	if t.flushed[string(r.hash)] {
+		// the node is still written, and deleted once the version is saved
+		t.orphans[string(r.hash)] = r.version
+	}
+}
+
+// begin starts building the given version on top of the latest one.
+func (t *tree) begin(version int64) error {
+	if version <= t.version {
+		return fmt.Errorf("cannot build version %d, the latest version is %d", version, t.version)
+	}
+
+	t.working = version
+	t.newNodes = make(map[string]*node)
+	t.flushed = make(map[string]bool)
+	t.orphans = make(map[string]int64)
+	return nil
+}
+
+// discard discards the working version.
+func (t *tree) discard() {
+	t.working, t.newNodes, t.flushed, t.orphans = 0, nil, nil, nil
+}
+
+// update sets the value of the key in the working version. A nil value deletes
+// the key.
+func (t *tree) update(root ref, key, value []byte) (ref, error) {
+	if value == nil {
+		root, _, err := t.remove(root, 0, hashKey(key))
+		return root, err
+	}
+
+	return t.insert(root, 0, newLeaf(key, value))
+}
+
+// flush writes the new nodes of the working version to the batch, in order to
+// bound the memory used by large versions.
+func (t *tree) flush(batch dbm.Batch) error {
+	for hash, n := range t.newNodes {
+		if err := batch.Set(nodeKey(ref{hash: []byte(hash), version: t.working}), n.encode()); err != nil {
+			return err
+		}
+		t.flushed[hash] = true
+	}
+	t.newNodes = make(map[string]*node)
+
+	return nil
+}
+
+// save writes the working version with the given root to the batch, and
+// returns the hashes of the nodes created in it. The tree is updated by
+// commit once the batch is written.
+func (t *tree) save(root ref, batch dbm.Batch) ([][]byte, error) {
+	for hash, created := range t.orphans {
+		r := ref{hash: []byte(hash), version: created}
+		if created == t.working {
+			// the node was created and orphaned in the working version
+			if err := batch.Delete(nodeKey(r)); err != nil {
+				return nil, err
+			}
+			delete(t.flushed, hash)
+			continue
+		}
+		if err := batch.Set(orphanKey(t.working, r), []byte{}); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.flush(batch); err != nil {
+		return nil, err
+	}
+	if err := batch.Set(rootKey(t.working), encodeRef(root)); err != nil {
+		return nil, err
+	}
+
+	created := make([][]byte, 0, len(t.flushed))
+	for hash := range t.flushed {
+		created = append(created, []byte(hash))
+	}
+
+	return created, nil
+}
+
+// commit makes the saved working version the latest one.
+func (t *tree) commit(root ref) {
+	t.version, t.root = t.working, root
+	t.discard()
+}
+
+// insert inserts or updates the leaf in the subtree at the given depth, and
+// returns the new root of the subtree.
+func (t *tree) insert(r ref, depth int, leaf *node) (ref, error) {
+	if r.isEmpty() {
+		return t.addNode(leaf), nil
+	}
+
+	n, err := t.getNode(r)
+	if err != nil {
+		return ref{}, err
+	}
+
+	if n.leaf {
+		if !bytes.Equal(n.path, leaf.path) {
+			return t.split(depth, r, n, leaf), nil
+		}
+		if bytes.Equal(n.valueHash, leaf.valueHash) {
+			return r, nil
+		}
+		t.removeNode(r)
+		return t.addNode(leaf), nil
+	}
+
+	inner := &node{left: n.left, right: n.right}
+	if bit(leaf.path, depth) == 0 {
+		inner.left, err = t.insert(n.left, depth+1, leaf)
+	} else {
+		inner.right, err = t.insert(n.right, depth+1, leaf)
+	}
+	if err != nil {
+		return ref{}, err
+	}
+	if bytes.Equal(inner.left.hash, n.left.hash) && bytes.Equal(inner.right.hash, n.right.hash) {
+		return r, nil
+	}
+
+	t.removeNode(r)
+	return t.addNode(inner), nil
+}
+
+// split returns the root of a subtree at the given depth which contains both
+// the existing leaf and the new leaf.
+func (t *tree) split(depth int, r ref, existing, leaf *node) ref {
+	inner := &node{left: emptyRef, right: emptyRef}
+
+	existingBit, leafBit := bit(existing.path, depth), bit(leaf.path, depth)
+	switch {
+	case existingBit != leafBit && leafBit == 0:
+		inner.left, inner.right = t.addNode(leaf), r
+	case existingBit != leafBit:
+		inner.left, inner.right = r, t.addNode(leaf)
+	case leafBit == 0:
+		inner.left = t.split(depth+1, r, existing, leaf)
+	default:
+		inner.right = t.split(depth+1, r, existing, leaf)
+	}
+
+	return t.addNode(inner)
+}
+
+// remove removes the leaf with the given path from the subtree at the given
+// depth, and returns the new root of the subtree and whether the leaf was
+// found.
+func (t *tree) remove(r ref, depth int, path []byte) (ref, bool, error) {
+	if r.isEmpty() {
+		return r, false, nil
+	}
+
+	n, err := t.getNode(r)
+	if err != nil {
+		return ref{}, false, err
+	}
+
+	if n.leaf {
+		if !bytes.Equal(n.path, path) {
+			return r, false, nil
+		}
+		t.removeNode(r)
+		return emptyRef, true, nil
+	}
+
+	child, sibling := n.left, n.right
+	if bit(path, depth) == 1 {
+		child, sibling = n.right, n.left
+	}
+
+	newChild, found, err := t.remove(child, depth+1, path)
+	if err != nil || !found {
+		return r, found, err
+	}
+	t.removeNode(r)
+
+	// a subtree containing a single leaf is replaced by that leaf
+	if newChild.isEmpty() {
+		siblingNode, err := t.getNode(sibling)
+		if err != nil {
+			return ref{}, false, err
+		}
+		if siblingNode.leaf {
+			return sibling, true, nil
+		}
+	} else if sibling.isEmpty() {
+		newChildNode, err := t.getNode(newChild)
+		if err != nil {
+			return ref{}, false, err
+		}
+		if newChildNode.leaf {
+			return newChild, true, nil
+		}
+	}
+
+	inner := &node{left: newChild, right: sibling}
+	if bit(path, depth) == 1 {
+		inner.left, inner.right = sibling, newChild
+	}
+
+	return t.addNode(inner), true, nil
+}
+
+// lookup walks down the tree from the root along the path. It returns the
+// siblings from the root down, and the leaf at which the walk ended, which may
+// have another path, or nil if it ended in an empty subtree.
+func (t *tree) lookup(root ref, path []byte) ([]ref, *node, error) {
+	var siblings []ref
+
+	r := root
+	for depth := 0; ; depth++ {
+		if r.isEmpty() {
+			return siblings, nil, nil
+		}
+
+		n, err := t.getNode(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if n.leaf {
+			return siblings, n, nil
+		}
+		if depth >= maxDepth {
+			return nil, nil, errors.New("tree is too deep")
+		}
+
+		if bit(path, depth) == 0 {
+			siblings = append(siblings, n.right)
+			r = n.left
+		} else {
+			siblings = append(siblings, n.left)
+			r = n.right
+		}
+	}
+}
+
+// get returns the value of the key at the given version, or nil if it does
+// not exist.
+func (t *tree) get(version int64, key []byte) ([]byte, error) {
+	root, err := t.rootAt(version)
+	if err != nil {
+		return nil, err
+	}
+
+	path := hashKey(key)
+	_, n, err := t.lookup(root, path)
+	if err != nil || n == nil || !bytes.Equal(n.path, path) {
+		return nil, err
+	}
+
+	return n.value, nil
+}
+
+// walk calls fn for every leaf of the subtree in the order of their paths.
+func (t *tree) walk(r ref, fn func(*node) error) error {
+	if r.isEmpty() {
+		return nil
+	}
+
+	n, err := t.getNode(r)
+	if err != nil {
+		return err
+	}
+	if n.leaf {
+		return fn(n)
+	}
+
+	if err := t.walk(n.left, fn); err != nil {
+		return err
+	}
+
+	return t.walk(n.right, fn)
+}
+
+// deleteVersion deletes the given version, which must not be the latest one,
+// along with the nodes which no longer belong to any version.
+func (t *tree) deleteVersion(version int64) error {
+	if version == t.version {
+		return fmt.Errorf("cannot delete latest version %d", version)
+	}
+	if !t.versionExists(version) {
+		return ErrVersionDoesNotExist
+	}
+
+	previous, err := t.adjacentVersion(version, false)
+	if err != nil {
+		return err
+	}
+	next, err := t.adjacentVersion(version, true)
+	if err != nil {
+		return err
+	}
+
+	// A node created at version c and orphaned at version o belongs to the
+	// versions [c, o). Once this version is deleted, the nodes created after the
+	// previous version and orphaned no later than the next version do not
+	// belong to any version anymore.
+	it, err := t.db.Iterator(
+		append(append([]byte{}, orphanPrefix...), uint64ToBigEndian(uint64(version+1))...),
+		append(append([]byte{}, orphanPrefix...), uint64ToBigEndian(uint64(next+1))...),
+	)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	batch := t.db.NewBatch()
+	defer batch.Close()
+
+	for ; it.Valid(); it.Next() {
+		key := it.Key()[len(orphanPrefix):]
+		r := ref{hash: key[16:], version: int64(binary.BigEndian.Uint64(key[8:16]))}
+		if r.version <= previous {
+			continue
+		}
+
+		if err := batch.Delete(nodeKey(r)); err != nil {
+			return err
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	if err := batch.Delete(rootKey(version)); err != nil {
+		return err
+	}
+
+	return batch.WriteSync()
+}
+
+// adjacentVersion returns the existing version following or preceding the
+// given one, or 0 if there is none.
+func (t *tree) adjacentVersion(version int64, next bool) (int64, error) {
+	var (
+		it  dbm.Iterator
+		err error
+	)
+	if next {
+		it, err = t.db.Iterator(rootKey(version+1), prefixEnd(rootPrefix))
+	} else {
+		it, err = t.db.ReverseIterator(rootPrefix, rootKey(version))
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	if !it.Valid() {
+		return 0, it.Error()
+	}
+
+	return int64(binary.BigEndian.Uint64(it.Key()[len(rootPrefix):])), nil
+}
+
+// prefixEnd returns the end of the range of the keys with the given prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package streaming
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/codec"
+	serverTypes "github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/store/streaming/file"
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+// ServiceConstructor is used to construct a streaming service
+type ServiceConstructor func(opts serverTypes.AppOptions, keys []types.StoreKey, marshaller codec.BinaryCodec) (baseapp.StreamingService, error)
+
+// ServiceType enum for specifying the type of StreamingService
+type ServiceType int
+
+const (
+	Unknown ServiceType = iota
+	File
+	// add more in the future
+)
+
+// Streaming option keys
+const (
+	OptStoreStreamers = "store.streamers"
+
+	OptStreamersFileKeys            = "streamers.file.keys"
+	OptStreamersFileWriteDir        = "streamers.file.write-dir"
+	OptStreamersFilePrefix          = "streamers.file.prefix"
+	OptStreamersFileStopNodeOnError = "streamers.file.stop-node-on-error"
+	OptStreamersFileFsync           = "streamers.file.fsync"
+)
+
+// NewStreamingServiceType returns the ServiceType corresponding to the provided name
+func NewStreamingServiceType(name string) ServiceType {
+	switch strings.ToLower(name) {
+	case "file", "f":
+		return File
+	default:
+		return Unknown
+	}
+}
+
+// String returns the string name of a ServiceType
+func (sst ServiceType) String() string {
+	switch sst {
+	case File:
+		return "file"
+	default:
+		return "unknown"
+	}
+}
+
+// ServiceConstructorLookupTable is a mapping of streaming.ServiceTypes to streaming.ServiceConstructors
+var ServiceConstructorLookupTable = map[ServiceType]ServiceConstructor{
+	File: NewFileStreamingService,
+}
+
+// NewServiceConstructor returns the streaming.ServiceConstructor corresponding to the provided name
+func NewServiceConstructor(name string) (ServiceConstructor, error) {
+	ssType := NewStreamingServiceType(name)
+	if ssType == Unknown {
+		return nil, fmt.Errorf("unrecognized streaming service name %s", name)
+	}
+	if constructor, ok := ServiceConstructorLookupTable[ssType]; ok && constructor != nil {
+		return constructor, nil
+	}
+	return nil, fmt.Errorf("streaming service constructor of type %s not found", ssType.String())
+}
+
+// NewFileStreamingService is the streaming.ServiceConstructor function for creating a FileStreamingService
+func NewFileStreamingService(opts serverTypes.AppOptions, keys []types.StoreKey, marshaller codec.BinaryCodec) (baseapp.StreamingService, error) {
+	filePrefix := cast.ToString(opts.Get(OptStreamersFilePrefix))
+	fileDir := cast.ToString(opts.Get(OptStreamersFileWriteDir))
+	if fileDir == "" {
+		return nil, fmt.Errorf("%s must be set when the file streaming service is enabled", OptStreamersFileWriteDir)
+	}
+	if !filepath.IsAbs(fileDir) {
+		fileDir = filepath.Join(cast.ToString(opts.Get(flags.FlagHome)), fileDir)
+	}
+	stopNodeOnError := cast.ToBool(opts.Get(OptStreamersFileStopNodeOnError))
+	fsync := cast.ToBool(opts.Get(OptStreamersFileFsync))
+
+	return file.NewStreamingService(fileDir, filePrefix, keys, marshaller, stopNodeOnError, fsync)
+}
+
+// LoadStreamingServices is a function for loading StreamingServices onto the BaseApp using the provided AppOptions, codec, and keys
+// It returns the StreamingServices it loaded so that the caller can close them when the application shuts down
+func LoadStreamingServices(bApp *baseapp.BaseApp, appOpts serverTypes.AppOptions, appCodec codec.BinaryCodec, keys map[string]*types.KVStoreKey) ([]baseapp.StreamingService, error) {
+	// configure state listening capabilities using AppOptions
+	streamers := cast.ToStringSlice(appOpts.Get(OptStoreStreamers))
+	activeStreamers := make([]baseapp.StreamingService, 0, len(streamers))
+	for _, streamerName := range streamers {
+		// get the store keys allowed to be exposed for this streaming service
+		exposeKeyStrs := cast.ToStringSlice(appOpts.Get(fmt.Sprintf("streamers.%s.keys", streamerName)))
+		exposeStoreKeys := exposeStoreKeysSorted(exposeKeyStrs, keys)
+
+		constructor, err := NewServiceConstructor(streamerName)
+		if err != nil {
+			return nil, err
+		}
+
+		// generate the streaming service using the constructor, appOptions, and the StoreKeys we want to expose
+		streamingService, err := constructor(appOpts, exposeStoreKeys, appCodec)
+		if err != nil {
+			return nil, err
+		}
+
+		// register the streaming service with the BaseApp
+		bApp.SetStreamingService(streamingService)
+		activeStreamers = append(activeStreamers, streamingService)
+	}
+
+	return activeStreamers, nil
+}
+
+// exposeStoreKeysSorted returns the store keys whose names are in the given
+// list, sorted by name. A "*" in the list selects all store keys.
+func exposeStoreKeysSorted(keysStr []string, keys map[string]*types.KVStoreKey) []types.StoreKey {
+	var exposeStoreKeys []types.StoreKey
+
+	exposeAll := false
+	for _, keyStr := range keysStr {
+		if keyStr == "*" {
+			exposeAll = true
+			break
+		}
+	}
+
+	if exposeAll {
+		exposeStoreKeys = make([]types.StoreKey, 0, len(keys))
+		for _, storeKey := range keys {
+			exposeStoreKeys = append(exposeStoreKeys, storeKey)
+		}
+	} else {
+		exposeStoreKeys = make([]types.StoreKey, 0, len(keysStr))
+		for _, keyStr := range keysStr {
+			if storeKey, ok := keys[keyStr]; ok {
+				exposeStoreKeys = append(exposeStoreKeys, storeKey)
+			}
+		}
+	}
+
+	// sort the keys so the listeners are registered deterministically
+	sort.SliceStable(exposeStoreKeys, func(i, j int) bool {
+		return exposeStoreKeys[i].Name() < exposeStoreKeys[j].Name()
+	})
+
+	return exposeStoreKeys
+}
@@ -0,0 +1,66 @@
+package streaming
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/store/streaming/file"
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+type fakeOptions map[string]interface{}
+
+func (f fakeOptions) Get(key string) interface{} { return f[key] }
+
+var (
+	mockKeys = []types.StoreKey{
+		types.NewKVStoreKey("mockKey1"),
+		types.NewKVStoreKey("mockKey2"),
+	}
+	interfaceRegistry = codecTypes.NewInterfaceRegistry()
+	testMarshaller    = codec.NewProtoCodec(interfaceRegistry)
+)
+
+func TestStreamingServiceConstructor(t *testing.T) {
+	_, err := NewServiceConstructor("unexpectedName")
+	require.NotNil(t, err)
+
+	constructor, err := NewServiceConstructor("file")
+	require.Nil(t, err)
+
+	opts := fakeOptions{
+		OptStreamersFileWriteDir:        t.TempDir(),
+		OptStreamersFilePrefix:          "prefix",
+		OptStreamersFileStopNodeOnError: true,
+	}
+	service, err := constructor(opts, mockKeys, testMarshaller)
+	require.Nil(t, err)
+	require.IsType(t, &file.StreamingService{}, service)
+	require.True(t, service.HaltAppOnDeliveryError())
+
+	listeners := service.Listeners()
+	for _, key := range mockKeys {
+		_, ok := listeners[key]
+		require.True(t, ok)
+	}
+
+	_, err = constructor(fakeOptions{}, mockKeys, testMarshaller)
+	require.Error(t, err)
+}
+
+func TestExposeStoreKeysSorted(t *testing.T) {
+	keys := map[string]*types.KVStoreKey{
+		"b": types.NewKVStoreKey("b"),
+		"a": types.NewKVStoreKey("a"),
+		"c": types.NewKVStoreKey("c"),
+	}
+
+	exposed := exposeStoreKeysSorted([]string{"*"}, keys)
+	require.Equal(t, []types.StoreKey{keys["a"], keys["b"], keys["c"]}, exposed)
+
+	exposed = exposeStoreKeysSorted([]string{"c", "a", "unknown"}, keys)
+	require.Equal(t, []types.StoreKey{keys["a"], keys["c"]}, exposed)
+}
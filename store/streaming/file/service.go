@@ -0,0 +1,224 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var _ baseapp.StreamingService = &StreamingService{}
+
+// StreamingService is a concrete implementation of baseapp.StreamingService
+// that writes the state changes and ABCI messages of every committed block to
+// a separate file.
+//
+// Each block file is named "{prefix}block-{height}" and contains a
+// length-prefixed protobuf encoded BlockMetadata followed by the
+// length-prefixed protobuf encoded StoreKVPairs of every state change made in
+// the block, in the order they were written to the root multistore.
+type StreamingService struct {
+	listenersByKey  map[types.StoreKey][]types.WriteListener
+	filePrefix      string            // optional prefix for each of the generated files
+	writeDir        string            // directory to write files into
+	codec           codec.BinaryCodec // marshaller used for re-marshalling the ABCI messages to write them out to the destination files
+	stopNodeOnError bool              // halt the node if the block file fails to be written
+	fsync           bool              // sync the block file to disk before it is renamed into place
+
+	mtx           sync.Mutex
+	buffer        *bytes.Buffer // buffer of the length-prefixed StoreKVPairs of the current block
+	blockMetadata types.BlockMetadata
+	closed        bool
+}
+
+// NewStreamingService creates a new StreamingService for the provided
+// writeDir, (optional) filePrefix, and storeKeys. The write directory is
+// created if it does not exist.
+func NewStreamingService(
+	writeDir, filePrefix string, storeKeys []types.StoreKey, c codec.BinaryCodec,
+	stopNodeOnError, fsync bool,
+) (*StreamingService, error) {
+	if err := os.MkdirAll(writeDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create write directory %s: %w", writeDir, err)
+	}
+
+	// sanity check that the directory is writable
+	if err := isDirWriteable(writeDir); err != nil {
+		return nil, err
+	}
+
+	ss := &StreamingService{
+		filePrefix:      filePrefix,
+		writeDir:        writeDir,
+		codec:           c,
+		stopNodeOnError: stopNodeOnError,
+		fsync:           fsync,
+		buffer:          new(bytes.Buffer),
+		listenersByKey:  make(map[types.StoreKey][]types.WriteListener, len(storeKeys)),
+	}
+
+	listener := types.NewStoreKVPairWriteListener(&lockedWriter{ss: ss}, c)
+	for _, key := range storeKeys {
+		ss.listenersByKey[key] = append(ss.listenersByKey[key], listener)
+	}
+
+	return ss, nil
+}
+
+// Listeners satisfies the baseapp.StreamingService interface. It returns the
+// StreamingService's underlying WriteListeners by StoreKey.
+func (fss *StreamingService) Listeners() map[types.StoreKey][]types.WriteListener {
+	return fss.listenersByKey
+}
+
+// ListenBeginBlock satisfies the baseapp.ABCIListener interface. It resets the
+// state of the service for the new block and records the BeginBlock messages.
+func (fss *StreamingService) ListenBeginBlock(_ sdk.Context, req abci.RequestBeginBlock, res abci.ResponseBeginBlock) error {
+	fss.mtx.Lock()
+	defer fss.mtx.Unlock()
+
+	fss.buffer.Reset()
+	fss.blockMetadata = types.BlockMetadata{
+		RequestBeginBlock:  &req,
+		ResponseBeginBlock: &res,
+	}
+
+	return nil
+}
+
+// ListenDeliverTx satisfies the baseapp.ABCIListener interface. It records the
+// DeliverTx messages.
+func (fss *StreamingService) ListenDeliverTx(_ sdk.Context, req abci.RequestDeliverTx, res abci.ResponseDeliverTx) error {
+	fss.mtx.Lock()
+	defer fss.mtx.Unlock()
+
+	fss.blockMetadata.DeliverTxs = append(fss.blockMetadata.DeliverTxs, &types.BlockMetadata_DeliverTx{
+		Request:  &req,
+		Response: &res,
+	})
+
+	return nil
+}
+
+// ListenEndBlock satisfies the baseapp.ABCIListener interface. It records the
+// EndBlock messages.
+func (fss *StreamingService) ListenEndBlock(_ sdk.Context, req abci.RequestEndBlock, res abci.ResponseEndBlock) error {
+	fss.mtx.Lock()
+	defer fss.mtx.Unlock()
+
+	fss.blockMetadata.RequestEndBlock = &req
+	fss.blockMetadata.ResponseEndBlock = &res
+
+	return nil
+}
+
+// ListenCommit satisfies the baseapp.ABCIListener interface. It writes the
+// block file of the committed block. The file is first written under a
+// temporary name and renamed into place once complete, so consumers never
+// observe a partially written block file.
+func (fss *StreamingService) ListenCommit(ctx sdk.Context, res abci.ResponseCommit) error {
+	fss.mtx.Lock()
+	defer fss.mtx.Unlock()
+
+	if fss.closed {
+		return errors.New("streaming service is closed")
+	}
+
+	fss.blockMetadata.ResponseCommit = &res
+	defer func() {
+		fss.buffer.Reset()
+		fss.blockMetadata = types.BlockMetadata{}
+	}()
+
+	bz, err := fss.codec.MarshalLengthPrefixed(&fss.blockMetadata)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%sblock-%d", fss.filePrefix, ctx.BlockHeight())
+	return fss.writeFile(name, bz, fss.buffer.Bytes())
+}
+
+// HaltAppOnDeliveryError satisfies the baseapp.ABCIListener interface.
+func (fss *StreamingService) HaltAppOnDeliveryError() bool {
+	return fss.stopNodeOnError
+}
+
+// Close satisfies the io.Closer interface. Any buffered state of a block that
+// has not been committed is discarded.
+func (fss *StreamingService) Close() error {
+	fss.mtx.Lock()
+	defer fss.mtx.Unlock()
+
+	fss.closed = true
+	fss.buffer.Reset()
+	fss.blockMetadata = types.BlockMetadata{}
+
+	return nil
+}
+
+// writeFile atomically writes the concatenation of the given chunks to the
+// named file in the write directory.
+func (fss *StreamingService) writeFile(name string, chunks ...[]byte) (err error) {
+	f, err := os.CreateTemp(fss.writeDir, name+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	for _, chunk := range chunks {
+		if _, err = f.Write(chunk); err != nil {
+			return err
+		}
+	}
+
+	if fss.fsync {
+		if err = f.Sync(); err != nil {
+			return err
+		}
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filepath.Join(fss.writeDir, name))
+}
+
+// lockedWriter appends to the buffer of the StreamingService while holding its
+// lock, as the WriteListeners are invoked outside of the ABCI hooks.
+type lockedWriter struct {
+	ss *StreamingService
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.ss.mtx.Lock()
+	defer w.ss.mtx.Unlock()
+
+	return w.ss.buffer.Write(p)
+}
+
+// isDirWriteable checks if dir is writable by writing and removing a file
+// to dir. It returns nil if dir is writable.
+func isDirWriteable(dir string) error {
+	f := filepath.Join(dir, ".touch")
+	if err := os.WriteFile(f, []byte(""), 0600); err != nil {
+		return err
+	}
+
+	return os.Remove(f)
+}
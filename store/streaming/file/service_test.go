@@ -0,0 +1,142 @@
+package file
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codecTypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var (
+	interfaceRegistry = codecTypes.NewInterfaceRegistry()
+	testMarshaller    = codec.NewProtoCodec(interfaceRegistry)
+
+	mockStoreKey1 = types.NewKVStoreKey("mockStore1")
+	mockStoreKey2 = types.NewKVStoreKey("mockStore2")
+	mockStoreKey3 = types.NewKVStoreKey("mockStore3")
+
+	testPrefix = "testPrefix-"
+)
+
+func newTestStreamingService(t *testing.T, dir string) *StreamingService {
+	fss, err := NewStreamingService(dir, testPrefix, []types.StoreKey{mockStoreKey1, mockStoreKey2}, testMarshaller, true, true)
+	require.NoError(t, err)
+	require.True(t, fss.HaltAppOnDeliveryError())
+
+	return fss
+}
+
+func TestFileStreamingService(t *testing.T) {
+	dir := t.TempDir()
+	fss := newTestStreamingService(t, dir)
+
+	listeners := fss.Listeners()
+	require.Len(t, listeners, 2)
+	require.Len(t, listeners[mockStoreKey1], 1)
+	require.Len(t, listeners[mockStoreKey2], 1)
+	require.Empty(t, listeners[mockStoreKey3])
+
+	ctx := sdk.NewContext(nil, tmproto.Header{Height: 1}, false, log.NewNopLogger())
+
+	reqBeginBlock := abci.RequestBeginBlock{Header: tmproto.Header{Height: 1}}
+	resBeginBlock := abci.ResponseBeginBlock{Events: []abci.Event{{Type: "begin"}}}
+	reqDeliverTx := abci.RequestDeliverTx{Tx: []byte("tx")}
+	resDeliverTx := abci.ResponseDeliverTx{Code: 1, Log: "failed"}
+	reqEndBlock := abci.RequestEndBlock{Height: 1}
+	resEndBlock := abci.ResponseEndBlock{Events: []abci.Event{{Type: "end"}}}
+	resCommit := abci.ResponseCommit{Data: []byte("hash")}
+
+	require.NoError(t, fss.ListenBeginBlock(ctx, reqBeginBlock, resBeginBlock))
+	require.NoError(t, fss.ListenDeliverTx(ctx, reqDeliverTx, resDeliverTx))
+	require.NoError(t, fss.ListenEndBlock(ctx, reqEndBlock, resEndBlock))
+
+	// state changes are only written to the listeners on commit
+	pairs := []types.StoreKVPair{
+		{StoreKey: mockStoreKey1.Name(), Key: []byte("key1"), Value: []byte("value1")},
+		{StoreKey: mockStoreKey2.Name(), Key: []byte("key2"), Delete: true},
+	}
+	for _, pair := range pairs {
+		key := mockStoreKey1
+		if pair.StoreKey == mockStoreKey2.Name() {
+			key = mockStoreKey2
+		}
+		require.NoError(t, listeners[key][0].OnWrite(key, pair.Key, pair.Value, pair.Delete))
+	}
+	require.NoError(t, fss.ListenCommit(ctx, resCommit))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, testPrefix+"block-1", entries[0].Name())
+
+	bz, err := os.ReadFile(filepath.Join(dir, testPrefix+"block-1"))
+	require.NoError(t, err)
+
+	chunks := readLengthPrefixed(t, bz)
+	require.Len(t, chunks, 3)
+
+	var metadata types.BlockMetadata
+	require.NoError(t, testMarshaller.Unmarshal(chunks[0], &metadata))
+	require.Equal(t, reqBeginBlock, *metadata.RequestBeginBlock)
+	require.Equal(t, resBeginBlock, *metadata.ResponseBeginBlock)
+	require.Len(t, metadata.DeliverTxs, 1)
+	require.Equal(t, reqDeliverTx, *metadata.DeliverTxs[0].Request)
+	require.Equal(t, resDeliverTx, *metadata.DeliverTxs[0].Response)
+	require.Equal(t, reqEndBlock, *metadata.RequestEndBlock)
+	require.Equal(t, resEndBlock, *metadata.ResponseEndBlock)
+	require.Equal(t, resCommit, *metadata.ResponseCommit)
+
+	for i, pair := range pairs {
+		var got types.StoreKVPair
+		require.NoError(t, testMarshaller.Unmarshal(chunks[i+1], &got))
+		require.Equal(t, pair.StoreKey, got.StoreKey)
+		require.Equal(t, pair.Key, got.Key)
+		require.Equal(t, pair.Delete, got.Delete)
+		require.Equal(t, len(pair.Value), len(got.Value))
+	}
+
+	// the next block starts with an empty buffer
+	ctx = ctx.WithBlockHeight(2)
+	require.NoError(t, fss.ListenBeginBlock(ctx, abci.RequestBeginBlock{}, abci.ResponseBeginBlock{}))
+	require.NoError(t, fss.ListenCommit(ctx, resCommit))
+
+	bz, err = os.ReadFile(filepath.Join(dir, testPrefix+"block-2"))
+	require.NoError(t, err)
+	require.Len(t, readLengthPrefixed(t, bz), 1)
+
+	require.NoError(t, fss.Close())
+	require.Error(t, fss.ListenCommit(ctx, resCommit))
+}
+
+func TestFileStreamingServiceUnwritableDir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+
+	_, err := NewStreamingService(path, testPrefix, nil, testMarshaller, false, false)
+	require.Error(t, err)
+}
+
+func readLengthPrefixed(t *testing.T, bz []byte) [][]byte {
+	var chunks [][]byte
+	for len(bz) > 0 {
+		size, n := binary.Uvarint(bz)
+		require.Positive(t, n)
+		bz = bz[n:]
+		require.GreaterOrEqual(t, uint64(len(bz)), size)
+		chunks = append(chunks, bz[:size])
+		bz = bz[size:]
+	}
+
+	return chunks
+}
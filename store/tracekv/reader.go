@@ -0,0 +1,146 @@
+package tracekv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxBinaryRecordSize bounds the size of the binary records read, to not
+// allocate arbitrary amounts of memory on corrupted traces.
+const maxBinaryRecordSize = 64 << 20
+
+// RecordReader reads the Records of a trace. The format of every record is
+// detected from its first byte, so that traces written by successive runs in
+// different formats can be read. The JSON lines written without a Tracer are
+// read as Records too, with the context of their metadata.
+type RecordReader struct {
+	r *bufio.Reader
+}
+
+// NewRecordReader returns a RecordReader reading from r.
+func NewRecordReader(r io.Reader) *RecordReader {
+	return &RecordReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next Record of the trace, or io.EOF at the end of the trace.
+func (rr *RecordReader) Next() (Record, error) {
+	for {
+		first, err := rr.r.Peek(1)
+		if err != nil {
+			return Record{}, err
+		}
+
+		switch first[0] {
+		case '\n', '\r', ' ':
+			if _, err := rr.r.ReadByte(); err != nil {
+				return Record{}, err
+			}
+		case '{':
+			return rr.nextJSON()
+		case binaryRecordMarker:
+			return rr.nextBinary()
+		default:
+			return Record{}, fmt.Errorf("invalid trace record starting with byte %#x", first[0])
+		}
+	}
+}
+
+func (rr *RecordReader) nextJSON() (Record, error) {
+	line, err := rr.r.ReadBytes('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+		return Record{}, err
+	}
+
+	rec := struct {
+		Record
+		Metadata map[string]interface{} `json:"metadata"`
+	}{Record: Record{TxIndex: -1, MsgIndex: -1}}
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return Record{}, fmt.Errorf("invalid trace record: %w", err)
+	}
+
+	if rec.Metadata != nil {
+		rec.BlockHeight = contextInt(rec.Metadata, TraceContextBlockHeight, rec.BlockHeight)
+		rec.TxIndex = contextInt(rec.Metadata, TraceContextTxIndex, rec.TxIndex)
+		rec.MsgIndex = contextInt(rec.Metadata, TraceContextMsgIndex, rec.MsgIndex)
+		if hash, ok := rec.Metadata[TraceContextTxHash].(string); ok {
+			rec.TxHash = hash
+		}
+	}
+
+	return rec.Record, nil
+}
+
+func (rr *RecordReader) nextBinary() (Record, error) {
+	if _, err := rr.r.ReadByte(); err != nil {
+		return Record{}, err
+	}
+	size, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return Record{}, unexpectedEOF(err)
+	}
+	if size > maxBinaryRecordSize {
+		return Record{}, fmt.Errorf("trace record of %d bytes exceeds the maximum size", size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(rr.r, payload); err != nil {
+		return Record{}, unexpectedEOF(err)
+	}
+
+	return decodeBinaryRecord(payload)
+}
+
+// decodeBinaryRecord decodes a payload encoded by appendBinaryRecord.
+func decodeBinaryRecord(payload []byte) (Record, error) {
+	errInvalid := errors.New("invalid binary trace record")
+	if len(payload) == 0 {
+		return Record{}, errInvalid
+	}
+
+	op, ok := codeOperations[payload[0]]
+	if !ok {
+		return Record{}, fmt.Errorf("unknown trace operation code %d", payload[0])
+	}
+	rec := Record{Operation: string(op)}
+	payload = payload[1:]
+
+	for _, v := range []*int64{&rec.BlockHeight, &rec.TxIndex, &rec.MsgIndex} {
+		n := 0
+		if *v, n = binary.Varint(payload); n <= 0 {
+			return Record{}, errInvalid
+		}
+		payload = payload[n:]
+	}
+
+	var txHash, store, module []byte
+	for _, field := range []*[]byte{&txHash, &store, &module, &rec.Key, &rec.Value, &rec.IterStart, &rec.IterEnd} {
+		size, n := binary.Uvarint(payload)
+		if n <= 0 || uint64(len(payload)-n) < size {
+			return Record{}, errInvalid
+		}
+		if size > 0 {
+			*field = payload[n : n+int(size)]
+		}
+		payload = payload[n+int(size):]
+	}
+	rec.TxHash, rec.Store, rec.Module = string(txHash), string(store), string(module)
+
+	if len(payload) != 1 {
+		return Record{}, errInvalid
+	}
+	rec.IterReverse = payload[0]&1 != 0
+
+	return rec, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
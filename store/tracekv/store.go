@@ -25,9 +25,10 @@ type (
 	// TODO: Should we use a buffered writer and implement Commit on
 	// Store?
 	Store struct {
-		parent  types.KVStore
-		writer  io.Writer
-		context types.TraceContext
+		parent   types.KVStore
+		writer   io.Writer
+		context  types.TraceContext
+		storeKey types.StoreKey
 	}
 
 	// operation represents an IO operation
@@ -48,12 +49,18 @@ func NewStore(parent types.KVStore, writer io.Writer, tc types.TraceContext) *St
 	return &Store{parent: parent, writer: writer, context: tc}
 }
 
+// NewStoreWithKey returns a reference to a new traceKVStore of the store of the
+// given key, whose name and module are recorded when the writer is a Tracer.
+func NewStoreWithKey(parent types.KVStore, writer io.Writer, tc types.TraceContext, key types.StoreKey) *Store {
+	return &Store{parent: parent, writer: writer, context: tc, storeKey: key}
+}
+
 // Get implements the KVStore interface. It traces a read operation and
 // delegates a Get call to the parent KVStore.
 func (tkv *Store) Get(key []byte) []byte {
 	value := tkv.parent.Get(key)
 
-	writeOperation(tkv.writer, readOp, tkv.context, key, value)
+	tkv.writeOperation(readOp, Record{Key: key, Value: value})
 	return value
 }
 
@@ -61,14 +68,14 @@ func (tkv *Store) Get(key []byte) []byte {
 // delegates the Set call to the parent KVStore.
 func (tkv *Store) Set(key []byte, value []byte) {
 	types.AssertValidKey(key)
-	writeOperation(tkv.writer, writeOp, tkv.context, key, value)
+	tkv.writeOperation(writeOp, Record{Key: key, Value: value})
 	tkv.parent.Set(key, value)
 }
 
 // Delete implements the KVStore interface. It traces a write operation and
 // delegates the Delete call to the parent KVStore.
 func (tkv *Store) Delete(key []byte) {
-	writeOperation(tkv.writer, deleteOp, tkv.context, key, nil)
+	tkv.writeOperation(deleteOp, Record{Key: key})
 	tkv.parent.Delete(key)
 }
 
@@ -101,17 +108,15 @@ func (tkv *Store) iterator(start, end []byte, ascending bool) types.Iterator {
 		parent = tkv.parent.ReverseIterator(start, end)
 	}
 
-	return newTraceIterator(tkv.writer, parent, tkv.context)
+	return &traceIterator{store: tkv, parent: parent, start: start, end: end, reverse: !ascending}
 }
 
 type traceIterator struct {
+	store   *Store
 	parent  types.Iterator
-	writer  io.Writer
-	context types.TraceContext
-}
-
-func newTraceIterator(w io.Writer, parent types.Iterator, tc types.TraceContext) types.Iterator {
-	return &traceIterator{writer: w, parent: parent, context: tc}
+	start   []byte
+	end     []byte
+	reverse bool
 }
 
 // Domain implements the Iterator interface.
@@ -133,7 +138,11 @@ func (ti *traceIterator) Next() {
 func (ti *traceIterator) Key() []byte {
 	key := ti.parent.Key()
 
-	writeOperation(ti.writer, iterKeyOp, ti.context, key, nil)
+	if _, ok := ti.store.writer.(*Tracer); ok {
+		ti.store.writeOperation(iterKeyOp, ti.record(key, nil))
+	} else {
+		writeOperation(ti.store.writer, iterKeyOp, ti.store.context, key, nil)
+	}
 	return key
 }
 
@@ -141,10 +150,18 @@ func (ti *traceIterator) Key() []byte {
 func (ti *traceIterator) Value() []byte {
 	value := ti.parent.Value()
 
-	writeOperation(ti.writer, iterValueOp, ti.context, nil, value)
+	if _, ok := ti.store.writer.(*Tracer); ok {
+		ti.store.writeOperation(iterValueOp, ti.record(ti.parent.Key(), value))
+	} else {
+		writeOperation(ti.store.writer, iterValueOp, ti.store.context, nil, value)
+	}
 	return value
 }
 
+func (ti *traceIterator) record(key, value []byte) Record {
+	return Record{Key: key, Value: value, IterStart: ti.start, IterEnd: ti.end, IterReverse: ti.reverse}
+}
+
 // Close implements the Iterator interface.
 func (ti *traceIterator) Close() error {
 	return ti.parent.Close()
@@ -178,6 +195,17 @@ func (tkv *Store) CacheWrapWithListeners(_ types.StoreKey, _ []types.WriteListen
 	panic("cannot CacheWrapWithListeners a TraceKVStore")
 }
 
+// writeOperation writes a KVStore operation as a Record if the writer is a
+// Tracer, and as a JSON line otherwise.
+func (tkv *Store) writeOperation(op operation, rec Record) {
+	if tracer, ok := tkv.writer.(*Tracer); ok {
+		tracer.trace(op, tkv.storeKey, tkv.context, rec)
+		return
+	}
+
+	writeOperation(tkv.writer, op, tkv.context, rec.Key, rec.Value)
+}
+
 // writeOperation writes a KVStore operation to the underlying io.Writer as
 // JSON-encoded data where the key/value pair is base64 encoded.
 func writeOperation(w io.Writer, op operation, tc types.TraceContext, key, value []byte) {
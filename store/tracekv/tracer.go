@@ -0,0 +1,245 @@
+package tracekv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+// The keys of the TraceContext set by the BaseApp, which the Tracer records.
+const (
+	TraceContextBlockHeight = "blockHeight"
+	TraceContextTxIndex     = "txIndex"
+	TraceContextTxHash      = "txHash"
+	TraceContextMsgIndex    = "msgIndex"
+)
+
+// TraceFormat is the format of the records written by a Tracer.
+type TraceFormat string
+
+const (
+	// TraceFormatJSON writes a JSON-encoded Record per line.
+	TraceFormatJSON TraceFormat = "json"
+	// TraceFormatBinary writes the Records in a compact length-prefixed binary
+	// encoding.
+	TraceFormatBinary TraceFormat = "binary"
+)
+
+// binaryRecordMarker starts every binary record, so that the format of a
+// trace can be told from its first byte.
+const binaryRecordMarker = 0xb7
+
+// Record is a traced KVStore operation along with the context it happened in.
+type Record struct {
+	Operation string `json:"operation"`
+	Store     string `json:"store,omitempty"`
+	Module    string `json:"module,omitempty"`
+
+	// BlockHeight is 0 outside of a block, and TxIndex and MsgIndex are -1
+	// outside of a tx and of a message.
+	BlockHeight int64  `json:"block_height"`
+	TxIndex     int64  `json:"tx_index"`
+	TxHash      string `json:"tx_hash,omitempty"`
+	MsgIndex    int64  `json:"msg_index"`
+
+	Key   []byte `json:"key,omitempty"`
+	Value []byte `json:"value,omitempty"`
+
+	// IterStart, IterEnd and IterReverse describe the range of the iterator
+	// of iterKey and iterValue operations.
+	IterStart   []byte `json:"iter_start,omitempty"`
+	IterEnd     []byte `json:"iter_end,omitempty"`
+	IterReverse bool   `json:"iter_reverse,omitempty"`
+}
+
+// Filter matches the operations on the keys with the given prefix of the store
+// of the given name. An empty store name matches all stores.
+type Filter struct {
+	StoreKey  string
+	KeyPrefix []byte
+}
+
+// ParseFilter parses a filter of the form <store>[:<hex key prefix>].
+func ParseFilter(s string) (Filter, error) {
+	parts := strings.SplitN(s, ":", 2)
+	var keyPrefix []byte
+	if len(parts) == 2 {
+		var err error
+		if keyPrefix, err = hex.DecodeString(parts[1]); err != nil {
+			return Filter{}, fmt.Errorf("invalid key prefix in trace filter %q: %w", s, err)
+		}
+	}
+
+	return Filter{StoreKey: parts[0], KeyPrefix: keyPrefix}, nil
+}
+
+func (f Filter) matches(store string, key []byte) bool {
+	return (f.StoreKey == "" || f.StoreKey == store) && bytes.HasPrefix(key, f.KeyPrefix)
+}
+
+// TracerOptions are the options of a Tracer.
+type TracerOptions struct {
+	// Format defaults to TraceFormatJSON.
+	Format TraceFormat
+	// Include, if not empty, restricts the traced operations to the ones
+	// matching any of the filters.
+	Include []Filter
+	// Exclude skips the operations matching any of the filters.
+	Exclude []Filter
+	// Modules maps store key names to module names. The module of a store
+	// which is not mapped is the name of its key, which is the module name for
+	// most modules.
+	Modules map[string]string
+}
+
+// Tracer writes structured trace records. Setting a Tracer as the trace writer
+// of a MultiStore enables structured tracing: the stores write Records to the
+// Tracer instead of the default JSON lines. It is safe for concurrent use.
+type Tracer struct {
+	mtx  sync.Mutex
+	w    io.Writer
+	opts TracerOptions
+	buf  []byte
+}
+
+var _ io.Writer = (*Tracer)(nil)
+
+// NewTracer returns a Tracer writing to w.
+func NewTracer(w io.Writer, opts TracerOptions) (*Tracer, error) {
+	switch opts.Format {
+	case "":
+		opts.Format = TraceFormatJSON
+	case TraceFormatJSON, TraceFormatBinary:
+	default:
+		return nil, fmt.Errorf("unknown trace format %q", opts.Format)
+	}
+
+	return &Tracer{w: w, opts: opts}, nil
+}
+
+// Write implements io.Writer. It writes p to the underlying writer as is.
+func (t *Tracer) Write(p []byte) (int, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return t.w.Write(p)
+}
+
+// trace writes the record of an operation on the store of the given key, if it
+// is not filtered out.
+func (t *Tracer) trace(op operation, key types.StoreKey, tc types.TraceContext, rec Record) {
+	if key != nil {
+		rec.Store = key.Name()
+		rec.Module = rec.Store
+		if module, ok := t.opts.Modules[rec.Store]; ok {
+			rec.Module = module
+		}
+	}
+	if !t.included(rec.Store, rec.Key) {
+		return
+	}
+
+	rec.Operation = string(op)
+	rec.BlockHeight = contextInt(tc, TraceContextBlockHeight, 0)
+	rec.TxIndex = contextInt(tc, TraceContextTxIndex, -1)
+	rec.MsgIndex = contextInt(tc, TraceContextMsgIndex, -1)
+	if hash, ok := tc[TraceContextTxHash].(string); ok {
+		rec.TxHash = hash
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	var err error
+	if t.opts.Format == TraceFormatBinary {
+		t.buf = appendBinaryRecord(t.buf[:0], rec)
+		_, err = t.w.Write(t.buf)
+	} else {
+		var raw []byte
+		if raw, err = json.Marshal(rec); err == nil {
+			_, err = t.w.Write(append(raw, '\n'))
+		}
+	}
+	if err != nil {
+		panic(fmt.Errorf("failed to write trace record: %w", err))
+	}
+}
+
+func (t *Tracer) included(store string, key []byte) bool {
+	for _, f := range t.opts.Exclude {
+		if f.matches(store, key) {
+			return false
+		}
+	}
+	if len(t.opts.Include) == 0 {
+		return true
+	}
+	for _, f := range t.opts.Include {
+		if f.matches(store, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// contextInt returns the integer value of a key of the TraceContext, or the
+// default value if it is not set.
+func contextInt(tc types.TraceContext, key string, defaultValue int64) int64 {
+	switch v := tc[key].(type) {
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	case float64:
+		return int64(v)
+	default:
+		return defaultValue
+	}
+}
+
+// The codes of the operations in the binary format.
+var (
+	operationCodes = map[operation]byte{readOp: 1, writeOp: 2, deleteOp: 3, iterKeyOp: 4, iterValueOp: 5}
+	codeOperations = map[byte]operation{1: readOp, 2: writeOp, 3: deleteOp, 4: iterKeyOp, 5: iterValueOp}
+)
+
+// appendBinaryRecord appends the binary encoding of the record: a marker byte,
+// the uvarint length of the payload and the payload, made of the operation
+// code, the block height, tx index and message index as varints, then the tx
+// hash, store, module, key, value, iterator start and end as length-prefixed
+// bytes, and a flags byte.
+func appendBinaryRecord(buf []byte, rec Record) []byte {
+	var (
+		payload []byte
+		scratch [binary.MaxVarintLen64]byte
+	)
+	payload = append(payload, operationCodes[operation(rec.Operation)])
+	for _, v := range []int64{rec.BlockHeight, rec.TxIndex, rec.MsgIndex} {
+		payload = append(payload, scratch[:binary.PutVarint(scratch[:], v)]...)
+	}
+	for _, field := range [][]byte{
+		[]byte(rec.TxHash), []byte(rec.Store), []byte(rec.Module), rec.Key, rec.Value, rec.IterStart, rec.IterEnd,
+	} {
+		payload = append(payload, scratch[:binary.PutUvarint(scratch[:], uint64(len(field)))]...)
+		payload = append(payload, field...)
+	}
+
+	var flags byte
+	if rec.IterReverse {
+		flags |= 1
+	}
+	payload = append(payload, flags)
+
+	buf = append(buf, binaryRecordMarker)
+	buf = append(buf, scratch[:binary.PutUvarint(scratch[:], uint64(len(payload)))]...)
+	return append(buf, payload...)
+}
@@ -0,0 +1,150 @@
+package tracekv_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/store/dbadapter"
+	"github.com/cosmos/cosmos-sdk/store/tracekv"
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+func newTracedStore(t *testing.T, w io.Writer, opts tracekv.TracerOptions, tc types.TraceContext) *tracekv.Store {
+	tracer, err := tracekv.NewTracer(w, opts)
+	require.NoError(t, err)
+
+	memDB := dbadapter.Store{DB: dbm.NewMemDB()}
+	return tracekv.NewStoreWithKey(memDB, tracer, tc, types.NewKVStoreKey("bank"))
+}
+
+func readRecords(t *testing.T, r io.Reader) []tracekv.Record {
+	var records []tracekv.Record
+
+	rr := tracekv.NewRecordReader(r)
+	for {
+		rec, err := rr.Next()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, rec)
+	}
+}
+
+func TestTracerRoundTrip(t *testing.T) {
+	for _, format := range []tracekv.TraceFormat{tracekv.TraceFormatJSON, tracekv.TraceFormatBinary} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			tc := types.TraceContext{
+				tracekv.TraceContextBlockHeight: int64(7),
+				tracekv.TraceContextTxIndex:     2,
+				tracekv.TraceContextTxHash:      "ABCD",
+			}
+			store := newTracedStore(t, &buf, tracekv.TracerOptions{
+				Format:  format,
+				Modules: map[string]string{"bank": "banking"},
+			}, tc)
+
+			store.Set(keyFmt(1), valFmt(1))
+			tc[tracekv.TraceContextMsgIndex] = 0
+			store.Get(keyFmt(1))
+			store.Delete(keyFmt(2))
+
+			iter := store.ReverseIterator(keyFmt(0), keyFmt(9))
+			iter.Key()
+			iter.Value()
+			require.NoError(t, iter.Close())
+
+			if format == tracekv.TraceFormatJSON {
+				require.Equal(t, 5, strings.Count(buf.String(), "\n"))
+			}
+
+			record := func(op string, msgIndex int64, key, value []byte, iter bool) tracekv.Record {
+				rec := tracekv.Record{
+					Operation: op, Store: "bank", Module: "banking",
+					BlockHeight: 7, TxIndex: 2, TxHash: "ABCD", MsgIndex: msgIndex,
+					Key: key, Value: value,
+				}
+				if iter {
+					rec.IterStart, rec.IterEnd, rec.IterReverse = keyFmt(0), keyFmt(9), true
+				}
+				return rec
+			}
+			expected := []tracekv.Record{
+				record("write", -1, keyFmt(1), valFmt(1), false),
+				record("read", 0, keyFmt(1), valFmt(1), false),
+				record("delete", 0, keyFmt(2), nil, false),
+				record("iterKey", 0, keyFmt(1), nil, true),
+				record("iterValue", 0, keyFmt(1), valFmt(1), true),
+			}
+
+			require.Equal(t, expected, readRecords(t, &buf))
+		})
+	}
+}
+
+func TestTracerFilters(t *testing.T) {
+	include, err := tracekv.ParseFilter("bank:6b6579")
+	require.NoError(t, err)
+	exclude, err := tracekv.ParseFilter(":6b657930303030303030")
+	require.NoError(t, err)
+	require.Equal(t, tracekv.Filter{StoreKey: "", KeyPrefix: []byte("key0000000")}, exclude)
+
+	_, err = tracekv.ParseFilter("bank:zz")
+	require.Error(t, err)
+
+	var buf bytes.Buffer
+	store := newTracedStore(t, &buf, tracekv.TracerOptions{
+		Include: []tracekv.Filter{include},
+		Exclude: []tracekv.Filter{exclude},
+	}, types.TraceContext{})
+
+	store.Set([]byte("other"), valFmt(1))
+	store.Set(keyFmt(1), valFmt(1))
+	store.Set(keyFmt(10), valFmt(10))
+
+	records := readRecords(t, &buf)
+	require.Len(t, records, 1)
+	require.Equal(t, keyFmt(10), records[0].Key)
+	require.Equal(t, int64(0), records[0].BlockHeight)
+	require.Equal(t, int64(-1), records[0].TxIndex)
+	require.Equal(t, int64(-1), records[0].MsgIndex)
+
+	_, err = tracekv.NewTracer(&buf, tracekv.TracerOptions{Format: "xml"})
+	require.Error(t, err)
+}
+
+func TestRecordReaderMixedFormats(t *testing.T) {
+	var buf bytes.Buffer
+
+	// records written without a Tracer
+	store := tracekv.NewStore(dbadapter.Store{DB: dbm.NewMemDB()}, &buf, types.TraceContext{
+		tracekv.TraceContextBlockHeight: 3,
+		tracekv.TraceContextTxIndex:     1,
+	})
+	store.Set(keyFmt(1), valFmt(1))
+
+	binaryStore := newTracedStore(t, &buf, tracekv.TracerOptions{Format: tracekv.TraceFormatBinary}, types.TraceContext{
+		tracekv.TraceContextBlockHeight: 4,
+	})
+	binaryStore.Get(keyFmt(1))
+
+	records := readRecords(t, &buf)
+	require.Equal(t, []tracekv.Record{
+		{Operation: "write", BlockHeight: 3, TxIndex: 1, MsgIndex: -1, Key: keyFmt(1), Value: valFmt(1)},
+		{Operation: "read", Store: "bank", Module: "bank", BlockHeight: 4, TxIndex: -1, MsgIndex: -1, Key: keyFmt(1)},
+	}, records)
+
+	_, err := tracekv.NewRecordReader(strings.NewReader("garbage")).Next()
+	require.Error(t, err)
+
+	var truncated bytes.Buffer
+	newTracedStore(t, &truncated, tracekv.TracerOptions{Format: tracekv.TraceFormatBinary}, nil).Set(keyFmt(1), valFmt(1))
+	_, err = tracekv.NewRecordReader(bytes.NewReader(truncated.Bytes()[:truncated.Len()-3])).Next()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -186,13 +186,13 @@ func (g *infiniteGasMeter) String() string {
 
 // GasConfig defines gas cost for each operation on KVStores
 type GasConfig struct {
-	HasCost          Gas
-	DeleteCost       Gas
-	ReadCostFlat     Gas
-	ReadCostPerByte  Gas
-	WriteCostFlat    Gas
-	WriteCostPerByte Gas
-	IterNextCostFlat Gas
+	HasCost          Gas `json:"has_cost" yaml:"has_cost"`
+	DeleteCost       Gas `json:"delete_cost" yaml:"delete_cost"`
+	ReadCostFlat     Gas `json:"read_cost_flat" yaml:"read_cost_flat"`
+	ReadCostPerByte  Gas `json:"read_cost_per_byte" yaml:"read_cost_per_byte"`
+	WriteCostFlat    Gas `json:"write_cost_flat" yaml:"write_cost_flat"`
+	WriteCostPerByte Gas `json:"write_cost_per_byte" yaml:"write_cost_per_byte"`
+	IterNextCostFlat Gas `json:"iter_next_cost_flat" yaml:"iter_next_cost_flat"`
 }
 
 // KVGasConfig returns a default gas config for KVStores.
@@ -126,9 +126,10 @@ type MultiStore interface {
 	// returned.
 	SetTracer(w io.Writer) MultiStore
 
-	// SetTracingContext sets the tracing context for a MultiStore. It is
-	// implied that the caller should update the context when necessary between
-	// tracing operations. The modified MultiStore is returned.
+	// SetTracingContext sets the tracing context for a MultiStore. The keys
+	// with a nil value are removed from the context. It is implied that the
+	// caller should update the context when necessary between tracing
+	// operations. The modified MultiStore is returned.
 	SetTracingContext(TraceContext) MultiStore
 
 	// ListeningEnabled returns if listening is enabled for the KVStore belonging the provided StoreKey
@@ -137,12 +138,20 @@ type MultiStore interface {
 	// AddListeners adds WriteListeners for the KVStore belonging to the provided StoreKey
 	// It appends the listeners to a current set, if one already exists
 	AddListeners(key StoreKey, listeners []WriteListener)
+
+	// GetGasConfig returns the gas config overriding the default one of the
+	// KVStore belonging to the provided StoreKey, if any.
+	GetGasConfig(key StoreKey) (GasConfig, bool)
 }
 
 // From MultiStore.CacheMultiStore()....
 type CacheMultiStore interface {
 	MultiStore
 	Write() // Writes operations to underlying KVStore
+
+	// WithGasConfigs returns a copy of the store sharing its cached writes,
+	// whose KVStores use the given gas configs instead of the default ones.
+	WithGasConfigs(configs map[StoreKey]GasConfig) CacheMultiStore
 }
 
 // CommitMultiStore is an interface for a MultiStore without cache capabilities.
@@ -188,6 +197,16 @@ type CommitMultiStore interface {
 	// SetInitialVersion sets the initial version of the IAVL tree. It is used when
 	// starting a new chain at an arbitrary height.
 	SetInitialVersion(version int64) error
+
+	// RollbackToVersion deletes all the versions above the given version and
+	// makes it the latest version. It errors without modifying any store if
+	// the version does not exist, e.g. because it has been pruned.
+	RollbackToVersion(version int64) error
+
+	// SetGasConfigs replaces the gas configs overriding the default ones of
+	// the KVStores belonging to the provided StoreKeys. The overrides apply to
+	// the branches of the MultiStore created afterwards.
+	SetGasConfigs(configs map[StoreKey]GasConfig)
 }
 
 //---------subsp-------------------------------
@@ -296,6 +315,7 @@ const (
 	StoreTypeIAVL
 	StoreTypeTransient
 	StoreTypeMemory
+	StoreTypeSMT
 )
 
 func (st StoreType) String() string {
@@ -314,6 +334,9 @@ func (st StoreType) String() string {
 
 	case StoreTypeMemory:
 		return "StoreTypeMemory"
+
+	case StoreTypeSMT:
+		return "StoreTypeSMT"
 	}
 
 	return "unknown store type"
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	metrics "github.com/armon/go-metrics"
@@ -13,8 +15,32 @@ import (
 )
 
 // globalLabels defines the set of global labels that will be applied to all
-// metrics emitted using the telemetry package function wrappers.
-var globalLabels = []metrics.Label{}
+// metrics emitted using the telemetry package function wrappers. The slice is
+// replaced by SetGlobalLabels, and never modified in place.
+var (
+	globalLabelsMtx sync.RWMutex
+	globalLabels    = []metrics.Label{}
+)
+
+// SetGlobalLabels replaces the global labels, given as name/value tuples,
+// which are applied to all metrics emitted using the telemetry package
+// function wrappers. It is safe for concurrent use.
+func SetGlobalLabels(labels [][]string) {
+	parsedGlobalLabels := make([]metrics.Label, len(labels))
+	for i, gl := range labels {
+		parsedGlobalLabels[i] = NewLabel(gl[0], gl[1])
+	}
+
+	globalLabelsMtx.Lock()
+	defer globalLabelsMtx.Unlock()
+	globalLabels = parsedGlobalLabels
+}
+
+func getGlobalLabels() []metrics.Label {
+	globalLabelsMtx.RLock()
+	defer globalLabelsMtx.RUnlock()
+	return globalLabels
+}
 
 // Metrics supported format types.
 const (
@@ -62,6 +88,7 @@ type Config struct {
 type Metrics struct {
 	memSink           *metrics.InmemSink
 	prometheusEnabled bool
+	sink              *toggleSink
 }
 
 // GatherResponse is the response type of registered metrics
@@ -76,13 +103,8 @@ func New(cfg Config) (*Metrics, error) {
 		return nil, nil
 	}
 
-	if numGlobalLables := len(cfg.GlobalLabels); numGlobalLables > 0 {
-		parsedGlobalLabels := make([]metrics.Label, numGlobalLables)
-		for i, gl := range cfg.GlobalLabels {
-			parsedGlobalLabels[i] = NewLabel(gl[0], gl[1])
-		}
-
-		globalLabels = parsedGlobalLabels
+	if len(cfg.GlobalLabels) > 0 {
+		SetGlobalLabels(cfg.GlobalLabels)
 	}
 
 	metricsConf := metrics.DefaultConfig(cfg.ServiceName)
@@ -109,17 +131,29 @@ func New(cfg Config) (*Metrics, error) {
 		fanout = append(fanout, promSink)
 	}
 
-	if _, err := metrics.NewGlobal(metricsConf, fanout); err != nil {
+	m.sink = &toggleSink{sink: fanout, enabled: 1}
+	if _, err := metrics.NewGlobal(metricsConf, m.sink); err != nil {
 		return nil, err
 	}
 
 	return m, nil
 }
 
+// SetEnabled enables or disables the metrics, e.g. when the telemetry is
+// toggled while the node is running. The metrics emitted while they are
+// disabled are dropped, and they cannot be gathered.
+func (m *Metrics) SetEnabled(enabled bool) {
+	m.sink.setEnabled(enabled)
+}
+
 // Gather collects all registered metrics and returns a GatherResponse where the
 // metrics are encoded depending on the type. Metrics are either encoded via
 // Prometheus or JSON if in-memory.
 func (m *Metrics) Gather(format string) (GatherResponse, error) {
+	if !m.sink.isEnabled() {
+		return GatherResponse{}, fmt.Errorf("telemetry is disabled")
+	}
+
 	switch format {
 	case FormatPrometheus:
 		return m.gatherPrometheus()
@@ -171,3 +205,66 @@ func (m *Metrics) gatherGeneric() (GatherResponse, error) {
 
 	return GatherResponse{ContentType: "application/json", Metrics: content}, nil
 }
+
+// toggleSink is a metrics sink forwarding the metrics to another sink while it
+// is enabled, and dropping them otherwise. The global metrics are created once
+// with it, so that they are toggled without creating new sinks, which would be
+// registered twice with Prometheus.
+type toggleSink struct {
+	sink    metrics.MetricSink
+	enabled int32
+}
+
+var _ metrics.MetricSink = (*toggleSink)(nil)
+
+func (s *toggleSink) setEnabled(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&s.enabled, v)
+}
+
+func (s *toggleSink) isEnabled() bool { return atomic.LoadInt32(&s.enabled) == 1 }
+
+func (s *toggleSink) SetGauge(key []string, val float32) {
+	if s.isEnabled() {
+		s.sink.SetGauge(key, val)
+	}
+}
+
+func (s *toggleSink) SetGaugeWithLabels(key []string, val float32, labels []metrics.Label) {
+	if s.isEnabled() {
+		s.sink.SetGaugeWithLabels(key, val, labels)
+	}
+}
+
+func (s *toggleSink) EmitKey(key []string, val float32) {
+	if s.isEnabled() {
+		s.sink.EmitKey(key, val)
+	}
+}
+
+func (s *toggleSink) IncrCounter(key []string, val float32) {
+	if s.isEnabled() {
+		s.sink.IncrCounter(key, val)
+	}
+}
+
+func (s *toggleSink) IncrCounterWithLabels(key []string, val float32, labels []metrics.Label) {
+	if s.isEnabled() {
+		s.sink.IncrCounterWithLabels(key, val, labels)
+	}
+}
+
+func (s *toggleSink) AddSample(key []string, val float32) {
+	if s.isEnabled() {
+		s.sink.AddSample(key, val)
+	}
+}
+
+func (s *toggleSink) AddSampleWithLabels(key []string, val float32, labels []metrics.Label) {
+	if s.isEnabled() {
+		s.sink.AddSampleWithLabels(key, val, labels)
+	}
+}
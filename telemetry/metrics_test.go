@@ -61,6 +61,48 @@ func TestMetrics_Prom(t *testing.T) {
 	require.True(t, strings.Contains(string(gr.Metrics), "test_dummy_counter 30"))
 }
 
+func TestMetrics_SetEnabled(t *testing.T) {
+	m, err := New(Config{Enabled: true, ServiceName: "test"})
+	require.NoError(t, err)
+
+	counter := func() float64 {
+		gr, err := m.Gather(FormatText)
+		require.NoError(t, err)
+
+		jsonMetrics := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(gr.Metrics, &jsonMetrics))
+		for _, c := range jsonMetrics["Counters"].([]interface{}) {
+			if c.(map[string]interface{})["Name"] == "test.toggled_counter" {
+				return c.(map[string]interface{})["Count"].(float64)
+			}
+		}
+		return 0
+	}
+
+	IncrCounter(1, "toggled_counter")
+	require.Equal(t, 1.0, counter())
+
+	// the metrics emitted while disabled are dropped
+	m.SetEnabled(false)
+	IncrCounter(1, "toggled_counter")
+	_, err = m.Gather(FormatText)
+	require.Error(t, err)
+
+	m.SetEnabled(true)
+	IncrCounter(1, "toggled_counter")
+	require.Equal(t, 2.0, counter())
+}
+
+func TestSetGlobalLabels(t *testing.T) {
+	t.Cleanup(func() { SetGlobalLabels(nil) })
+
+	SetGlobalLabels([][]string{{"chain_id", "test-chain"}})
+	require.Equal(t, []metrics.Label{NewLabel("chain_id", "test-chain")}, getGlobalLabels())
+
+	SetGlobalLabels(nil)
+	require.Empty(t, getGlobalLabels())
+}
+
 func emitMetrics() {
 	ticker := time.NewTicker(time.Second)
 	timeout := time.After(30 * time.Second)
@@ -8,9 +8,10 @@ import (
 
 // Common metric key constants
 const (
-	MetricKeyBeginBlocker = "begin_blocker"
-	MetricKeyEndBlocker   = "end_blocker"
-	MetricLabelNameModule = "module"
+	MetricKeyBeginBlocker  = "begin_blocker"
+	MetricKeyEndBlocker    = "end_blocker"
+	MetricLabelNameModule  = "module"
+	MetricLabelNameMsgType = "msg_type"
 )
 
 // NewLabel creates a new instance of Label with name and value
@@ -25,7 +26,7 @@ func ModuleMeasureSince(module string, start time.Time, keys ...string) {
 	metrics.MeasureSinceWithLabels(
 		keys,
 		start.UTC(),
-		append([]metrics.Label{NewLabel(MetricLabelNameModule, module)}, globalLabels...),
+		append([]metrics.Label{NewLabel(MetricLabelNameModule, module)}, getGlobalLabels()...),
 	)
 }
 
@@ -36,36 +37,48 @@ func ModuleSetGauge(module string, val float32, keys ...string) {
 	metrics.SetGaugeWithLabels(
 		keys,
 		val,
-		append([]metrics.Label{NewLabel(MetricLabelNameModule, module)}, globalLabels...),
+		append([]metrics.Label{NewLabel(MetricLabelNameModule, module)}, getGlobalLabels()...),
 	)
 }
 
 // IncrCounter provides a wrapper functionality for emitting a counter metric with
 // global labels (if any).
 func IncrCounter(val float32, keys ...string) {
-	metrics.IncrCounterWithLabels(keys, val, globalLabels)
+	metrics.IncrCounterWithLabels(keys, val, getGlobalLabels())
 }
 
 // IncrCounterWithLabels provides a wrapper functionality for emitting a counter
 // metric with global labels (if any) along with the provided labels.
 func IncrCounterWithLabels(keys []string, val float32, labels []metrics.Label) {
-	metrics.IncrCounterWithLabels(keys, val, append(labels, globalLabels...))
+	metrics.IncrCounterWithLabels(keys, val, append(labels, getGlobalLabels()...))
 }
 
 // SetGauge provides a wrapper functionality for emitting a gauge metric with
 // global labels (if any).
 func SetGauge(val float32, keys ...string) {
-	metrics.SetGaugeWithLabels(keys, val, globalLabels)
+	metrics.SetGaugeWithLabels(keys, val, getGlobalLabels())
 }
 
 // SetGaugeWithLabels provides a wrapper functionality for emitting a gauge
 // metric with global labels (if any) along with the provided labels.
 func SetGaugeWithLabels(keys []string, val float32, labels []metrics.Label) {
-	metrics.SetGaugeWithLabels(keys, val, append(labels, globalLabels...))
+	metrics.SetGaugeWithLabels(keys, val, append(labels, getGlobalLabels()...))
 }
 
 // MeasureSince provides a wrapper functionality for emitting a a time measure
 // metric with global labels (if any).
 func MeasureSince(start time.Time, keys ...string) {
-	metrics.MeasureSinceWithLabels(keys, start.UTC(), globalLabels)
+	metrics.MeasureSinceWithLabels(keys, start.UTC(), getGlobalLabels())
+}
+
+// MeasureSinceWithLabels provides a wrapper functionality for emitting a time
+// measure metric with global labels (if any) along with the provided labels.
+func MeasureSinceWithLabels(keys []string, start time.Time, labels []metrics.Label) {
+	metrics.MeasureSinceWithLabels(keys, start.UTC(), append(labels, getGlobalLabels()...))
+}
+
+// AddSampleWithLabels provides a wrapper functionality for emitting a sample
+// metric with global labels (if any) along with the provided labels.
+func AddSampleWithLabels(keys []string, val float32, labels []metrics.Label) {
+	metrics.AddSampleWithLabels(keys, val, append(labels, getGlobalLabels()...))
 }
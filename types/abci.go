@@ -19,3 +19,11 @@ type EndBlocker func(ctx Context, req abci.RequestEndBlock) abci.ResponseEndBloc
 
 // PeerFilter responds to p2p filtering queries from Tendermint
 type PeerFilter func(info string) abci.ResponseQuery
+
+// PrepareProposalHandler modifies the block data proposed by Tendermint before
+// it is included in a block proposal
+type PrepareProposalHandler func(ctx Context, req abci.RequestPrepareProposal) abci.ResponsePrepareProposal
+
+// ProcessProposalHandler validates the block data of a proposal received from
+// another validator and decides whether to accept or reject it
+type ProcessProposalHandler func(ctx Context, req abci.RequestProcessProposal) abci.ResponseProcessProposal
@@ -242,14 +242,24 @@ func (c Context) Value(key interface{}) interface{} {
 // Store / Caching
 // ----------------------------------------------------------------------------
 
-// KVStore fetches a KVStore from the MultiStore.
+// KVStore fetches a KVStore from the MultiStore. Its gas config is the one
+// overridden on the MultiStore for the key, if any, or KVGasConfig.
 func (c Context) KVStore(key StoreKey) KVStore {
-	return gaskv.NewStore(c.MultiStore().GetKVStore(key), c.GasMeter(), stypes.KVGasConfig())
+	return gaskv.NewStore(c.MultiStore().GetKVStore(key), c.GasMeter(), c.gasConfig(key, stypes.KVGasConfig()))
 }
 
-// TransientStore fetches a TransientStore from the MultiStore.
+// TransientStore fetches a TransientStore from the MultiStore. Its gas config
+// is the one overridden on the MultiStore for the key, if any, or
+// TransientGasConfig.
 func (c Context) TransientStore(key StoreKey) KVStore {
-	return gaskv.NewStore(c.MultiStore().GetKVStore(key), c.GasMeter(), stypes.TransientGasConfig())
+	return gaskv.NewStore(c.MultiStore().GetKVStore(key), c.GasMeter(), c.gasConfig(key, stypes.TransientGasConfig()))
+}
+
+func (c Context) gasConfig(key StoreKey, defaultConfig GasConfig) GasConfig {
+	if config, ok := c.MultiStore().GetGasConfig(key); ok {
+		return config
+	}
+	return defaultConfig
 }
 
 // CacheContext returns a new Context with the multi-store cached and a new
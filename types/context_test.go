@@ -11,6 +11,7 @@ import (
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
 	"github.com/cosmos/cosmos-sdk/tests/mocks"
 	"github.com/cosmos/cosmos-sdk/testutil"
 	"github.com/cosmos/cosmos-sdk/types"
@@ -51,6 +52,31 @@ func (s *contextTestSuite) TestCacheContext() {
 	s.Require().Equal(v2, store.Get(k2))
 }
 
+func (s *contextTestSuite) TestStoreGasConfig() {
+	key := types.NewKVStoreKey(s.T().Name())
+	tkey := types.NewTransientStoreKey("transient_" + s.T().Name())
+	ctx := testutil.DefaultContext(key, tkey)
+
+	hasCosts := func() (types.Gas, types.Gas) {
+		ctx := ctx.WithGasMeter(types.NewInfiniteGasMeter())
+		ctx.KVStore(key).Has([]byte("key"))
+		kvCost := ctx.GasMeter().GasConsumed()
+		ctx.TransientStore(tkey).Has([]byte("key"))
+		return kvCost, ctx.GasMeter().GasConsumed() - kvCost
+	}
+	kvCost, transientCost := hasCosts()
+	s.Require().Equal(storetypes.KVGasConfig().HasCost, kvCost)
+	s.Require().Equal(storetypes.TransientGasConfig().HasCost, transientCost)
+
+	ctx.MultiStore().(types.CommitMultiStore).SetGasConfigs(map[types.StoreKey]types.GasConfig{
+		key:  {HasCost: 7},
+		tkey: {HasCost: 3},
+	})
+	kvCost, transientCost = hasCosts()
+	s.Require().Equal(types.Gas(7), kvCost)
+	s.Require().Equal(types.Gas(3), transientCost)
+}
+
 func (s *contextTestSuite) TestLogContext() {
 	key := types.NewKVStoreKey(s.T().Name())
 	ctx := testutil.DefaultContext(key, types.NewTransientStoreKey("transient_"+s.T().Name()))
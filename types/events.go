@@ -231,10 +231,11 @@ var (
 
 	EventTypeMessage = "message"
 
-	AttributeKeyAction = "action"
-	AttributeKeyModule = "module"
-	AttributeKeySender = "sender"
-	AttributeKeyAmount = "amount"
+	AttributeKeyAction  = "action"
+	AttributeKeyModule  = "module"
+	AttributeKeySender  = "sender"
+	AttributeKeyAmount  = "amount"
+	AttributeKeyGasUsed = "gas_used"
 )
 
 type (
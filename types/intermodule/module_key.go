@@ -0,0 +1,123 @@
+// Package intermodule defines the ModuleKeys which allow the modules to call
+// the Msg and Query services of other modules, as described in ADR-033.
+package intermodule
+
+import (
+	"context"
+	"fmt"
+
+	gogogrpc "github.com/gogo/protobuf/grpc"
+	"github.com/tendermint/tendermint/crypto"
+	"google.golang.org/grpc"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/address"
+)
+
+// ModuleID identifies the account of a module, either its root module account
+// or an account derived from it with the given path, as described in ADR-033.
+type ModuleID struct {
+	ModuleName string
+	Path       []byte
+}
+
+// Address returns the address of the account identified by the ModuleID. The
+// address of the root account is the address of the module account of the
+// same name.
+func (id ModuleID) Address() sdk.AccAddress {
+	if len(id.Path) == 0 {
+		return sdk.AccAddress(crypto.AddressHash([]byte(id.ModuleName)))
+	}
+
+	return sdk.AccAddress(address.Module(id.ModuleName, id.Path))
+}
+
+// ModuleKey is the key of a module account, which allows a module to call the
+// Msg and Query services of other modules through the generated gRPC clients,
+// e.g. banktypes.NewMsgClient(key). A Msg sent with a ModuleKey must have the
+// address of the key as its only signer.
+type ModuleKey interface {
+	gogogrpc.ClientConn
+
+	ID() ModuleID
+	Address() sdk.AccAddress
+}
+
+// Invoker executes the Msg or query sent by the caller with a
+// ModuleKey, e.g. through the routers of the BaseApp.
+type Invoker func(ctx context.Context, caller ModuleID, method string, args, reply interface{}) error
+
+// RootModuleKey is the ModuleKey of the root account of a module.
+type RootModuleKey struct {
+	moduleName string
+	invoker    Invoker
+}
+
+// DerivedModuleKey is the ModuleKey of an account derived from the root
+// account of a module.
+type DerivedModuleKey struct {
+	moduleName string
+	path       []byte
+	invoker    Invoker
+}
+
+var (
+	_ ModuleKey = RootModuleKey{}
+	_ ModuleKey = DerivedModuleKey{}
+)
+
+// NewRootModuleKey returns the ModuleKey of the root account of the given
+// module, whose calls are executed by the given invoker.
+func NewRootModuleKey(moduleName string, invoker Invoker) RootModuleKey {
+	return RootModuleKey{moduleName: moduleName, invoker: invoker}
+}
+
+// ID implements the ModuleKey interface.
+func (key RootModuleKey) ID() ModuleID {
+	return ModuleID{ModuleName: key.moduleName}
+}
+
+// Address implements the ModuleKey interface.
+func (key RootModuleKey) Address() sdk.AccAddress {
+	return key.ID().Address()
+}
+
+// Derive returns the ModuleKey of the account derived from the root account
+// with the given path.
+func (key RootModuleKey) Derive(path []byte) DerivedModuleKey {
+	return DerivedModuleKey{
+		moduleName: key.moduleName,
+		path:       append([]byte(nil), path...),
+		invoker:    key.invoker,
+	}
+}
+
+// Invoke implements the grpc ClientConn.Invoke method.
+func (key RootModuleKey) Invoke(ctx context.Context, method string, args, reply interface{}, _ ...grpc.CallOption) error {
+	return key.invoker(ctx, key.ID(), method, args, reply)
+}
+
+// NewStream implements the grpc ClientConn.NewStream method.
+func (key RootModuleKey) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+// ID implements the ModuleKey interface.
+func (key DerivedModuleKey) ID() ModuleID {
+	return ModuleID{ModuleName: key.moduleName, Path: key.path}
+}
+
+// Address implements the ModuleKey interface.
+func (key DerivedModuleKey) Address() sdk.AccAddress {
+	return key.ID().Address()
+}
+
+// Invoke implements the grpc ClientConn.Invoke method.
+func (key DerivedModuleKey) Invoke(ctx context.Context, method string, args, reply interface{}, _ ...grpc.CallOption) error {
+	return key.invoker(ctx, key.ID(), method, args, reply)
+}
+
+// NewStream implements the grpc ClientConn.NewStream method.
+func (key DerivedModuleKey) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, fmt.Errorf("not supported")
+}
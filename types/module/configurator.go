@@ -6,6 +6,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/intermodule"
 )
 
 // Configurator provides the hooks to allow modules to configure and register
@@ -34,6 +35,21 @@ type Configurator interface {
 	RegisterMigration(moduleName string, forVersion uint64, handler MigrationHandler) error
 }
 
+// ModuleKeyProvider is implemented by the Configurators which hand the modules
+// their ADR-033 ModuleKey, i.e. the ones created with
+// NewConfiguratorWithModuleKeys. A module gets its key from its
+// RegisterServices method:
+//
+//	if provider, ok := cfg.(module.ModuleKeyProvider); ok {
+//		key := provider.ModuleKey()
+//	}
+type ModuleKeyProvider interface {
+	// ModuleKey returns the ModuleKey of the module whose services are being
+	// registered. It can only be called once per module, from the
+	// RegisterServices method called by Manager.RegisterServices.
+	ModuleKey() intermodule.RootModuleKey
+}
+
 type configurator struct {
 	cdc         codec.Codec
 	msgServer   grpc.Server
@@ -41,6 +57,11 @@ type configurator struct {
 
 	// migrations is a map of moduleName -> forVersion -> migration script handler
 	migrations map[string]map[uint64]MigrationHandler
+
+	// issueModuleKey issues the module keys, and moduleName is the name of the
+	// module whose services are being registered
+	issueModuleKey func(moduleName string) intermodule.RootModuleKey
+	moduleName     string
 }
 
 // NewConfigurator returns a new Configurator instance
@@ -53,7 +74,21 @@ func NewConfigurator(cdc codec.Codec, msgServer grpc.Server, queryServer grpc.Se
 	}
 }
 
-var _ Configurator = configurator{}
+// NewConfiguratorWithModuleKeys returns a new Configurator instance which hands
+// the modules their ModuleKey through the ModuleKeyProvider interface, issued
+// with the function returned by BaseApp.ModuleKeyIssuer.
+func NewConfiguratorWithModuleKeys(
+	cdc codec.Codec, msgServer grpc.Server, queryServer grpc.Server, issueModuleKey func(moduleName string) intermodule.RootModuleKey,
+) Configurator {
+	c := NewConfigurator(cdc, msgServer, queryServer).(configurator)
+	c.issueModuleKey = issueModuleKey
+	return c
+}
+
+var (
+	_ Configurator      = configurator{}
+	_ ModuleKeyProvider = configurator{}
+)
 
 // MsgServer implements the Configurator.MsgServer method
 func (c configurator) MsgServer() grpc.Server {
@@ -65,6 +100,30 @@ func (c configurator) QueryServer() grpc.Server {
 	return c.queryServer
 }
 
+// ModuleKey implements the ModuleKeyProvider.ModuleKey method
+func (c configurator) ModuleKey() intermodule.RootModuleKey {
+	if c.issueModuleKey == nil {
+		panic("module keys are not enabled, the configurator must be created with NewConfiguratorWithModuleKeys")
+	}
+	if c.moduleName == "" {
+		panic("ModuleKey() must be called from the RegisterServices method of a module")
+	}
+
+	return c.issueModuleKey(c.moduleName)
+}
+
+// forModule returns the Configurator of a module, whose ModuleKey is the key
+// of the module.
+func forModule(cfg Configurator, moduleName string) Configurator {
+	c, ok := cfg.(configurator)
+	if !ok {
+		return cfg
+	}
+
+	c.moduleName = moduleName
+	return c
+}
+
 // RegisterMigration implements the Configurator.RegisterMigration method
 func (c configurator) RegisterMigration(moduleName string, forVersion uint64, handler MigrationHandler) error {
 	if forVersion == 0 {
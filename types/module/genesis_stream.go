@@ -0,0 +1,363 @@
+package module
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GenesisStreamFormat is the encoding of the records of a genesis stream.
+type GenesisStreamFormat string
+
+const (
+	// GenesisStreamFormatJSON encodes every record as a line holding a JSON
+	// object with the kind of the record and the record in proto JSON.
+	GenesisStreamFormatJSON GenesisStreamFormat = "json"
+	// GenesisStreamFormatProto encodes every record as its length-prefixed
+	// kind followed by the length-prefixed proto encoding of the record.
+	GenesisStreamFormatProto GenesisStreamFormat = "proto"
+)
+
+// maxGenesisRecordSize bounds the size of the records of the proto format, to
+// not allocate arbitrary amounts of memory on corrupted streams.
+const maxGenesisRecordSize = 256 << 20
+
+// AppModuleGenesisStream is implemented by the modules which can export and
+// import their genesis state as a stream of records, without holding all of
+// it in memory at once. The kinds of the records identify their type, and are
+// defined by each module.
+type AppModuleGenesisStream interface {
+	ExportGenesisStream(sdk.Context, GenesisStreamWriter) error
+	InitGenesisStream(sdk.Context, GenesisStreamReader) ([]abci.ValidatorUpdate, error)
+}
+
+// AppModuleBasicGenesisStream is implemented by the basics of the modules
+// implementing AppModuleGenesisStream, to validate their genesis stream
+// without a state like ValidateGenesis.
+type AppModuleBasicGenesisStream interface {
+	ValidateGenesisStream(codec.Codec, client.TxEncodingConfig, GenesisStreamReader) error
+}
+
+// GenesisStreamWriter writes the records of a genesis stream.
+type GenesisStreamWriter interface {
+	// Write writes a record of the given kind.
+	Write(kind string, record codec.ProtoMarshaler) error
+}
+
+// GenesisStreamReader reads the records of a genesis stream.
+type GenesisStreamReader interface {
+	// Next advances to the next record and returns its kind, or io.EOF at the
+	// end of the stream.
+	Next() (string, error)
+	// Decode decodes the current record into the given record.
+	Decode(record codec.ProtoMarshaler) error
+}
+
+// GenesisStreamRef is the genesis state of a module whose genesis is streamed
+// to a separate file. It appears in the genesis as
+// {"genesis_stream": {"format": ..., "file": ..., "sha256": ...}}, the file is
+// relative to the genesis stream directory of the Manager, and the hex-encoded
+// SHA-256 hash of its content is checked before it is read, so that the
+// genesis hash commits to the streamed states too.
+type GenesisStreamRef struct {
+	Format GenesisStreamFormat `json:"format"`
+	File   string              `json:"file"`
+	SHA256 string              `json:"sha256"`
+}
+
+type genesisStreamPlaceholder struct {
+	GenesisStream *GenesisStreamRef `json:"genesis_stream"`
+}
+
+// ParseGenesisStreamRef returns the reference of a module genesis state which
+// is streamed to a separate file, and false if the genesis state is not such a
+// reference. Only the first key of the genesis state is read to tell, so that
+// large genesis states are not decoded.
+func ParseGenesisStreamRef(bz json.RawMessage) (GenesisStreamRef, bool) {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return GenesisStreamRef{}, false
+	}
+	if tok, err := dec.Token(); err != nil || tok != "genesis_stream" {
+		return GenesisStreamRef{}, false
+	}
+
+	var placeholder genesisStreamPlaceholder
+	if err := json.Unmarshal(bz, &placeholder); err != nil || placeholder.GenesisStream == nil {
+		return GenesisStreamRef{}, false
+	}
+
+	return *placeholder.GenesisStream, true
+}
+
+// NewGenesisStreamWriter returns a GenesisStreamWriter writing records in the
+// given format to w.
+func NewGenesisStreamWriter(w io.Writer, cdc codec.Codec, format GenesisStreamFormat) (GenesisStreamWriter, error) {
+	switch format {
+	case GenesisStreamFormatJSON:
+		return &jsonGenesisStreamWriter{w: w, cdc: cdc}, nil
+	case GenesisStreamFormatProto:
+		return &protoGenesisStreamWriter{w: w, cdc: cdc}, nil
+	default:
+		return nil, fmt.Errorf("unknown genesis stream format %q", format)
+	}
+}
+
+// NewGenesisStreamReader returns a GenesisStreamReader reading records in the
+// given format from r.
+func NewGenesisStreamReader(r io.Reader, cdc codec.Codec, format GenesisStreamFormat) (GenesisStreamReader, error) {
+	switch format {
+	case GenesisStreamFormatJSON:
+		return &jsonGenesisStreamReader{r: bufio.NewReader(r), cdc: cdc}, nil
+	case GenesisStreamFormatProto:
+		return &protoGenesisStreamReader{r: bufio.NewReader(r), cdc: cdc}, nil
+	default:
+		return nil, fmt.Errorf("unknown genesis stream format %q", format)
+	}
+}
+
+type jsonGenesisRecord struct {
+	Kind   string          `json:"kind"`
+	Record json.RawMessage `json:"record"`
+}
+
+type jsonGenesisStreamWriter struct {
+	w   io.Writer
+	cdc codec.Codec
+}
+
+func (gw *jsonGenesisStreamWriter) Write(kind string, record codec.ProtoMarshaler) error {
+	bz, err := gw.cdc.MarshalJSON(record)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(jsonGenesisRecord{Kind: kind, Record: bz})
+	if err != nil {
+		return err
+	}
+
+	_, err = gw.w.Write(append(line, '\n'))
+	return err
+}
+
+type jsonGenesisStreamReader struct {
+	r       *bufio.Reader
+	cdc     codec.Codec
+	current json.RawMessage
+}
+
+func (gr *jsonGenesisStreamReader) Next() (string, error) {
+	for {
+		line, err := gr.r.ReadBytes('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+			return "", err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var rec jsonGenesisRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return "", fmt.Errorf("invalid genesis stream record: %w", err)
+		}
+		gr.current = rec.Record
+
+		return rec.Kind, nil
+	}
+}
+
+func (gr *jsonGenesisStreamReader) Decode(record codec.ProtoMarshaler) error {
+	return gr.cdc.UnmarshalJSON(gr.current, record)
+}
+
+type protoGenesisStreamWriter struct {
+	w   io.Writer
+	cdc codec.Codec
+	buf []byte
+}
+
+func (gw *protoGenesisStreamWriter) Write(kind string, record codec.ProtoMarshaler) error {
+	bz, err := gw.cdc.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	var scratch [binary.MaxVarintLen64]byte
+	gw.buf = append(gw.buf[:0], scratch[:binary.PutUvarint(scratch[:], uint64(len(kind)))]...)
+	gw.buf = append(gw.buf, kind...)
+	gw.buf = append(gw.buf, scratch[:binary.PutUvarint(scratch[:], uint64(len(bz)))]...)
+	gw.buf = append(gw.buf, bz...)
+
+	_, err = gw.w.Write(gw.buf)
+	return err
+}
+
+type protoGenesisStreamReader struct {
+	r       *bufio.Reader
+	cdc     codec.Codec
+	current []byte
+}
+
+func (gr *protoGenesisStreamReader) Next() (string, error) {
+	kind, err := gr.readBytes()
+	if err != nil {
+		return "", err
+	}
+	if gr.current, err = gr.readBytes(); err != nil {
+		return "", unexpectedEOF(err)
+	}
+
+	return string(kind), nil
+}
+
+func (gr *protoGenesisStreamReader) readBytes() ([]byte, error) {
+	size, err := binary.ReadUvarint(gr.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxGenesisRecordSize {
+		return nil, fmt.Errorf("genesis stream record of %d bytes exceeds the maximum size", size)
+	}
+
+	bz := make([]byte, size)
+	if _, err := io.ReadFull(gr.r, bz); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return bz, nil
+}
+
+func (gr *protoGenesisStreamReader) Decode(record codec.ProtoMarshaler) error {
+	return gr.cdc.Unmarshal(gr.current, record)
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// genesisStreamFile returns the name of the genesis stream file of a module.
+func genesisStreamFile(moduleName string, format GenesisStreamFormat) string {
+	if format == GenesisStreamFormatProto {
+		return moduleName + ".genesis.pb"
+	}
+	return moduleName + ".genesis.jsonl"
+}
+
+// exportGenesisStream streams the genesis state of a module to its file in
+// the given directory, and returns the hex-encoded SHA-256 hash of the file.
+func exportGenesisStream(
+	ctx sdk.Context, cdc codec.Codec, module AppModuleGenesisStream, dir string, ref GenesisStreamRef,
+) (hash string, err error) {
+	f, err := os.Create(filepath.Join(dir, ref.File))
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	h := sha256.New()
+	bw := bufio.NewWriter(io.MultiWriter(f, h))
+	w, err := NewGenesisStreamWriter(bw, cdc, ref.Format)
+	if err != nil {
+		return "", err
+	}
+	if err := module.ExportGenesisStream(ctx, w); err != nil {
+		return "", err
+	}
+	if err := bw.Flush(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// initGenesisStream initializes the genesis state of a module from its file in
+// the given directory.
+func initGenesisStream(
+	ctx sdk.Context, cdc codec.Codec, module AppModuleGenesisStream, dir string, ref GenesisStreamRef,
+) ([]abci.ValidatorUpdate, error) {
+	f, err := openGenesisStream(dir, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := NewGenesisStreamReader(f, cdc, ref.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	return module.InitGenesisStream(ctx, r)
+}
+
+// validateGenesisStream validates the genesis state of a module from its file
+// in the given directory.
+func validateGenesisStream(
+	cdc codec.Codec, txEncCfg client.TxEncodingConfig, module AppModuleBasicGenesisStream, dir string, ref GenesisStreamRef,
+) error {
+	f, err := openGenesisStream(dir, ref)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := NewGenesisStreamReader(f, cdc, ref.Format)
+	if err != nil {
+		return err
+	}
+
+	return module.ValidateGenesisStream(cdc, txEncCfg, r)
+}
+
+// openGenesisStream opens the file of a genesis stream in the given directory,
+// once its content is checked against the hash of the reference. The file is
+// read twice, so that no record is read before the whole file is checked.
+func openGenesisStream(dir string, ref GenesisStreamRef) (*os.File, error) {
+	if ref.File == "" || filepath.Base(ref.File) != ref.File {
+		return nil, fmt.Errorf("invalid genesis stream file %q", ref.File)
+	}
+	if ref.SHA256 == "" {
+		return nil, fmt.Errorf("genesis stream file %s has no sha256 hash", ref.File)
+	}
+
+	f, err := os.Open(filepath.Join(dir, ref.File))
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if hash := hex.EncodeToString(h.Sum(nil)); hash != ref.SHA256 {
+		f.Close()
+		return nil, fmt.Errorf("genesis stream file %s has sha256 hash %s, expected %s", ref.File, hash, ref.SHA256)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
@@ -0,0 +1,230 @@
+package module_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/tests/mocks"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+)
+
+// streamAppModule is an AppModule streaming its genesis state of coins.
+type streamAppModule struct {
+	*mocks.MockAppModule
+	coins []sdk.Coin
+}
+
+func (am *streamAppModule) ExportGenesisStream(_ sdk.Context, w module.GenesisStreamWriter) error {
+	for i := range am.coins {
+		if err := w.Write("coin", &am.coins[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (am *streamAppModule) InitGenesisStream(_ sdk.Context, r module.GenesisStreamReader) ([]abci.ValidatorUpdate, error) {
+	am.coins = nil
+	for {
+		kind, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if kind != "coin" {
+			return nil, errors.New("unexpected record")
+		}
+
+		var coin sdk.Coin
+		if err := r.Decode(&coin); err != nil {
+			return nil, err
+		}
+		am.coins = append(am.coins, coin)
+	}
+}
+
+func (am *streamAppModule) ValidateGenesisStream(_ codec.Codec, _ client.TxEncodingConfig, r module.GenesisStreamReader) error {
+	for {
+		kind, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if kind != "coin" {
+			return errors.New("unexpected record")
+		}
+
+		var coin sdk.Coin
+		if err := r.Decode(&coin); err != nil {
+			return err
+		}
+		if err := coin.Validate(); err != nil {
+			return err
+		}
+	}
+}
+
+func TestGenesisStreamRoundTrip(t *testing.T) {
+	cdc := codec.NewProtoCodec(types.NewInterfaceRegistry())
+	coins := []sdk.Coin{sdk.NewInt64Coin("atom", 1), sdk.NewInt64Coin("stake", 20)}
+
+	for _, format := range []module.GenesisStreamFormat{module.GenesisStreamFormatJSON, module.GenesisStreamFormatProto} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := module.NewGenesisStreamWriter(&buf, cdc, format)
+			require.NoError(t, err)
+			for i := range coins {
+				require.NoError(t, w.Write("coin", &coins[i]))
+			}
+
+			r, err := module.NewGenesisStreamReader(bytes.NewReader(buf.Bytes()), cdc, format)
+			require.NoError(t, err)
+			for _, expected := range coins {
+				kind, err := r.Next()
+				require.NoError(t, err)
+				require.Equal(t, "coin", kind)
+
+				var coin sdk.Coin
+				require.NoError(t, r.Decode(&coin))
+				require.Equal(t, expected, coin)
+			}
+			_, err = r.Next()
+			require.ErrorIs(t, err, io.EOF)
+
+			r, err = module.NewGenesisStreamReader(bytes.NewReader(buf.Bytes()[:buf.Len()-2]), cdc, format)
+			require.NoError(t, err)
+			_, err = r.Next()
+			require.NoError(t, err)
+			_, err = r.Next()
+			require.Error(t, err)
+		})
+	}
+
+	_, err := module.NewGenesisStreamWriter(io.Discard, cdc, "xml")
+	require.Error(t, err)
+}
+
+func TestParseGenesisStreamRef(t *testing.T) {
+	ref, ok := module.ParseGenesisStreamRef(json.RawMessage(`{"genesis_stream": {"format": "proto", "file": "bank.genesis.pb", "sha256": "00ff"}}`))
+	require.True(t, ok)
+	require.Equal(t, module.GenesisStreamRef{Format: module.GenesisStreamFormatProto, File: "bank.genesis.pb", SHA256: "00ff"}, ref)
+
+	for _, bz := range []string{``, `[]`, `{}`, `{"balances": [], "genesis_stream": {}}`, `{"genesis_stream": null}`} {
+		_, ok := module.ParseGenesisStreamRef(json.RawMessage(bz))
+		require.False(t, ok, bz)
+	}
+}
+
+func TestManager_ExportGenesisToDir(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockAppModule1 := mocks.NewMockAppModule(mockCtrl)
+	mockAppModule1.EXPECT().Name().Times(2).Return("module1")
+	mockAppModule2 := &streamAppModule{
+		MockAppModule: mocks.NewMockAppModule(mockCtrl),
+		coins:         []sdk.Coin{sdk.NewInt64Coin("atom", 1), sdk.NewInt64Coin("stake", 20)},
+	}
+	mockAppModule2.EXPECT().Name().Times(2).Return("module2")
+	mm := module.NewManager(mockAppModule1, mockAppModule2)
+
+	ctx := sdk.Context{}
+	cdc := codec.NewProtoCodec(types.NewInterfaceRegistry())
+	dir := t.TempDir()
+
+	mockAppModule1.EXPECT().ExportGenesis(gomock.Eq(ctx), gomock.Eq(cdc)).Times(1).Return(json.RawMessage(`{"key1": "value1"}`))
+	genesisData, err := mm.ExportGenesisToDir(ctx, cdc, dir, module.GenesisStreamFormatProto)
+	require.NoError(t, err)
+	require.Equal(t, json.RawMessage(`{"key1": "value1"}`), genesisData["module1"])
+	bz, err := ioutil.ReadFile(filepath.Join(dir, "module2.genesis.pb"))
+	require.NoError(t, err)
+	hash := sha256.Sum256(bz)
+	require.JSONEq(t, fmt.Sprintf(
+		`{"genesis_stream": {"format": "proto", "file": "module2.genesis.pb", "sha256": "%x"}}`, hash,
+	), string(genesisData["module2"]))
+
+	exported := mockAppModule2.coins
+	mockAppModule2.coins = nil
+	mm.SetGenesisStreamDir(dir)
+	mockAppModule1.EXPECT().InitGenesis(gomock.Eq(ctx), gomock.Eq(cdc), gomock.Eq(genesisData["module1"])).Times(1).Return(nil)
+	mm.InitGenesis(ctx, cdc, genesisData)
+	require.Equal(t, exported, mockAppModule2.coins)
+
+	// missing stream file
+	mm.SetGenesisStreamDir(t.TempDir())
+	require.Panics(t, func() {
+		mm.InitGenesis(ctx, cdc, map[string]json.RawMessage{"module2": genesisData["module2"]})
+	})
+
+	// module which does not support streamed genesis states
+	require.Panics(t, func() {
+		mm.InitGenesis(ctx, cdc, map[string]json.RawMessage{"module1": genesisData["module2"]})
+	})
+
+	// stream file whose content does not match its hash
+	mm.SetGenesisStreamDir(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "module2.genesis.pb"), bz[:len(bz)-1], 0o600))
+	require.Panics(t, func() {
+		mm.InitGenesis(ctx, cdc, map[string]json.RawMessage{"module2": genesisData["module2"]})
+	})
+}
+
+func TestBasicManager_ValidateGenesisWithStreamDir(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockAppModule1 := mocks.NewMockAppModuleBasic(mockCtrl)
+	mockAppModule1.EXPECT().Name().AnyTimes().Return("module1")
+	mockAppModule2 := &streamAppModule{
+		MockAppModule: mocks.NewMockAppModule(mockCtrl),
+		coins:         []sdk.Coin{sdk.NewInt64Coin("atom", 1), {Denom: "stake", Amount: sdk.NewInt(-1)}},
+	}
+	mockAppModule2.EXPECT().Name().AnyTimes().Return("module2")
+	mm := module.NewManager(mockAppModule2)
+
+	cdc := codec.NewProtoCodec(types.NewInterfaceRegistry())
+	dir := t.TempDir()
+	genesisData, err := mm.ExportGenesisToDir(sdk.Context{}, cdc, dir, module.GenesisStreamFormatJSON)
+	require.NoError(t, err)
+	genesisData["module1"] = json.RawMessage(`{}`)
+
+	// the streamed genesis states are validated by their module
+	bm := module.NewBasicManager(mockAppModule1, mockAppModule2)
+	mockAppModule1.EXPECT().ValidateGenesis(gomock.Eq(cdc), gomock.Eq(nil), gomock.Eq(genesisData["module1"])).AnyTimes().Return(nil)
+	require.Error(t, bm.ValidateGenesisWithStreamDir(cdc, nil, genesisData, dir))
+	require.Error(t, bm.ValidateGenesis(cdc, nil, genesisData))
+
+	mockAppModule2.coins = mockAppModule2.coins[:1]
+	genesisData, err = mm.ExportGenesisToDir(sdk.Context{}, cdc, dir, module.GenesisStreamFormatJSON)
+	require.NoError(t, err)
+	genesisData["module1"] = json.RawMessage(`{}`)
+	require.NoError(t, bm.ValidateGenesisWithStreamDir(cdc, nil, genesisData, dir))
+
+	// stream file whose content does not match its hash
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "module2.genesis.jsonl"), []byte("\n"), 0o600))
+	require.Error(t, bm.ValidateGenesisWithStreamDir(cdc, nil, genesisData, dir))
+
+	// module which does not support streamed genesis states
+	genesisData["module1"] = genesisData["module2"]
+	delete(genesisData, "module2")
+	require.Error(t, bm.ValidateGenesisWithStreamDir(cdc, nil, genesisData, dir))
+}
@@ -30,6 +30,8 @@ package module
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sort"
 
 	"github.com/gorilla/mux"
@@ -96,9 +98,27 @@ func (bm BasicManager) DefaultGenesis(cdc codec.JSONCodec) map[string]json.RawMe
 	return genesis
 }
 
-// ValidateGenesis performs genesis state validation for all modules
+// ValidateGenesis performs genesis state validation for all modules. It fails
+// on the genesis states which are streamed to separate files, which are
+// validated by ValidateGenesisWithStreamDir.
 func (bm BasicManager) ValidateGenesis(cdc codec.JSONCodec, txEncCfg client.TxEncodingConfig, genesis map[string]json.RawMessage) error {
+	return bm.ValidateGenesisWithStreamDir(cdc, txEncCfg, genesis, "")
+}
+
+// ValidateGenesisWithStreamDir performs genesis state validation for all
+// modules like ValidateGenesis, and validates the genesis states which are
+// streamed to separate files of the given directory with the
+// ValidateGenesisStream method of their module.
+func (bm BasicManager) ValidateGenesisWithStreamDir(
+	cdc codec.JSONCodec, txEncCfg client.TxEncodingConfig, genesis map[string]json.RawMessage, streamDir string,
+) error {
 	for _, b := range bm {
+		if ref, ok := ParseGenesisStreamRef(genesis[b.Name()]); ok {
+			if err := validateGenesisStreamOf(cdc, txEncCfg, b, streamDir, ref); err != nil {
+				return fmt.Errorf("invalid genesis stream of module %s: %w", b.Name(), err)
+			}
+			continue
+		}
 		if err := b.ValidateGenesis(cdc, txEncCfg, genesis[b.Name()]); err != nil {
 			return err
 		}
@@ -107,6 +127,24 @@ func (bm BasicManager) ValidateGenesis(cdc codec.JSONCodec, txEncCfg client.TxEn
 	return nil
 }
 
+func validateGenesisStreamOf(
+	cdc codec.JSONCodec, txEncCfg client.TxEncodingConfig, b AppModuleBasic, streamDir string, ref GenesisStreamRef,
+) error {
+	if streamDir == "" {
+		return errors.New("no genesis stream directory to read it from")
+	}
+	module, ok := b.(AppModuleBasicGenesisStream)
+	if !ok {
+		return errors.New("the module does not support streamed genesis states")
+	}
+	streamCdc, ok := cdc.(codec.Codec)
+	if !ok {
+		return fmt.Errorf("streamed genesis states require a binary codec, got %T", cdc)
+	}
+
+	return validateGenesisStream(streamCdc, txEncCfg, module, streamDir, ref)
+}
+
 // RegisterRESTRoutes registers all module rest routes
 func (bm BasicManager) RegisterRESTRoutes(clientCtx client.Context, rtr *mux.Router) {
 	for _, b := range bm {
@@ -229,6 +267,8 @@ type Manager struct {
 	OrderExportGenesis []string
 	OrderBeginBlockers []string
 	OrderEndBlockers   []string
+
+	genesisStreamDir string
 }
 
 // NewManager creates a new Manager object
@@ -260,6 +300,12 @@ func (m *Manager) SetOrderExportGenesis(moduleNames ...string) {
 	m.OrderExportGenesis = moduleNames
 }
 
+// SetGenesisStreamDir sets the directory of the files of the genesis states
+// which are streamed to separate files.
+func (m *Manager) SetGenesisStreamDir(dir string) {
+	m.genesisStreamDir = dir
+}
+
 // SetOrderBeginBlockers sets the order of set begin-blocker calls
 func (m *Manager) SetOrderBeginBlockers(moduleNames ...string) {
 	m.OrderBeginBlockers = moduleNames
@@ -289,10 +335,11 @@ func (m *Manager) RegisterRoutes(router sdk.Router, queryRouter sdk.QueryRouter,
 	}
 }
 
-// RegisterServices registers all module services
+// RegisterServices registers all module services. Every module is handed a
+// Configurator whose ModuleKeyProvider, if any, provides its own key.
 func (m *Manager) RegisterServices(cfg Configurator) {
-	for _, module := range m.Modules {
-		module.RegisterServices(cfg)
+	for name, module := range m.Modules {
+		module.RegisterServices(forModule(cfg, name))
 	}
 }
 
@@ -304,7 +351,12 @@ func (m *Manager) InitGenesis(ctx sdk.Context, cdc codec.JSONCodec, genesisData
 			continue
 		}
 
-		moduleValUpdates := m.Modules[moduleName].InitGenesis(ctx, cdc, genesisData[moduleName])
+		var moduleValUpdates []abci.ValidatorUpdate
+		if ref, ok := ParseGenesisStreamRef(genesisData[moduleName]); ok {
+			moduleValUpdates = m.initGenesisStream(ctx, cdc, moduleName, ref)
+		} else {
+			moduleValUpdates = m.Modules[moduleName].InitGenesis(ctx, cdc, genesisData[moduleName])
+		}
 
 		// use these validator updates if provided, the module manager assumes
 		// only one module will update the validator set
@@ -331,6 +383,59 @@ func (m *Manager) ExportGenesis(ctx sdk.Context, cdc codec.JSONCodec) map[string
 	return genesisData
 }
 
+// initGenesisStream initializes the genesis state of a module from the file
+// of its stream. It panics if the module does not implement
+// AppModuleGenesisStream or the stream cannot be read.
+func (m *Manager) initGenesisStream(ctx sdk.Context, cdc codec.JSONCodec, moduleName string, ref GenesisStreamRef) []abci.ValidatorUpdate {
+	module, ok := m.Modules[moduleName].(AppModuleGenesisStream)
+	if !ok {
+		panic(fmt.Sprintf("module %s does not support streamed genesis states", moduleName))
+	}
+	streamCdc, ok := cdc.(codec.Codec)
+	if !ok {
+		panic(fmt.Sprintf("streamed genesis states require a binary codec, got %T", cdc))
+	}
+
+	valUpdates, err := initGenesisStream(ctx, streamCdc, module, m.genesisStreamDir, ref)
+	if err != nil {
+		panic(fmt.Errorf("failed to init the genesis state of module %s from %s: %w", moduleName, ref.File, err))
+	}
+
+	return valUpdates
+}
+
+// ExportGenesisToDir performs export genesis functionality for modules like
+// ExportGenesis, except that the modules which implement
+// AppModuleGenesisStream stream their genesis state to a file of the given
+// directory, in the given format. Their genesis state is then a
+// GenesisStreamRef to the file, which is read by InitGenesis from the genesis
+// stream directory.
+func (m *Manager) ExportGenesisToDir(ctx sdk.Context, cdc codec.Codec, dir string, format GenesisStreamFormat) (map[string]json.RawMessage, error) {
+	genesisData := make(map[string]json.RawMessage)
+	for _, moduleName := range m.OrderExportGenesis {
+		module, ok := m.Modules[moduleName].(AppModuleGenesisStream)
+		if !ok {
+			genesisData[moduleName] = m.Modules[moduleName].ExportGenesis(ctx, cdc)
+			continue
+		}
+
+		ref := GenesisStreamRef{Format: format, File: genesisStreamFile(moduleName, format)}
+		hash, err := exportGenesisStream(ctx, cdc, module, dir, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export the genesis state of module %s: %w", moduleName, err)
+		}
+		ref.SHA256 = hash
+
+		bz, err := json.Marshal(genesisStreamPlaceholder{GenesisStream: &ref})
+		if err != nil {
+			return nil, err
+		}
+		genesisData[moduleName] = bz
+	}
+
+	return genesisData, nil
+}
+
 // MigrationHandler is the migration function that each module registers.
 type MigrationHandler func(sdk.Context) error
 
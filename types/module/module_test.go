@@ -12,11 +12,15 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
 
+	"github.com/cosmos/cosmos-sdk/baseapp"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/tests/mocks"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/intermodule"
 	"github.com/cosmos/cosmos-sdk/types/module"
 )
 
@@ -182,12 +186,51 @@ func TestManager_RegisterQueryServices(t *testing.T) {
 	interfaceRegistry := types.NewInterfaceRegistry()
 	cdc := codec.NewProtoCodec(interfaceRegistry)
 	cfg := module.NewConfigurator(cdc, msgRouter, queryRouter)
-	mockAppModule1.EXPECT().RegisterServices(cfg).Times(1)
-	mockAppModule2.EXPECT().RegisterServices(cfg).Times(1)
+	// every module is handed its own configurator
+	mockAppModule1.EXPECT().RegisterServices(gomock.Any()).Times(1)
+	mockAppModule2.EXPECT().RegisterServices(gomock.Any()).Times(1)
 
 	mm.RegisterServices(cfg)
 }
 
+func TestManager_RegisterServicesModuleKeys(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockAppModule1 := mocks.NewMockAppModule(mockCtrl)
+	mockAppModule2 := mocks.NewMockAppModule(mockCtrl)
+	mockAppModule1.EXPECT().Name().Times(2).Return("module1")
+	mockAppModule2.EXPECT().Name().Times(2).Return("module2")
+	mm := module.NewManager(mockAppModule1, mockAppModule2)
+
+	app := baseapp.NewBaseApp("test", log.NewNopLogger(), dbm.NewMemDB(), nil)
+	cdc := codec.NewProtoCodec(types.NewInterfaceRegistry())
+	cfg := module.NewConfiguratorWithModuleKeys(cdc, app.MsgServiceRouter(), app.GRPCQueryRouter(), app.ModuleKeyIssuer())
+
+	// the configurator of a module hands it its own key, only once
+	keys := map[string]intermodule.RootModuleKey{}
+	registerServices := func(name string) func(module.Configurator) {
+		return func(cfg module.Configurator) {
+			provider, ok := cfg.(module.ModuleKeyProvider)
+			require.True(t, ok)
+			keys[name] = provider.ModuleKey()
+			require.Panics(t, func() { provider.ModuleKey() })
+		}
+	}
+	mockAppModule1.EXPECT().RegisterServices(gomock.Any()).Times(1).Do(registerServices("module1"))
+	mockAppModule2.EXPECT().RegisterServices(gomock.Any()).Times(1).Do(registerServices("module2"))
+
+	mm.RegisterServices(cfg)
+	require.Equal(t, intermodule.ModuleID{ModuleName: "module1"}, keys["module1"].ID())
+	require.Equal(t, intermodule.ModuleID{ModuleName: "module2"}, keys["module2"].ID())
+
+	// the key of a module is only handed out by the module manager
+	require.Panics(t, func() { cfg.(module.ModuleKeyProvider).ModuleKey() })
+	require.Panics(t, func() {
+		module.NewConfigurator(cdc, app.MsgServiceRouter(), app.GRPCQueryRouter()).(module.ModuleKeyProvider).ModuleKey()
+	})
+}
+
 func TestManager_InitGenesis(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	t.Cleanup(mockCtrl.Finish)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/syndtr/goleveldb/leveldb/opt"
 	dbm "github.com/tendermint/tm-db"
 )
 
@@ -95,6 +96,16 @@ func NewLevelDB(name, dir string) (db dbm.DB, err error) {
 	return dbm.NewDB(name, backend, dir)
 }
 
+// NewReadOnlyLevelDB opens an existing LevelDB instance read-only. Only the
+// goleveldb backend supports it.
+func NewReadOnlyLevelDB(name, dir string) (dbm.DB, error) {
+	if backend != dbm.GoLevelDBBackend {
+		return nil, fmt.Errorf("read-only databases are not supported by the %s backend", backend)
+	}
+
+	return dbm.NewGoLevelDBWithOpts(name, dir, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+}
+
 // copy bytes
 func CopyBytes(bz []byte) (ret []byte) {
 	if bz == nil {
@@ -15,6 +15,11 @@ type HandlerOptions struct {
 	FeegrantKeeper  FeegrantKeeper
 	SignModeHandler authsigning.SignModeHandler
 	SigGasConsumer  func(meter sdk.GasMeter, sig signing.SignatureV2, params types.Params) error
+	// TxFeeChecker checks the fee of a tx. If it is nil, the minimum gas prices
+	// of the validator are enforced in CheckTx.
+	TxFeeChecker TxFeeChecker
+	// CircuitBreaker, if set, rejects txs containing disabled Msg types.
+	CircuitBreaker CircuitBreaker
 }
 
 // NewAnteHandler returns an AnteHandler that checks and increments sequence
@@ -41,7 +46,8 @@ func NewAnteHandler(options HandlerOptions) (sdk.AnteHandler, error) {
 	anteDecorators := []sdk.AnteDecorator{
 		NewSetUpContextDecorator(), // outermost AnteDecorator. SetUpContext must be called first
 		NewRejectExtensionOptionsDecorator(),
-		NewMempoolFeeDecorator(),
+		NewCircuitBreakerDecorator(options.CircuitBreaker),
+		NewMempoolFeeDecoratorWithChecker(options.TxFeeChecker),
 		NewValidateBasicDecorator(),
 		NewTxTimeoutHeightDecorator(),
 		NewValidateMemoDecorator(options.AccountKeeper),
@@ -0,0 +1,36 @@
+package ante
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// CircuitBreakerDecorator rejects txs containing a Msg whose type has been
+// disabled by the circuit breaker, so that they are rejected in CheckTx too.
+// Nested Msgs, such as those of an authz MsgExec, are rejected by the
+// MsgServiceRouter when they are dispatched. If the circuit breaker is nil,
+// all Msgs are allowed.
+type CircuitBreakerDecorator struct {
+	circuitBreaker CircuitBreaker
+}
+
+// NewCircuitBreakerDecorator creates a new CircuitBreakerDecorator
+func NewCircuitBreakerDecorator(cb CircuitBreaker) CircuitBreakerDecorator {
+	return CircuitBreakerDecorator{
+		circuitBreaker: cb,
+	}
+}
+
+// AnteHandle implements the AnteDecorator.AnteHandle method
+func (cbd CircuitBreakerDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	if cbd.circuitBreaker != nil {
+		for _, msg := range tx.GetMsgs() {
+			typeURL := sdk.MsgTypeURL(msg)
+			if !cbd.circuitBreaker.IsAllowed(ctx, typeURL) {
+				return ctx, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "%s is disabled by the circuit breaker", typeURL)
+			}
+		}
+	}
+
+	return next(ctx, tx, simulate)
+}
@@ -0,0 +1,41 @@
+package ante_test
+
+import (
+	"github.com/cosmos/cosmos-sdk/testutil/testdata"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth/ante"
+)
+
+type mockCircuitBreaker map[string]bool
+
+func (cb mockCircuitBreaker) IsAllowed(_ sdk.Context, typeURL string) bool {
+	return !cb[typeURL]
+}
+
+func (suite *AnteTestSuite) TestCircuitBreakerDecorator() {
+	suite.SetupTest(true) // setup
+	suite.txBuilder = suite.clientCtx.TxConfig.NewTxBuilder()
+
+	_, _, addr1 := testdata.KeyTestPubAddr()
+	msg := testdata.NewTestMsg(addr1)
+	suite.Require().NoError(suite.txBuilder.SetMsgs(msg))
+	theTx := suite.txBuilder.GetTx()
+
+	// a nil circuit breaker allows all msgs
+	antehandler := sdk.ChainAnteDecorators(ante.NewCircuitBreakerDecorator(nil))
+	_, err := antehandler(suite.ctx, theTx, false)
+	suite.Require().NoError(err)
+
+	breaker := mockCircuitBreaker{}
+	antehandler = sdk.ChainAnteDecorators(ante.NewCircuitBreakerDecorator(breaker))
+	_, err = antehandler(suite.ctx, theTx, false)
+	suite.Require().NoError(err)
+
+	// a disabled msg is rejected in CheckTx and DeliverTx
+	breaker[sdk.MsgTypeURL(msg)] = true
+	_, err = antehandler(suite.ctx.WithIsCheckTx(true), theTx, false)
+	suite.Require().ErrorIs(err, sdkerrors.ErrUnauthorized)
+	_, err = antehandler(suite.ctx.WithIsCheckTx(false), theTx, false)
+	suite.Require().ErrorIs(err, sdkerrors.ErrUnauthorized)
+}
@@ -18,3 +18,9 @@ type AccountKeeper interface {
 type FeegrantKeeper interface {
 	UseGrantedFees(ctx sdk.Context, granter, grantee sdk.AccAddress, fee sdk.Coins, msgs []sdk.Msg) error
 }
+
+// CircuitBreaker defines the expected circuit breaker, which reports whether
+// Msgs of a given type URL may be executed.
+type CircuitBreaker interface {
+	IsAllowed(ctx sdk.Context, typeURL string) bool
+}
@@ -8,46 +8,41 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/auth/types"
 )
 
-// MempoolFeeDecorator will check if the transaction's fee is at least as large
-// as the local validator's minimum gasFee (defined in validator config).
+// MempoolFeeDecorator checks the fee of the transaction with a TxFeeChecker.
+// The default TxFeeChecker will check if the transaction's fee is at least as
+// large as the local validator's minimum gasFee (defined in validator config)
+// when ctx.CheckTx = true.
 // If fee is too low, decorator returns error and tx is rejected from mempool.
-// Note this only applies when ctx.CheckTx = true
 // If fee is high enough or not CheckTx, then call next AnteHandler
 // CONTRACT: Tx must implement FeeTx to use MempoolFeeDecorator
-type MempoolFeeDecorator struct{}
+type MempoolFeeDecorator struct {
+	txFeeChecker TxFeeChecker
+}
 
 func NewMempoolFeeDecorator() MempoolFeeDecorator {
-	return MempoolFeeDecorator{}
+	return NewMempoolFeeDecoratorWithChecker(nil)
+}
+
+// NewMempoolFeeDecoratorWithChecker returns a new MempoolFeeDecorator with the
+// given TxFeeChecker, or with CheckTxFeeWithValidatorMinGasPrices if it is nil.
+func NewMempoolFeeDecoratorWithChecker(tfc TxFeeChecker) MempoolFeeDecorator {
+	if tfc == nil {
+		tfc = CheckTxFeeWithValidatorMinGasPrices
+	}
+
+	return MempoolFeeDecorator{
+		txFeeChecker: tfc,
+	}
 }
 
 func (mfd MempoolFeeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
-	feeTx, ok := tx.(sdk.FeeTx)
-	if !ok {
+	if _, ok := tx.(sdk.FeeTx); !ok {
 		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
 	}
 
-	feeCoins := feeTx.GetFee()
-	gas := feeTx.GetGas()
-
-	// Ensure that the provided fees meet a minimum threshold for the validator,
-	// if this is a CheckTx. This is only for local mempool purposes, and thus
-	// is only ran on check tx.
-	if ctx.IsCheckTx() && !simulate {
-		minGasPrices := ctx.MinGasPrices()
-		if !minGasPrices.IsZero() {
-			requiredFees := make(sdk.Coins, len(minGasPrices))
-
-			// Determine the required fees by multiplying each required minimum gas
-			// price by the gas limit, where fee = ceil(minGasPrice * gasLimit).
-			glDec := sdk.NewDec(int64(gas))
-			for i, gp := range minGasPrices {
-				fee := gp.Amount.Mul(glDec)
-				requiredFees[i] = sdk.NewCoin(gp.Denom, fee.Ceil().RoundInt())
-			}
-
-			if !feeCoins.IsAnyGTE(requiredFees) {
-				return ctx, sdkerrors.Wrapf(sdkerrors.ErrInsufficientFee, "insufficient fees; got: %s required: %s", feeCoins, requiredFees)
-			}
+	if !simulate {
+		if err := mfd.txFeeChecker(ctx, tx); err != nil {
+			return ctx, err
 		}
 	}
 
@@ -1,10 +1,13 @@
 package ante_test
 
 import (
+	"errors"
+
 	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	"github.com/cosmos/cosmos-sdk/simapp"
 	"github.com/cosmos/cosmos-sdk/testutil/testdata"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/cosmos/cosmos-sdk/x/auth/ante"
 )
 
@@ -60,6 +63,42 @@ func (suite *AnteTestSuite) TestEnsureMempoolFees() {
 	suite.Require().Nil(err, "Decorator should not have errored on fee higher than local gasPrice")
 }
 
+func (suite *AnteTestSuite) TestMempoolFeeDecoratorWithChecker() {
+	suite.SetupTest(true) // setup
+	suite.txBuilder = suite.clientCtx.TxConfig.NewTxBuilder()
+
+	priv1, _, addr1 := testdata.KeyTestPubAddr()
+	suite.Require().NoError(suite.txBuilder.SetMsgs(testdata.NewTestMsg(addr1)))
+	suite.txBuilder.SetFeeAmount(testdata.NewTestFeeAmount())
+	suite.txBuilder.SetGasLimit(testdata.NewTestGasLimit())
+
+	privs, accNums, accSeqs := []cryptotypes.PrivKey{priv1}, []uint64{0}, []uint64{0}
+	tx, err := suite.CreateTestTx(privs, accNums, accSeqs, suite.ctx.ChainID())
+	suite.Require().NoError(err)
+
+	// without a checker, the min gas prices are enforced in CheckTx
+	highGasPrice := []sdk.DecCoin{sdk.NewDecCoinFromDec("atom", sdk.NewDec(200).Quo(sdk.NewDec(100000)))}
+	antehandler := sdk.ChainAnteDecorators(ante.NewMempoolFeeDecoratorWithChecker(nil))
+	_, err = antehandler(suite.ctx.WithMinGasPrices(highGasPrice), tx, false)
+	suite.Require().ErrorIs(err, sdkerrors.ErrInsufficientFee)
+
+	// a custom checker can reject txs in any mode
+	checkerErr := errors.New("fee rejected")
+	calls := 0
+	antehandler = sdk.ChainAnteDecorators(ante.NewMempoolFeeDecoratorWithChecker(func(ctx sdk.Context, tx sdk.Tx) error {
+		calls++
+		return checkerErr
+	}))
+	_, err = antehandler(suite.ctx.WithIsCheckTx(false), tx, false)
+	suite.Require().ErrorIs(err, checkerErr)
+	suite.Require().Equal(1, calls)
+
+	// the checker is not called in simulations
+	_, err = antehandler(suite.ctx, tx, true)
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, calls)
+}
+
 func (suite *AnteTestSuite) TestDeductFees() {
 	suite.SetupTest(false) // setup
 	suite.txBuilder = suite.clientCtx.TxConfig.NewTxBuilder()
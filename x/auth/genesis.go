@@ -1,11 +1,24 @@
 package auth
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/auth/keeper"
 	"github.com/cosmos/cosmos-sdk/x/auth/types"
 )
 
+// The kinds of the records of the genesis stream of the auth module.
+const (
+	GenesisRecordParams  = "params"
+	GenesisRecordAccount = "account"
+)
+
 // InitGenesis - Init store state from genesis data
 //
 // CONTRACT: old coins from the FeeCollectionKeeper need to be transferred through
@@ -40,3 +53,166 @@ func ExportGenesis(ctx sdk.Context, ak keeper.AccountKeeper) *types.GenesisState
 
 	return types.NewGenesisState(params, genAccounts)
 }
+
+// InitGenesisStream initializes the auth module's state from a genesis stream
+// of params and account records. The account records must be sorted by
+// address, as written by ExportGenesisStream. Unlike InitGenesis, the accounts
+// keep their account numbers, which must then be unique, and the next account
+// number follows the highest of them.
+func InitGenesisStream(ctx sdk.Context, ak keeper.AccountKeeper, r module.GenesisStreamReader) error {
+	var nextAccNumber uint64
+	accs := newGenesisStreamAccounts()
+
+	for {
+		kind, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case GenesisRecordParams:
+			var params types.Params
+			if err := r.Decode(&params); err != nil {
+				return err
+			}
+			if err := params.Validate(); err != nil {
+				return err
+			}
+			ak.SetParams(ctx, params)
+
+		case GenesisRecordAccount:
+			var any codectypes.Any
+			if err := r.Decode(&any); err != nil {
+				return err
+			}
+			var acc types.GenesisAccount
+			if err := ak.GetCodec().UnpackAny(&any, &acc); err != nil {
+				return err
+			}
+			if err := acc.Validate(); err != nil {
+				return err
+			}
+			if err := accs.check(acc); err != nil {
+				return err
+			}
+			if ak.GetAccount(ctx, acc.GetAddress()) != nil {
+				return fmt.Errorf("duplicate account found in genesis state; address: %s", acc.GetAddress())
+			}
+
+			ak.SetAccount(ctx, acc)
+			if acc.GetAccountNumber() >= nextAccNumber {
+				nextAccNumber = acc.GetAccountNumber() + 1
+			}
+
+		default:
+			return fmt.Errorf("unknown auth genesis record %q", kind)
+		}
+	}
+
+	ak.SetNextAccountNumber(ctx, nextAccNumber)
+	ak.GetModuleAccount(ctx, types.FeeCollectorName)
+
+	return nil
+}
+
+// ValidateGenesisStream validates a genesis stream of the auth module like
+// InitGenesisStream, without initializing any state.
+func ValidateGenesisStream(unpacker codectypes.AnyUnpacker, r module.GenesisStreamReader) error {
+	accs := newGenesisStreamAccounts()
+
+	for {
+		kind, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case GenesisRecordParams:
+			var params types.Params
+			if err := r.Decode(&params); err != nil {
+				return err
+			}
+			if err := params.Validate(); err != nil {
+				return err
+			}
+
+		case GenesisRecordAccount:
+			var any codectypes.Any
+			if err := r.Decode(&any); err != nil {
+				return err
+			}
+			var acc types.GenesisAccount
+			if err := unpacker.UnpackAny(&any, &acc); err != nil {
+				return err
+			}
+			if err := acc.Validate(); err != nil {
+				return err
+			}
+			if err := accs.check(acc); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("unknown auth genesis record %q", kind)
+		}
+	}
+}
+
+// genesisStreamAccounts checks the accounts of a genesis stream. As they are
+// sorted by address, a duplicate address is detected by comparing every
+// address with the previous one, while the account numbers are kept in memory,
+// i.e. 8 bytes per account, to detect duplicate account numbers.
+type genesisStreamAccounts struct {
+	prevAddr   sdk.AccAddress
+	accNumbers map[uint64]struct{}
+}
+
+func newGenesisStreamAccounts() *genesisStreamAccounts {
+	return &genesisStreamAccounts{accNumbers: make(map[uint64]struct{})}
+}
+
+func (s *genesisStreamAccounts) check(acc types.GenesisAccount) error {
+	addr := acc.GetAddress()
+	switch cmp := bytes.Compare(addr, s.prevAddr); {
+	case cmp == 0:
+		return fmt.Errorf("duplicate account found in genesis state; address: %s", addr)
+	case cmp < 0:
+		return fmt.Errorf("accounts of the genesis stream are not sorted by address; address: %s", addr)
+	}
+	s.prevAddr = addr
+
+	accNumber := acc.GetAccountNumber()
+	if _, ok := s.accNumbers[accNumber]; ok {
+		return fmt.Errorf("duplicate account number found in genesis state; address: %s, account number: %d", addr, accNumber)
+	}
+	s.accNumbers[accNumber] = struct{}{}
+
+	return nil
+}
+
+// ExportGenesisStream writes the auth module's state to a genesis stream, with
+// a record per account, sorted by address.
+func ExportGenesisStream(ctx sdk.Context, ak keeper.AccountKeeper, w module.GenesisStreamWriter) error {
+	params := ak.GetParams(ctx)
+	if err := w.Write(GenesisRecordParams, &params); err != nil {
+		return err
+	}
+
+	var err error
+	ak.IterateAccounts(ctx, func(account types.AccountI) bool {
+		var any *codectypes.Any
+		if any, err = codectypes.NewAnyWithValue(account); err != nil {
+			return true
+		}
+		err = w.Write(GenesisRecordAccount, any)
+		return err != nil
+	})
+
+	return err
+}
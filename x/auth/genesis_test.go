@@ -0,0 +1,136 @@
+package auth_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+func TestGenesisStream(t *testing.T) {
+	app := simapp.Setup(true)
+	ctx := app.BaseApp.NewContext(true, tmproto.Header{})
+	app.AccountKeeper.SetParams(ctx, types.DefaultParams())
+
+	pubKey := secp256k1.GenPrivKey().PubKey()
+	acc := app.AccountKeeper.NewAccountWithAddress(ctx, sdk.AccAddress(pubKey.Address()))
+	require.NoError(t, acc.SetPubKey(pubKey))
+	require.NoError(t, acc.SetSequence(3))
+	app.AccountKeeper.SetAccount(ctx, acc)
+	app.AccountKeeper.GetModuleAccount(ctx, types.FeeCollectorName)
+	expected := auth.ExportGenesis(ctx, app.AccountKeeper)
+	nextAccNumber := app.AccountKeeper.GetNextAccountNumber(ctx)
+
+	for _, format := range []module.GenesisStreamFormat{module.GenesisStreamFormatJSON, module.GenesisStreamFormatProto} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := module.NewGenesisStreamWriter(&buf, app.AppCodec(), format)
+			require.NoError(t, err)
+			require.NoError(t, auth.ExportGenesisStream(ctx, app.AccountKeeper, w))
+
+			r, err := module.NewGenesisStreamReader(bytes.NewReader(buf.Bytes()), app.AppCodec(), format)
+			require.NoError(t, err)
+			require.NoError(t, auth.ValidateGenesisStream(app.AppCodec(), r))
+
+			// an account appears twice
+			dup := append(append([]byte{}, buf.Bytes()...), buf.Bytes()...)
+			r, err = module.NewGenesisStreamReader(bytes.NewReader(dup), app.AppCodec(), format)
+			require.NoError(t, err)
+			require.Error(t, auth.ValidateGenesisStream(app.AppCodec(), r))
+
+			newApp := simapp.Setup(true)
+			newCtx := newApp.BaseApp.NewContext(true, tmproto.Header{})
+			r, err = module.NewGenesisStreamReader(bytes.NewReader(buf.Bytes()), newApp.AppCodec(), format)
+			require.NoError(t, err)
+			require.NoError(t, auth.InitGenesisStream(newCtx, newApp.AccountKeeper, r))
+
+			require.Equal(t, expected, auth.ExportGenesis(newCtx, newApp.AccountKeeper))
+			require.Equal(t, nextAccNumber, newApp.AccountKeeper.GetNextAccountNumber(newCtx))
+
+			// the accounts already exist
+			r, err = module.NewGenesisStreamReader(bytes.NewReader(buf.Bytes()), newApp.AppCodec(), format)
+			require.NoError(t, err)
+			require.Error(t, auth.InitGenesisStream(newCtx, newApp.AccountKeeper, r))
+		})
+	}
+}
+
+func TestGenesisStreamAccountOrder(t *testing.T) {
+	app := simapp.Setup(true)
+
+	addrs := []sdk.AccAddress{
+		sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address()),
+		sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address()),
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i], addrs[j]) < 0 })
+
+	account := func(i int, accNumber uint64) types.GenesisAccount {
+		return types.NewBaseAccount(addrs[i], nil, accNumber, 0)
+	}
+	stream := func(accs ...types.GenesisAccount) []byte {
+		var buf bytes.Buffer
+		w, err := module.NewGenesisStreamWriter(&buf, app.AppCodec(), module.GenesisStreamFormatJSON)
+		require.NoError(t, err)
+		for _, acc := range accs {
+			any, err := codectypes.NewAnyWithValue(acc)
+			require.NoError(t, err)
+			require.NoError(t, w.Write(auth.GenesisRecordAccount, any))
+		}
+		return buf.Bytes()
+	}
+	reversed := func(bz []byte) []byte {
+		lines := bytes.SplitAfter(bz, []byte("\n"))
+		for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+			lines[i], lines[j] = lines[j], lines[i]
+		}
+		return bytes.Join(lines, nil)
+	}
+
+	testCases := []struct {
+		name   string
+		stream []byte
+		expErr string
+	}{
+		{"sorted", stream(account(0, 5), account(1, 2)), ""},
+		{"duplicate account number", stream(account(0, 5), account(1, 5)), "duplicate account number"},
+		{"duplicate address", stream(account(0, 5), account(0, 2)), "duplicate account found"},
+		{"not sorted", reversed(stream(account(0, 5), account(1, 2))), "not sorted by address"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := module.NewGenesisStreamReader(bytes.NewReader(tc.stream), app.AppCodec(), module.GenesisStreamFormatJSON)
+			require.NoError(t, err)
+			validateErr := auth.ValidateGenesisStream(app.AppCodec(), r)
+
+			newApp := simapp.Setup(true)
+			newCtx := newApp.BaseApp.NewContext(true, tmproto.Header{})
+			r, err = module.NewGenesisStreamReader(bytes.NewReader(tc.stream), newApp.AppCodec(), module.GenesisStreamFormatJSON)
+			require.NoError(t, err)
+			initErr := auth.InitGenesisStream(newCtx, newApp.AccountKeeper, r)
+
+			if tc.expErr == "" {
+				require.NoError(t, validateErr)
+				require.NoError(t, initErr)
+				// the fee collector takes the account number following the highest one
+				require.Equal(t, uint64(5), newApp.AccountKeeper.GetAccount(newCtx, addrs[0]).GetAccountNumber())
+				require.Equal(t, uint64(6), newApp.AccountKeeper.GetModuleAccount(newCtx, types.FeeCollectorName).GetAccountNumber())
+				return
+			}
+			require.Error(t, validateErr)
+			require.Contains(t, validateErr.Error(), tc.expErr)
+			require.Error(t, initErr)
+			require.Contains(t, initErr.Error(), tc.expErr)
+		})
+	}
+}
@@ -140,6 +140,13 @@ func (ak AccountKeeper) GetNextAccountNumber(ctx sdk.Context) uint64 {
 	return accNumber
 }
 
+// SetNextAccountNumber sets the global account number counter, that is the
+// number of the next account created.
+func (ak AccountKeeper) SetNextAccountNumber(ctx sdk.Context, accNumber uint64) {
+	bz := ak.cdc.MustMarshal(&gogotypes.UInt64Value{Value: accNumber})
+	ctx.KVStore(ak.key).Set(types.GlobalAccountNumberKey, bz)
+}
+
 // ValidatePermissions validates that the module account has been granted
 // permissions within its set of allowed permissions.
 func (ak AccountKeeper) ValidatePermissions(macc types.ModuleAccountI) error {
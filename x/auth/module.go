@@ -29,6 +29,9 @@ var (
 	_ module.AppModule           = AppModule{}
 	_ module.AppModuleBasic      = AppModuleBasic{}
 	_ module.AppModuleSimulation = AppModule{}
+
+	_ module.AppModuleGenesisStream      = AppModule{}
+	_ module.AppModuleBasicGenesisStream = AppModuleBasic{}
 )
 
 // AppModuleBasic defines the basic application module used by the auth module.
@@ -60,6 +63,12 @@ func (AppModuleBasic) ValidateGenesis(cdc codec.JSONCodec, config client.TxEncod
 	return types.ValidateGenesis(data)
 }
 
+// ValidateGenesisStream performs genesis stream validation for the auth
+// module.
+func (AppModuleBasic) ValidateGenesisStream(cdc codec.Codec, _ client.TxEncodingConfig, r module.GenesisStreamReader) error {
+	return ValidateGenesisStream(cdc, r)
+}
+
 // RegisterRESTRoutes registers the REST routes for the auth module.
 func (AppModuleBasic) RegisterRESTRoutes(clientCtx client.Context, rtr *mux.Router) {
 	rest.RegisterRoutes(clientCtx, rtr, types.StoreKey)
@@ -152,6 +161,21 @@ func (am AppModule) ExportGenesis(ctx sdk.Context, cdc codec.JSONCodec) json.Raw
 	return cdc.MustMarshalJSON(gs)
 }
 
+// InitGenesisStream performs genesis initialization for the auth module from
+// a genesis stream. It returns no validator updates.
+func (am AppModule) InitGenesisStream(ctx sdk.Context, r module.GenesisStreamReader) ([]abci.ValidatorUpdate, error) {
+	if err := InitGenesisStream(ctx, am.accountKeeper, r); err != nil {
+		return nil, err
+	}
+	return []abci.ValidatorUpdate{}, nil
+}
+
+// ExportGenesisStream writes the exported genesis state of the auth module to a
+// genesis stream.
+func (am AppModule) ExportGenesisStream(ctx sdk.Context, w module.GenesisStreamWriter) error {
+	return ExportGenesisStream(ctx, am.accountKeeper, w)
+}
+
 // ConsensusVersion implements AppModule/ConsensusVersion.
 func (AppModule) ConsensusVersion() uint64 { return 2 }
 
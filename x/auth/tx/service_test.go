@@ -169,6 +169,70 @@ func (s IntegrationTestSuite) TestSimulateTx_GRPCGateway() {
 	}
 }
 
+func (s IntegrationTestSuite) TestSimulateTx_StateOverrides() {
+	val := s.network.Validators[0]
+	s.Require().NoError(s.network.WaitForNextBlock())
+
+	// Simulate a tx of an account which does not exist yet.
+	_, pubKey, addr := testdata.KeyTestPubAddr()
+	txBuilder := val.ClientCtx.TxConfig.NewTxBuilder()
+	s.Require().NoError(
+		txBuilder.SetMsgs(&banktypes.MsgSend{
+			FromAddress: addr.String(),
+			ToAddress:   val.Address.String(),
+			Amount:      sdk.Coins{sdk.NewInt64Coin(s.cfg.BondDenom, 10)},
+		}),
+	)
+	txBuilder.SetFeeAmount(sdk.Coins{sdk.NewInt64Coin(s.cfg.BondDenom, 10)})
+	txBuilder.SetGasLimit(testdata.NewTestGasLimit())
+	s.Require().NoError(txBuilder.SetSignatures(signing.SignatureV2{
+		PubKey:   pubKey,
+		Data:     &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_DIRECT},
+		Sequence: 3,
+	}))
+	txBytes, err := val.ClientCtx.TxConfig.TxEncoder()(txBuilder.GetTx())
+	s.Require().NoError(err)
+
+	balanceOverride := tx.StateOverride{Sum: &tx.StateOverride_Balance{Balance: &tx.BalanceOverride{
+		Address:  addr.String(),
+		Balances: sdk.Coins{sdk.NewInt64Coin(s.cfg.BondDenom, 100)},
+	}}}
+	sequenceOverride := tx.StateOverride{Sum: &tx.StateOverride_AccountSequence{AccountSequence: &tx.AccountSequenceOverride{
+		Address:  addr.String(),
+		Sequence: 3,
+	}}}
+
+	testCases := []struct {
+		name      string
+		overrides []tx.StateOverride
+		expErr    bool
+		expErrMsg string
+	}{
+		{"no overrides", nil, true, "does not exist"},
+		{"balance override only", []tx.StateOverride{balanceOverride}, true, "account sequence mismatch"},
+		{"unknown store", []tx.StateOverride{{Sum: &tx.StateOverride_KvStore{KvStore: &tx.KVStoreOverride{StoreKey: "foo", Key: []byte("bar")}}}}, true, "unknown store"},
+		{"balance and sequence overrides", []tx.StateOverride{balanceOverride, sequenceOverride}, false, ""},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			res, err := s.queryClient.Simulate(context.Background(), &tx.SimulateRequest{TxBytes: txBytes, StateOverrides: tc.overrides})
+			if tc.expErr {
+				s.Require().Error(err)
+				s.Require().Contains(err.Error(), tc.expErrMsg)
+			} else {
+				s.Require().NoError(err)
+				s.Require().True(res.GetGasInfo().GetGasUsed() > 0)
+			}
+		})
+	}
+
+	// The overrides must not be persisted.
+	_, err = s.queryClient.Simulate(context.Background(), &tx.SimulateRequest{TxBytes: txBytes})
+	s.Require().Error(err)
+}
+
 func (s IntegrationTestSuite) TestGetTxEvents_GRPC() {
 	testCases := []struct {
 		name      string
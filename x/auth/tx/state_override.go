@@ -0,0 +1,81 @@
+package tx
+
+import (
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+// AccountKeeper defines the contract needed by NewStateOverrideHandler to
+// create accounts and override their sequences.
+type AccountKeeper interface {
+	GetAccount(ctx sdk.Context, addr sdk.AccAddress) types.AccountI
+	NewAccountWithAddress(ctx sdk.Context, addr sdk.AccAddress) types.AccountI
+	SetAccount(ctx sdk.Context, acc types.AccountI)
+}
+
+// BankKeeper defines the contract needed by NewStateOverrideHandler to
+// override the balances of accounts.
+type BankKeeper interface {
+	OverrideBalances(ctx sdk.Context, addr sdk.AccAddress, balances sdk.Coins) error
+}
+
+// NewStateOverrideHandler returns a baseapp.StateOverrideHandler which applies
+// the balance and account sequence overrides of a simulation. The overridden
+// accounts are created if they do not exist.
+func NewStateOverrideHandler(ak AccountKeeper, bk BankKeeper) baseapp.StateOverrideHandler {
+	return func(ctx sdk.Context, override txtypes.StateOverride) error {
+		switch o := override.Sum.(type) {
+		case *txtypes.StateOverride_Balance:
+			if o.Balance == nil {
+				return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "empty balance override")
+			}
+
+			acc, err := getOrCreateAccount(ctx, ak, o.Balance.Address)
+			if err != nil {
+				return err
+			}
+
+			return bk.OverrideBalances(ctx, acc.GetAddress(), o.Balance.Balances)
+
+		case *txtypes.StateOverride_AccountSequence:
+			if o.AccountSequence == nil {
+				return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "empty account sequence override")
+			}
+
+			acc, err := getOrCreateAccount(ctx, ak, o.AccountSequence.Address)
+			if err != nil {
+				return err
+			}
+
+			if err := acc.SetSequence(o.AccountSequence.Sequence); err != nil {
+				return err
+			}
+
+			ak.SetAccount(ctx, acc)
+			return nil
+
+		default:
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "unsupported state override %T", o)
+		}
+	}
+}
+
+// getOrCreateAccount returns the account with the given bech32 address, which
+// is created and stored first if it does not exist.
+func getOrCreateAccount(ctx sdk.Context, ak AccountKeeper, address string) (types.AccountI, error) {
+	addr, err := sdk.AccAddressFromBech32(address)
+	if err != nil {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid address %q: %s", address, err)
+	}
+
+	acc := ak.GetAccount(ctx, addr)
+	if acc == nil {
+		acc = ak.NewAccountWithAddress(ctx, addr)
+		ak.SetAccount(ctx, acc)
+	}
+
+	return acc, nil
+}
@@ -1,13 +1,25 @@
 package keeper
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/types/query"
 	"github.com/cosmos/cosmos-sdk/x/bank/types"
 )
 
+// The kinds of the records of the genesis stream of the bank module.
+const (
+	GenesisRecordParams        = "params"
+	GenesisRecordBalance       = "balance"
+	GenesisRecordSupply        = "supply"
+	GenesisRecordDenomMetadata = "denom_metadata"
+)
+
 // InitGenesis initializes the bank module's state from a given genesis state.
 func (k BaseKeeper) InitGenesis(ctx sdk.Context, genState *types.GenesisState) {
 	k.SetParams(ctx, genState.Params)
@@ -55,3 +67,228 @@ func (k BaseKeeper) ExportGenesis(ctx sdk.Context) *types.GenesisState {
 		k.GetAllDenomMetaData(ctx),
 	)
 }
+
+// InitGenesisStream initializes the bank module's state from a genesis stream
+// of params, balance, supply and denom metadata records. The balance records
+// must be sorted by address in the order of the store, as written by
+// ExportGenesisStream. The records are validated, and an error is returned if
+// an address has several balance records or the supply records do not match
+// the sum of the balances.
+func (k BaseKeeper) InitGenesisStream(ctx sdk.Context, r module.GenesisStreamReader) error {
+	var prevAddr sdk.AccAddress
+	totalSupply, expectedSupply := sdk.Coins{}, sdk.Coins{}
+
+	for {
+		kind, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case GenesisRecordParams:
+			var params types.Params
+			if err := r.Decode(&params); err != nil {
+				return err
+			}
+			if err := params.Validate(); err != nil {
+				return err
+			}
+			k.SetParams(ctx, params)
+
+		case GenesisRecordBalance:
+			var balance types.Balance
+			if err := r.Decode(&balance); err != nil {
+				return err
+			}
+			if err := balance.Validate(); err != nil {
+				return err
+			}
+
+			addr := balance.GetAddress()
+			if err := checkBalanceOrder(prevAddr, addr); err != nil {
+				return err
+			}
+			prevAddr = addr
+			if !k.GetAllBalances(ctx, addr).Empty() {
+				return fmt.Errorf("duplicate balance for address %s", balance.Address)
+			}
+			if err := k.initBalances(ctx, addr, balance.Coins); err != nil {
+				return fmt.Errorf("error on setting balances %w", err)
+			}
+
+			totalSupply = totalSupply.Add(balance.Coins...)
+
+		case GenesisRecordSupply:
+			var supply sdk.Coin
+			if err := r.Decode(&supply); err != nil {
+				return err
+			}
+			expectedSupply = expectedSupply.Add(supply)
+
+		case GenesisRecordDenomMetadata:
+			var meta types.Metadata
+			if err := r.Decode(&meta); err != nil {
+				return err
+			}
+			if err := meta.Validate(); err != nil {
+				return err
+			}
+			k.SetDenomMetaData(ctx, meta)
+
+		default:
+			return fmt.Errorf("unknown bank genesis record %q", kind)
+		}
+	}
+
+	if !expectedSupply.Empty() && !expectedSupply.IsEqual(totalSupply) {
+		return fmt.Errorf("genesis supply is incorrect, expected %v, got %v", expectedSupply, totalSupply)
+	}
+
+	for _, supply := range totalSupply {
+		k.setSupply(ctx, supply)
+	}
+
+	return nil
+}
+
+// ValidateGenesisStream validates a genesis stream of the bank module like
+// InitGenesisStream, without initializing any state.
+func ValidateGenesisStream(r module.GenesisStreamReader) error {
+	var prevAddr sdk.AccAddress
+	totalSupply, expectedSupply := sdk.Coins{}, sdk.Coins{}
+
+	for {
+		kind, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case GenesisRecordParams:
+			var params types.Params
+			if err := r.Decode(&params); err != nil {
+				return err
+			}
+			if err := params.Validate(); err != nil {
+				return err
+			}
+
+		case GenesisRecordBalance:
+			var balance types.Balance
+			if err := r.Decode(&balance); err != nil {
+				return err
+			}
+			if err := balance.Validate(); err != nil {
+				return err
+			}
+			addr := balance.GetAddress()
+			if err := checkBalanceOrder(prevAddr, addr); err != nil {
+				return err
+			}
+			prevAddr = addr
+
+			totalSupply = totalSupply.Add(balance.Coins...)
+
+		case GenesisRecordSupply:
+			var supply sdk.Coin
+			if err := r.Decode(&supply); err != nil {
+				return err
+			}
+			expectedSupply = expectedSupply.Add(supply)
+
+		case GenesisRecordDenomMetadata:
+			var meta types.Metadata
+			if err := r.Decode(&meta); err != nil {
+				return err
+			}
+			if err := meta.Validate(); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("unknown bank genesis record %q", kind)
+		}
+	}
+
+	if !expectedSupply.Empty() && !expectedSupply.IsEqual(totalSupply) {
+		return fmt.Errorf("genesis supply is incorrect, expected %v, got %v", expectedSupply, totalSupply)
+	}
+
+	return nil
+}
+
+// checkBalanceOrder checks that the balance of addr follows the balance of the
+// previous address of a genesis stream, in the order of the store, i.e. of the
+// length-prefixed addresses, so that a duplicate balance is detected without
+// keeping all the addresses in memory.
+func checkBalanceOrder(prevAddr, addr sdk.AccAddress) error {
+	if prevAddr == nil {
+		return nil
+	}
+
+	switch cmp := bytes.Compare(types.CreateAccountBalancesPrefix(addr), types.CreateAccountBalancesPrefix(prevAddr)); {
+	case cmp == 0:
+		return fmt.Errorf("duplicate balance for address %s", addr)
+	case cmp < 0:
+		return fmt.Errorf("balances of the genesis stream are not sorted by address; address: %s", addr)
+	}
+
+	return nil
+}
+
+// ExportGenesisStream writes the bank module's state to a genesis stream, with
+// a record per account balance, sorted by address, supply denom and denom
+// metadata.
+func (k BaseKeeper) ExportGenesisStream(ctx sdk.Context, w module.GenesisStreamWriter) error {
+	params := k.GetParams(ctx)
+	if err := w.Write(GenesisRecordParams, &params); err != nil {
+		return err
+	}
+
+	// the balances of an account are contiguous in the store
+	var (
+		balance types.Balance
+		err     error
+	)
+	k.IterateAllBalances(ctx, func(addr sdk.AccAddress, coin sdk.Coin) bool {
+		if address := addr.String(); address != balance.Address {
+			if len(balance.Coins) > 0 {
+				if err = w.Write(GenesisRecordBalance, &balance); err != nil {
+					return true
+				}
+			}
+			balance = types.Balance{Address: address}
+		}
+		balance.Coins = append(balance.Coins, coin)
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	if len(balance.Coins) > 0 {
+		if err := w.Write(GenesisRecordBalance, &balance); err != nil {
+			return err
+		}
+	}
+
+	k.IterateTotalSupply(ctx, func(coin sdk.Coin) bool {
+		err = w.Write(GenesisRecordSupply, &coin)
+		return err != nil
+	})
+	if err != nil {
+		return err
+	}
+
+	k.IterateAllDenomMetaData(ctx, func(meta types.Metadata) bool {
+		err = w.Write(GenesisRecordDenomMetadata, &meta)
+		return err != nil
+	})
+
+	return err
+}
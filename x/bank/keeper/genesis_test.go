@@ -1,8 +1,16 @@
 package keeper_test
 
 import (
+	"bytes"
+	"sort"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/x/bank/keeper"
 	"github.com/cosmos/cosmos-sdk/x/bank/types"
 	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
 )
@@ -112,3 +120,116 @@ func (suite *IntegrationTestSuite) TestTotalSupply() {
 		})
 	}
 }
+
+func (suite *IntegrationTestSuite) TestGenesisStream() {
+	app, ctx := suite.app, suite.ctx
+
+	app.BankKeeper.SetDenomMetaData(ctx, suite.getTestMetadata()[0])
+	expectedBalances, _ := suite.getTestBalancesAndSupply()
+	for i := range expectedBalances {
+		accAddr, err := sdk.AccAddressFromBech32(expectedBalances[i].Address)
+		suite.Require().NoError(err)
+		suite.Require().NoError(app.BankKeeper.MintCoins(ctx, minttypes.ModuleName, expectedBalances[i].Coins))
+		suite.Require().NoError(app.BankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, accAddr, expectedBalances[i].Coins))
+	}
+	expected := app.BankKeeper.ExportGenesis(ctx)
+
+	for _, format := range []module.GenesisStreamFormat{module.GenesisStreamFormatJSON, module.GenesisStreamFormatProto} {
+		suite.Run(string(format), func() {
+			var buf bytes.Buffer
+			w, err := module.NewGenesisStreamWriter(&buf, app.AppCodec(), format)
+			suite.Require().NoError(err)
+			suite.Require().NoError(app.BankKeeper.ExportGenesisStream(ctx, w))
+
+			r, err := module.NewGenesisStreamReader(bytes.NewReader(buf.Bytes()), app.AppCodec(), format)
+			suite.Require().NoError(err)
+			suite.Require().NoError(keeper.ValidateGenesisStream(r))
+
+			newApp := simapp.Setup(true)
+			newCtx := newApp.BaseApp.NewContext(true, tmproto.Header{})
+			r, err = module.NewGenesisStreamReader(bytes.NewReader(buf.Bytes()), newApp.AppCodec(), format)
+			suite.Require().NoError(err)
+			suite.Require().NoError(newApp.BankKeeper.InitGenesisStream(newCtx, r))
+			suite.Require().Equal(expected, newApp.BankKeeper.ExportGenesis(newCtx))
+
+			// the balances already exist
+			r, err = module.NewGenesisStreamReader(bytes.NewReader(buf.Bytes()), newApp.AppCodec(), format)
+			suite.Require().NoError(err)
+			suite.Require().Error(newApp.BankKeeper.InitGenesisStream(newCtx, r))
+		})
+	}
+
+	var buf bytes.Buffer
+	w, err := module.NewGenesisStreamWriter(&buf, app.AppCodec(), module.GenesisStreamFormatJSON)
+	suite.Require().NoError(err)
+	suite.Require().NoError(w.Write(keeper.GenesisRecordBalance, &expectedBalances[0]))
+	wrongSupply := sdk.NewInt64Coin("wrongcoin", 1)
+	suite.Require().NoError(w.Write(keeper.GenesisRecordSupply, &wrongSupply))
+
+	r, err := module.NewGenesisStreamReader(bytes.NewReader(buf.Bytes()), app.AppCodec(), module.GenesisStreamFormatJSON)
+	suite.Require().NoError(err)
+	suite.Require().EqualError(
+		keeper.ValidateGenesisStream(r),
+		"genesis supply is incorrect, expected 1wrongcoin, got 32testcoin1,34testcoin2",
+	)
+
+	newApp := simapp.Setup(true)
+	r, err = module.NewGenesisStreamReader(&buf, newApp.AppCodec(), module.GenesisStreamFormatJSON)
+	suite.Require().NoError(err)
+	suite.Require().EqualError(
+		newApp.BankKeeper.InitGenesisStream(newApp.BaseApp.NewContext(true, tmproto.Header{}), r),
+		"genesis supply is incorrect, expected 1wrongcoin, got 32testcoin1,34testcoin2",
+	)
+}
+
+func (suite *IntegrationTestSuite) TestGenesisStreamBalanceOrder() {
+	app := suite.app
+
+	balances, _ := suite.getTestBalancesAndSupply()
+	sort.Slice(balances, func(i, j int) bool {
+		return bytes.Compare(balances[i].GetAddress(), balances[j].GetAddress()) < 0
+	})
+
+	stream := func(balances ...types.Balance) []byte {
+		var buf bytes.Buffer
+		w, err := module.NewGenesisStreamWriter(&buf, app.AppCodec(), module.GenesisStreamFormatJSON)
+		suite.Require().NoError(err)
+		for i := range balances {
+			suite.Require().NoError(w.Write(keeper.GenesisRecordBalance, &balances[i]))
+		}
+		return buf.Bytes()
+	}
+
+	testCases := []struct {
+		name   string
+		stream []byte
+		expErr string
+	}{
+		{"sorted", stream(balances[0], balances[1]), ""},
+		{"duplicate address", stream(balances[0], balances[0]), "duplicate balance for address"},
+		{"not sorted", stream(balances[1], balances[0]), "not sorted by address"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			r, err := module.NewGenesisStreamReader(bytes.NewReader(tc.stream), app.AppCodec(), module.GenesisStreamFormatJSON)
+			suite.Require().NoError(err)
+			validateErr := keeper.ValidateGenesisStream(r)
+
+			newApp := simapp.Setup(true)
+			r, err = module.NewGenesisStreamReader(bytes.NewReader(tc.stream), newApp.AppCodec(), module.GenesisStreamFormatJSON)
+			suite.Require().NoError(err)
+			initErr := newApp.BankKeeper.InitGenesisStream(newApp.BaseApp.NewContext(true, tmproto.Header{}), r)
+
+			if tc.expErr == "" {
+				suite.Require().NoError(validateErr)
+				suite.Require().NoError(initErr)
+				return
+			}
+			suite.Require().Error(validateErr)
+			suite.Require().Contains(validateErr.Error(), tc.expErr)
+			suite.Require().Error(initErr)
+			suite.Require().Contains(initErr.Error(), tc.expErr)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/store/prefix"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/types/query"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	vestexported "github.com/cosmos/cosmos-sdk/x/auth/vesting/exported"
@@ -23,6 +24,8 @@ type Keeper interface {
 
 	InitGenesis(sdk.Context, *types.GenesisState)
 	ExportGenesis(sdk.Context) *types.GenesisState
+	InitGenesisStream(sdk.Context, module.GenesisStreamReader) error
+	ExportGenesisStream(sdk.Context, module.GenesisStreamWriter) error
 
 	GetSupply(ctx sdk.Context, denom string) sdk.Coin
 	GetPaginatedTotalSupply(ctx sdk.Context, pagination *query.PageRequest) (sdk.Coins, *query.PageResponse, error)
@@ -429,6 +432,34 @@ func (k BaseKeeper) BurnCoins(ctx sdk.Context, moduleName string, amounts sdk.Co
 	return nil
 }
 
+// OverrideBalances replaces all the balances of an account and adjusts the
+// supply accordingly. It bypasses the checks of sends, mints and burns, and is
+// only meant to be used on a branch of the state, e.g. to apply the state
+// overrides of a simulation.
+func (k BaseKeeper) OverrideBalances(ctx sdk.Context, addr sdk.AccAddress, balances sdk.Coins) error {
+	if !balances.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, balances.String())
+	}
+
+	for _, balance := range k.GetAllBalances(ctx, addr) {
+		k.setSupply(ctx, k.GetSupply(ctx, balance.Denom).Sub(balance))
+
+		if err := k.setBalance(ctx, addr, sdk.NewCoin(balance.Denom, sdk.ZeroInt())); err != nil {
+			return err
+		}
+	}
+
+	for _, balance := range balances {
+		k.setSupply(ctx, k.GetSupply(ctx, balance.Denom).Add(balance))
+
+		if err := k.setBalance(ctx, addr, balance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // setSupply sets the supply for the given coin
 func (k BaseKeeper) setSupply(ctx sdk.Context, coin sdk.Coin) {
 	intBytes, err := coin.Amount.Marshal()
@@ -303,6 +303,29 @@ func (suite *IntegrationTestSuite) TestSupply_BurnCoins() {
 	suite.Require().Equal(supplyAfterInflation.Sub(initCoins), supplyAfterBurn)
 }
 
+func (suite *IntegrationTestSuite) TestOverrideBalances() {
+	app, ctx := suite.app, suite.ctx
+	bankKeeper := app.BankKeeper.(keeper.BaseKeeper)
+	balances := sdk.NewCoins(newFooCoin(100), newBarCoin(50))
+
+	addr := sdk.AccAddress([]byte("addr1_______________"))
+	suite.Require().NoError(simapp.FundAccount(app.BankKeeper, ctx, addr, balances))
+	fooSupply := app.BankKeeper.GetSupply(ctx, fooDenom)
+	barSupply := app.BankKeeper.GetSupply(ctx, barDenom)
+
+	suite.Require().Error(bankKeeper.OverrideBalances(ctx, addr, sdk.Coins{sdk.Coin{Denom: fooDenom, Amount: sdk.NewInt(-10)}}))
+
+	overrides := sdk.NewCoins(newFooCoin(30))
+	suite.Require().NoError(bankKeeper.OverrideBalances(ctx, addr, overrides))
+	suite.Require().Equal(overrides, app.BankKeeper.GetAllBalances(ctx, addr))
+	suite.Require().True(fooSupply.SubAmount(sdk.NewInt(70)).IsEqual(app.BankKeeper.GetSupply(ctx, fooDenom)))
+	suite.Require().True(barSupply.SubAmount(sdk.NewInt(50)).IsEqual(app.BankKeeper.GetSupply(ctx, barDenom)))
+
+	suite.Require().NoError(bankKeeper.OverrideBalances(ctx, addr, sdk.Coins{}))
+	suite.Require().Empty(app.BankKeeper.GetAllBalances(ctx, addr))
+	suite.Require().True(fooSupply.SubAmount(sdk.NewInt(100)).IsEqual(app.BankKeeper.GetSupply(ctx, fooDenom)))
+}
+
 func (suite *IntegrationTestSuite) TestSendCoinsNewAccount() {
 	app, ctx := suite.app, suite.ctx
 	balances := sdk.NewCoins(newFooCoin(100), newBarCoin(50))
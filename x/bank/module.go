@@ -31,6 +31,9 @@ var (
 	_ module.AppModule           = AppModule{}
 	_ module.AppModuleBasic      = AppModuleBasic{}
 	_ module.AppModuleSimulation = AppModule{}
+
+	_ module.AppModuleGenesisStream      = AppModule{}
+	_ module.AppModuleBasicGenesisStream = AppModuleBasic{}
 )
 
 // AppModuleBasic defines the basic application module used by the bank module.
@@ -62,6 +65,12 @@ func (AppModuleBasic) ValidateGenesis(cdc codec.JSONCodec, _ client.TxEncodingCo
 	return data.Validate()
 }
 
+// ValidateGenesisStream performs genesis stream validation for the bank
+// module.
+func (AppModuleBasic) ValidateGenesisStream(_ codec.Codec, _ client.TxEncodingConfig, r module.GenesisStreamReader) error {
+	return keeper.ValidateGenesisStream(r)
+}
+
 // RegisterRESTRoutes registers the REST routes for the bank module.
 func (AppModuleBasic) RegisterRESTRoutes(clientCtx client.Context, rtr *mux.Router) {
 	rest.RegisterHandlers(clientCtx, rtr)
@@ -156,6 +165,21 @@ func (am AppModule) ExportGenesis(ctx sdk.Context, cdc codec.JSONCodec) json.Raw
 	return cdc.MustMarshalJSON(gs)
 }
 
+// InitGenesisStream performs genesis initialization for the bank module from
+// a genesis stream. It returns no validator updates.
+func (am AppModule) InitGenesisStream(ctx sdk.Context, r module.GenesisStreamReader) ([]abci.ValidatorUpdate, error) {
+	if err := am.keeper.InitGenesisStream(ctx, r); err != nil {
+		return nil, err
+	}
+	return []abci.ValidatorUpdate{}, nil
+}
+
+// ExportGenesisStream writes the exported genesis state of the bank module to a
+// genesis stream.
+func (am AppModule) ExportGenesisStream(ctx sdk.Context, w module.GenesisStreamWriter) error {
+	return am.keeper.ExportGenesisStream(ctx, w)
+}
+
 // ConsensusVersion implements AppModule/ConsensusVersion.
 func (AppModule) ConsensusVersion() uint64 { return 2 }
 
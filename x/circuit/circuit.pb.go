@@ -0,0 +1,712 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: cosmos/circuit/v1beta1/circuit.proto
+
+package circuit
+
+import (
+	fmt "fmt"
+	_ "github.com/gogo/protobuf/gogoproto"
+	proto "github.com/gogo/protobuf/proto"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// Params defines the parameters of the circuit module.
+type Params struct {
+	// authorized_accounts are the addresses of the accounts which may trip and
+	// reset the circuit breaker of any Msg type.
+	AuthorizedAccounts []string `protobuf:"bytes,1,rep,name=authorized_accounts,json=authorizedAccounts,proto3" json:"authorized_accounts,omitempty" yaml:"authorized_accounts"`
+}
+
+func (m *Params) Reset()      { *m = Params{} }
+func (*Params) ProtoMessage() {}
+func (*Params) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7cb755ccf3b4467, []int{0}
+}
+func (m *Params) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params.Merge(m, src)
+}
+func (m *Params) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params proto.InternalMessageInfo
+
+func (m *Params) GetAuthorizedAccounts() []string {
+	if m != nil {
+		return m.AuthorizedAccounts
+	}
+	return nil
+}
+
+// CircuitBreakerProposal is a gov Content type for disabling and re-enabling
+// Msg types.
+type CircuitBreakerProposal struct {
+	Title       string `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// trip_msg_type_urls are the type URLs of the Msgs to disable.
+	TripMsgTypeUrls []string `protobuf:"bytes,3,rep,name=trip_msg_type_urls,json=tripMsgTypeUrls,proto3" json:"trip_msg_type_urls,omitempty" yaml:"trip_msg_type_urls"`
+	// reset_msg_type_urls are the type URLs of the Msgs to re-enable.
+	ResetMsgTypeUrls []string `protobuf:"bytes,4,rep,name=reset_msg_type_urls,json=resetMsgTypeUrls,proto3" json:"reset_msg_type_urls,omitempty" yaml:"reset_msg_type_urls"`
+}
+
+func (m *CircuitBreakerProposal) Reset()      { *m = CircuitBreakerProposal{} }
+func (*CircuitBreakerProposal) ProtoMessage() {}
+func (*CircuitBreakerProposal) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e7cb755ccf3b4467, []int{1}
+}
+func (m *CircuitBreakerProposal) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CircuitBreakerProposal) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CircuitBreakerProposal.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CircuitBreakerProposal) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CircuitBreakerProposal.Merge(m, src)
+}
+func (m *CircuitBreakerProposal) XXX_Size() int {
+	return m.Size()
+}
+func (m *CircuitBreakerProposal) XXX_DiscardUnknown() {
+	xxx_messageInfo_CircuitBreakerProposal.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CircuitBreakerProposal proto.InternalMessageInfo
+
+func (m *CircuitBreakerProposal) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *CircuitBreakerProposal) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *CircuitBreakerProposal) GetTripMsgTypeUrls() []string {
+	if m != nil {
+		return m.TripMsgTypeUrls
+	}
+	return nil
+}
+
+func (m *CircuitBreakerProposal) GetResetMsgTypeUrls() []string {
+	if m != nil {
+		return m.ResetMsgTypeUrls
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Params)(nil), "cosmos.circuit.v1beta1.Params")
+	proto.RegisterType((*CircuitBreakerProposal)(nil), "cosmos.circuit.v1beta1.CircuitBreakerProposal")
+}
+
+func init() {
+	proto.RegisterFile("cosmos/circuit/v1beta1/circuit.proto", fileDescriptor_e7cb755ccf3b4467)
+}
+
+var fileDescriptor_e7cb755ccf3b4467 = []byte{
+	// 342 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0x31, 0x6b, 0xf2, 0x40,
+	0x18, 0xc7, 0x73, 0xaf, 0xbe, 0x52, 0xaf, 0x43, 0xcb, 0x29, 0x92, 0x0a, 0xbd, 0x48, 0x28, 0xc5,
+	0xa5, 0x06, 0xe9, 0xe6, 0xd4, 0xda, 0xad, 0x20, 0x95, 0xd0, 0x2e, 0x5d, 0xc2, 0x79, 0x1e, 0xf1,
+	0x30, 0xf1, 0xc2, 0xdd, 0xa5, 0xd4, 0x7e, 0x8a, 0x8e, 0x1d, 0xfd, 0x38, 0x1d, 0x1d, 0x3b, 0x49,
+	0xd1, 0xa5, 0xb3, 0xd0, 0xbd, 0x24, 0x31, 0x54, 0xac, 0x53, 0xf2, 0xfc, 0xee, 0xc7, 0xc3, 0xff,
+	0xe1, 0x0f, 0xcf, 0xa8, 0x50, 0xa1, 0x50, 0x0e, 0xe5, 0x92, 0xc6, 0x5c, 0x3b, 0x4f, 0xed, 0x01,
+	0xd3, 0xa4, 0x9d, 0xcf, 0xad, 0x48, 0x0a, 0x2d, 0x50, 0x2d, 0xb3, 0x5a, 0x39, 0xdd, 0x58, 0xf5,
+	0xaa, 0x2f, 0x7c, 0x91, 0x2a, 0x4e, 0xf2, 0x97, 0xd9, 0xb6, 0x07, 0x4b, 0x7d, 0x22, 0x49, 0xa8,
+	0xd0, 0x1d, 0xac, 0x90, 0x58, 0x8f, 0x84, 0xe4, 0x2f, 0x6c, 0xe8, 0x11, 0x4a, 0x45, 0x3c, 0xd1,
+	0xca, 0x04, 0x8d, 0x42, 0xb3, 0xdc, 0xc5, 0xeb, 0x85, 0x55, 0x9f, 0x92, 0x30, 0xe8, 0xd8, 0x7b,
+	0x24, 0xdb, 0x45, 0xbf, 0xf4, 0x7a, 0x03, 0x3b, 0xc5, 0xb7, 0x99, 0x65, 0xd8, 0xdf, 0x00, 0xd6,
+	0x6e, 0xb2, 0x28, 0x5d, 0xc9, 0xc8, 0x98, 0xc9, 0xbe, 0x14, 0x91, 0x50, 0x24, 0x40, 0x55, 0xf8,
+	0x5f, 0x73, 0x1d, 0x30, 0x13, 0x34, 0x40, 0xb3, 0xec, 0x66, 0x03, 0x6a, 0xc0, 0xc3, 0x21, 0x53,
+	0x54, 0xf2, 0x48, 0x73, 0x31, 0x31, 0xff, 0xa5, 0x6f, 0xdb, 0x08, 0xdd, 0x42, 0xa4, 0x25, 0x8f,
+	0xbc, 0x50, 0xf9, 0x9e, 0x9e, 0x46, 0xcc, 0x8b, 0x65, 0xa0, 0xcc, 0x42, 0x1a, 0xf4, 0x74, 0xbd,
+	0xb0, 0x4e, 0xb2, 0xa0, 0x7f, 0x1d, 0xdb, 0x3d, 0x4a, 0x60, 0x4f, 0xf9, 0xf7, 0xd3, 0x88, 0x3d,
+	0xc8, 0x40, 0xa1, 0x1e, 0xac, 0x48, 0xa6, 0x98, 0xde, 0x59, 0x56, 0xdc, 0xbd, 0x7a, 0x8f, 0x64,
+	0xbb, 0xc7, 0x29, 0xdd, 0x5a, 0xd7, 0x39, 0x48, 0x6e, 0xfe, 0x9a, 0x59, 0xa0, 0x7b, 0xf5, 0xbe,
+	0xc4, 0x60, 0xbe, 0xc4, 0xe0, 0x73, 0x89, 0xc1, 0xeb, 0x0a, 0x1b, 0xf3, 0x15, 0x36, 0x3e, 0x56,
+	0xd8, 0x78, 0x3c, 0xf7, 0xb9, 0x1e, 0xc5, 0x83, 0x16, 0x15, 0xa1, 0x93, 0x37, 0x9a, 0x7e, 0x2e,
+	0xd4, 0x70, 0xec, 0x3c, 0xe7, 0x75, 0x0e, 0x4a, 0x69, 0x43, 0x97, 0x3f, 0x03, 0x00, 0x2a, 0xff,
+	0x14, 0x2e, 0xf7, 0x01, 0x00, 0x00,
+}
+
+func (this *CircuitBreakerProposal) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*CircuitBreakerProposal)
+	if !ok {
+		that2, ok := that.(CircuitBreakerProposal)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Title != that1.Title {
+		return false
+	}
+	if this.Description != that1.Description {
+		return false
+	}
+	if len(this.TripMsgTypeUrls) != len(that1.TripMsgTypeUrls) {
+		return false
+	}
+	for i := range this.TripMsgTypeUrls {
+		if this.TripMsgTypeUrls[i] != that1.TripMsgTypeUrls[i] {
+			return false
+		}
+	}
+	if len(this.ResetMsgTypeUrls) != len(that1.ResetMsgTypeUrls) {
+		return false
+	}
+	for i := range this.ResetMsgTypeUrls {
+		if this.ResetMsgTypeUrls[i] != that1.ResetMsgTypeUrls[i] {
+			return false
+		}
+	}
+	return true
+}
+func (m *Params) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.AuthorizedAccounts) > 0 {
+		for iNdEx := len(m.AuthorizedAccounts) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.AuthorizedAccounts[iNdEx])
+			copy(dAtA[i:], m.AuthorizedAccounts[iNdEx])
+			i = encodeVarintCircuit(dAtA, i, uint64(len(m.AuthorizedAccounts[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CircuitBreakerProposal) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CircuitBreakerProposal) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CircuitBreakerProposal) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.ResetMsgTypeUrls) > 0 {
+		for iNdEx := len(m.ResetMsgTypeUrls) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ResetMsgTypeUrls[iNdEx])
+			copy(dAtA[i:], m.ResetMsgTypeUrls[iNdEx])
+			i = encodeVarintCircuit(dAtA, i, uint64(len(m.ResetMsgTypeUrls[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.TripMsgTypeUrls) > 0 {
+		for iNdEx := len(m.TripMsgTypeUrls) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TripMsgTypeUrls[iNdEx])
+			copy(dAtA[i:], m.TripMsgTypeUrls[iNdEx])
+			i = encodeVarintCircuit(dAtA, i, uint64(len(m.TripMsgTypeUrls[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Description) > 0 {
+		i -= len(m.Description)
+		copy(dAtA[i:], m.Description)
+		i = encodeVarintCircuit(dAtA, i, uint64(len(m.Description)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Title) > 0 {
+		i -= len(m.Title)
+		copy(dAtA[i:], m.Title)
+		i = encodeVarintCircuit(dAtA, i, uint64(len(m.Title)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintCircuit(dAtA []byte, offset int, v uint64) int {
+	offset -= sovCircuit(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *Params) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.AuthorizedAccounts) > 0 {
+		for _, s := range m.AuthorizedAccounts {
+			l = len(s)
+			n += 1 + l + sovCircuit(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *CircuitBreakerProposal) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Title)
+	if l > 0 {
+		n += 1 + l + sovCircuit(uint64(l))
+	}
+	l = len(m.Description)
+	if l > 0 {
+		n += 1 + l + sovCircuit(uint64(l))
+	}
+	if len(m.TripMsgTypeUrls) > 0 {
+		for _, s := range m.TripMsgTypeUrls {
+			l = len(s)
+			n += 1 + l + sovCircuit(uint64(l))
+		}
+	}
+	if len(m.ResetMsgTypeUrls) > 0 {
+		for _, s := range m.ResetMsgTypeUrls {
+			l = len(s)
+			n += 1 + l + sovCircuit(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovCircuit(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozCircuit(x uint64) (n int) {
+	return sovCircuit(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *Params) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCircuit
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Params: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Params: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthorizedAccounts", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCircuit
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AuthorizedAccounts = append(m.AuthorizedAccounts, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCircuit(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CircuitBreakerProposal) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCircuit
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CircuitBreakerProposal: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CircuitBreakerProposal: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Title", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCircuit
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Title = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Description", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCircuit
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Description = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TripMsgTypeUrls", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCircuit
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TripMsgTypeUrls = append(m.TripMsgTypeUrls, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResetMsgTypeUrls", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCircuit
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ResetMsgTypeUrls = append(m.ResetMsgTypeUrls, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCircuit(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthCircuit
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipCircuit(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowCircuit
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowCircuit
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowCircuit
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthCircuit
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupCircuit
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthCircuit
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthCircuit        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowCircuit          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupCircuit = fmt.Errorf("proto: unexpected end of group")
+)
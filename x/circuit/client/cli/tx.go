@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/version"
+	"github.com/cosmos/cosmos-sdk/x/circuit"
+	govcli "github.com/cosmos/cosmos-sdk/x/gov/client/cli"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+)
+
+const (
+	FlagTrip  = "trip"
+	FlagReset = "reset"
+)
+
+// GetTxCmd returns the transaction commands for this module
+func GetTxCmd() *cobra.Command {
+	circuitTxCmd := &cobra.Command{
+		Use:                        circuit.ModuleName,
+		Short:                      "Circuit breaker transaction subcommands",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	circuitTxCmd.AddCommand(
+		NewCmdTripCircuitBreaker(),
+		NewCmdResetCircuitBreaker(),
+	)
+
+	return circuitTxCmd
+}
+
+// NewCmdTripCircuitBreaker returns a CLI command handler for creating a
+// MsgTripCircuitBreaker transaction.
+func NewCmdTripCircuitBreaker() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trip [msg_type_url]...",
+		Short: "Disable the given Msg types",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Disable the given Msg types. The signer must be an authorized account.
+
+Example:
+$ %s tx %s trip /cosmos.bank.v1beta1.MsgSend --from mykey
+`, version.AppName, circuit.ModuleName),
+		),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			msg := circuit.NewMsgTripCircuitBreaker(clientCtx.GetFromAddress(), args)
+
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+		},
+	}
+
+	flags.AddTxFlagsToCmd(cmd)
+	return cmd
+}
+
+// NewCmdResetCircuitBreaker returns a CLI command handler for creating a
+// MsgResetCircuitBreaker transaction.
+func NewCmdResetCircuitBreaker() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reset [msg_type_url]...",
+		Short: "Re-enable the given Msg types",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Re-enable the given Msg types. The signer must be an authorized account.
+
+Example:
+$ %s tx %s reset /cosmos.bank.v1beta1.MsgSend --from mykey
+`, version.AppName, circuit.ModuleName),
+		),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			msg := circuit.NewMsgResetCircuitBreaker(clientCtx.GetFromAddress(), args)
+
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+		},
+	}
+
+	flags.AddTxFlagsToCmd(cmd)
+	return cmd
+}
+
+// NewCmdSubmitCircuitBreakerProposal implements a command handler for
+// submitting a circuit breaker proposal transaction.
+func NewCmdSubmitCircuitBreakerProposal() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "circuit-breaker (--trip [msg_type_url,...]) (--reset [msg_type_url,...]) [flags]",
+		Args:  cobra.NoArgs,
+		Short: "Submit a proposal to disable or re-enable Msg types",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Submit a proposal to disable or re-enable Msg types along with an initial deposit.
+
+Example:
+$ %s tx gov submit-proposal circuit-breaker --trip /cosmos.bank.v1beta1.MsgSend --title "..." --description "..." --deposit 1000stake --from mykey
+`, version.AppName),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			title, err := cmd.Flags().GetString(govcli.FlagTitle)
+			if err != nil {
+				return err
+			}
+
+			description, err := cmd.Flags().GetString(govcli.FlagDescription)
+			if err != nil {
+				return err
+			}
+
+			trip, err := cmd.Flags().GetStringSlice(FlagTrip)
+			if err != nil {
+				return err
+			}
+
+			reset, err := cmd.Flags().GetStringSlice(FlagReset)
+			if err != nil {
+				return err
+			}
+
+			depositStr, err := cmd.Flags().GetString(govcli.FlagDeposit)
+			if err != nil {
+				return err
+			}
+			deposit, err := sdk.ParseCoinsNormalized(depositStr)
+			if err != nil {
+				return err
+			}
+
+			content := circuit.NewCircuitBreakerProposal(title, description, trip, reset)
+			msg, err := govtypes.NewMsgSubmitProposal(content, deposit, clientCtx.GetFromAddress())
+			if err != nil {
+				return err
+			}
+
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+		},
+	}
+
+	cmd.Flags().String(govcli.FlagTitle, "", "title of proposal")
+	cmd.Flags().String(govcli.FlagDescription, "", "description of proposal")
+	cmd.Flags().String(govcli.FlagDeposit, "", "deposit of proposal")
+	cmd.Flags().StringSlice(FlagTrip, []string{}, "Msg type URLs to disable")
+	cmd.Flags().StringSlice(FlagReset, []string{}, "Msg type URLs to re-enable")
+
+	return cmd
+}
@@ -0,0 +1,10 @@
+package client
+
+import (
+	"github.com/cosmos/cosmos-sdk/x/circuit/client/cli"
+	"github.com/cosmos/cosmos-sdk/x/circuit/client/rest"
+	govclient "github.com/cosmos/cosmos-sdk/x/gov/client"
+)
+
+// ProposalHandler is the circuit breaker proposal handler.
+var ProposalHandler = govclient.NewProposalHandler(cli.NewCmdSubmitCircuitBreakerProposal, rest.ProposalRESTHandler)
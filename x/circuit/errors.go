@@ -0,0 +1,19 @@
+package circuit
+
+import (
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// Codes for circuit errors
+const (
+	DefaultCodespace = ModuleName
+)
+
+var (
+	// ErrUnknownMsgTypeURL error if a Msg type URL has no registered handler
+	ErrUnknownMsgTypeURL = sdkerrors.Register(DefaultCodespace, 2, "unknown msg type url")
+	// ErrProtectedMsgTypeURL error if a Msg type URL of the circuit module itself is to be disabled
+	ErrProtectedMsgTypeURL = sdkerrors.Register(DefaultCodespace, 3, "msg type url cannot be disabled")
+	// ErrUnauthorized error if the authority of a Msg is not an authorized account
+	ErrUnauthorized = sdkerrors.Register(DefaultCodespace, 4, "unauthorized account")
+)
@@ -0,0 +1,11 @@
+package circuit
+
+// circuit module events
+const (
+	EventTypeTripCircuitBreaker  = "trip_circuit_breaker"
+	EventTypeResetCircuitBreaker = "reset_circuit_breaker"
+
+	AttributeKeyMsgTypeURL = "msg_type_url"
+
+	AttributeValueCategory = ModuleName
+)
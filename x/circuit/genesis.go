@@ -0,0 +1,39 @@
+package circuit
+
+import (
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// NewGenesisState creates a new GenesisState object
+func NewGenesisState(params Params, disabledMsgTypeURLs []string) *GenesisState {
+	return &GenesisState{
+		Params:              params,
+		DisabledMsgTypeUrls: disabledMsgTypeURLs,
+	}
+}
+
+// DefaultGenesisState returns the default genesis state, in which no Msg type
+// is disabled.
+func DefaultGenesisState() *GenesisState {
+	return NewGenesisState(DefaultParams(), []string{})
+}
+
+// ValidateGenesis validates the provided genesis state to ensure the expected
+// invariants holds.
+func ValidateGenesis(data GenesisState) error {
+	if err := data.Params.Validate(); err != nil {
+		return err
+	}
+
+	if err := ValidateMsgTypeURLs(data.DisabledMsgTypeUrls); err != nil {
+		return err
+	}
+
+	for _, url := range data.DisabledMsgTypeUrls {
+		if IsProtectedMsgTypeURL(url) {
+			return sdkerrors.Wrap(ErrProtectedMsgTypeURL, url)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/circuit"
+)
+
+// InitGenesis initializes the circuit module's state from a given genesis state.
+func (k Keeper) InitGenesis(ctx sdk.Context, data *circuit.GenesisState) {
+	k.SetParams(ctx, data.Params)
+
+	store := ctx.KVStore(k.storeKey)
+	for _, url := range data.DisabledMsgTypeUrls {
+		store.Set(circuit.DisabledMsgTypeURLKey(url), []byte{})
+	}
+}
+
+// ExportGenesis returns the circuit module's exported genesis.
+func (k Keeper) ExportGenesis(ctx sdk.Context) *circuit.GenesisState {
+	return circuit.NewGenesisState(k.GetParams(ctx), k.GetDisabledMsgTypeURLs(ctx))
+}
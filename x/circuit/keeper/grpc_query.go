@@ -0,0 +1,35 @@
+package keeper
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/circuit"
+)
+
+var _ circuit.QueryServer = Keeper{}
+
+// Params returns the parameters of the circuit module.
+func (k Keeper) Params(c context.Context, req *circuit.QueryParamsRequest) (*circuit.QueryParamsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	return &circuit.QueryParamsResponse{Params: k.GetParams(ctx)}, nil
+}
+
+// DisabledList returns the type URLs of the disabled Msgs.
+func (k Keeper) DisabledList(c context.Context, req *circuit.QueryDisabledListRequest) (*circuit.QueryDisabledListResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	return &circuit.QueryDisabledListResponse{DisabledMsgTypeUrls: k.GetDisabledMsgTypeURLs(ctx)}, nil
+}
@@ -0,0 +1,126 @@
+package keeper
+
+import (
+	"fmt"
+
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store/prefix"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/circuit"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+)
+
+// Keeper manages the set of disabled Msg type URLs. It implements the
+// baseapp.CircuitBreaker interface and must be set on the MsgServiceRouter
+// with SetCircuitBreaker to take effect.
+type Keeper struct {
+	cdc        codec.BinaryCodec
+	storeKey   sdk.StoreKey
+	paramSpace paramtypes.Subspace
+	router     *baseapp.MsgServiceRouter
+}
+
+var _ baseapp.CircuitBreaker = Keeper{}
+
+// NewKeeper creates a circuit Keeper. The router is used to check that the
+// Msg types to disable exist.
+func NewKeeper(cdc codec.BinaryCodec, storeKey sdk.StoreKey, paramSpace paramtypes.Subspace, router *baseapp.MsgServiceRouter) Keeper {
+	// set KeyTable if it has not already been set
+	if !paramSpace.HasKeyTable() {
+		paramSpace = paramSpace.WithKeyTable(circuit.ParamKeyTable())
+	}
+
+	return Keeper{
+		cdc:        cdc,
+		storeKey:   storeKey,
+		paramSpace: paramSpace,
+		router:     router,
+	}
+}
+
+// Logger returns a module-specific logger.
+func (k Keeper) Logger(ctx sdk.Context) log.Logger {
+	return ctx.Logger().With("module", fmt.Sprintf("x/%s", circuit.ModuleName))
+}
+
+// GetParams returns the total set of circuit parameters.
+func (k Keeper) GetParams(ctx sdk.Context) (params circuit.Params) {
+	k.paramSpace.GetParamSet(ctx, &params)
+	return params
+}
+
+// SetParams sets the total set of circuit parameters.
+func (k Keeper) SetParams(ctx sdk.Context, params circuit.Params) {
+	k.paramSpace.SetParamSet(ctx, &params)
+}
+
+// IsAllowed implements the baseapp.CircuitBreaker interface. It returns false
+// if the given Msg type URL is disabled. The lookup does not consume gas, as
+// it is performed for every Msg.
+func (k Keeper) IsAllowed(ctx sdk.Context, msgTypeURL string) bool {
+	store := ctx.MultiStore().GetKVStore(k.storeKey)
+	return !store.Has(circuit.DisabledMsgTypeURLKey(msgTypeURL))
+}
+
+// TripCircuitBreaker disables the given Msg type URLs. Every type URL must
+// have a registered Msg service handler and must not belong to the circuit
+// module itself.
+func (k Keeper) TripCircuitBreaker(ctx sdk.Context, msgTypeURLs []string) error {
+	for _, url := range msgTypeURLs {
+		if circuit.IsProtectedMsgTypeURL(url) {
+			return sdkerrors.Wrap(circuit.ErrProtectedMsgTypeURL, url)
+		}
+		if k.router.HandlerByTypeURL(url) == nil {
+			return sdkerrors.Wrap(circuit.ErrUnknownMsgTypeURL, url)
+		}
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	for _, url := range msgTypeURLs {
+		store.Set(circuit.DisabledMsgTypeURLKey(url), []byte{})
+
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				circuit.EventTypeTripCircuitBreaker,
+				sdk.NewAttribute(circuit.AttributeKeyMsgTypeURL, url),
+			),
+		)
+	}
+
+	return nil
+}
+
+// ResetCircuitBreaker re-enables the given Msg type URLs. Type URLs which are
+// not disabled are ignored.
+func (k Keeper) ResetCircuitBreaker(ctx sdk.Context, msgTypeURLs []string) {
+	store := ctx.KVStore(k.storeKey)
+	for _, url := range msgTypeURLs {
+		store.Delete(circuit.DisabledMsgTypeURLKey(url))
+
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				circuit.EventTypeResetCircuitBreaker,
+				sdk.NewAttribute(circuit.AttributeKeyMsgTypeURL, url),
+			),
+		)
+	}
+}
+
+// GetDisabledMsgTypeURLs returns the disabled Msg type URLs in ascending order.
+func (k Keeper) GetDisabledMsgTypeURLs(ctx sdk.Context) []string {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), circuit.DisabledMsgTypeURLPrefix)
+
+	iterator := store.Iterator(nil, nil)
+	defer iterator.Close()
+
+	urls := []string{}
+	for ; iterator.Valid(); iterator.Next() {
+		urls = append(urls, string(iterator.Key()))
+	}
+
+	return urls
+}
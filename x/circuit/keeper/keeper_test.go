@@ -0,0 +1,136 @@
+package keeper_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/circuit"
+	"github.com/cosmos/cosmos-sdk/x/circuit/keeper"
+)
+
+var (
+	msgSendTypeURL      = sdk.MsgTypeURL(&banktypes.MsgSend{})
+	msgMultiSendTypeURL = sdk.MsgTypeURL(&banktypes.MsgMultiSend{})
+)
+
+type KeeperTestSuite struct {
+	suite.Suite
+
+	app     *simapp.SimApp
+	sdkCtx  sdk.Context
+	addrs   []sdk.AccAddress
+	msgSrvr circuit.MsgServer
+	ctx     context.Context
+	keeper  keeper.Keeper
+}
+
+func TestKeeperTestSuite(t *testing.T) {
+	suite.Run(t, new(KeeperTestSuite))
+}
+
+func (suite *KeeperTestSuite) SetupTest() {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{})
+
+	suite.app = app
+	suite.sdkCtx = ctx
+	suite.addrs = simapp.AddTestAddrsIncremental(app, ctx, 2, sdk.NewInt(30000000))
+	suite.ctx = sdk.WrapSDKContext(ctx)
+	suite.keeper = suite.app.CircuitKeeper
+	suite.msgSrvr = keeper.NewMsgServerImpl(suite.keeper)
+}
+
+func (suite *KeeperTestSuite) TestTripAndResetCircuitBreaker() {
+	suite.Require().True(suite.keeper.IsAllowed(suite.sdkCtx, msgSendTypeURL))
+	suite.Require().Empty(suite.keeper.GetDisabledMsgTypeURLs(suite.sdkCtx))
+
+	err := suite.keeper.TripCircuitBreaker(suite.sdkCtx, []string{msgSendTypeURL, msgMultiSendTypeURL})
+	suite.Require().NoError(err)
+	suite.Require().False(suite.keeper.IsAllowed(suite.sdkCtx, msgSendTypeURL))
+	suite.Require().False(suite.keeper.IsAllowed(suite.sdkCtx, msgMultiSendTypeURL))
+	suite.Require().Equal([]string{msgMultiSendTypeURL, msgSendTypeURL}, suite.keeper.GetDisabledMsgTypeURLs(suite.sdkCtx))
+
+	// the router rejects disabled msgs
+	msg := banktypes.NewMsgSend(suite.addrs[0], suite.addrs[1], sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1)))
+	_, err = suite.app.MsgServiceRouter().Handler(msg)(suite.sdkCtx, msg)
+	suite.Require().ErrorIs(err, sdkerrors.ErrUnauthorized)
+
+	suite.keeper.ResetCircuitBreaker(suite.sdkCtx, []string{msgSendTypeURL})
+	suite.Require().True(suite.keeper.IsAllowed(suite.sdkCtx, msgSendTypeURL))
+	suite.Require().False(suite.keeper.IsAllowed(suite.sdkCtx, msgMultiSendTypeURL))
+
+	_, err = suite.app.MsgServiceRouter().Handler(msg)(suite.sdkCtx, msg)
+	suite.Require().NoError(err)
+
+	// unknown and protected msg types cannot be disabled
+	err = suite.keeper.TripCircuitBreaker(suite.sdkCtx, []string{"/cosmos.foo.v1beta1.MsgFoo"})
+	suite.Require().ErrorIs(err, circuit.ErrUnknownMsgTypeURL)
+	err = suite.keeper.TripCircuitBreaker(suite.sdkCtx, []string{msgSendTypeURL, sdk.MsgTypeURL(&circuit.MsgResetCircuitBreaker{})})
+	suite.Require().ErrorIs(err, circuit.ErrProtectedMsgTypeURL)
+	suite.Require().True(suite.keeper.IsAllowed(suite.sdkCtx, msgSendTypeURL))
+}
+
+func (suite *KeeperTestSuite) TestMsgServer() {
+	authority := suite.addrs[0].String()
+
+	_, err := suite.msgSrvr.TripCircuitBreaker(suite.ctx, &circuit.MsgTripCircuitBreaker{Authority: authority, MsgTypeUrls: []string{msgSendTypeURL}})
+	suite.Require().ErrorIs(err, circuit.ErrUnauthorized)
+	suite.Require().True(suite.keeper.IsAllowed(suite.sdkCtx, msgSendTypeURL))
+
+	suite.keeper.SetParams(suite.sdkCtx, circuit.NewParams([]string{authority}))
+
+	_, err = suite.msgSrvr.TripCircuitBreaker(suite.ctx, &circuit.MsgTripCircuitBreaker{Authority: authority, MsgTypeUrls: []string{msgSendTypeURL}})
+	suite.Require().NoError(err)
+	suite.Require().False(suite.keeper.IsAllowed(suite.sdkCtx, msgSendTypeURL))
+
+	_, err = suite.msgSrvr.ResetCircuitBreaker(suite.ctx, &circuit.MsgResetCircuitBreaker{Authority: suite.addrs[1].String(), MsgTypeUrls: []string{msgSendTypeURL}})
+	suite.Require().ErrorIs(err, circuit.ErrUnauthorized)
+
+	_, err = suite.msgSrvr.ResetCircuitBreaker(suite.ctx, &circuit.MsgResetCircuitBreaker{Authority: authority, MsgTypeUrls: []string{msgSendTypeURL}})
+	suite.Require().NoError(err)
+	suite.Require().True(suite.keeper.IsAllowed(suite.sdkCtx, msgSendTypeURL))
+}
+
+func (suite *KeeperTestSuite) TestGRPCQuery() {
+	params := circuit.NewParams([]string{suite.addrs[0].String()})
+	suite.keeper.SetParams(suite.sdkCtx, params)
+	suite.Require().NoError(suite.keeper.TripCircuitBreaker(suite.sdkCtx, []string{msgSendTypeURL}))
+
+	paramsRes, err := suite.keeper.Params(suite.ctx, &circuit.QueryParamsRequest{})
+	suite.Require().NoError(err)
+	suite.Require().Equal(params, paramsRes.Params)
+
+	listRes, err := suite.keeper.DisabledList(suite.ctx, &circuit.QueryDisabledListRequest{})
+	suite.Require().NoError(err)
+	suite.Require().Equal([]string{msgSendTypeURL}, listRes.DisabledMsgTypeUrls)
+
+	_, err = suite.keeper.DisabledList(suite.ctx, nil)
+	suite.Require().Error(err)
+}
+
+func (suite *KeeperTestSuite) TestCircuitBreakerProposal() {
+	handler := keeper.NewCircuitBreakerProposalHandler(suite.keeper)
+
+	err := handler(suite.sdkCtx, circuit.NewCircuitBreakerProposal("title", "description", []string{msgSendTypeURL, msgMultiSendTypeURL}, nil))
+	suite.Require().NoError(err)
+	suite.Require().Equal([]string{msgMultiSendTypeURL, msgSendTypeURL}, suite.keeper.GetDisabledMsgTypeURLs(suite.sdkCtx))
+
+	err = handler(suite.sdkCtx, circuit.NewCircuitBreakerProposal("title", "description", nil, []string{msgMultiSendTypeURL}))
+	suite.Require().NoError(err)
+	suite.Require().Equal([]string{msgSendTypeURL}, suite.keeper.GetDisabledMsgTypeURLs(suite.sdkCtx))
+}
+
+func (suite *KeeperTestSuite) TestImportExportGenesis() {
+	genesis := circuit.NewGenesisState(circuit.NewParams([]string{suite.addrs[0].String()}), []string{msgMultiSendTypeURL, msgSendTypeURL})
+	suite.keeper.InitGenesis(suite.sdkCtx, genesis)
+
+	suite.Require().False(suite.keeper.IsAllowed(suite.sdkCtx, msgSendTypeURL))
+	suite.Require().Equal(genesis, suite.keeper.ExportGenesis(suite.sdkCtx))
+}
@@ -0,0 +1,53 @@
+package keeper
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/circuit"
+)
+
+type msgServer struct {
+	Keeper
+}
+
+// NewMsgServerImpl returns an implementation of the circuit MsgServer interface
+// for the provided Keeper.
+func NewMsgServerImpl(k Keeper) circuit.MsgServer {
+	return &msgServer{
+		Keeper: k,
+	}
+}
+
+var _ circuit.MsgServer = msgServer{}
+
+// TripCircuitBreaker disables the given Msg types if the authority is an
+// authorized account.
+func (k msgServer) TripCircuitBreaker(goCtx context.Context, msg *circuit.MsgTripCircuitBreaker) (*circuit.MsgTripCircuitBreakerResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if !k.GetParams(ctx).IsAuthorized(msg.Authority) {
+		return nil, sdkerrors.Wrap(circuit.ErrUnauthorized, msg.Authority)
+	}
+
+	if err := k.Keeper.TripCircuitBreaker(ctx, msg.MsgTypeUrls); err != nil {
+		return nil, err
+	}
+
+	return &circuit.MsgTripCircuitBreakerResponse{}, nil
+}
+
+// ResetCircuitBreaker re-enables the given Msg types if the authority is an
+// authorized account.
+func (k msgServer) ResetCircuitBreaker(goCtx context.Context, msg *circuit.MsgResetCircuitBreaker) (*circuit.MsgResetCircuitBreakerResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	if !k.GetParams(ctx).IsAuthorized(msg.Authority) {
+		return nil, sdkerrors.Wrap(circuit.ErrUnauthorized, msg.Authority)
+	}
+
+	k.Keeper.ResetCircuitBreaker(ctx, msg.MsgTypeUrls)
+
+	return &circuit.MsgResetCircuitBreakerResponse{}, nil
+}
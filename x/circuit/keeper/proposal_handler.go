@@ -0,0 +1,27 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/circuit"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+)
+
+// NewCircuitBreakerProposalHandler creates a governance handler to disable and
+// re-enable Msg types with a CircuitBreakerProposal.
+func NewCircuitBreakerProposalHandler(k Keeper) govtypes.Handler {
+	return func(ctx sdk.Context, content govtypes.Content) error {
+		switch c := content.(type) {
+		case *circuit.CircuitBreakerProposal:
+			return handleCircuitBreakerProposal(ctx, k, c)
+
+		default:
+			return sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unrecognized circuit proposal content type: %T", c)
+		}
+	}
+}
+
+func handleCircuitBreakerProposal(ctx sdk.Context, k Keeper, p *circuit.CircuitBreakerProposal) error {
+	k.ResetCircuitBreaker(ctx, p.ResetMsgTypeUrls)
+	return k.TripCircuitBreaker(ctx, p.TripMsgTypeUrls)
+}
@@ -0,0 +1,27 @@
+package circuit
+
+const (
+	// ModuleName is the module name constant used in many places
+	ModuleName = "circuit"
+
+	// StoreKey is the store key string for circuit
+	StoreKey = ModuleName
+
+	// RouterKey is the message route for circuit
+	RouterKey = ModuleName
+
+	// QuerierRoute is the querier route for circuit
+	QuerierRoute = ModuleName
+)
+
+var (
+	// DisabledMsgTypeURLPrefix is the prefix of the keys of the disabled Msg
+	// type URLs
+	DisabledMsgTypeURLPrefix = []byte{0x01}
+)
+
+// DisabledMsgTypeURLKey returns the key under which the given Msg type URL is
+// stored if it is disabled.
+func DisabledMsgTypeURLKey(msgTypeURL string) []byte {
+	return append(DisabledMsgTypeURLPrefix, msgTypeURL...)
+}
@@ -0,0 +1,124 @@
+package circuit
+
+import (
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+)
+
+var (
+	_, _ sdk.Msg            = &MsgTripCircuitBreaker{}, &MsgResetCircuitBreaker{}
+	_, _ legacytx.LegacyMsg = &MsgTripCircuitBreaker{}, &MsgResetCircuitBreaker{} // For amino support.
+)
+
+// NewMsgTripCircuitBreaker returns a message to disable the given Msg types.
+//nolint:interfacer
+func NewMsgTripCircuitBreaker(authority sdk.AccAddress, msgTypeURLs []string) *MsgTripCircuitBreaker {
+	return &MsgTripCircuitBreaker{Authority: authority.String(), MsgTypeUrls: msgTypeURLs}
+}
+
+// ValidateBasic implements the sdk.Msg interface.
+func (msg MsgTripCircuitBreaker) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Authority); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid authority address: %s", err)
+	}
+
+	if len(msg.MsgTypeUrls) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "msg type urls cannot be empty")
+	}
+
+	return ValidateMsgTypeURLs(msg.MsgTypeUrls)
+}
+
+// GetSigners returns the authority of the message.
+func (msg MsgTripCircuitBreaker) GetSigners() []sdk.AccAddress {
+	authority, err := sdk.AccAddressFromBech32(msg.Authority)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{authority}
+}
+
+// Type implements the LegacyMsg.Type method.
+func (msg MsgTripCircuitBreaker) Type() string {
+	return sdk.MsgTypeURL(&msg)
+}
+
+// Route implements the LegacyMsg.Route method.
+func (msg MsgTripCircuitBreaker) Route() string {
+	return sdk.MsgTypeURL(&msg)
+}
+
+// GetSignBytes implements the LegacyMsg.GetSignBytes method.
+func (msg MsgTripCircuitBreaker) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(&msg))
+}
+
+// NewMsgResetCircuitBreaker returns a message to re-enable the given Msg types.
+//nolint:interfacer
+func NewMsgResetCircuitBreaker(authority sdk.AccAddress, msgTypeURLs []string) *MsgResetCircuitBreaker {
+	return &MsgResetCircuitBreaker{Authority: authority.String(), MsgTypeUrls: msgTypeURLs}
+}
+
+// ValidateBasic implements the sdk.Msg interface.
+func (msg MsgResetCircuitBreaker) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Authority); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid authority address: %s", err)
+	}
+
+	if len(msg.MsgTypeUrls) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "msg type urls cannot be empty")
+	}
+
+	return ValidateMsgTypeURLs(msg.MsgTypeUrls)
+}
+
+// GetSigners returns the authority of the message.
+func (msg MsgResetCircuitBreaker) GetSigners() []sdk.AccAddress {
+	authority, err := sdk.AccAddressFromBech32(msg.Authority)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{authority}
+}
+
+// Type implements the LegacyMsg.Type method.
+func (msg MsgResetCircuitBreaker) Type() string {
+	return sdk.MsgTypeURL(&msg)
+}
+
+// Route implements the LegacyMsg.Route method.
+func (msg MsgResetCircuitBreaker) Route() string {
+	return sdk.MsgTypeURL(&msg)
+}
+
+// GetSignBytes implements the LegacyMsg.GetSignBytes method.
+func (msg MsgResetCircuitBreaker) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(&msg))
+}
+
+// ValidateMsgTypeURLs checks that the given Msg type URLs are well-formed and
+// unique.
+func ValidateMsgTypeURLs(msgTypeURLs []string) error {
+	seen := make(map[string]bool, len(msgTypeURLs))
+	for _, url := range msgTypeURLs {
+		if !strings.HasPrefix(url, "/") || len(url) == 1 {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid msg type url %s", url)
+		}
+		if seen[url] {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "duplicate msg type url %s", url)
+		}
+		seen[url] = true
+	}
+
+	return nil
+}
+
+// IsProtectedMsgTypeURL returns true if the Msg type URL belongs to the
+// circuit module itself. These Msgs cannot be disabled, so that the circuit
+// breaker can always be reset.
+func IsProtectedMsgTypeURL(msgTypeURL string) bool {
+	return strings.HasPrefix(msgTypeURL, "/cosmos.circuit.")
+}
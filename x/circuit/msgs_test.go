@@ -0,0 +1,60 @@
+package circuit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/circuit"
+)
+
+func TestMsgTripCircuitBreakerValidateBasic(t *testing.T) {
+	addr := sdk.AccAddress("authority")
+
+	cases := map[string]struct {
+		msg   *circuit.MsgTripCircuitBreaker
+		valid bool
+	}{
+		"valid":          {circuit.NewMsgTripCircuitBreaker(addr, []string{"/cosmos.bank.v1beta1.MsgSend"}), true},
+		"no authority":   {&circuit.MsgTripCircuitBreaker{MsgTypeUrls: []string{"/cosmos.bank.v1beta1.MsgSend"}}, false},
+		"no type urls":   {circuit.NewMsgTripCircuitBreaker(addr, nil), false},
+		"invalid url":    {circuit.NewMsgTripCircuitBreaker(addr, []string{"cosmos.bank.v1beta1.MsgSend"}), false},
+		"duplicate urls": {circuit.NewMsgTripCircuitBreaker(addr, []string{"/a", "/a"}), false},
+	}
+
+	for name, tc := range cases {
+		err := tc.msg.ValidateBasic()
+		if tc.valid {
+			require.NoError(t, err, name)
+			require.Equal(t, []sdk.AccAddress{addr}, tc.msg.GetSigners(), name)
+		} else {
+			require.Error(t, err, name)
+		}
+	}
+}
+
+func TestValidateGenesis(t *testing.T) {
+	require.NoError(t, circuit.ValidateGenesis(*circuit.DefaultGenesisState()))
+
+	addr := sdk.AccAddress("authority").String()
+	genesis := circuit.NewGenesisState(circuit.NewParams([]string{addr}), []string{"/cosmos.bank.v1beta1.MsgSend"})
+	require.NoError(t, circuit.ValidateGenesis(*genesis))
+
+	genesis.Params = circuit.NewParams([]string{addr, addr})
+	require.Error(t, circuit.ValidateGenesis(*genesis))
+
+	genesis.Params = circuit.NewParams([]string{"invalid"})
+	require.Error(t, circuit.ValidateGenesis(*genesis))
+
+	genesis.Params = circuit.DefaultParams()
+	genesis.DisabledMsgTypeUrls = []string{"/cosmos.circuit.v1beta1.MsgResetCircuitBreaker"}
+	require.ErrorIs(t, circuit.ValidateGenesis(*genesis), circuit.ErrProtectedMsgTypeURL)
+}
+
+func TestCircuitBreakerProposalValidateBasic(t *testing.T) {
+	require.NoError(t, circuit.NewCircuitBreakerProposal("title", "description", []string{"/a"}, []string{"/b"}).ValidateBasic())
+	require.Error(t, circuit.NewCircuitBreakerProposal("title", "description", nil, nil).ValidateBasic())
+	require.Error(t, circuit.NewCircuitBreakerProposal("title", "description", []string{"/a"}, []string{"/a"}).ValidateBasic())
+	require.Error(t, circuit.NewCircuitBreakerProposal("", "description", []string{"/a"}, nil).ValidateBasic())
+}
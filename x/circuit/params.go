@@ -0,0 +1,84 @@
+package circuit
+
+import (
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+)
+
+var (
+	// KeyAuthorizedAccounts is the parameter store key of the authorized accounts
+	KeyAuthorizedAccounts = []byte("AuthorizedAccounts")
+)
+
+var _ paramtypes.ParamSet = &Params{}
+
+// ParamKeyTable returns the parameter key table of the circuit module.
+func ParamKeyTable() paramtypes.KeyTable {
+	return paramtypes.NewKeyTable().RegisterParamSet(&Params{})
+}
+
+// NewParams creates a new Params object
+func NewParams(authorizedAccounts []string) Params {
+	return Params{
+		AuthorizedAccounts: authorizedAccounts,
+	}
+}
+
+// DefaultParams returns the default parameters, which authorize no accounts.
+func DefaultParams() Params {
+	return NewParams([]string{})
+}
+
+// ParamSetPairs implements the ParamSet interface and returns all the key/value
+// pairs of the circuit module's parameters.
+func (p *Params) ParamSetPairs() paramtypes.ParamSetPairs {
+	return paramtypes.ParamSetPairs{
+		paramtypes.NewParamSetPair(KeyAuthorizedAccounts, &p.AuthorizedAccounts, validateAuthorizedAccounts),
+	}
+}
+
+// Validate checks that the parameters have valid values.
+func (p Params) Validate() error {
+	return validateAuthorizedAccounts(p.AuthorizedAccounts)
+}
+
+// IsAuthorized returns true if the given address is an authorized account.
+func (p Params) IsAuthorized(address string) bool {
+	for _, account := range p.AuthorizedAccounts {
+		if account == address {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String implements the stringer interface.
+func (p Params) String() string {
+	out, _ := yaml.Marshal(p)
+	return string(out)
+}
+
+func validateAuthorizedAccounts(i interface{}) error {
+	v, ok := i.([]string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	seen := make(map[string]bool, len(v))
+	for _, account := range v {
+		if _, err := sdk.AccAddressFromBech32(account); err != nil {
+			return fmt.Errorf("invalid authorized account %s: %w", account, err)
+		}
+		if seen[account] {
+			return fmt.Errorf("duplicate authorized account %s", account)
+		}
+		seen[account] = true
+	}
+
+	return nil
+}
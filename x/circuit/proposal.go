@@ -0,0 +1,52 @@
+package circuit
+
+import (
+	"fmt"
+	"strings"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+)
+
+const (
+	// ProposalTypeCircuitBreaker defines the type for a CircuitBreakerProposal
+	ProposalTypeCircuitBreaker = "CircuitBreaker"
+)
+
+// Implements Proposal Interface
+var _ govtypes.Content = &CircuitBreakerProposal{}
+
+func init() {
+	govtypes.RegisterProposalType(ProposalTypeCircuitBreaker)
+	govtypes.RegisterProposalTypeCodec(&CircuitBreakerProposal{}, "cosmos-sdk/CircuitBreakerProposal")
+}
+
+// NewCircuitBreakerProposal creates a new circuit breaker proposal.
+func NewCircuitBreakerProposal(title, description string, tripMsgTypeURLs, resetMsgTypeURLs []string) *CircuitBreakerProposal {
+	return &CircuitBreakerProposal{title, description, tripMsgTypeURLs, resetMsgTypeURLs}
+}
+
+func (cbp *CircuitBreakerProposal) ProposalRoute() string { return RouterKey }
+func (cbp *CircuitBreakerProposal) ProposalType() string  { return ProposalTypeCircuitBreaker }
+
+// ValidateBasic runs basic stateless validity checks
+func (cbp *CircuitBreakerProposal) ValidateBasic() error {
+	if err := govtypes.ValidateAbstract(cbp); err != nil {
+		return err
+	}
+
+	if len(cbp.TripMsgTypeUrls) == 0 && len(cbp.ResetMsgTypeUrls) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "proposal must trip or reset at least one msg type url")
+	}
+
+	return ValidateMsgTypeURLs(append(append([]string{}, cbp.TripMsgTypeUrls...), cbp.ResetMsgTypeUrls...))
+}
+
+func (cbp CircuitBreakerProposal) String() string {
+	return fmt.Sprintf(`Circuit Breaker Proposal:
+  Title:       %s
+  Description: %s
+  Trip:        %s
+  Reset:       %s
+`, cbp.Title, cbp.Description, strings.Join(cbp.TripMsgTypeUrls, ", "), strings.Join(cbp.ResetMsgTypeUrls, ", "))
+}
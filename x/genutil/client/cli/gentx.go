@@ -93,7 +93,7 @@ $ %s gentx my-key-name 1000000stake --home=/path/to/home/dir --keyring-backend=o
 				return errors.Wrap(err, "failed to unmarshal genesis state")
 			}
 
-			if err = mbm.ValidateGenesis(cdc, txEncCfg, genesisState); err != nil {
+			if err = mbm.ValidateGenesisWithStreamDir(cdc, txEncCfg, genesisState, filepath.Dir(config.GenesisFile())); err != nil {
 				return errors.Wrap(err, "failed to validate genesis state")
 			}
 
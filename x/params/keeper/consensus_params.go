@@ -24,5 +24,8 @@ func ConsensusParamsKeyTable() types.KeyTable {
 		types.NewParamSetPair(
 			baseapp.ParamStoreKeyValidatorParams, tmproto.ValidatorParams{}, baseapp.ValidateValidatorParams,
 		),
+		types.NewParamSetPair(
+			baseapp.ParamStoreKeyStoreGasConfigs, []baseapp.StoreGasConfig{}, baseapp.ValidateStoreGasConfigs,
+		),
 	)
 }
@@ -7,6 +7,7 @@ import (
 
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
+	"github.com/cosmos/cosmos-sdk/baseapp"
 	"github.com/cosmos/cosmos-sdk/simapp"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
@@ -75,6 +76,23 @@ func (suite *HandlerTestSuite) TestProposalHandler() {
 			},
 			false,
 		},
+		{
+			"store gas configs",
+			testProposal(proposal.ParamChange{
+				Subspace: baseapp.Paramspace,
+				Key:      string(baseapp.ParamStoreKeyStoreGasConfigs),
+				Value:    `[{"store_key": "params", "gas_config": {"has_cost": "10", "read_cost_flat": "20"}}]`,
+			}),
+			func() {
+				var configs []baseapp.StoreGasConfig
+				subspace, _ := suite.app.ParamsKeeper.GetSubspace(baseapp.Paramspace)
+				subspace.Get(suite.ctx, baseapp.ParamStoreKeyStoreGasConfigs, &configs)
+				suite.Require().Equal([]baseapp.StoreGasConfig{
+					{StoreKey: "params", GasConfig: sdk.GasConfig{HasCost: 10, ReadCostFlat: 20}},
+				}, configs)
+			},
+			false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -70,16 +70,19 @@ func (s Subspace) WithKeyTable(table KeyTable) Subspace {
 
 // Returns a KVStore identical with ctx.KVStore(s.key).Prefix()
 func (s Subspace) kvStore(ctx sdk.Context) sdk.KVStore {
-	// append here is safe, appends within a function won't cause
-	// weird side effects when its singlethreaded
-	return prefix.NewStore(ctx.KVStore(s.key), append(s.name, '/'))
+	return prefix.NewStore(ctx.KVStore(s.key), s.storePrefix())
 }
 
 // Returns a transient store for modification
 func (s Subspace) transientStore(ctx sdk.Context) sdk.KVStore {
-	// append here is safe, appends within a function won't cause
-	// weird side effects when its singlethreaded
-	return prefix.NewStore(ctx.TransientStore(s.tkey), append(s.name, '/'))
+	return prefix.NewStore(ctx.TransientStore(s.tkey), s.storePrefix())
+}
+
+// storePrefix returns the prefix of the subspace within the parameter stores.
+// It does not append to the name in place, as the Subspace may be used by
+// concurrently executing transactions.
+func (s Subspace) storePrefix() []byte {
+	return append(append(make([]byte, 0, len(s.name)+1), s.name...), '/')
 }
 
 // Validate attempts to validate a parameter value by its key. If the key is not